@@ -0,0 +1,354 @@
+// Package backup reads and writes diagram backup archives: a zip holding one
+// JSON array per collection and a manifest.json that records the format
+// version, the diagrams inside and the SHA-256 checksum of every file.
+//
+// Read checks the manifest version and every checksum before decoding, so a
+// restore can reject an archive without having written anything.
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Version is the archive format written by this package; Read accepts
+// archives up to this version
+const Version = 1
+
+const (
+	ManifestFile = "manifest.json"
+	ContentType  = "application/zip"
+)
+
+// Collections in archive order; each is stored as <collection>.json
+const (
+	CollectionDiagrams       = "diagrams"
+	CollectionTables         = "tables"
+	CollectionRelationships  = "relationships"
+	CollectionDependencies   = "dependencies"
+	CollectionAreas          = "areas"
+	CollectionCustomTypes    = "custom_types"
+	CollectionNotes          = "notes"
+	CollectionDiagramFilters = "diagram_filters"
+)
+
+var collections = []string{
+	CollectionDiagrams,
+	CollectionTables,
+	CollectionRelationships,
+	CollectionDependencies,
+	CollectionAreas,
+	CollectionCustomTypes,
+	CollectionNotes,
+	CollectionDiagramFilters,
+}
+
+// Manifest describes an archive
+type Manifest struct {
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Diagrams  []DiagramEntry `json:"diagrams"`
+	Files     []FileEntry    `json:"files"`
+}
+
+// DiagramEntry identifies a diagram stored in the archive
+type DiagramEntry struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Revision int64  `json:"revision"`
+}
+
+// FileEntry describes one collection file
+type FileEntry struct {
+	Name       string `json:"name"`
+	Collection string `json:"collection"`
+	Count      int    `json:"count"`
+	SHA256     string `json:"sha256"`
+}
+
+// collectionData holds the entities of every collection
+type collectionData struct {
+	Diagrams       []domain.Diagram
+	Tables         []domain.Table
+	Relationships  []domain.Relationship
+	Dependencies   []domain.Dependency
+	Areas          []domain.Area
+	CustomTypes    []domain.CustomType
+	Notes          []domain.Note
+	DiagramFilters []domain.DiagramFilter
+}
+
+// items returns a pointer to the slice holding a collection, and its length
+func (d *collectionData) items(collection string) (interface{}, int) {
+	switch collection {
+	case CollectionDiagrams:
+		return &d.Diagrams, len(d.Diagrams)
+	case CollectionTables:
+		return &d.Tables, len(d.Tables)
+	case CollectionRelationships:
+		return &d.Relationships, len(d.Relationships)
+	case CollectionDependencies:
+		return &d.Dependencies, len(d.Dependencies)
+	case CollectionAreas:
+		return &d.Areas, len(d.Areas)
+	case CollectionCustomTypes:
+		return &d.CustomTypes, len(d.CustomTypes)
+	case CollectionNotes:
+		return &d.Notes, len(d.Notes)
+	case CollectionDiagramFilters:
+		return &d.DiagramFilters, len(d.DiagramFilters)
+	}
+	return nil, 0
+}
+
+// Write archives the snapshots
+func Write(snaps []*domain.DiagramSnapshot, now time.Time) ([]byte, error) {
+	data := &collectionData{}
+	manifest := Manifest{
+		Version:   Version,
+		CreatedAt: now.UTC(),
+		Diagrams:  make([]DiagramEntry, 0, len(snaps)),
+		Files:     make([]FileEntry, 0, len(collections)),
+	}
+	for _, s := range snaps {
+		manifest.Diagrams = append(manifest.Diagrams, DiagramEntry{ID: s.Diagram.ID, Name: s.Diagram.Name, Revision: s.Diagram.Revision})
+		data.Diagrams = append(data.Diagrams, *s.Diagram)
+		data.Tables = append(data.Tables, s.Tables...)
+		data.Relationships = append(data.Relationships, s.Relationships...)
+		data.Dependencies = append(data.Dependencies, s.Dependencies...)
+		data.Areas = append(data.Areas, s.Areas...)
+		data.CustomTypes = append(data.CustomTypes, s.CustomTypes...)
+		data.Notes = append(data.Notes, s.Notes...)
+		if s.Filter != nil {
+			data.DiagramFilters = append(data.DiagramFilters, *s.Filter)
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, content []byte) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	}
+
+	for _, c := range collections {
+		v, n := data.items(c)
+		content, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s: %v", c, err)
+		}
+		// Empty collections are written as [] rather than null
+		if n == 0 {
+			content = []byte("[]")
+		}
+		sum := sha256.Sum256(content)
+		name := c + ".json"
+		manifest.Files = append(manifest.Files, FileEntry{Name: name, Collection: c, Count: n, SHA256: hex.EncodeToString(sum[:])})
+		if err := write(name, content); err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := write(ManifestFile, content); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// maxFileSize bounds the uncompressed size of one archive file
+const maxFileSize = 256 << 20
+
+// Read verifies an archive and returns its snapshots in manifest order
+func Read(data []byte) (*Manifest, []*domain.DiagramSnapshot, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid backup archive: %v", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	read := func(name string) ([]byte, error) {
+		f, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid backup archive: missing %s", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup archive: %s: %v", name, err)
+		}
+		defer rc.Close()
+		content, err := io.ReadAll(io.LimitReader(rc, maxFileSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup archive: %s: %v", name, err)
+		}
+		if len(content) > maxFileSize {
+			return nil, fmt.Errorf("invalid backup archive: %s is too large", name)
+		}
+		return content, nil
+	}
+
+	// 1. The manifest version decides whether anything else can be read
+	content, err := read(ManifestFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid backup manifest: %v", err)
+	}
+	if manifest.Version < 1 || manifest.Version > Version {
+		return nil, nil, fmt.Errorf("unsupported backup version %d (this build reads versions up to %d)", manifest.Version, Version)
+	}
+
+	// 2. Every collection file must be listed, intact and hold what the manifest says
+	listed := make(map[string]FileEntry, len(manifest.Files))
+	for _, f := range manifest.Files {
+		listed[f.Collection] = f
+	}
+	col := &collectionData{}
+	for _, c := range collections {
+		entry, ok := listed[c]
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid backup manifest: no file for collection %s", c)
+		}
+		content, err := read(entry.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		sum := sha256.Sum256(content)
+		if hex.EncodeToString(sum[:]) != entry.SHA256 {
+			return nil, nil, fmt.Errorf("invalid backup archive: checksum mismatch for %s", entry.Name)
+		}
+		v, _ := col.items(c)
+		if err := json.Unmarshal(content, v); err != nil {
+			return nil, nil, fmt.Errorf("invalid backup archive: %s: %v", entry.Name, err)
+		}
+		if _, n := col.items(c); n != entry.Count {
+			return nil, nil, fmt.Errorf("invalid backup archive: %s holds %d items, the manifest lists %d", entry.Name, n, entry.Count)
+		}
+	}
+
+	snaps, err := group(&manifest, col)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &manifest, snaps, nil
+}
+
+// group assigns the entities to their diagrams
+func group(manifest *Manifest, col *collectionData) ([]*domain.DiagramSnapshot, error) {
+	byID := make(map[string]*domain.DiagramSnapshot, len(col.Diagrams))
+	for i := range col.Diagrams {
+		d := &col.Diagrams[i]
+		if d.ID == "" {
+			return nil, fmt.Errorf("invalid backup archive: diagram %q has no ID", d.Name)
+		}
+		if _, dup := byID[d.ID]; dup {
+			return nil, fmt.Errorf("invalid backup archive: diagram %s appears twice", d.ID)
+		}
+		byID[d.ID] = &domain.DiagramSnapshot{
+			Diagram:       d,
+			Tables:        make([]domain.Table, 0),
+			Relationships: make([]domain.Relationship, 0),
+			Dependencies:  make([]domain.Dependency, 0),
+			Areas:         make([]domain.Area, 0),
+			CustomTypes:   make([]domain.CustomType, 0),
+			Notes:         make([]domain.Note, 0),
+		}
+	}
+
+	owner := func(collection, diagramID string) (*domain.DiagramSnapshot, error) {
+		s, ok := byID[diagramID]
+		if !ok {
+			return nil, fmt.Errorf("invalid backup archive: %s entry belongs to unknown diagram %q", collection, diagramID)
+		}
+		return s, nil
+	}
+	for _, t := range col.Tables {
+		s, err := owner(CollectionTables, t.DiagramID)
+		if err != nil {
+			return nil, err
+		}
+		s.Tables = append(s.Tables, t)
+	}
+	for _, r := range col.Relationships {
+		s, err := owner(CollectionRelationships, r.DiagramID)
+		if err != nil {
+			return nil, err
+		}
+		s.Relationships = append(s.Relationships, r)
+	}
+	for _, d := range col.Dependencies {
+		s, err := owner(CollectionDependencies, d.DiagramID)
+		if err != nil {
+			return nil, err
+		}
+		s.Dependencies = append(s.Dependencies, d)
+	}
+	for _, a := range col.Areas {
+		s, err := owner(CollectionAreas, a.DiagramID)
+		if err != nil {
+			return nil, err
+		}
+		s.Areas = append(s.Areas, a)
+	}
+	for _, ct := range col.CustomTypes {
+		s, err := owner(CollectionCustomTypes, ct.DiagramID)
+		if err != nil {
+			return nil, err
+		}
+		s.CustomTypes = append(s.CustomTypes, ct)
+	}
+	for _, n := range col.Notes {
+		s, err := owner(CollectionNotes, n.DiagramID)
+		if err != nil {
+			return nil, err
+		}
+		s.Notes = append(s.Notes, n)
+	}
+	for i := range col.DiagramFilters {
+		f := &col.DiagramFilters[i]
+		s, err := owner(CollectionDiagramFilters, f.DiagramID)
+		if err != nil {
+			return nil, err
+		}
+		s.Filter = f
+	}
+
+	// Manifest order first, then any diagram the manifest does not list
+	snaps := make([]*domain.DiagramSnapshot, 0, len(byID))
+	seen := make(map[string]bool, len(byID))
+	for _, e := range manifest.Diagrams {
+		if s, ok := byID[e.ID]; ok && !seen[e.ID] {
+			snaps = append(snaps, s)
+			seen[e.ID] = true
+		}
+	}
+	for i := range col.Diagrams {
+		if id := col.Diagrams[i].ID; !seen[id] {
+			snaps = append(snaps, byID[id])
+			seen[id] = true
+		}
+	}
+	return snaps, nil
+}
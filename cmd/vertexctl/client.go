@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// client implements backend against the server's /api routes
+type client struct {
+	base string
+	http *http.Client
+}
+
+func newClient(server string) *client {
+	return &client{
+		base: strings.TrimRight(server, "/") + "/api",
+		http: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// do sends a request and returns the response body of a 2xx response; other
+// statuses become errors carrying the server's message, wrapping the domain
+// error that maps to the status
+func (c *client) do(ctx context.Context, method, path string, query url.Values, body []byte) ([]byte, http.Header, error) {
+	u := c.base + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return data, resp.Header, nil
+	}
+
+	var msg struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &msg) != nil || msg.Error == "" {
+		msg.Error = strings.TrimSpace(string(data))
+	}
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, nil, fmt.Errorf("%w (server: %s)", domain.ErrNotFound, msg.Error)
+	case http.StatusBadRequest:
+		return nil, nil, fmt.Errorf("%w (server: %s)", domain.ErrBadParamInput, msg.Error)
+	case http.StatusConflict:
+		return nil, nil, fmt.Errorf("%w (server: %s)", domain.ErrConflict, msg.Error)
+	}
+	return nil, nil, fmt.Errorf("server returned %s: %s", resp.Status, msg.Error)
+}
+
+func (c *client) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	data, _, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (c *client) GetAll(ctx context.Context, q domain.DiagramListQuery) (*domain.DiagramPage, error) {
+	query := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	set("cursor", q.Cursor)
+	set("sort", q.Sort)
+	set("order", q.Order)
+	set("search", q.Search)
+	set("match", q.SearchMode)
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	var page domain.DiagramPage
+	if err := c.getJSON(ctx, "/diagrams", query, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (c *client) GetOne(ctx context.Context, id string) (*domain.Diagram, error) {
+	var d domain.Diagram
+	if err := c.getJSON(ctx, "/diagrams/"+url.PathEscape(id), nil, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (c *client) Delete(ctx context.Context, id string) error {
+	_, _, err := c.do(ctx, http.MethodDelete, "/diagrams/"+url.PathEscape(id), nil, nil)
+	return err
+}
+
+func (c *client) Import(ctx context.Context, req domain.ImportRequest) (*domain.ImportResult, error) {
+	query := url.Values{}
+	if req.Name != "" {
+		query.Set("name", req.Name)
+	}
+	data, _, err := c.do(ctx, http.MethodPost, "/diagrams/import/"+url.PathEscape(req.Format), query, req.Data)
+	if err != nil {
+		return nil, err
+	}
+	var result domain.ImportResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *client) Export(ctx context.Context, req domain.ExportRequest) (*domain.ExportResult, error) {
+	query := url.Values{}
+	for k, v := range req.Options {
+		query.Set(k, v)
+	}
+	query.Set("download", "true")
+	path := "/diagrams/" + url.PathEscape(req.DiagramID) + "/export/" + url.PathEscape(req.Format)
+	data, header, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ExportResult{ContentType: header.Get("Content-Type"), FileName: attachmentName(header), Data: data}, nil
+}
+
+func (c *client) Lint(ctx context.Context, id string) (*domain.LintReport, error) {
+	var report domain.LintReport
+	if err := c.getJSON(ctx, "/diagrams/"+url.PathEscape(id)+"/lint", nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (c *client) Diff(ctx context.Context, fromID, toID string) (*domain.DiagramDiff, error) {
+	var d domain.DiagramDiff
+	if err := c.getJSON(ctx, "/diagrams/"+url.PathEscape(fromID)+"/diff/"+url.PathEscape(toID), nil, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (c *client) Backup(ctx context.Context, ids []string) (*domain.ExportResult, error) {
+	query := url.Values{}
+	if len(ids) > 0 {
+		query.Set("ids", strings.Join(ids, ","))
+	}
+	data, header, err := c.do(ctx, http.MethodGet, "/backup", query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ExportResult{ContentType: header.Get("Content-Type"), FileName: attachmentName(header), Data: data}, nil
+}
+
+func (c *client) Restore(ctx context.Context, req domain.RestoreRequest) (*domain.RestoreResult, error) {
+	query := url.Values{}
+	if req.Mode != "" {
+		query.Set("mode", req.Mode)
+	}
+	data, _, err := c.do(ctx, http.MethodPost, "/restore", query, req.Data)
+	if err != nil {
+		return nil, err
+	}
+	var result domain.RestoreResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// attachmentName reads the file name of a Content-Disposition header
+func attachmentName(header http.Header) string {
+	_, params, err := mime.ParseMediaType(header.Get("Content-Disposition"))
+	if err != nil {
+		return ""
+	}
+	return params["filename"]
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// listPageSize is the page size used to walk the whole list
+const listPageSize = 200
+
+func runList(ctx context.Context, b backend, args []string) error {
+	fs := newFlags("list")
+	search := fs.String("search", "", "only diagrams whose name contains text")
+	sortBy := fs.String("sort", domain.DiagramSortUpdatedAt, "name, updated_at or created_at")
+	order := fs.String("order", "", "asc or desc (default: desc for dates, asc for names)")
+	limit := fs.Int("limit", 0, "stop after n diagrams (default: all)")
+	fs.Parse(args)
+
+	q := domain.DiagramListQuery{Search: *search, Sort: *sortBy, Order: *order, Limit: listPageSize}
+	items := make([]domain.DiagramSummary, 0)
+	for {
+		page, err := b.GetAll(ctx, q)
+		if err != nil {
+			return err
+		}
+		items = append(items, page.Items...)
+		if page.NextCursor == "" || (*limit > 0 && len(items) >= *limit) {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+	if *limit > 0 && len(items) > *limit {
+		items = items[:*limit]
+	}
+
+	if jsonOutput {
+		return printJSON(items)
+	}
+	tw := newTable()
+	fmt.Fprintln(tw, "ID\tNAME\tTABLES\tRELATIONSHIPS\tUPDATED")
+	for _, d := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n", d.ID, d.Name, d.TableCount, d.RelationshipCount, formatTime(d.UpdatedAt))
+	}
+	return tw.Flush()
+}
+
+func runGet(ctx context.Context, b backend, args []string) error {
+	fs := newFlags("get")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("get needs exactly one diagram ID")
+	}
+
+	d, err := b.GetOne(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printJSON(d)
+	}
+
+	// The usecase merges typed entities into the content and the server sends
+	// them as JSON; a round trip reads both the same way
+	var content struct {
+		Tables        []domain.Table        `json:"tables"`
+		Relationships []domain.Relationship `json:"relationships"`
+		DatabaseType  string                `json:"databaseType"`
+	}
+	raw, err := json.Marshal(d.Content)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return err
+	}
+
+	tw := newTable()
+	fmt.Fprintf(tw, "ID:\t%s\n", d.ID)
+	fmt.Fprintf(tw, "Name:\t%s\n", d.Name)
+	fmt.Fprintf(tw, "Database:\t%s\n", orDash(content.DatabaseType))
+	fmt.Fprintf(tw, "Revision:\t%d\n", d.Revision)
+	fmt.Fprintf(tw, "Created:\t%s\n", formatTime(d.CreatedAt))
+	fmt.Fprintf(tw, "Updated:\t%s\n", formatTime(d.UpdatedAt))
+	fmt.Fprintf(tw, "Relationships:\t%d\n", len(content.Relationships))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	tw = newTable()
+	fmt.Fprintln(tw, "SCHEMA\tTABLE\tFIELDS\tINDEXES\tCOMMENT")
+	for _, t := range content.Tables {
+		name := t.Name
+		if t.IsView {
+			name += " (view)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n", orDash(t.Schema), name, len(t.Fields), len(t.Indexes), oneLine(t.Comments))
+	}
+	return tw.Flush()
+}
+
+func runDelete(ctx context.Context, b backend, args []string) error {
+	fs := newFlags("delete")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("delete needs at least one diagram ID")
+	}
+
+	deleted := make([]string, 0, fs.NArg())
+	for _, id := range fs.Args() {
+		if err := b.Delete(ctx, id); err != nil {
+			return fmt.Errorf("deleting %s: %w", id, err)
+		}
+		deleted = append(deleted, id)
+		if !jsonOutput {
+			fmt.Printf("moved %s to the trash\n", id)
+		}
+	}
+	if jsonOutput {
+		return printJSON(map[string][]string{"deleted": deleted})
+	}
+	return nil
+}
+
+// importFormats maps file extensions to import formats
+var importFormats = map[string]string{
+	".sql":    domain.FormatSQL,
+	".ddl":    domain.FormatSQL,
+	".dbml":   domain.FormatDBML,
+	".prisma": domain.FormatPrisma,
+}
+
+func runImport(ctx context.Context, b backend, args []string) error {
+	fs := newFlags("import")
+	format := fs.String("format", "", "sql, dbml or prisma (default: from the file extension)")
+	name := fs.String("name", "", "diagram name (default: the name in the file, or the file name)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("import needs exactly one file")
+	}
+
+	path := fs.Arg(0)
+	data, err := readInput(path)
+	if err != nil {
+		return err
+	}
+	if *format == "" {
+		if *format = importFormats[strings.ToLower(filepath.Ext(path))]; *format == "" {
+			return fmt.Errorf("cannot tell the format of %s; use -format", path)
+		}
+	}
+	if *name == "" && *format == domain.FormatSQL && path != "-" {
+		// SQL scripts carry no diagram name
+		*name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	result, err := b.Import(ctx, domain.ImportRequest{Format: *format, Name: *name, Data: data})
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printJSON(result)
+	}
+	for _, w := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	fmt.Printf("created %s  %s (%d tables, %d relationships, %d custom types)\n",
+		result.Diagram.ID, result.Diagram.Name, result.Tables, result.Relationships, result.CustomTypes)
+	return nil
+}
+
+// optionFlags collects repeated -opt key=value flags
+type optionFlags map[string]string
+
+func (o optionFlags) String() string { return "" }
+
+func (o optionFlags) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	o[k] = v
+	return nil
+}
+
+func runExport(ctx context.Context, b backend, args []string) error {
+	fs := newFlags("export")
+	format := fs.String("format", domain.FormatSQL, "export format, e.g. sql, mermaid, dbml, prisma, svg")
+	dialect := fs.String("dialect", "", "SQL dialect (default: the diagram's database type)")
+	out := fs.String("o", "", "output file (default: stdout)")
+	opts := optionFlags{}
+	fs.Var(opts, "opt", "format option as key=value; may be repeated")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("export needs exactly one diagram ID")
+	}
+	if *dialect != "" {
+		opts["dialect"] = *dialect
+	}
+
+	result, err := b.Export(ctx, domain.ExportRequest{DiagramID: fs.Arg(0), Format: *format, Options: opts})
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err := os.Stdout.Write(result.Data)
+		return err
+	}
+	if err := os.WriteFile(*out, result.Data, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s (%d bytes)\n", *out, len(result.Data))
+	return nil
+}
+
+func runLint(ctx context.Context, b backend, args []string) error {
+	fs := newFlags("lint")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("lint needs exactly one diagram ID")
+	}
+
+	report, err := b.Lint(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		tw := newTable()
+		fmt.Fprintln(tw, "SEVERITY\tRULE\tTABLE\tFIELD\tMESSAGE")
+		for _, issue := range report.Issues {
+			table := "-"
+			if issue.Table != nil {
+				table = domain.QualifiedName(issue.Table.Schema, issue.Table.Name)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", issue.Severity, issue.Rule, table, orDash(issue.Field), issue.Message)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\n%d errors, %d warnings, %d infos\n", report.Errors, report.Warnings, report.Infos)
+	}
+	if report.Errors > 0 {
+		return exitStatus(1)
+	}
+	return nil
+}
+
+func runDiff(ctx context.Context, b backend, args []string) error {
+	fs := newFlags("diff")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return fmt.Errorf("diff needs two diagram IDs")
+	}
+
+	d, err := b.Diff(ctx, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		if err := printJSON(d); err != nil {
+			return err
+		}
+	} else {
+		printDiff(os.Stdout, d)
+	}
+	if !d.Identical {
+		return exitStatus(1)
+	}
+	return nil
+}
+
+// printDiff writes a diff as +, - and ~ lines grouped by table
+func printDiff(w io.Writer, d *domain.DiagramDiff) {
+	if d.Identical {
+		fmt.Fprintln(w, "no schema changes")
+		return
+	}
+	for _, t := range d.TablesAdded {
+		fmt.Fprintf(w, "+ table %s\n", domain.QualifiedName(t.Schema, t.Name))
+	}
+	for _, t := range d.TablesRemoved {
+		fmt.Fprintf(w, "- table %s\n", domain.QualifiedName(t.Schema, t.Name))
+	}
+	for _, t := range d.TablesChanged {
+		fmt.Fprintf(w, "~ table %s\n", domain.QualifiedName(t.Schema, t.Name))
+		for _, c := range t.Changes {
+			fmt.Fprintf(w, "    ~ %s\n", c)
+		}
+		for _, f := range t.FieldsAdded {
+			fmt.Fprintf(w, "    + field %s\n", f)
+		}
+		for _, f := range t.FieldsRemoved {
+			fmt.Fprintf(w, "    - field %s\n", f)
+		}
+		for _, f := range t.FieldsChanged {
+			fmt.Fprintf(w, "    ~ field %s: %s\n", f.Name, strings.Join(f.Changes, "; "))
+		}
+		for _, idx := range t.IndexesAdded {
+			fmt.Fprintf(w, "    + index %s\n", idx)
+		}
+		for _, idx := range t.IndexesRemoved {
+			fmt.Fprintf(w, "    - index %s\n", idx)
+		}
+	}
+	for _, r := range d.RelationshipsAdded {
+		fmt.Fprintf(w, "+ relationship %s\n", r)
+	}
+	for _, r := range d.RelationshipsRemoved {
+		fmt.Fprintf(w, "- relationship %s\n", r)
+	}
+	for _, ct := range d.CustomTypesAdded {
+		fmt.Fprintf(w, "+ type %s\n", ct)
+	}
+	for _, ct := range d.CustomTypesRemoved {
+		fmt.Fprintf(w, "- type %s\n", ct)
+	}
+	for _, ct := range d.CustomTypesChanged {
+		fmt.Fprintf(w, "~ type %s\n", ct)
+	}
+}
+
+func runBackup(ctx context.Context, b backend, args []string) error {
+	fs := newFlags("backup")
+	out := fs.String("o", "", "output file (default: the archive's own name)")
+	fs.Parse(args)
+
+	result, err := b.Backup(ctx, fs.Args())
+	if err != nil {
+		return err
+	}
+	name := *out
+	if name == "" {
+		name = result.FileName
+	}
+	if name == "" {
+		name = "vertex-backup-" + time.Now().UTC().Format("20060102-150405") + ".zip"
+	}
+	if err := os.WriteFile(name, result.Data, 0o644); err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printJSON(map[string]interface{}{"file": name, "bytes": len(result.Data)})
+	}
+	fmt.Printf("wrote %s (%d bytes)\n", name, len(result.Data))
+	return nil
+}
+
+func runRestore(ctx context.Context, b backend, args []string) error {
+	fs := newFlags("restore")
+	mode := fs.String("mode", domain.RestoreSkipExisting, "keep, regenerate or skip existing IDs")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("restore needs exactly one archive file")
+	}
+
+	data, err := readInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	result, err := b.Restore(ctx, domain.RestoreRequest{Data: data, Mode: *mode})
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return printJSON(result)
+	}
+	tw := newTable()
+	fmt.Fprintln(tw, "STATUS\tSOURCE ID\tID\tNAME\tTABLES\tRELATIONSHIPS")
+	for _, d := range result.Restored {
+		fmt.Fprintf(tw, "restored\t%s\t%s\t%s\t%d\t%d\n", d.SourceID, d.ID, d.Name, d.Tables, d.Relationships)
+	}
+	for _, d := range result.Skipped {
+		fmt.Fprintf(tw, "skipped\t%s\t-\t%s\t-\t-\n", d.SourceID, d.Name)
+	}
+	return tw.Flush()
+}
+
+// readInput reads a file, or stdin for "-"
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,166 @@
+// Command vertexctl manages Vertex diagrams from the command line.
+//
+// With -server (or VERTEX_SERVER) it talks to a running server over HTTP;
+// otherwise it works directly against the MongoDB database configured for
+// the server (.env, MONGO_URI and DB_NAME).
+//
+//	vertexctl list [-search text] [-sort name|updated_at|created_at] [-order asc|desc] [-limit n]
+//	vertexctl get id
+//	vertexctl delete id...
+//	vertexctl import [-format sql|dbml|prisma] [-name name] file
+//	vertexctl export [-format sql|mermaid|dbml|...] [-dialect name] [-o file] [-opt key=value]... id
+//	vertexctl lint id
+//	vertexctl diff from-id to-id
+//	vertexctl backup [-o file] [id...]
+//	vertexctl restore [-mode keep|regenerate|skip] file
+//
+// Every command prints a table, or JSON with -json. lint exits with status 1
+// when it finds errors and diff when the diagrams differ, like diff(1).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/infrastructure/config"
+	"github.com/iots1/vertex-diagram/infrastructure/database"
+	"github.com/iots1/vertex-diagram/repository"
+	"github.com/iots1/vertex-diagram/usecase"
+)
+
+// backend is the part of domain.DiagramUsecase the commands use; the
+// usecase itself serves direct mode and client serves HTTP mode
+type backend interface {
+	GetAll(ctx context.Context, q domain.DiagramListQuery) (*domain.DiagramPage, error)
+	GetOne(ctx context.Context, id string) (*domain.Diagram, error)
+	Delete(ctx context.Context, id string) error
+	Import(ctx context.Context, req domain.ImportRequest) (*domain.ImportResult, error)
+	Export(ctx context.Context, req domain.ExportRequest) (*domain.ExportResult, error)
+	Lint(ctx context.Context, id string) (*domain.LintReport, error)
+	Diff(ctx context.Context, fromID, toID string) (*domain.DiagramDiff, error)
+	Backup(ctx context.Context, ids []string) (*domain.ExportResult, error)
+	Restore(ctx context.Context, req domain.RestoreRequest) (*domain.RestoreResult, error)
+}
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, b backend, args []string) error
+}
+
+var commands = map[string]command{
+	"list":    {"list [-search text] [-sort field] [-order asc|desc] [-limit n]   list diagrams", runList},
+	"get":     {"get id   show a diagram and its tables", runGet},
+	"delete":  {"delete id...   move diagrams to the trash", runDelete},
+	"import":  {"import [-format sql|dbml|prisma] [-name name] file   create a diagram from a file (- for stdin)", runImport},
+	"export":  {"export [-format name] [-dialect name] [-o file] [-opt key=value]... id   render a diagram (default: sql to stdout)", runExport},
+	"lint":    {"lint id   check a diagram for schema problems; exits 1 on errors", runLint},
+	"diff":    {"diff from-id to-id   list schema changes between two diagrams; exits 1 when they differ", runDiff},
+	"backup":  {"backup [-o file] [id...]   archive the given diagrams, or all of them", runBackup},
+	"restore": {"restore [-mode keep|regenerate|skip] file   restore diagrams from an archive", runRestore},
+}
+
+// jsonOutput is set by -json, before the command or among its flags
+var jsonOutput bool
+
+// exitStatus ends the program with a status but no message, for commands
+// whose output already says what went wrong
+type exitStatus int
+
+func (e exitStatus) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+func main() {
+	server := flag.String("server", os.Getenv("VERTEX_SERVER"), "base URL of a running server, e.g. http://localhost:8080 (default: $VERTEX_SERVER, or direct database access)")
+	verbose := flag.Bool("v", false, "log what the server code does (direct mode)")
+	flag.BoolVar(&jsonOutput, "json", false, "print JSON instead of tables")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "vertexctl: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	var b backend
+	if *server != "" {
+		b = newClient(*server)
+	} else {
+		uc, err := connect()
+		if err != nil {
+			fatal(err)
+		}
+		defer database.CloseMongoDB()
+		b = uc
+	}
+
+	if err := cmd.run(context.Background(), b, flag.Args()[1:]); err != nil {
+		var status exitStatus
+		if errors.As(err, &status) {
+			database.CloseMongoDB()
+			os.Exit(int(status))
+		}
+		fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: vertexctl [-server url] [-json] [-v] <command> [arguments]\n\ncommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nflags:\n")
+	flag.PrintDefaults()
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "vertexctl: %v\n", err)
+	os.Exit(1)
+}
+
+// newFlags returns a command's flag set; every command also accepts -json
+func newFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.BoolVar(&jsonOutput, "json", jsonOutput, "print JSON instead of tables")
+	return fs
+}
+
+// connect wires the diagram usecase the same way the server does
+func connect() (domain.DiagramUsecase, error) {
+	cfg := config.LoadConfig()
+	client, err := database.GetMongoClient(cfg.MongoURI)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to MongoDB: %v", err)
+	}
+	db := client.Database(cfg.DBName)
+	return usecase.NewDiagramUsecase(
+		repository.NewMongoRepository(db.Collection("diagrams")),
+		repository.NewMongoTableRepository(db.Collection("tables")),
+		repository.NewMongoRelationshipRepository(db.Collection("relationships")),
+		repository.NewMongoDependencyRepository(db.Collection("dependencies")),
+		repository.NewMongoAreaRepository(db.Collection("areas")),
+		repository.NewMongoCustomTypeRepository(db.Collection("custom_types")),
+		repository.NewMongoNoteRepository(db.Collection("notes")),
+		repository.NewMongoDiagramFilterRepository(db.Collection("diagram_filters")),
+		30*time.Second,
+	), nil
+}
@@ -0,0 +1,299 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Django generates a Django application with one model class per table.
+// Options: app, the application package name (default "models").
+type Django struct{}
+
+func (Django) Generate(s *Schema, opts map[string]string) ([]File, error) {
+	app := opts["app"]
+	if app == "" {
+		app = "models"
+	}
+	if !isIdentifier(app) || pythonKeywords[app] {
+		return nil, fmt.Errorf("%w: invalid app name %q", domain.ErrBadParamInput, app)
+	}
+
+	w := &djangoWriter{schema: s}
+	models := w.models()
+	apps := fmt.Sprintf("from django.apps import AppConfig\n\n\nclass %sConfig(AppConfig):\n    default_auto_field = \"django.db.models.BigAutoField\"\n    name = %s\n",
+		Pascal(app), pyString(app))
+	return []File{
+		{Path: app + "/__init__.py", Content: []byte{}},
+		{Path: app + "/apps.py", Content: []byte(apps)},
+		{Path: app + "/models.py", Content: models},
+	}, nil
+}
+
+type djangoWriter struct {
+	schema    *Schema
+	b         strings.Builder
+	postgres  bool // ArrayField is used
+	functions bool // Now() is used
+}
+
+func (w *djangoWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&w.b, format, args...)
+}
+
+func (w *djangoWriter) models() []byte {
+	for _, e := range w.schema.Enums {
+		w.enum(e)
+	}
+	for _, m := range w.schema.Models {
+		w.model(m)
+	}
+
+	var head strings.Builder
+	head.WriteString("# Code generated by vertex-diagram. DO NOT EDIT.\n\n")
+	if w.postgres {
+		head.WriteString("from django.contrib.postgres.fields import ArrayField\n")
+	}
+	head.WriteString("from django.db import models\n")
+	if w.functions {
+		head.WriteString("from django.db.models.functions import Now\n")
+	}
+	return []byte(head.String() + strings.TrimRight(w.b.String(), "\n") + "\n")
+}
+
+func (w *djangoWriter) enum(e *Enum) {
+	w.printf("\n\nclass %s(models.TextChoices):\n", e.Name)
+	if len(e.Values) == 0 {
+		w.printf("    pass\n")
+		return
+	}
+	members := make(uniqueNames)
+	for _, v := range e.Values {
+		name := members.take(identifier(strings.ToUpper(Snake(v)), "VALUE_"))
+		w.printf("    %s = %s, %s\n", name, pyString(v), pyString(v))
+	}
+}
+
+func (w *djangoWriter) model(m *Model) {
+	w.printf("\n\nclass %s(models.Model):\n", m.Name)
+	pyDocstring(&w.b, "    ", m.Comment)
+
+	pk := m.PrimaryKey()
+	for _, c := range m.Columns {
+		if r := m.ForeignKey(c); r != nil {
+			w.foreignKey(m, c, r, len(pk) == 1)
+			continue
+		}
+		w.printf("    %s = %s\n", pyName(c.Attr), w.field(c, len(pk) == 1))
+	}
+	for _, r := range m.Relations {
+		if r.Kind == BelongsTo && len(r.Columns) > 1 {
+			w.printf("    # Composite foreign key (%s) to %s is not supported by Django\n", attrList(r.Columns), r.Target.Name)
+		}
+	}
+	if len(pk) > 1 {
+		names := make([]string, 0, len(pk))
+		for _, c := range pk {
+			names = append(names, pyString(w.fieldName(m, c)))
+		}
+		w.printf("    pk = models.CompositePrimaryKey(%s)\n", strings.Join(names, ", "))
+	}
+
+	w.printf("\n    class Meta:\n")
+	if m.Schema != "" {
+		w.printf("        # Schema: %s\n", m.Schema)
+	}
+	w.printf("        db_table = %s\n", pyString(m.Table))
+	if m.Comment != "" {
+		w.printf("        db_table_comment = %s\n", pyString(m.Comment))
+	}
+	if m.View {
+		w.printf("        managed = False\n")
+	}
+
+	indexes, constraints := make([]string, 0), make([]string, 0)
+	for _, idx := range m.Indexes {
+		fields := make([]string, 0, len(idx.Columns))
+		for _, c := range idx.Columns {
+			fields = append(fields, pyString(w.fieldName(m, c)))
+		}
+		if idx.Unique {
+			name := idx.Name
+			if name == "" {
+				name = m.Table + "_" + strings.Join(attrs(idx.Columns), "_") + "_uniq"
+			}
+			constraints = append(constraints, fmt.Sprintf("models.UniqueConstraint(fields=[%s], name=%s)", strings.Join(fields, ", "), pyString(name)))
+			continue
+		}
+		index := "models.Index(fields=[" + strings.Join(fields, ", ") + "]"
+		// Django limits index names to 30 characters
+		if idx.Name != "" && len(idx.Name) <= 30 {
+			index += ", name=" + pyString(idx.Name)
+		}
+		indexes = append(indexes, index+")")
+	}
+	if len(indexes) > 0 {
+		w.printf("        indexes = [\n            %s,\n        ]\n", strings.Join(indexes, ",\n            "))
+	}
+	if len(constraints) > 0 {
+		w.printf("        constraints = [\n            %s,\n        ]\n", strings.Join(constraints, ",\n            "))
+	}
+}
+
+// fieldName is the Django field that maps a column: the relation for a single-column foreign key
+func (w *djangoWriter) fieldName(m *Model, c *Column) string {
+	if r := m.ForeignKey(c); r != nil {
+		return pyName(r.Name)
+	}
+	return pyName(c.Attr)
+}
+
+// foreignKey writes a ForeignKey or OneToOneField in place of its column
+func (w *djangoWriter) foreignKey(m *Model, c *Column, r *Relation, singlePK bool) {
+	target := pyString(r.Target.Name)
+	if r.Target == m {
+		target = `"self"`
+	}
+	kind := "ForeignKey"
+	if r.Inverse.Kind == HasOne {
+		kind = "OneToOneField"
+	}
+	onDelete := "models.CASCADE"
+	if r.Optional {
+		onDelete = "models.SET_NULL"
+	}
+	args := []string{target, "on_delete=" + onDelete, "related_name=" + pyString(r.Inverse.Name)}
+	if ref := r.References[0]; !ref.PrimaryKey || len(r.Target.PrimaryKey()) != 1 {
+		args = append(args, "to_field="+pyString(pyName(ref.Attr)))
+	}
+	if c.Name != r.Name+"_id" {
+		args = append(args, "db_column="+pyString(c.Name))
+	}
+	if c.PrimaryKey && singlePK {
+		args = append(args, "primary_key=True")
+	}
+	if r.Optional {
+		args = append(args, "null=True", "blank=True")
+	}
+	if c.Comment != "" {
+		args = append(args, "db_comment="+pyString(c.Comment))
+	}
+	w.printf("    %s = models.%s(%s)\n", pyName(r.Name), kind, strings.Join(args, ", "))
+}
+
+// field returns the model field expression of a column
+func (w *djangoWriter) field(c *Column, singlePK bool) string {
+	typ, args := w.fieldType(c, singlePK)
+	if c.List {
+		w.postgres = true
+		typ, args = "ArrayField", []string{"models." + typ + "(" + strings.Join(args, ", ") + ")"}
+	} else {
+		typ = "models." + typ
+	}
+
+	if c.PrimaryKey && singlePK {
+		args = append(args, "primary_key=True")
+	}
+	if c.Unique && !c.PrimaryKey {
+		args = append(args, "unique=True")
+	}
+	if c.Nullable {
+		args = append(args, "null=True", "blank=True")
+	}
+	if c.Default != "" && !c.AutoIncrement {
+		if lit, ok := pyLiteral(c); ok {
+			args = append(args, "default="+lit)
+		} else if isNow(c.Default) {
+			w.functions = true
+			args = append(args, "db_default=Now()")
+		}
+	}
+	if pyName(c.Attr) != c.Name {
+		args = append(args, "db_column="+pyString(c.Name))
+	}
+	if c.Comment != "" {
+		args = append(args, "db_comment="+pyString(c.Comment))
+	}
+	expr := typ + "(" + strings.Join(args, ", ") + ")"
+	if c.Default != "" && !c.AutoIncrement {
+		if _, ok := pyLiteral(c); !ok && !isNow(c.Default) {
+			expr += "  # Database default: " + c.Default
+		}
+	}
+	if c.Kind == KindString && !characterTypes[c.Type] && c.Type != "" {
+		expr += "  # Database type: " + c.Type
+	}
+	return expr
+}
+
+// fieldType returns the Django field class and its type arguments
+func (w *djangoWriter) fieldType(c *Column, singlePK bool) (string, []string) {
+	switch c.Kind {
+	case KindEnum:
+		longest := 1
+		for _, v := range c.Enum.Values {
+			longest = max(longest, len(v))
+		}
+		return "CharField", []string{"max_length=" + strconv.Itoa(longest), "choices=" + c.Enum.Name + ".choices"}
+	case KindInteger:
+		size := integerSize(c.Type)
+		if c.AutoIncrement && c.PrimaryKey && singlePK {
+			return map[int]string{16: "SmallAutoField", 32: "AutoField", 64: "BigAutoField"}[size], nil
+		}
+		return map[int]string{16: "SmallIntegerField", 32: "IntegerField", 64: "BigIntegerField"}[size], nil
+	case KindNumber:
+		return "FloatField", nil
+	case KindDecimal:
+		digits, places := 19, 4
+		if c.Precision != nil {
+			digits, places = *c.Precision, 0
+			if c.Scale != nil {
+				places = *c.Scale
+			}
+		}
+		return "DecimalField", []string{"max_digits=" + strconv.Itoa(digits), "decimal_places=" + strconv.Itoa(places)}
+	case KindBoolean:
+		return "BooleanField", nil
+	case KindDate:
+		return "DateField", nil
+	case KindTime:
+		return "TimeField", nil
+	case KindDateTime:
+		return "DateTimeField", nil
+	case KindUUID:
+		return "UUIDField", nil
+	case KindJSON:
+		return "JSONField", nil
+	case KindBytes:
+		return "BinaryField", nil
+	}
+	if c.Length > 0 {
+		return "CharField", []string{"max_length=" + strconv.Itoa(c.Length)}
+	}
+	return "TextField", nil
+}
+
+// integerSize returns 16, 32 or 64 for an integer SQL type
+func integerSize(typ string) int {
+	switch typ {
+	case "smallint", "int2", "smallserial", "tinyint":
+		return 16
+	case "bigint", "int8", "bigserial":
+		return 64
+	}
+	return 32
+}
+
+func attrs(columns []*Column) []string {
+	out := make([]string, 0, len(columns))
+	for _, c := range columns {
+		out = append(out, c.Attr)
+	}
+	return out
+}
+
+func attrList(columns []*Column) string {
+	return strings.Join(attrs(columns), ", ")
+}
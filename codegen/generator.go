@@ -0,0 +1,55 @@
+package codegen
+
+import (
+	"archive/zip"
+	"bytes"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// File is one generated source file
+type File struct {
+	Path    string // Slash-separated, relative to the archive root
+	Content []byte
+}
+
+// Generator turns a normalized schema into source files. Options come from
+// the export request, e.g. an application name.
+type Generator interface {
+	Generate(s *Schema, opts map[string]string) ([]File, error)
+}
+
+// Archive implements domain.Exporter, packing the files of a generator into a zip archive
+type Archive struct {
+	Generator Generator
+	Name      string // Used in the file extension, e.g. "django" gives name.django.zip
+}
+
+func (a Archive) ContentType() string { return "application/zip" }
+
+func (a Archive) Extension() string { return a.Name + ".zip" }
+
+func (a Archive) Export(snap *domain.DiagramSnapshot, opts map[string]string) ([]byte, error) {
+	schema := NewSchema(snap)
+	files, err := a.Generator.Generate(schema, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.Path, Method: zip.Deflate, Modified: schema.Time.In(time.UTC)})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(f.Content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,402 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Struct tag styles of the Go generator
+const (
+	GoStylePlain = "plain"
+	GoStyleGORM  = "gorm"
+	GoStyleSQLX  = "sqlx"
+)
+
+// Go implements domain.Exporter, generating one Go source file with a struct
+// per table. Options: style (plain, gorm or sqlx) and package (default models).
+type Go struct{}
+
+func (Go) ContentType() string { return "text/x-go; charset=utf-8" }
+
+func (Go) Extension() string { return "go" }
+
+func (Go) Export(snap *domain.DiagramSnapshot, opts map[string]string) ([]byte, error) {
+	style := strings.ToLower(opts["style"])
+	if style == "" {
+		style = GoStylePlain
+	}
+	if style != GoStylePlain && style != GoStyleGORM && style != GoStyleSQLX {
+		return nil, fmt.Errorf("%w: unknown style %q", domain.ErrBadParamInput, style)
+	}
+	pkg := opts["package"]
+	if pkg == "" {
+		pkg = "models"
+	}
+	if !token.IsIdentifier(pkg) || token.IsKeyword(pkg) {
+		return nil, fmt.Errorf("%w: invalid package name %q", domain.ErrBadParamInput, pkg)
+	}
+
+	g := newGoGen(snap, style)
+	src := g.file(pkg)
+	out, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return out, nil
+}
+
+// goType is the Go type of a column, with its nullable form under the sqlx style
+type goType struct {
+	name    string
+	null    string // sql.Null* type; empty means sql.Null[name]
+	nilable bool   // Already accepts nil, never wrapped
+	imp     string // Import path needed by name
+}
+
+// Go types shared by several SQL types
+var (
+	goInt16   = goType{name: "int16", null: "sql.NullInt16"}
+	goInt32   = goType{name: "int32", null: "sql.NullInt32"}
+	goInt64   = goType{name: "int64", null: "sql.NullInt64"}
+	goBool    = goType{name: "bool", null: "sql.NullBool"}
+	goFloat32 = goType{name: "float32"}
+	goFloat64 = goType{name: "float64", null: "sql.NullFloat64"}
+	goString  = goType{name: "string", null: "sql.NullString"}
+	goTime    = goType{name: "time.Time", null: "sql.NullTime", imp: "time"}
+	goJSON    = goType{name: "json.RawMessage", nilable: true, imp: "encoding/json"}
+	goBytes   = goType{name: "[]byte", nilable: true}
+)
+
+// goTypes maps lower-case SQL type names to Go types; character types and
+// anything unrecognized map to string
+var goTypes = map[string]goType{
+	"tinyint":     {name: "int8"},
+	"smallint":    goInt16,
+	"int2":        goInt16,
+	"smallserial": goInt16,
+	"int":         goInt32,
+	"integer":     goInt32,
+	"int4":        goInt32,
+	"mediumint":   goInt32,
+	"serial":      goInt32,
+	"bigint":      goInt64,
+	"int8":        goInt64,
+	"bigserial":   goInt64,
+	"bool":        goBool,
+	"boolean":     goBool,
+	"bit":         goBool,
+	"real":        goFloat32,
+	"float4":      goFloat32,
+	"float":       goFloat64,
+	"float8":      goFloat64,
+	"double":      goFloat64,
+
+	"double precision": goFloat64,
+
+	// Exact numerics stay strings to keep their precision
+	"decimal": goString,
+	"numeric": goString,
+	"money":   goString,
+	"number":  goString,
+
+	"date":          goTime,
+	"time":          goTime,
+	"timetz":        goTime,
+	"datetime":      goTime,
+	"datetime2":     goTime,
+	"smalldatetime": goTime,
+	"timestamp":     goTime,
+	"timestamptz":   goTime,
+
+	"timestamp with time zone":    goTime,
+	"timestamp without time zone": goTime,
+
+	"json":      goJSON,
+	"jsonb":     goJSON,
+	"bytea":     goBytes,
+	"blob":      goBytes,
+	"longblob":  goBytes,
+	"binary":    goBytes,
+	"varbinary": goBytes,
+	"image":     goBytes,
+}
+
+type goGen struct {
+	snap    *domain.DiagramSnapshot
+	style   string
+	b       strings.Builder
+	imports map[string]bool
+	enums   map[string]string // lower-case custom type name -> Go type name
+	structs map[string]string // table ID -> struct name
+	tables  map[string]domain.Table
+}
+
+func newGoGen(snap *domain.DiagramSnapshot, style string) *goGen {
+	g := &goGen{
+		snap:    snap,
+		style:   style,
+		imports: make(map[string]bool),
+		enums:   make(map[string]string),
+		structs: make(map[string]string),
+		tables:  make(map[string]domain.Table),
+	}
+	names := make(uniqueNames)
+	for _, ct := range snap.CustomTypes {
+		if ct.Kind == "enum" {
+			g.enums[strings.ToLower(ct.Type)] = names.take(identifier(Pascal(ct.Type), "Type"))
+		}
+	}
+	for _, t := range snap.Tables {
+		g.tables[t.TableID] = t
+		g.structs[t.TableID] = names.take(identifier(Pascal(Singular(t.Name)), "Table"))
+	}
+	return g
+}
+
+func (g *goGen) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.b, format, args...)
+}
+
+// file renders the whole source; imports are collected while rendering the body
+func (g *goGen) file(pkg string) []byte {
+	for _, ct := range g.snap.CustomTypes {
+		g.customType(ct)
+	}
+	for _, t := range g.snap.Tables {
+		g.table(t)
+	}
+
+	var head strings.Builder
+	head.WriteString("// Code generated by vertex-diagram. DO NOT EDIT.\n\n")
+	if g.snap.Diagram != nil && g.snap.Diagram.Name != "" {
+		fmt.Fprintf(&head, "// Package %s holds the models of the %s diagram.\n", pkg, comment(g.snap.Diagram.Name))
+	}
+	fmt.Fprintf(&head, "package %s\n\n", pkg)
+	if len(g.imports) > 0 {
+		paths := make([]string, 0, len(g.imports))
+		for p := range g.imports {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		head.WriteString("import (\n")
+		for _, p := range paths {
+			fmt.Fprintf(&head, "\t%q\n", p)
+		}
+		head.WriteString(")\n\n")
+	}
+	return []byte(head.String() + g.b.String())
+}
+
+// customType writes an enum as a string type with one constant per value
+func (g *goGen) customType(ct domain.CustomType) {
+	name, ok := g.enums[strings.ToLower(ct.Type)]
+	if !ok {
+		g.printf("// %s type %s has no Go equivalent; columns using it are generated as string.\n\n",
+			nonEmpty(ct.Kind, "custom"), comment(ct.Type))
+		return
+	}
+	g.printf("// %s is the %s enum.\ntype %s string\n\n", name, comment(ct.Type), name)
+	values := ct.ValueList()
+	if len(values) == 0 {
+		return
+	}
+	consts := make(uniqueNames)
+	g.printf("const (\n")
+	for _, v := range values {
+		c := consts.take(name + identifier(Pascal(v), "Value"))
+		g.printf("\t%s %s = %q\n", c, name, v)
+	}
+	g.printf(")\n\n")
+}
+
+func (g *goGen) table(t domain.Table) {
+	name := g.structs[t.TableID]
+	kind := "table"
+	if t.IsView {
+		kind = "view"
+	}
+	g.printf("// %s maps the %s %s.", name, comment(t.QualifiedName()), kind)
+	if t.Comments != "" {
+		g.printf("\n//\n// %s", comment(t.Comments))
+	}
+	g.printf("\ntype %s struct {\n", name)
+
+	fields := make(uniqueNames)
+	for _, f := range t.FieldList() {
+		fieldName := fields.take(identifier(Pascal(f.Name), "F"))
+		if f.Comments != "" {
+			g.printf("\t// %s\n", comment(f.Comments))
+		}
+		g.printf("\t%s %s `%s`\n", fieldName, g.fieldType(f), g.fieldTag(f))
+	}
+	g.relations(t, fields)
+	g.printf("}\n\n")
+
+	g.printf("// TableName returns the name of the %s %s.\n", comment(t.QualifiedName()), kind)
+	g.printf("func (%s) TableName() string {\n\treturn %q\n}\n\n", name, t.QualifiedName())
+}
+
+// fieldType resolves the Go type of a column, wrapping nullable columns in a
+// pointer or, with the sqlx style, a sql.Null type
+func (g *goGen) fieldType(f domain.Field) string {
+	base := strings.ToLower(strings.TrimSpace(f.Type))
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		base = strings.TrimSpace(base[:i])
+	}
+	base = strings.TrimSuffix(base, "[]")
+
+	var typ goType
+	if enum, ok := g.enums[base]; ok {
+		typ = goType{name: enum}
+	} else if known, ok := goTypes[base]; ok {
+		typ = known
+	} else {
+		typ = goString
+	}
+	// MySQL booleans are usually tinyint(1)
+	if base == "tinyint" && f.CharacterMaximumLength == "1" {
+		typ = goBool
+	}
+	if typ.imp != "" {
+		g.imports[typ.imp] = true
+	}
+
+	if !f.Nullable || f.PrimaryKey || typ.nilable {
+		return typ.name
+	}
+	if g.style != GoStyleSQLX {
+		return "*" + typ.name
+	}
+	g.imports["database/sql"] = true
+	if typ.null != "" {
+		return typ.null
+	}
+	return "sql.Null[" + typ.name + "]"
+}
+
+func (g *goGen) fieldTag(f domain.Field) string {
+	jsonTag := `json:"` + f.Name
+	if f.Nullable && !f.PrimaryKey {
+		jsonTag += ",omitempty"
+	}
+	jsonTag += `"`
+
+	switch g.style {
+	case GoStyleSQLX:
+		return `db:"` + f.Name + `" ` + jsonTag
+	case GoStyleGORM:
+		opts := []string{"column:" + f.Name}
+		if f.Type != "" {
+			opts = append(opts, "type:"+f.SQLType())
+		}
+		if f.PrimaryKey {
+			opts = append(opts, "primaryKey")
+		}
+		if f.Increment {
+			opts = append(opts, "autoIncrement")
+		}
+		if !f.Nullable && !f.PrimaryKey {
+			opts = append(opts, "not null")
+		}
+		if f.Unique && !f.PrimaryKey {
+			opts = append(opts, "unique")
+		}
+		if f.Default != "" {
+			opts = append(opts, "default:"+f.Default)
+		}
+		return `gorm:"` + tagEscape(strings.Join(opts, ";")) + `" ` + jsonTag
+	}
+	return jsonTag
+}
+
+// relations adds navigation fields for the relationships of a table: the
+// foreign key side points to one parent, the other side holds one child or a
+// slice of children depending on the cardinality
+func (g *goGen) relations(t domain.Table, names uniqueNames) {
+	for _, r := range g.snap.Relationships {
+		childTableID, fkID := r.ForeignKey()
+		parentTableID, refID := r.TargetTableID, r.TargetFieldID
+		childMany, parentMany := r.SourceCardinality == domain.CardinalityMany, r.TargetCardinality == domain.CardinalityMany
+		if childTableID == r.TargetTableID && fkID == r.TargetFieldID {
+			parentTableID, refID = r.SourceTableID, r.SourceFieldID
+			childMany, parentMany = parentMany, childMany
+		}
+		child, okChild := g.tables[childTableID]
+		parent, okParent := g.tables[parentTableID]
+		if !okChild || !okParent {
+			continue
+		}
+		fk, _ := child.FieldByID(fkID)
+		ref, _ := parent.FieldByID(refID)
+
+		if childMany && parentMany {
+			if t.TableID == childTableID || t.TableID == parentTableID {
+				g.printf("\t// Many-to-many relationship %s needs a join table and is not generated.\n", comment(nonEmpty(r.Name, child.Name+" - "+parent.Name)))
+			}
+			continue
+		}
+
+		// Foreign key side: a pointer to the parent
+		if t.TableID == childTableID {
+			base := Pascal(strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(fk.Name), "_id"), "id"))
+			if base == "" || strings.EqualFold(base, Pascal(fk.Name)) {
+				base = g.structs[parentTableID]
+			}
+			field := names.take(base)
+			tag := ""
+			if g.style == GoStyleGORM {
+				tag = "foreignKey:" + Pascal(fk.Name) + ";references:" + Pascal(ref.Name)
+			}
+			g.printf("\t%s *%s `%s`\n", field, g.structs[parentTableID], g.relationTag(Snake(field), tag))
+		}
+		// Referenced side: one child or a slice of children
+		if t.TableID == parentTableID {
+			childStruct := g.structs[childTableID]
+			tag := ""
+			if g.style == GoStyleGORM {
+				tag = "foreignKey:" + Pascal(fk.Name) + ";references:" + Pascal(ref.Name)
+			}
+			if childMany {
+				field := names.take(Plural(childStruct))
+				g.printf("\t%s []%s `%s`\n", field, childStruct, g.relationTag(Snake(field), tag))
+			} else {
+				field := names.take(childStruct)
+				g.printf("\t%s *%s `%s`\n", field, childStruct, g.relationTag(Snake(field), tag))
+			}
+		}
+	}
+}
+
+// relationTag keeps navigation fields out of column mapping
+func (g *goGen) relationTag(jsonName, gormTag string) string {
+	jsonTag := `json:"` + jsonName + `,omitempty"`
+	switch g.style {
+	case GoStyleSQLX:
+		return `db:"-" ` + jsonTag
+	case GoStyleGORM:
+		return `gorm:"` + gormTag + `" ` + jsonTag
+	}
+	return jsonTag
+}
+
+// comment flattens text onto one comment line
+func comment(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// tagEscape keeps a struct tag value inside its double quotes and backquotes
+func tagEscape(s string) string {
+	return strings.NewReplacer(`"`, `'`, "`", "'", "\n", " ").Replace(s)
+}
+
+func nonEmpty(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,227 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// JSONSchemaDraft is the dialect of the generated JSON Schema documents
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema implements domain.Exporter, generating a JSON Schema (draft
+// 2020-12) document. Options: table (ID or name) for a single table instead
+// of the whole diagram, and depth to nest related objects that many levels;
+// nested objects refer to TypeWithRelations<level> definitions.
+type JSONSchema struct{}
+
+func (JSONSchema) ContentType() string { return "application/schema+json" }
+
+func (JSONSchema) Extension() string { return "schema.json" }
+
+func (JSONSchema) Export(snap *domain.DiagramSnapshot, opts map[string]string) ([]byte, error) {
+	s := newShape(snap)
+	table, depth, err := s.options(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	_, enums := s.reachable(table, depth)
+	defs := object{}
+	for _, ct := range enums {
+		values := make([]interface{}, 0)
+		for _, v := range ct.ValueList() {
+			values = append(values, v)
+		}
+		defs = defs.set(s.enums[strings.ToLower(ct.Type)], object{}.set("title", ct.Type).set("enum", values))
+	}
+
+	n := s.nesting(depth)
+	var doc object
+	if table != nil {
+		doc = append(object{}.set("$schema", JSONSchemaDraft), s.tableSchema(*table, depth, n)...)
+	} else {
+		doc = object{}.set("$schema", JSONSchemaDraft)
+		if snap.Diagram != nil && snap.Diagram.Name != "" {
+			doc = doc.set("title", snap.Diagram.Name)
+		}
+		for _, t := range snap.Tables {
+			n.name(t.TableID, 0)
+		}
+		for _, t := range snap.Tables {
+			n.name(t.TableID, depth)
+		}
+	}
+	// Related rows are $refs to one definition per table and level
+	for i := 0; i < len(n.order); i++ {
+		k := n.order[i]
+		defs = defs.set(n.names[k], s.tableSchema(s.tables[k.tableID], k.depth, n))
+	}
+	if len(defs) > 0 || table == nil {
+		doc = doc.set("$defs", defs)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// tableSchema describes one row of a table, with related rows nested depth
+// levels as references to the definitions named by n
+func (s *shape) tableSchema(t domain.Table, depth int, n *nesting) object {
+	props := object{}
+	required := make([]string, 0)
+	for _, f := range t.FieldList() {
+		props = props.set(f.Name, s.columnSchema(s.column(f)))
+		required = append(required, f.Name)
+	}
+	if depth > 0 {
+		for _, nav := range s.navigations(t) {
+			var schema interface{} = object{}.set("$ref", "#/$defs/"+n.name(nav.tableID, depth-1))
+			switch {
+			case nav.many:
+				schema = object{}.set("type", "array").set("items", schema)
+			case nav.nullable:
+				schema = object{}.set("anyOf", []interface{}{schema, object{}.set("type", "null")})
+			}
+			props = props.set(nav.name, schema)
+		}
+	}
+
+	out := object{}.set("title", t.QualifiedName())
+	if t.Comments != "" {
+		out = out.set("description", t.Comments)
+	}
+	return out.set("type", "object").
+		set("properties", props).
+		set("required", required).
+		set("additionalProperties", false)
+}
+
+// columnSchema maps a column type, wrapping lists in an array and allowing null when nullable
+func (s *shape) columnSchema(c column) object {
+	item := object{}
+	switch c.kind {
+	case KindEnum:
+		item = item.set("$ref", "#/$defs/"+c.enum)
+	case KindInteger:
+		item = item.set("type", "integer")
+		if c.bounded {
+			item = item.set("minimum", c.min).set("maximum", c.max)
+		}
+	case KindNumber:
+		item = item.set("type", "number")
+	case KindDecimal:
+		item = item.set("type", "number")
+		if c.bounded {
+			item = item.set("exclusiveMinimum", c.min).set("exclusiveMaximum", c.max)
+		}
+		if step := c.step(); step > 0 {
+			item = item.set("multipleOf", step)
+		}
+	case KindBoolean:
+		item = item.set("type", "boolean")
+	case KindDate:
+		item = item.set("type", "string").set("format", "date")
+	case KindTime:
+		item = item.set("type", "string").set("format", "time")
+	case KindDateTime:
+		item = item.set("type", "string").set("format", "date-time")
+	case KindUUID:
+		item = item.set("type", "string").set("format", "uuid")
+	case KindBytes:
+		item = item.set("type", "string").set("contentEncoding", "base64")
+	case KindJSON:
+		// Any JSON value
+	default:
+		item = item.set("type", "string")
+		if c.maxLength > 0 {
+			item = item.set("maxLength", c.maxLength)
+		}
+	}
+
+	schema := item
+	if c.list {
+		schema = object{}.set("type", "array").set("items", item)
+	}
+	if c.nullable() && c.kind != KindJSON {
+		if t, ok := schema.get("type").(string); ok {
+			schema = schema.set("type", []string{t, "null"})
+		} else {
+			schema = object{}.set("anyOf", []interface{}{schema, object{}.set("type", "null")})
+		}
+	}
+	if c.field.Comments != "" {
+		schema = schema.set("description", c.field.Comments)
+	}
+	return schema
+}
+
+// member is one key of an object
+type member struct {
+	key   string
+	value interface{}
+}
+
+// object is a JSON object that keeps its keys in insertion order
+type object []member
+
+func (o object) set(key string, value interface{}) object {
+	for i := range o {
+		if o[i].key == key {
+			o[i].value = value
+			return o
+		}
+	}
+	return append(o, member{key, value})
+}
+
+func (o object) get(key string) interface{} {
+	for _, m := range o {
+		if m.key == key {
+			return m.value
+		}
+	}
+	return nil
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshal encodes a value without escaping HTML characters
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
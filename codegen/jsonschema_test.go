@@ -0,0 +1,133 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// starSnapshot has a fact table referencing n dimension tables, each of which
+// references the fact table's owner, so every level fans out again
+func starSnapshot(n int) *domain.DiagramSnapshot {
+	snap := &domain.DiagramSnapshot{Diagram: &domain.Diagram{ID: "d1", Name: "star"}}
+	snap.Tables = append(snap.Tables, domain.Table{TableID: "fact", Name: "facts", Fields: []map[string]interface{}{
+		{"id": "fact.id", "name": "id", "type": "bigint", "primaryKey": true},
+	}})
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("dim%d", i)
+		snap.Tables = append(snap.Tables, domain.Table{TableID: id, Name: fmt.Sprintf("dim_%d", i), Fields: []map[string]interface{}{
+			{"id": id + ".id", "name": "id", "type": "bigint", "primaryKey": true},
+			{"id": id + ".fact_id", "name": "fact_id", "type": "bigint", "nullable": true},
+		}})
+		snap.Relationships = append(snap.Relationships, domain.Relationship{
+			RelationshipID: "r" + id,
+			SourceTableID:  id, SourceFieldID: id + ".fact_id", SourceCardinality: domain.CardinalityMany,
+			TargetTableID: "fact", TargetFieldID: "fact.id", TargetCardinality: domain.CardinalityOne,
+		})
+	}
+	return snap
+}
+
+func TestJSONSchemaNestingIsLinear(t *testing.T) {
+	tests := []struct {
+		name string
+		opts map[string]string
+	}{
+		{"diagram", map[string]string{"depth": "5"}},
+		{"table", map[string]string{"depth": "5", "table": "facts"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := JSONSchema{}.Export(starSnapshot(30), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(out) > 1<<20 {
+				t.Fatalf("schema is %d bytes", len(out))
+			}
+
+			var doc struct {
+				Defs map[string]json.RawMessage `json:"$defs"`
+			}
+			if err := json.Unmarshal(out, &doc); err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range regexp.MustCompile(`"\$ref":"#/\$defs/([^"]+)"`).FindAllSubmatch(out, -1) {
+				if _, ok := doc.Defs[string(m[1])]; !ok {
+					t.Errorf("unresolved $ref %s", m[1])
+				}
+			}
+		})
+	}
+}
+
+func TestJSONSchemaDepthZeroHasNoRefs(t *testing.T) {
+	out, err := JSONSchema{}.Export(starSnapshot(3), map[string]string{"table": "facts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "$ref") || strings.Contains(string(out), "$defs") {
+		t.Errorf("depth 0 output nests related objects:\n%s", out)
+	}
+}
+
+func TestTypeScriptNestingIsLinear(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter domain.Exporter
+	}{
+		{"typescript", TypeScript{}},
+		{"zod", Zod{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.exporter.Export(starSnapshot(30), map[string]string{"depth": "5"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(out) > 1<<20 {
+				t.Fatalf("output is %d bytes", len(out))
+			}
+			if !strings.Contains(string(out), "FactWithRelations ") && !strings.Contains(string(out), "FactWithRelationsSchema ") {
+				t.Errorf("no root type:\n%s", out)
+			}
+		})
+	}
+}
+
+// Zod schemas are constants, so each must be declared before it is used
+func TestZodDeclarationOrder(t *testing.T) {
+	out, err := Zod{}.Export(starSnapshot(3), map[string]string{"depth": "3", "table": "facts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	declaration := regexp.MustCompile(`^export const (\w+) = (.*)`)
+	use := regexp.MustCompile(`\b\w+Schema\b`)
+	declared := make(map[string]bool)
+	used := 0
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "export type") {
+			continue
+		}
+		name, rest := "", line
+		if m := declaration.FindStringSubmatch(line); m != nil {
+			name, rest = m[1], m[2]
+		}
+		for _, ref := range use.FindAllString(rest, -1) {
+			used++
+			if !declared[ref] {
+				t.Errorf("%s is used before it is declared:\n%s", ref, out)
+			}
+		}
+		if name != "" {
+			declared[name] = true
+		}
+	}
+	if used == 0 {
+		t.Fatalf("no schema references:\n%s", out)
+	}
+}
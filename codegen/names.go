@@ -0,0 +1,123 @@
+// Package codegen generates application source code from diagrams, such as
+// Go structs with struct tags for the plain, GORM or sqlx styles. Multi-file
+// targets (Django, SQLAlchemy, Rails) implement Generator against the
+// normalized Schema and are exported as zip archives.
+package codegen
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Words written in upper case in Go identifiers
+var initialisms = map[string]bool{
+	"id": true, "url": true, "uri": true, "uuid": true, "api": true, "http": true,
+	"json": true, "sql": true, "ip": true, "html": true, "xml": true, "ui": true,
+}
+
+// words splits an identifier on non-alphanumerics and lower-to-upper case changes
+func words(s string) []string {
+	out := make([]string, 0)
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			out = append(out, strings.ToLower(string(cur)))
+			cur = nil
+		}
+	}
+	rs := []rune(s)
+	for i, r := range rs {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(rs[i-1]) || (i+1 < len(rs) && unicode.IsLower(rs[i+1]) && unicode.IsUpper(rs[i-1]))):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+	return out
+}
+
+// Pascal converts an identifier to PascalCase, keeping Go initialisms upper case
+func Pascal(s string) string {
+	var b strings.Builder
+	for _, w := range words(s) {
+		if initialisms[w] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		rs := []rune(w)
+		rs[0] = unicode.ToUpper(rs[0])
+		b.WriteString(string(rs))
+	}
+	return b.String()
+}
+
+// Camel converts an identifier to camelCase
+func Camel(s string) string {
+	ws := words(s)
+	if len(ws) == 0 {
+		return ""
+	}
+	return ws[0] + Pascal(strings.Join(ws[1:], "_"))
+}
+
+// Snake converts an identifier to snake_case
+func Snake(s string) string {
+	return strings.Join(words(s), "_")
+}
+
+// Singular returns a naive English singular of a plural word
+func Singular(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		return s
+	case strings.HasSuffix(lower, "s") && len(s) > 1:
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// Plural returns a naive English plural of a singular word
+func Plural(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
+
+// identifier makes s a valid identifier, prefixing fallback when it starts with a digit or is empty
+func identifier(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	if unicode.IsDigit([]rune(s)[0]) {
+		return fallback + s
+	}
+	return s
+}
+
+// uniqueNames hands out names, suffixing repeats with 2, 3, ...
+type uniqueNames map[string]bool
+
+func (u uniqueNames) take(name string) string {
+	candidate := name
+	for n := 2; u[strings.ToLower(candidate)]; n++ {
+		candidate = name + strconv.Itoa(n)
+	}
+	u[strings.ToLower(candidate)] = true
+	return candidate
+}
@@ -0,0 +1,92 @@
+package codegen
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Python keywords, which cannot be used as attribute names
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true,
+	"await": true, "break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true,
+	"else": true, "except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true,
+	"pass": true, "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// pyName escapes Python keywords with a trailing underscore
+func pyName(name string) string {
+	if pythonKeywords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// pyString writes a double-quoted Python string literal
+func pyString(s string) string {
+	return strconv.Quote(s)
+}
+
+// pyDocstring writes an indented docstring. Every quote is escaped, since a
+// quote at the end of the text would otherwise close the docstring early
+func pyDocstring(b *strings.Builder, indent, text string) {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	text = strings.ReplaceAll(strings.ReplaceAll(text, `\`, `\\`), `"`, `\"`)
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		b.WriteString(indent + `"""` + text + `"""` + "\n")
+		return
+	}
+	b.WriteString(indent + `"""` + lines[0] + "\n")
+	for _, line := range lines[1:] {
+		b.WriteString(indent + strings.TrimRight(line, " ") + "\n")
+	}
+	b.WriteString(indent + `"""` + "\n")
+}
+
+var numberPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// pyLiteral converts a SQL default into a Python literal when it is a
+// constant; expressions are reported as not literal
+func pyLiteral(c *Column) (string, bool) {
+	d := strings.TrimSpace(c.Default)
+	switch {
+	case len(d) >= 2 && d[0] == '\'' && d[len(d)-1] == '\'':
+		return pyString(strings.ReplaceAll(d[1:len(d)-1], "''", "'")), true
+	case c.Kind == KindBoolean && (strings.EqualFold(d, "true") || d == "1"):
+		return "True", true
+	case c.Kind == KindBoolean && (strings.EqualFold(d, "false") || d == "0"):
+		return "False", true
+	case numberPattern.MatchString(d) && (c.Kind == KindInteger || c.Kind == KindNumber || c.Kind == KindDecimal):
+		return d, true
+	}
+	return "", false
+}
+
+// isNow reports whether a SQL default is the current timestamp
+func isNow(expr string) bool {
+	switch strings.ToLower(strings.TrimSpace(expr)) {
+	case "now()", "current_timestamp", "current_timestamp()", "getdate()", "sysdate", "localtimestamp":
+		return true
+	}
+	return false
+}
+
+// isIdentifier reports whether s is a valid Python or Ruby identifier
+func isIdentifier(s string) bool {
+	return identPattern.MatchString(s)
+}
+
+var identPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// characterTypes are the SQL types of KindString columns that hold text; other
+// KindString columns have a type the generators do not know
+var characterTypes = map[string]bool{
+	"text": true, "varchar": true, "char": true, "character": true, "character varying": true,
+	"nvarchar": true, "nchar": true, "varchar2": true, "nvarchar2": true, "citext": true, "string": true,
+	"tinytext": true, "mediumtext": true, "longtext": true, "ntext": true, "clob": true,
+}
@@ -0,0 +1,106 @@
+package codegen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// awkwardComment ends in a quote, which used to close the docstring early
+const awkwardComment = `a <b> & "c"`
+
+func pythonSnapshot() *domain.DiagramSnapshot {
+	return &domain.DiagramSnapshot{
+		Diagram: &domain.Diagram{ID: "d1", Name: "shop"},
+		Tables: []domain.Table{
+			{
+				TableID:  "t1",
+				Name:     "users",
+				Comments: awkwardComment,
+				Fields: []map[string]interface{}{
+					{"id": "f1", "name": "id", "type": "bigint", "primaryKey": true, "increment": true},
+					{"id": "f2", "name": "class", "type": "varchar", "characterMaximumLength": "20", "comments": `ends with \`},
+					{"id": "f3", "name": "status", "type": "status", "default": `'it''s "on"'`},
+				},
+			},
+			{
+				TableID:  "t2",
+				Name:     "orders",
+				Comments: "\"\"\"quoted\"\"\"\r\nsecond line \"",
+				Fields: []map[string]interface{}{
+					{"id": "f4", "name": "id", "type": "integer", "primaryKey": true},
+					{"id": "f5", "name": "user_id", "type": "bigint", "nullable": true},
+				},
+			},
+		},
+		Relationships: []domain.Relationship{{
+			RelationshipID: "r1", Name: "fk_orders_user",
+			SourceTableID: "t2", SourceFieldID: "f5", SourceCardinality: domain.CardinalityMany,
+			TargetTableID: "t1", TargetFieldID: "f1", TargetCardinality: domain.CardinalityOne,
+		}},
+		CustomTypes: []domain.CustomType{{Type: "status", Kind: "enum", Values: []interface{}{"on", `off"`}}},
+	}
+}
+
+func TestPythonOutputParses(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not available")
+	}
+
+	tests := []struct {
+		name      string
+		generator Generator
+	}{
+		{"django", Django{}},
+		{"sqlalchemy", SQLAlchemy{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := tt.generator.Generate(NewSchema(pythonSnapshot()), map[string]string{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			for _, f := range files {
+				if !strings.HasSuffix(f.Path, ".py") {
+					continue
+				}
+				path := filepath.Join(dir, filepath.FromSlash(f.Path))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, f.Content, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				out, err := exec.Command(python, "-c", `import ast, sys; ast.parse(open(sys.argv[1]).read(), sys.argv[1])`, path).CombinedOutput()
+				if err != nil {
+					t.Errorf("%s does not parse: %v\n%s\n%s", f.Path, err, out, f.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestPyDocstring(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"", ""},
+		{"plain", `    """plain"""` + "\n"},
+		{awkwardComment, `    """a <b> & \"c\""""` + "\n"},
+		{`back\slash`, `    """back\\slash"""` + "\n"},
+		{"one\r\ntwo", "    \"\"\"one\n    two\n    \"\"\"\n"},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		pyDocstring(&b, "    ", tt.text)
+		if b.String() != tt.want {
+			t.Errorf("pyDocstring(%q) = %q, want %q", tt.text, b.String(), tt.want)
+		}
+	}
+}
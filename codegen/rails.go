@@ -0,0 +1,290 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// railsVersion is the ActiveRecord migration version; composite primary and
+// foreign keys need 7.1
+const railsVersion = "7.1"
+
+// Rails generates ActiveRecord migrations: one for the enums, one per table
+// and a last one adding the foreign keys. Views are left out, as migrations
+// cannot describe them without their SQL.
+type Rails struct{}
+
+func (Rails) Generate(s *Schema, _ map[string]string) ([]File, error) {
+	g := &railsMigrations{stamp: s.Time, slugs: make(uniqueNames)}
+	if len(s.Enums) > 0 {
+		g.add("create_enums", railsEnums(s.Enums))
+	}
+	for _, m := range s.Models {
+		if !m.View {
+			g.add("create_"+Snake(m.Table), railsTable(m))
+		}
+	}
+	if fks := railsForeignKeys(s.Models); fks != "" {
+		g.add("add_foreign_keys", fks)
+	}
+	return g.files, nil
+}
+
+// railsMigrations numbers migration files one second apart from the time of
+// the diagram's last change
+type railsMigrations struct {
+	stamp time.Time
+	slugs uniqueNames
+	files []File
+}
+
+func (g *railsMigrations) add(slug, body string) {
+	slug = strings.ToLower(g.slugs.take(slug))
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Code generated by vertex-diagram. DO NOT EDIT.\n\nclass %s < ActiveRecord::Migration[%s]\n  def change\n", camelize(slug), railsVersion)
+	b.WriteString(body)
+	b.WriteString("  end\nend\n")
+	g.files = append(g.files, File{
+		Path:    "db/migrate/" + g.stamp.Format("20060102150405") + "_" + slug + ".rb",
+		Content: []byte(b.String()),
+	})
+	g.stamp = g.stamp.Add(time.Second)
+}
+
+func railsEnums(enums []*Enum) string {
+	var b strings.Builder
+	for _, e := range enums {
+		values := make([]string, 0, len(e.Values))
+		for _, v := range e.Values {
+			values = append(values, rbString(v))
+		}
+		fmt.Fprintf(&b, "    create_enum %s, [%s]\n", rbString(qualifiedType(e)), strings.Join(values, ", "))
+	}
+	return b.String()
+}
+
+func railsTable(m *Model) string {
+	var b strings.Builder
+	pk := m.PrimaryKey()
+	options := []string{rbSymbol(m.QualifiedTable())}
+	implicit := (*Column)(nil) // The primary key create_table adds itself
+	switch {
+	case len(pk) == 1 && pk[0].AutoIncrement && pk[0].Kind == KindInteger && !pk[0].List:
+		implicit = pk[0]
+		if integerSize(pk[0].Type) != 64 {
+			options = append(options, "id: :integer")
+		}
+	case len(pk) == 1 && pk[0].Kind == KindUUID && !pk[0].List:
+		implicit = pk[0]
+		options = append(options, "id: :uuid")
+		if pk[0].Default != "" {
+			options = append(options, "default: "+rbDefault(pk[0]))
+		}
+	default:
+		options = append(options, "id: false")
+		if len(pk) > 1 {
+			keys := make([]string, 0, len(pk))
+			for _, c := range pk {
+				keys = append(keys, rbSymbol(c.Name))
+			}
+			options = append(options, "primary_key: ["+strings.Join(keys, ", ")+"]")
+		}
+	}
+	if implicit != nil && implicit.Name != "id" {
+		options = append(options, "primary_key: "+rbSymbol(implicit.Name))
+	}
+	if m.Comment != "" {
+		options = append(options, "comment: "+rbString(m.Comment))
+	}
+
+	fmt.Fprintf(&b, "    create_table %s do |t|\n", strings.Join(options, ", "))
+	for _, c := range m.Columns {
+		if c == implicit {
+			continue
+		}
+		b.WriteString("      " + railsColumn(c, len(pk) == 1) + "\n")
+	}
+	for _, idx := range m.Indexes {
+		columns := make([]string, 0, len(idx.Columns))
+		for _, c := range idx.Columns {
+			columns = append(columns, rbSymbol(c.Name))
+		}
+		args := []string{columns[0]}
+		if len(columns) > 1 {
+			args[0] = "[" + strings.Join(columns, ", ") + "]"
+		}
+		if idx.Unique {
+			args = append(args, "unique: true")
+		}
+		if idx.Name != "" {
+			args = append(args, "name: "+rbString(idx.Name))
+		}
+		b.WriteString("      t.index " + strings.Join(args, ", ") + "\n")
+	}
+	b.WriteString("    end\n")
+	return b.String()
+}
+
+// railsColumn returns the t.<type> statement of a column
+func railsColumn(c *Column, singlePK bool) string {
+	typ, args := railsType(c)
+	statement := "t." + typ + " " + rbSymbol(c.Name)
+	if typ == "column" {
+		statement = "t.column " + rbSymbol(c.Name) + ", " + rbString(c.Type)
+	}
+	if c.List {
+		args = append(args, "array: true")
+	}
+	if c.PrimaryKey && singlePK {
+		args = append(args, "primary_key: true")
+	}
+	if !c.Nullable {
+		args = append(args, "null: false")
+	}
+	if c.Default != "" && !c.AutoIncrement {
+		args = append(args, "default: "+rbDefault(c))
+	}
+	if c.Comment != "" {
+		args = append(args, "comment: "+rbString(c.Comment))
+	}
+	if len(args) > 0 {
+		statement += ", " + strings.Join(args, ", ")
+	}
+	return statement
+}
+
+// railsType returns the column method and its type options
+func railsType(c *Column) (string, []string) {
+	switch c.Kind {
+	case KindEnum:
+		return "enum", []string{"enum_type: " + rbString(qualifiedType(c.Enum))}
+	case KindInteger:
+		switch integerSize(c.Type) {
+		case 16:
+			return "integer", []string{"limit: 2"}
+		case 64:
+			return "bigint", nil
+		}
+		return "integer", nil
+	case KindNumber:
+		return "float", nil
+	case KindDecimal:
+		var args []string
+		if c.Precision != nil {
+			args = append(args, "precision: "+strconv.Itoa(*c.Precision))
+			if c.Scale != nil {
+				args = append(args, "scale: "+strconv.Itoa(*c.Scale))
+			}
+		}
+		return "decimal", args
+	case KindBoolean:
+		return "boolean", nil
+	case KindDate:
+		return "date", nil
+	case KindTime:
+		return "time", nil
+	case KindDateTime:
+		if c.Type == "timestamptz" || strings.Contains(c.Type, "with time zone") {
+			return "timestamptz", nil
+		}
+		return "datetime", nil
+	case KindUUID:
+		return "uuid", nil
+	case KindJSON:
+		if c.Type == "jsonb" {
+			return "jsonb", nil
+		}
+		return "json", nil
+	case KindBytes:
+		return "binary", nil
+	}
+	switch {
+	case c.Length > 0:
+		return "string", []string{"limit: " + strconv.Itoa(c.Length)}
+	case c.Type == "text" || strings.HasSuffix(c.Type, "text") || c.Type == "clob":
+		return "text", nil
+	case characterTypes[c.Type]:
+		return "string", nil
+	}
+	return "column", nil
+}
+
+// railsForeignKeys adds the foreign keys once every table exists
+func railsForeignKeys(models []*Model) string {
+	var b strings.Builder
+	for _, m := range models {
+		if m.View {
+			continue
+		}
+		for _, r := range m.Relations {
+			if r.Kind != BelongsTo || r.Target.View {
+				continue
+			}
+			column, primary := rbSymbol(r.Columns[0].Name), rbSymbol(r.References[0].Name)
+			if len(r.Columns) > 1 {
+				columns, references := make([]string, 0), make([]string, 0)
+				for i, c := range r.Columns {
+					columns = append(columns, rbSymbol(c.Name))
+					references = append(references, rbSymbol(r.References[i].Name))
+				}
+				column, primary = "["+strings.Join(columns, ", ")+"]", "["+strings.Join(references, ", ")+"]"
+			}
+			fmt.Fprintf(&b, "    add_foreign_key %s, %s, column: %s, primary_key: %s\n",
+				rbSymbol(m.QualifiedTable()), rbSymbol(r.Target.QualifiedTable()), column, primary)
+		}
+	}
+	return b.String()
+}
+
+func qualifiedType(e *Enum) string {
+	if e.Schema == "" {
+		return e.Type
+	}
+	return e.Schema + "." + e.Type
+}
+
+// rbDefault writes a SQL default as a Ruby literal, or as a lambda returning
+// the SQL expression
+func rbDefault(c *Column) string {
+	d := strings.TrimSpace(c.Default)
+	switch lit, ok := pyLiteral(c); {
+	case !ok:
+		return "-> { " + rbString(d) + " }"
+	case lit == "True":
+		return "true"
+	case lit == "False":
+		return "false"
+	case strings.HasPrefix(lit, `"`):
+		return rbString(strings.ReplaceAll(d[1:len(d)-1], "''", "'"))
+	default:
+		return lit
+	}
+}
+
+// rbString writes a single-quoted Ruby string, which does no interpolation
+func rbString(s string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", `\'`) + "'"
+}
+
+// rbSymbol writes a Ruby symbol, falling back to a string for names a bare
+// symbol cannot hold
+func rbSymbol(s string) string {
+	if isIdentifier(s) {
+		return ":" + s
+	}
+	return rbString(s)
+}
+
+// camelize mirrors ActiveSupport's String#camelize, which Rails uses to find
+// the migration class from the file name
+func camelize(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
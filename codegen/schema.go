@@ -0,0 +1,276 @@
+package codegen
+
+import (
+	"strings"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/sqlgen"
+)
+
+// Schema is the normalized form of a diagram that generators work from:
+// models with resolved names, classified columns and two-sided relations
+type Schema struct {
+	Name    string
+	Dialect sqlgen.Dialect
+	Time    time.Time // Last change of the diagram, for generated timestamps
+	Enums   []*Enum
+	Models  []*Model
+}
+
+// Enum is an enum custom type
+type Enum struct {
+	Name   string // PascalCase type name
+	Type   string // Database type name
+	Schema string
+	Values []string
+}
+
+// Model is a table or view
+type Model struct {
+	Name      string // PascalCase, singular
+	Table     string
+	Schema    string
+	View      bool
+	Comment   string
+	Columns   []*Column
+	Indexes   []*Index
+	Relations []*Relation
+}
+
+// Column is a table field
+type Column struct {
+	Name          string // Database name
+	Attr          string // snake_case identifier
+	Type          string // Lower-case SQL type without arguments or array suffix
+	Kind          Kind
+	Enum          *Enum
+	List          bool
+	Length        int // Maximum length of character types, zero when unbounded
+	Precision     *int
+	Scale         *int
+	Nullable      bool
+	PrimaryKey    bool
+	Unique        bool
+	AutoIncrement bool
+	Default       string // SQL expression
+	Comment       string
+}
+
+// Index is a table index
+type Index struct {
+	Name    string
+	Unique  bool
+	Columns []*Column
+}
+
+// RelationKind tells which side of a foreign key a relation is
+type RelationKind int
+
+const (
+	BelongsTo RelationKind = iota // The model holds the foreign key
+	HasOne                        // One-to-one, seen from the referenced model
+	HasMany                       // One-to-many, seen from the referenced model
+)
+
+// Relation is one side of a foreign key. Columns are the local columns
+// (the foreign key for BelongsTo, the referenced key otherwise) and
+// References the matching columns of Target.
+type Relation struct {
+	Name       string // snake_case identifier
+	Kind       RelationKind
+	Target     *Model
+	Columns    []*Column
+	References []*Column
+	Optional   bool // The foreign key is nullable
+	Inverse    *Relation
+	Label      string // Relationship name in the diagram
+}
+
+// PrimaryKey returns the primary key columns
+func (m *Model) PrimaryKey() []*Column {
+	pk := make([]*Column, 0, 1)
+	for _, c := range m.Columns {
+		if c.PrimaryKey {
+			pk = append(pk, c)
+		}
+	}
+	return pk
+}
+
+// ForeignKey returns the BelongsTo relation whose only column is c
+func (m *Model) ForeignKey(c *Column) *Relation {
+	for _, r := range m.Relations {
+		if r.Kind == BelongsTo && len(r.Columns) == 1 && r.Columns[0] == c {
+			return r
+		}
+	}
+	return nil
+}
+
+// QualifiedTable returns schema.table, or the table alone
+func (m *Model) QualifiedTable() string {
+	if m.Schema == "" {
+		return m.Table
+	}
+	return m.Schema + "." + m.Table
+}
+
+// NewSchema normalizes a snapshot. Many-to-many relationships, which need a
+// join table, are left out.
+func NewSchema(snap *domain.DiagramSnapshot) *Schema {
+	s := newShape(snap)
+	out := &Schema{Dialect: sqlgen.Generic, Time: time.Now().UTC()}
+	if d := snap.Diagram; d != nil {
+		out.Name = d.Name
+		if !d.UpdatedAt.IsZero() {
+			out.Time = d.UpdatedAt.UTC()
+		}
+		if db, ok := d.Content["databaseType"].(string); ok {
+			if dialect, ok := sqlgen.ParseDialect(db); ok {
+				out.Dialect = dialect
+			}
+		}
+	}
+
+	enums := make(map[string]*Enum)
+	for _, ct := range s.enumCTs {
+		e := &Enum{Name: s.enums[strings.ToLower(ct.Type)], Type: ct.Type, Schema: ct.Schema, Values: ct.ValueList()}
+		enums[e.Name] = e
+		out.Enums = append(out.Enums, e)
+	}
+
+	models := make(map[string]*Model, len(snap.Tables))
+	columns := make(map[string]*Column) // Field ID -> column
+	for _, t := range snap.Tables {
+		m := &Model{Name: s.types[t.TableID], Table: t.Name, Schema: t.Schema, View: t.IsView, Comment: t.Comments}
+		attrs := make(uniqueNames)
+		for _, f := range t.FieldList() {
+			cl := s.column(f)
+			c := &Column{
+				Name:          f.Name,
+				Attr:          attrs.take(identifier(Snake(f.Name), "f")),
+				Type:          baseType(f.Type),
+				Kind:          cl.kind,
+				Enum:          enums[cl.enum],
+				List:          cl.list,
+				Length:        cl.maxLength,
+				Precision:     f.Precision,
+				Scale:         f.Scale,
+				Nullable:      cl.nullable(),
+				PrimaryKey:    f.PrimaryKey,
+				Unique:        f.Unique,
+				AutoIncrement: f.Increment || strings.HasSuffix(baseType(f.Type), "serial"),
+				Default:       f.Default,
+				Comment:       f.Comments,
+			}
+			m.Columns = append(m.Columns, c)
+			columns[f.ID] = c
+		}
+		for _, idx := range t.IndexList() {
+			index := &Index{Name: idx.Name, Unique: idx.Unique}
+			for _, id := range idx.FieldIDs {
+				if c, ok := columns[id]; ok {
+					index.Columns = append(index.Columns, c)
+				}
+			}
+			if len(index.Columns) > 0 {
+				m.Indexes = append(m.Indexes, index)
+			}
+		}
+		models[t.TableID] = m
+		out.Models = append(out.Models, m)
+	}
+
+	out.relations(snap.Relationships, models, columns)
+	return out
+}
+
+// relations pairs the sides of every foreign key. Relationships sharing
+// tables and a non-empty name form one composite foreign key.
+func (s *Schema) relations(rels []domain.Relationship, models map[string]*Model, columns map[string]*Column) {
+	type group struct {
+		label         string
+		child, parent *Model
+		fks, refs     []*Column
+		one           bool
+	}
+	groups := make([]*group, 0)
+	byKey := make(map[string]*group)
+	for _, r := range rels {
+		childID, fkID := r.ForeignKey()
+		parentID, refID := r.TargetTableID, r.TargetFieldID
+		childMany, parentMany := r.SourceCardinality == domain.CardinalityMany, r.TargetCardinality == domain.CardinalityMany
+		if childID == r.TargetTableID && fkID == r.TargetFieldID {
+			parentID, refID = r.SourceTableID, r.SourceFieldID
+			childMany, parentMany = parentMany, childMany
+		}
+		child, parent := models[childID], models[parentID]
+		fk, ref := columns[fkID], columns[refID]
+		if child == nil || parent == nil || fk == nil || ref == nil || (childMany && parentMany) {
+			continue
+		}
+		key := childID + "\x00" + parentID + "\x00" + r.Name
+		g, ok := byKey[key]
+		if !ok || r.Name == "" {
+			g = &group{label: r.Name, child: child, parent: parent, one: !childMany}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.fks = append(g.fks, fk)
+		g.refs = append(g.refs, ref)
+	}
+
+	taken := make(map[*Model]uniqueNames)
+	names := func(m *Model) uniqueNames {
+		if taken[m] == nil {
+			taken[m] = make(uniqueNames)
+			for _, c := range m.Columns {
+				taken[m].take(c.Attr)
+			}
+		}
+		return taken[m]
+	}
+
+	for _, g := range groups {
+		optional := false
+		for _, fk := range g.fks {
+			optional = optional || fk.Nullable
+		}
+		name := ""
+		if len(g.fks) == 1 && strings.HasSuffix(g.fks[0].Attr, "_id") && len(g.fks[0].Attr) > 3 {
+			name = strings.TrimSuffix(g.fks[0].Attr, "_id")
+		}
+		if name == "" {
+			name = Snake(g.parent.Name)
+		}
+		belongs := &Relation{
+			Name:       names(g.child).take(name),
+			Kind:       BelongsTo,
+			Target:     g.parent,
+			Columns:    g.fks,
+			References: g.refs,
+			Optional:   optional,
+			Label:      g.label,
+		}
+
+		inverse := &Relation{Kind: HasMany, Target: g.child, Columns: g.refs, References: g.fks, Optional: optional, Label: g.label}
+		if g.one {
+			inverse.Kind, inverse.Name = HasOne, names(g.parent).take(Snake(g.child.Name))
+		} else {
+			inverse.Name = names(g.parent).take(Snake(Plural(g.child.Name)))
+		}
+		belongs.Inverse, inverse.Inverse = inverse, belongs
+		g.child.Relations = append(g.child.Relations, belongs)
+		g.parent.Relations = append(g.parent.Relations, inverse)
+	}
+}
+
+// baseType lower-cases a SQL type and strips its arguments and array suffix
+func baseType(t string) string {
+	base := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(t)), "[]")
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		base = strings.TrimSpace(base[:i])
+	}
+	return base
+}
@@ -0,0 +1,290 @@
+package codegen
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// MaxDepth bounds the depth option of the API schema generators
+const MaxDepth = 5
+
+// Kind is the portable class of a column type
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInteger
+	KindNumber
+	KindDecimal
+	KindBoolean
+	KindDate
+	KindTime
+	KindDateTime
+	KindUUID
+	KindJSON
+	KindBytes
+	KindEnum
+)
+
+// column is a field classified for the API schema generators
+type column struct {
+	field     domain.Field
+	kind      Kind
+	list      bool
+	enum      string // Type name of an enum column
+	maxLength int    // Zero when unbounded
+	min, max  float64
+	bounded   bool // min and max apply
+}
+
+// kinds maps lower-case SQL type names to kinds; anything else is a string
+var kinds = map[string]Kind{
+	"smallint": KindInteger, "int2": KindInteger, "smallserial": KindInteger, "tinyint": KindInteger,
+	"int": KindInteger, "integer": KindInteger, "int4": KindInteger, "mediumint": KindInteger, "serial": KindInteger,
+	"bigint": KindInteger, "int8": KindInteger, "bigserial": KindInteger,
+	"real": KindNumber, "float": KindNumber, "float4": KindNumber, "float8": KindNumber,
+	"double": KindNumber, "double precision": KindNumber,
+	"decimal": KindDecimal, "numeric": KindDecimal, "money": KindDecimal, "number": KindDecimal,
+	"bool": KindBoolean, "boolean": KindBoolean, "bit": KindBoolean,
+	"date": KindDate, "time": KindTime, "timetz": KindTime,
+	"datetime": KindDateTime, "datetime2": KindDateTime, "smalldatetime": KindDateTime,
+	"timestamp": KindDateTime, "timestamptz": KindDateTime,
+	"timestamp with time zone": KindDateTime, "timestamp without time zone": KindDateTime,
+	"uuid": KindUUID, "uniqueidentifier": KindUUID,
+	"json": KindJSON, "jsonb": KindJSON,
+	"bytea": KindBytes, "blob": KindBytes, "longblob": KindBytes, "binary": KindBytes, "varbinary": KindBytes, "image": KindBytes,
+}
+
+// intRanges bounds the integer types narrower than 53 bits
+var intRanges = map[string][2]float64{
+	"tinyint":     {math.MinInt8, math.MaxInt8},
+	"smallint":    {math.MinInt16, math.MaxInt16},
+	"int2":        {math.MinInt16, math.MaxInt16},
+	"smallserial": {1, math.MaxInt16},
+	"int":         {math.MinInt32, math.MaxInt32},
+	"integer":     {math.MinInt32, math.MaxInt32},
+	"int4":        {math.MinInt32, math.MaxInt32},
+	"serial":      {1, math.MaxInt32},
+	"mediumint":   {-1 << 23, 1<<23 - 1},
+}
+
+// navigation is a property holding related rows, found by following a relationship
+type navigation struct {
+	name     string
+	tableID  string
+	many     bool
+	nullable bool
+}
+
+// shape holds a snapshot with the names the API schema generators give its
+// tables and enums
+type shape struct {
+	snap    *domain.DiagramSnapshot
+	tables  map[string]domain.Table
+	types   map[string]string // Table ID -> type name
+	enums   map[string]string // Lower-case custom type name -> type name
+	enumCTs []domain.CustomType
+	snake   bool // Property names follow snake_case columns
+}
+
+func newShape(snap *domain.DiagramSnapshot) *shape {
+	s := &shape{
+		snap:   snap,
+		tables: make(map[string]domain.Table, len(snap.Tables)),
+		types:  make(map[string]string, len(snap.Tables)),
+		enums:  make(map[string]string),
+	}
+	names := make(uniqueNames)
+	for _, ct := range snap.CustomTypes {
+		if ct.Kind == "enum" {
+			s.enums[strings.ToLower(ct.Type)] = names.take(identifier(Pascal(ct.Type), "Type"))
+			s.enumCTs = append(s.enumCTs, ct)
+		}
+	}
+	for _, t := range snap.Tables {
+		s.tables[t.TableID] = t
+		s.types[t.TableID] = names.take(identifier(Pascal(Singular(t.Name)), "Table"))
+		for _, f := range t.FieldList() {
+			s.snake = s.snake || strings.Contains(f.Name, "_")
+		}
+	}
+	return s
+}
+
+// column classifies a field
+func (s *shape) column(f domain.Field) column {
+	c := column{field: f}
+	base := strings.ToLower(strings.TrimSpace(f.Type))
+	if strings.HasSuffix(base, "[]") {
+		c.list, base = true, strings.TrimSuffix(base, "[]")
+	}
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		base = strings.TrimSpace(base[:i])
+	}
+
+	if name, ok := s.enums[base]; ok {
+		c.kind, c.enum = KindEnum, name
+		return c
+	}
+	c.kind = kinds[base]
+	switch c.kind {
+	case KindString:
+		if n, err := strconv.Atoi(f.CharacterMaximumLength); err == nil && n > 0 {
+			c.maxLength = n
+		}
+	case KindInteger:
+		if r, ok := intRanges[base]; ok {
+			c.min, c.max, c.bounded = r[0], r[1], true
+		}
+		// MySQL booleans are usually tinyint(1)
+		if base == "tinyint" && f.CharacterMaximumLength == "1" {
+			c.kind, c.bounded = KindBoolean, false
+		}
+	case KindDecimal:
+		if f.Precision != nil {
+			scale := 0
+			if f.Scale != nil {
+				scale = *f.Scale
+			}
+			limit := math.Pow10(*f.Precision - scale)
+			c.min, c.max, c.bounded = -limit, limit, true
+		}
+	}
+	return c
+}
+
+// step is the multipleOf of a decimal column, zero when unknown
+func (c column) step() float64 {
+	if c.kind != KindDecimal || c.field.Scale == nil {
+		return 0
+	}
+	return math.Pow10(-*c.field.Scale)
+}
+
+// nullable reports whether the column accepts null
+func (c column) nullable() bool {
+	return c.field.Nullable && !c.field.PrimaryKey
+}
+
+// navigations lists the related-row properties of a table, named so they do
+// not clash with its columns: one parent per foreign key, and one child or a
+// list of children per referencing table. Many-to-many relationships are skipped.
+func (s *shape) navigations(t domain.Table) []navigation {
+	taken := make(uniqueNames)
+	for _, f := range t.FieldList() {
+		taken.take(f.Name)
+	}
+	navs := make([]navigation, 0)
+	for _, r := range s.snap.Relationships {
+		childID, fkID := r.ForeignKey()
+		parentID := r.TargetTableID
+		childMany, parentMany := r.SourceCardinality == domain.CardinalityMany, r.TargetCardinality == domain.CardinalityMany
+		if childID == r.TargetTableID && fkID == r.TargetFieldID {
+			parentID = r.SourceTableID
+			childMany, parentMany = parentMany, childMany
+		}
+		child, okChild := s.tables[childID]
+		_, okParent := s.tables[parentID]
+		if !okChild || !okParent || (childMany && parentMany) {
+			continue
+		}
+
+		if t.TableID == childID {
+			fk, _ := child.FieldByID(fkID)
+			name := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(fk.Name, "_id"), "Id"), "ID")
+			if name == "" || name == fk.Name {
+				name = s.property(s.types[parentID])
+			}
+			navs = append(navs, navigation{name: taken.take(name), tableID: parentID, nullable: fk.Nullable})
+		}
+		if t.TableID == parentID {
+			if childMany {
+				navs = append(navs, navigation{name: taken.take(s.property(Plural(s.types[childID]))), tableID: childID, many: true})
+			} else {
+				navs = append(navs, navigation{name: taken.take(s.property(s.types[childID])), tableID: childID, nullable: true})
+			}
+		}
+	}
+	return navs
+}
+
+// nesting names the types of rows with their related rows nested some levels
+// deep. Each table and level is described once and refers to the next level
+// down by name, so the output grows with tables times depth rather than
+// exponentially with depth.
+type nesting struct {
+	shape *shape
+	depth int // Level of the roots, named TypeWithRelations
+	names map[nestKey]string
+	taken uniqueNames
+	order []nestKey // Levels in the order they were first named
+}
+
+type nestKey struct {
+	tableID string
+	depth   int
+}
+
+func (s *shape) nesting(depth int) *nesting {
+	taken := make(uniqueNames)
+	for _, name := range s.types {
+		taken.take(name)
+	}
+	for _, name := range s.enums {
+		taken.take(name)
+	}
+	return &nesting{shape: s, depth: depth, names: make(map[nestKey]string), taken: taken}
+}
+
+// name returns the type of a table's rows with related rows nested depth
+// levels, queueing it to be written; at depth 0 it is the plain table type
+func (n *nesting) name(tableID string, depth int) string {
+	k := nestKey{tableID, depth}
+	if name, ok := n.names[k]; ok {
+		return name
+	}
+	name := n.shape.types[tableID]
+	switch {
+	case depth == n.depth && depth > 0:
+		name = n.taken.take(name + "WithRelations")
+	case depth > 0:
+		name = n.taken.take(name + "WithRelations" + strconv.Itoa(depth))
+	}
+	n.names[k] = name
+	n.order = append(n.order, k)
+	return name
+}
+
+// property converts a type name to the property naming style of the columns
+func (s *shape) property(typeName string) string {
+	if s.snake {
+		return Snake(typeName)
+	}
+	return Camel(typeName)
+}
+
+// options reads the table and depth options of the API schema generators
+func (s *shape) options(opts map[string]string) (*domain.Table, int, error) {
+	depth := 0
+	if d := opts["depth"]; d != "" {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 0 || n > MaxDepth {
+			return nil, 0, fmt.Errorf("%w: depth must be between 0 and %d", domain.ErrBadParamInput, MaxDepth)
+		}
+		depth = n
+	}
+	ref := opts["table"]
+	if ref == "" {
+		return nil, depth, nil
+	}
+	for _, t := range s.snap.Tables {
+		if t.TableID == ref || t.Name == ref || t.QualifiedName() == ref {
+			return &t, depth, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("%w: table %q", domain.ErrNotFound, ref)
+}
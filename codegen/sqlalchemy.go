@@ -0,0 +1,345 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SQLAlchemy generates SQLAlchemy 2.0 declarative models with typed
+// Mapped attributes and relationship() back-references
+type SQLAlchemy struct{}
+
+func (SQLAlchemy) Generate(s *Schema, _ map[string]string) ([]File, error) {
+	w := &sqlalchemyWriter{schema: s, imports: make(map[string]map[string]bool)}
+	for _, e := range s.Enums {
+		w.enum(e)
+	}
+	for _, m := range s.Models {
+		w.model(m)
+	}
+	return []File{{Path: "models.py", Content: w.bytes()}}, nil
+}
+
+type sqlalchemyWriter struct {
+	schema  *Schema
+	b       strings.Builder
+	imports map[string]map[string]bool // Module -> names
+	modules map[string]bool            // Plain "import x" statements
+}
+
+func (w *sqlalchemyWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&w.b, format, args...)
+}
+
+// use records an import of name from module and returns the name
+func (w *sqlalchemyWriter) use(module, name string) string {
+	if w.imports[module] == nil {
+		w.imports[module] = make(map[string]bool)
+	}
+	w.imports[module][name] = true
+	return name
+}
+
+// module records a plain import and returns the qualified name
+func (w *sqlalchemyWriter) module(module, name string) string {
+	if w.modules == nil {
+		w.modules = make(map[string]bool)
+	}
+	w.modules[module] = true
+	return module + "." + name
+}
+
+func (w *sqlalchemyWriter) bytes() []byte {
+	w.use("sqlalchemy.orm", "DeclarativeBase")
+
+	var head strings.Builder
+	head.WriteString("# Code generated by vertex-diagram. DO NOT EDIT.\n\n")
+	head.WriteString("from __future__ import annotations\n\n")
+	// Standard library imports first, then SQLAlchemy
+	plain, std, third := make([]string, 0), make([]string, 0), make([]string, 0)
+	for m := range w.modules {
+		plain = append(plain, "import "+m)
+	}
+	for m, names := range w.imports {
+		list := make([]string, 0, len(names))
+		for n := range names {
+			list = append(list, n)
+		}
+		sort.Strings(list)
+		line := "from " + m + " import " + strings.Join(list, ", ")
+		if strings.HasPrefix(m, "sqlalchemy") {
+			third = append(third, line)
+		} else {
+			std = append(std, line)
+		}
+	}
+	sort.Strings(plain)
+	sort.Strings(std)
+	sort.Strings(third)
+	std = append(plain, std...)
+	if len(std) > 0 {
+		head.WriteString(strings.Join(std, "\n") + "\n\n")
+	}
+	head.WriteString(strings.Join(third, "\n") + "\n")
+	head.WriteString("\n\nclass Base(DeclarativeBase):\n    pass\n")
+	return []byte(head.String() + strings.TrimRight(w.b.String(), "\n") + "\n")
+}
+
+func (w *sqlalchemyWriter) enum(e *Enum) {
+	w.printf("\n\nclass %s(%s):\n", e.Name, w.module("enum", "Enum"))
+	if len(e.Values) == 0 {
+		w.printf("    pass\n")
+		return
+	}
+	members := make(uniqueNames)
+	for _, v := range e.Values {
+		name := members.take(identifier(strings.ToUpper(Snake(v)), "VALUE_"))
+		w.printf("    %s = %s\n", name, pyString(v))
+	}
+}
+
+func (w *sqlalchemyWriter) model(m *Model) {
+	mapped := w.use("sqlalchemy.orm", "Mapped")
+	w.printf("\n\nclass %s(Base):\n", m.Name)
+	pyDocstring(&w.b, "    ", m.Comment)
+	if len(m.PrimaryKey()) == 0 {
+		w.printf("    # No primary key: SQLAlchemy needs one to map this table\n")
+	}
+	w.printf("    __tablename__ = %s\n", pyString(m.Table))
+	if args := w.tableArgs(m); args != "" {
+		w.printf("    __table_args__ = %s\n", args)
+	}
+	w.b.WriteString("\n")
+
+	for _, c := range m.Columns {
+		typ := w.pyType(c)
+		if c.Nullable {
+			typ = w.use("typing", "Optional") + "[" + typ + "]"
+		}
+		line := fmt.Sprintf("    %s: %s[%s] = %s(%s)", pyName(c.Attr), mapped, typ, w.use("sqlalchemy.orm", "mapped_column"), strings.Join(w.columnArgs(m, c), ", "))
+		if c.Kind == KindString && !characterTypes[c.Type] && c.Type != "" {
+			line += "  # Database type: " + c.Type
+		}
+		w.printf("%s\n", line)
+	}
+
+	if len(m.Relations) > 0 {
+		w.b.WriteString("\n")
+	}
+	for _, r := range m.Relations {
+		relationship := w.use("sqlalchemy.orm", "relationship")
+		target := pyString(r.Target.Name)
+		args := []string{"back_populates=" + pyString(pyName(r.Inverse.Name))}
+		var typ string
+		switch r.Kind {
+		case BelongsTo:
+			typ = target
+			if r.Optional {
+				typ = w.use("typing", "Optional") + "[" + target + "]"
+			}
+			args = append(args, "foreign_keys=["+strings.Join(pyNames(r.Columns), ", ")+"]")
+			if r.Target == m {
+				args = append(args, "remote_side=["+strings.Join(pyNames(r.References), ", ")+"]")
+			}
+		case HasOne:
+			typ = w.use("typing", "Optional") + "[" + target + "]"
+			args = append(args, "foreign_keys="+pyString(foreignKeys(r)))
+		case HasMany:
+			typ = w.use("typing", "List") + "[" + target + "]"
+			args = append(args, "foreign_keys="+pyString(foreignKeys(r)))
+		}
+		w.printf("    %s: %s[%s] = %s(%s)\n", pyName(r.Name), mapped, typ, relationship, strings.Join(args, ", "))
+	}
+}
+
+// foreignKeys lists the foreign key attributes of the target, for the
+// referenced side of a relation
+func foreignKeys(r *Relation) string {
+	keys := make([]string, 0, len(r.References))
+	for _, c := range r.References {
+		keys = append(keys, r.Target.Name+"."+pyName(c.Attr))
+	}
+	return "[" + strings.Join(keys, ", ") + "]"
+}
+
+func pyNames(columns []*Column) []string {
+	out := make([]string, 0, len(columns))
+	for _, c := range columns {
+		out = append(out, pyName(c.Attr))
+	}
+	return out
+}
+
+// tableArgs returns the __table_args__ tuple: indexes, composite foreign
+// keys and a trailing dict with the schema and comment
+func (w *sqlalchemyWriter) tableArgs(m *Model) string {
+	args := make([]string, 0)
+	for _, idx := range m.Indexes {
+		name := idx.Name
+		if name == "" {
+			prefix := "ix_"
+			if idx.Unique {
+				prefix = "uq_"
+			}
+			name = prefix + m.Table + "_" + strings.Join(attrs(idx.Columns), "_")
+		}
+		parts := []string{pyString(name)}
+		for _, c := range idx.Columns {
+			parts = append(parts, pyString(c.Name))
+		}
+		if idx.Unique {
+			parts = append(parts, "unique=True")
+		}
+		args = append(args, w.use("sqlalchemy", "Index")+"("+strings.Join(parts, ", ")+")")
+	}
+	for _, r := range m.Relations {
+		if r.Kind != BelongsTo || len(r.Columns) == 1 {
+			continue
+		}
+		local, remote := make([]string, 0), make([]string, 0)
+		for i, c := range r.Columns {
+			local = append(local, pyString(c.Name))
+			remote = append(remote, pyString(r.Target.QualifiedTable()+"."+r.References[i].Name))
+		}
+		args = append(args, fmt.Sprintf("%s([%s], [%s])", w.use("sqlalchemy", "ForeignKeyConstraint"), strings.Join(local, ", "), strings.Join(remote, ", ")))
+	}
+
+	kwargs := make([]string, 0)
+	if m.Schema != "" {
+		kwargs = append(kwargs, `"schema": `+pyString(m.Schema))
+	}
+	if m.Comment != "" {
+		kwargs = append(kwargs, `"comment": `+pyString(m.Comment))
+	}
+	if len(kwargs) > 0 {
+		args = append(args, "{"+strings.Join(kwargs, ", ")+"}")
+	}
+	switch len(args) {
+	case 0:
+		return ""
+	case 1:
+		if len(kwargs) > 0 {
+			return args[0]
+		}
+		return "(" + args[0] + ",)"
+	}
+	return "(\n        " + strings.Join(args, ",\n        ") + ",\n    )"
+}
+
+// columnArgs returns the mapped_column() arguments of a column
+func (w *sqlalchemyWriter) columnArgs(m *Model, c *Column) []string {
+	args := make([]string, 0, 4)
+	if pyName(c.Attr) != c.Name {
+		args = append(args, pyString(c.Name))
+	}
+	args = append(args, w.saType(c))
+	if r := m.ForeignKey(c); r != nil {
+		args = append(args, w.use("sqlalchemy", "ForeignKey")+"("+pyString(r.Target.QualifiedTable()+"."+r.References[0].Name)+")")
+	}
+	if c.PrimaryKey {
+		args = append(args, "primary_key=True")
+	}
+	if c.AutoIncrement {
+		args = append(args, "autoincrement=True")
+	}
+	if c.Unique && !c.PrimaryKey {
+		args = append(args, "unique=True")
+	}
+	if c.Default != "" && !c.AutoIncrement {
+		args = append(args, "server_default="+w.use("sqlalchemy", "text")+"("+pyString(c.Default)+")")
+	}
+	if c.Comment != "" {
+		args = append(args, "comment="+pyString(c.Comment))
+	}
+	return args
+}
+
+// pyType returns the Python type of a column value
+func (w *sqlalchemyWriter) pyType(c *Column) string {
+	var typ string
+	switch c.Kind {
+	case KindEnum:
+		typ = c.Enum.Name
+	case KindInteger:
+		typ = "int"
+	case KindNumber:
+		typ = "float"
+	case KindDecimal:
+		typ = w.module("decimal", "Decimal")
+	case KindBoolean:
+		typ = "bool"
+	case KindDate:
+		typ = w.module("datetime", "date")
+	case KindTime:
+		typ = w.module("datetime", "time")
+	case KindDateTime:
+		typ = w.module("datetime", "datetime")
+	case KindUUID:
+		typ = w.module("uuid", "UUID")
+	case KindJSON:
+		typ = w.use("typing", "Any")
+	case KindBytes:
+		typ = "bytes"
+	default:
+		typ = "str"
+	}
+	if c.List {
+		typ = w.use("typing", "List") + "[" + typ + "]"
+	}
+	return typ
+}
+
+// saType returns the SQLAlchemy column type of a column
+func (w *sqlalchemyWriter) saType(c *Column) string {
+	var typ string
+	switch c.Kind {
+	case KindEnum:
+		args := []string{c.Enum.Name, "name=" + pyString(c.Enum.Type), "values_callable=lambda e: [m.value for m in e]"}
+		if c.Enum.Schema != "" {
+			args = append(args, "schema="+pyString(c.Enum.Schema))
+		}
+		typ = w.use("sqlalchemy", "Enum") + "(" + strings.Join(args, ", ") + ")"
+	case KindInteger:
+		typ = w.use("sqlalchemy", map[int]string{16: "SmallInteger", 32: "Integer", 64: "BigInteger"}[integerSize(c.Type)])
+	case KindNumber:
+		typ = w.use("sqlalchemy", "Float")
+	case KindDecimal:
+		typ = w.use("sqlalchemy", "Numeric")
+		if c.Precision != nil {
+			typ += "(" + strconv.Itoa(*c.Precision)
+			if c.Scale != nil {
+				typ += ", " + strconv.Itoa(*c.Scale)
+			}
+			typ += ")"
+		}
+	case KindBoolean:
+		typ = w.use("sqlalchemy", "Boolean")
+	case KindDate:
+		typ = w.use("sqlalchemy", "Date")
+	case KindTime:
+		typ = w.use("sqlalchemy", "Time")
+	case KindDateTime:
+		typ = w.use("sqlalchemy", "DateTime")
+		if c.Type == "timestamptz" || strings.Contains(c.Type, "with time zone") || c.Type == "datetimeoffset" {
+			typ += "(timezone=True)"
+		}
+	case KindUUID:
+		typ = w.use("sqlalchemy", "Uuid")
+	case KindJSON:
+		typ = w.use("sqlalchemy", "JSON")
+	case KindBytes:
+		typ = w.use("sqlalchemy", "LargeBinary")
+	default:
+		if c.Length > 0 {
+			typ = w.use("sqlalchemy", "String") + "(" + strconv.Itoa(c.Length) + ")"
+		} else {
+			typ = w.use("sqlalchemy", "Text")
+		}
+	}
+	if c.List {
+		typ = w.use("sqlalchemy", "ARRAY") + "(" + typ + ")"
+	}
+	return typ
+}
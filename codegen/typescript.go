@@ -0,0 +1,328 @@
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// TypeScript implements domain.Exporter, generating an interface per table
+// and a union type per enum. Options as for JSONSchema: with depth > 0 a
+// WithRelations interface adds the related objects, typed with one
+// WithRelations<level> interface per table and remaining level.
+type TypeScript struct{}
+
+func (TypeScript) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (TypeScript) Extension() string { return "ts" }
+
+func (TypeScript) Export(snap *domain.DiagramSnapshot, opts map[string]string) ([]byte, error) {
+	s := newShape(snap)
+	table, depth, err := s.options(opts)
+	if err != nil {
+		return nil, err
+	}
+	tables, enums := s.reachable(table, depth)
+
+	var b strings.Builder
+	b.WriteString("// Code generated by vertex-diagram. DO NOT EDIT.\n\n")
+	for _, ct := range enums {
+		name := s.enums[strings.ToLower(ct.Type)]
+		values := quoteAll(ct.ValueList())
+		if len(values) == 0 {
+			values = []string{"string"}
+		}
+		fmt.Fprintf(&b, "/** The %s enum */\nexport type %s = %s;\n\n", tsComment(ct.Type), name, strings.Join(values, " | "))
+	}
+	for _, t := range tables {
+		writeJSDoc(&b, "", t.Comments)
+		fmt.Fprintf(&b, "export interface %s {\n", s.types[t.TableID])
+		for _, f := range t.FieldList() {
+			writeJSDoc(&b, "  ", f.Comments)
+			fmt.Fprintf(&b, "  %s: %s;\n", propertyKey(f.Name), s.tsType(s.column(f)))
+		}
+		b.WriteString("}\n\n")
+	}
+	if depth > 0 {
+		n := s.nesting(depth)
+		for _, t := range s.roots(table) {
+			n.name(t.TableID, depth)
+		}
+		for i := 0; i < len(n.order); i++ {
+			k := n.order[i]
+			if k.depth == 0 {
+				continue
+			}
+			t := s.tables[k.tableID]
+			fmt.Fprintf(&b, "export interface %s extends %s {\n", n.names[k], s.types[t.TableID])
+			for _, nav := range s.navigations(t) {
+				fmt.Fprintf(&b, "  %s?: %s;\n", propertyKey(nav.name), tsNavigation(nav, n.name(nav.tableID, k.depth-1)))
+			}
+			b.WriteString("}\n\n")
+		}
+	}
+	return []byte(strings.TrimRight(b.String(), "\n") + "\n"), nil
+}
+
+func (s *shape) tsType(c column) string {
+	var typ string
+	switch c.kind {
+	case KindEnum:
+		typ = c.enum
+	case KindInteger, KindNumber, KindDecimal:
+		typ = "number"
+	case KindBoolean:
+		typ = "boolean"
+	case KindJSON:
+		typ = "unknown"
+	default:
+		typ = "string"
+	}
+	if c.list {
+		typ += "[]"
+	}
+	if c.nullable() && c.kind != KindJSON {
+		typ += " | null"
+	}
+	return typ
+}
+
+// tsNavigation types a related object as the named type of its level
+func tsNavigation(n navigation, typ string) string {
+	switch {
+	case n.many:
+		return typ + "[]"
+	case n.nullable:
+		return typ + " | null"
+	}
+	return typ
+}
+
+// Zod implements domain.Exporter, generating a Zod schema and inferred type
+// per table and enum. Options as for TypeScript.
+type Zod struct{}
+
+func (Zod) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (Zod) Extension() string { return "ts" }
+
+func (Zod) Export(snap *domain.DiagramSnapshot, opts map[string]string) ([]byte, error) {
+	s := newShape(snap)
+	table, depth, err := s.options(opts)
+	if err != nil {
+		return nil, err
+	}
+	tables, enums := s.reachable(table, depth)
+
+	var b strings.Builder
+	b.WriteString("// Code generated by vertex-diagram. DO NOT EDIT.\n\nimport { z } from \"zod\";\n\n")
+	for _, ct := range enums {
+		name := s.enums[strings.ToLower(ct.Type)]
+		schema := "z.string()"
+		if values := quoteAll(ct.ValueList()); len(values) > 0 {
+			schema = "z.enum([" + strings.Join(values, ", ") + "])"
+		}
+		fmt.Fprintf(&b, "export const %sSchema = %s;\nexport type %s = z.infer<typeof %sSchema>;\n\n", name, schema, name, name)
+	}
+	for _, t := range tables {
+		name := s.types[t.TableID]
+		writeJSDoc(&b, "", t.Comments)
+		fmt.Fprintf(&b, "export const %sSchema = z.object({\n", name)
+		for _, f := range t.FieldList() {
+			fmt.Fprintf(&b, "  %s: %s,\n", propertyKey(f.Name), s.zodType(s.column(f)))
+		}
+		fmt.Fprintf(&b, "});\nexport type %s = z.infer<typeof %sSchema>;\n\n", name, name)
+	}
+	if depth > 0 {
+		n := s.nesting(depth)
+		for _, t := range s.roots(table) {
+			n.name(t.TableID, depth)
+		}
+		for i := 0; i < len(n.order); i++ {
+			for _, nav := range s.navigations(s.tables[n.order[i].tableID]) {
+				if n.order[i].depth > 0 {
+					n.name(nav.tableID, n.order[i].depth-1)
+				}
+			}
+		}
+		// A schema constant must be declared before the levels above use it
+		levels := slices.Clone(n.order)
+		sort.SliceStable(levels, func(i, j int) bool { return levels[i].depth < levels[j].depth })
+		for _, k := range levels {
+			if k.depth == 0 {
+				continue
+			}
+			t := s.tables[k.tableID]
+			name := n.names[k]
+			fmt.Fprintf(&b, "export const %sSchema = %sSchema.extend({\n", name, s.types[t.TableID])
+			for _, nav := range s.navigations(t) {
+				fmt.Fprintf(&b, "  %s: %s.optional(),\n", propertyKey(nav.name), zodNavigation(nav, n.name(nav.tableID, k.depth-1)+"Schema"))
+			}
+			fmt.Fprintf(&b, "});\nexport type %s = z.infer<typeof %sSchema>;\n\n", name, name)
+		}
+	}
+	return []byte(strings.TrimRight(b.String(), "\n") + "\n"), nil
+}
+
+func (s *shape) zodType(c column) string {
+	var schema string
+	switch c.kind {
+	case KindEnum:
+		schema = c.enum + "Schema"
+	case KindInteger:
+		schema = "z.number().int()"
+		if c.bounded {
+			schema += ".min(" + number(c.min) + ").max(" + number(c.max) + ")"
+		}
+	case KindNumber:
+		schema = "z.number()"
+	case KindDecimal:
+		schema = "z.number()"
+		if c.bounded {
+			schema += ".gt(" + number(c.min) + ").lt(" + number(c.max) + ")"
+		}
+		if step := c.step(); step > 0 {
+			schema += ".multipleOf(" + number(step) + ")"
+		}
+	case KindBoolean:
+		schema = "z.boolean()"
+	case KindDate:
+		schema = "z.string().date()"
+	case KindTime:
+		schema = "z.string().time()"
+	case KindDateTime:
+		schema = "z.string().datetime({ offset: true })"
+	case KindUUID:
+		schema = "z.string().uuid()"
+	case KindBytes:
+		schema = "z.string().base64()"
+	case KindJSON:
+		schema = "z.unknown()"
+	default:
+		schema = "z.string()"
+		if c.maxLength > 0 {
+			schema += ".max(" + strconv.Itoa(c.maxLength) + ")"
+		}
+	}
+	if c.list {
+		schema = "z.array(" + schema + ")"
+	}
+	if c.nullable() && c.kind != KindJSON {
+		schema += ".nullable()"
+	}
+	if c.field.Comments != "" {
+		schema += ".describe(" + strconv.Quote(c.field.Comments) + ")"
+	}
+	return schema
+}
+
+// zodNavigation mirrors tsNavigation with schemas
+func zodNavigation(n navigation, schema string) string {
+	switch {
+	case n.many:
+		return "z.array(" + schema + ")"
+	case n.nullable:
+		return schema + ".nullable()"
+	}
+	return schema
+}
+
+// roots returns the tables the output is about: the selected one or all of them
+func (s *shape) roots(table *domain.Table) []domain.Table {
+	if table != nil {
+		return []domain.Table{*table}
+	}
+	return s.snap.Tables
+}
+
+// reachable returns, in diagram order, the root tables and those related to
+// them within depth steps, with the enums their columns use
+func (s *shape) reachable(table *domain.Table, depth int) ([]domain.Table, []domain.CustomType) {
+	included := make(map[string]bool)
+	frontier := make([]string, 0)
+	for _, t := range s.roots(table) {
+		included[t.TableID] = true
+		frontier = append(frontier, t.TableID)
+	}
+	for level := 0; level < depth && table != nil; level++ {
+		next := make([]string, 0)
+		for _, id := range frontier {
+			for _, n := range s.navigations(s.tables[id]) {
+				if !included[n.tableID] {
+					included[n.tableID] = true
+					next = append(next, n.tableID)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	tables := make([]domain.Table, 0, len(included))
+	used := make(map[string]bool)
+	for _, t := range s.snap.Tables {
+		if !included[t.TableID] {
+			continue
+		}
+		tables = append(tables, t)
+		for _, f := range t.FieldList() {
+			if c := s.column(f); c.kind == KindEnum {
+				used[c.enum] = true
+			}
+		}
+	}
+	enums := make([]domain.CustomType, 0)
+	for _, ct := range s.enumCTs {
+		if table == nil || used[s.enums[strings.ToLower(ct.Type)]] {
+			enums = append(enums, ct)
+		}
+	}
+	return tables, enums
+}
+
+var jsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// propertyKey quotes property names that are not identifiers
+func propertyKey(name string) string {
+	if jsIdentifier.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+func quoteAll(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		out = append(out, strconv.Quote(v))
+	}
+	return out
+}
+
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func writeJSDoc(b *strings.Builder, indent, text string) {
+	if text == "" {
+		return
+	}
+	lines := strings.Split(tsComment(text), "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(b, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	fmt.Fprintf(b, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(b, "%s * %s\n", indent, strings.TrimRight(line, " "))
+	}
+	fmt.Fprintf(b, "%s */\n", indent)
+}
+
+// tsComment keeps text from closing a block comment
+func tsComment(s string) string {
+	return strings.ReplaceAll(s, "*/", "*\\/")
+}
@@ -0,0 +1,31 @@
+// Package dbml reads and writes DBML (https://dbml.dbdiagram.io).
+//
+// Tables, columns, indexes, refs, enums, table groups and sticky notes map
+// onto the diagram model; settings without a counterpart are reported as
+// warnings on import and never produced on export.
+package dbml
+
+import (
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Size given to imported sticky notes
+const (
+	noteWidth  = 240
+	noteHeight = 120
+)
+
+// Format implements domain.Importer and domain.Exporter for DBML
+type Format struct{}
+
+func (Format) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (Format) Extension() string { return "dbml" }
+
+func (Format) Export(snap *domain.DiagramSnapshot, _ map[string]string) ([]byte, error) {
+	return Write(snap), nil
+}
+
+func (Format) Import(data []byte) (*domain.DiagramSnapshot, []string, error) {
+	return Parse(string(data))
+}
@@ -0,0 +1,163 @@
+package dbml
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF     tokenKind = iota
+	tokNewline           // Column, value and ref lines end at a newline
+	tokWord              // Bare identifier, keyword, number or color
+	tokIdent             // "double quoted" identifier
+	tokString            // 'single' or '''triple''' quoted string
+	tokExpr              // `backtick` expression
+	tokPunct             // { } [ ] ( ) : , . < > - <>
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of input"
+	case tokNewline:
+		return "end of line"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex splits DBML source into tokens, dropping comments
+func lex(src string) ([]token, error) {
+	tokens := make([]token, 0)
+	rs := []rune(src)
+	line := 1
+	emit := func(kind tokenKind, text string) {
+		tokens = append(tokens, token{kind: kind, text: text, line: line})
+	}
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case r == '\n':
+			emit(tokNewline, "\n")
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '*':
+			end := strings.Index(string(rs[i+2:]), "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			body := []rune(string(rs[i+2:])[:end])
+			line += strings.Count(string(body), "\n")
+			i += 2 + len(body) + 2
+		case r == '\'' && i+2 < len(rs) && rs[i+1] == '\'' && rs[i+2] == '\'':
+			start := line
+			end := strings.Index(string(rs[i+3:]), "'''")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated string", start)
+			}
+			body := []rune(string(rs[i+3:])[:end])
+			tokens = append(tokens, token{kind: tokString, text: dedent(string(body)), line: start})
+			line += strings.Count(string(body), "\n")
+			i += 3 + len(body) + 3
+		case r == '\'' || r == '"' || r == '`':
+			kind := map[rune]tokenKind{'\'': tokString, '"': tokIdent, '`': tokExpr}[r]
+			start := line
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(rs) && rs[j] != r; j++ {
+				if rs[j] == '\\' && j+1 < len(rs) {
+					j++
+					switch rs[j] {
+					case 'n':
+						b.WriteRune('\n')
+					case 't':
+						b.WriteRune('\t')
+					default:
+						b.WriteRune(rs[j])
+					}
+					continue
+				}
+				if rs[j] == '\n' {
+					line++
+				}
+				b.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("line %d: unterminated %c", start, r)
+			}
+			tokens = append(tokens, token{kind: kind, text: b.String(), line: start})
+			i = j + 1
+		case r == '<' && i+1 < len(rs) && rs[i+1] == '>':
+			emit(tokPunct, "<>")
+			i += 2
+		case strings.ContainsRune("{}[](),:.<>-", r):
+			emit(tokPunct, string(r))
+			i++
+		case isWordRune(r) || r == '#':
+			j := i + 1
+			for j < len(rs) && isWordRune(rs[j]) {
+				j++
+			}
+			// Keep decimals such as 1.5 in one word
+			if unicode.IsDigit(r) && j+1 < len(rs) && rs[j] == '.' && unicode.IsDigit(rs[j+1]) {
+				j++
+				for j < len(rs) && unicode.IsDigit(rs[j]) {
+					j++
+				}
+			}
+			emit(tokWord, string(rs[i:j]))
+			i = j
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, r)
+		}
+	}
+	emit(tokEOF, "")
+	return tokens, nil
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// dedent trims a multi-line string the way DBML does: leading and trailing
+// blank lines are dropped along with the common indentation
+func dedent(s string) string {
+	lines := strings.Split(s, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			lines[i] = l[indent:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,986 @@
+package dbml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/sqlgen"
+)
+
+// endpoint is one side of a Ref: a table and one or more of its columns
+type endpoint struct {
+	parts   []string // [schema.]table
+	columns []string
+}
+
+type pendingRef struct {
+	name        string
+	left, right endpoint
+	op          string
+	line        int
+}
+
+type pendingGroup struct {
+	area    domain.Area
+	members [][]string
+	line    int
+}
+
+// tableDraft is a table whose field types may still refer to enums declared later
+type tableDraft struct {
+	table   domain.Table
+	alias   string
+	fields  []domain.Field
+	indexes []domain.Index
+}
+
+type parser struct {
+	toks     []token
+	pos      int
+	warnings []string
+	nextID   int
+
+	diagram *domain.Diagram
+	tables  []*tableDraft
+	enums   []domain.CustomType
+	notes   []domain.Note
+	refs    []pendingRef
+	groups  []pendingGroup
+}
+
+// Parse reads DBML into a snapshot. IDs are placeholders to be replaced by
+// the caller; canvas positions are left at zero. Constructs without a
+// counterpart in the model are reported as warnings.
+func Parse(src string) (*domain.DiagramSnapshot, []string, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &parser{
+		toks:    toks,
+		diagram: &domain.Diagram{Content: map[string]interface{}{}},
+	}
+	if err := p.parseFile(); err != nil {
+		return nil, p.warnings, err
+	}
+	return p.snapshot(), p.warnings, nil
+}
+
+func (p *parser) id(prefix string) string {
+	p.nextID++
+	return fmt.Sprintf("%s%d", prefix, p.nextID)
+}
+
+func (p *parser) warn(line int, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...))
+}
+
+// Token helpers
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) peekAt(n int) token {
+	if p.pos+n < len(p.toks) {
+		return p.toks[p.pos+n]
+	}
+	return p.toks[len(p.toks)-1]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) skipNewlines() {
+	for p.peek().kind == tokNewline {
+		p.pos++
+	}
+}
+
+func (p *parser) isPunct(s string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.text == s
+}
+
+func (p *parser) isWord(kw string) bool {
+	t := p.peek()
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+func (p *parser) expect(s string) error {
+	if !p.isPunct(s) {
+		t := p.peek()
+		return fmt.Errorf("line %d: expected %q, found %s", t.line, s, t)
+	}
+	p.pos++
+	return nil
+}
+
+// ident reads one bare or quoted identifier
+func (p *parser) ident() (string, error) {
+	t := p.peek()
+	if t.kind != tokWord && t.kind != tokIdent {
+		return "", fmt.Errorf("line %d: expected a name, found %s", t.line, t)
+	}
+	p.pos++
+	return t.text, nil
+}
+
+// name reads a dotted name such as schema.table
+func (p *parser) name() ([]string, error) {
+	first, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{first}
+	for p.isPunct(".") && (p.peekAt(1).kind == tokWord || p.peekAt(1).kind == tokIdent) {
+		p.pos++
+		part, _ := p.ident()
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// skipBlock skips a balanced { ... } block
+func (p *parser) skipBlock() error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for depth := 1; depth > 0; {
+		t := p.next()
+		switch {
+		case t.kind == tokEOF:
+			return fmt.Errorf("line %d: unterminated block", t.line)
+		case t.kind == tokPunct && t.text == "{":
+			depth++
+		case t.kind == tokPunct && t.text == "}":
+			depth--
+		}
+	}
+	return nil
+}
+
+// setting is one item of a [ ... ] list: a lowercased key and its value tokens
+type setting struct {
+	key   string
+	value []token
+	line  int
+}
+
+func (p *parser) settings() ([]setting, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	out := make([]setting, 0)
+	cur := make([]token, 0)
+	flush := func() {
+		if len(cur) == 0 {
+			return
+		}
+		s := setting{line: cur[0].line}
+		keyEnd := len(cur)
+		for i, t := range cur {
+			if t.kind == tokPunct && t.text == ":" {
+				keyEnd = i
+				s.value = cur[i+1:]
+				break
+			}
+		}
+		words := make([]string, 0, keyEnd)
+		for _, t := range cur[:keyEnd] {
+			words = append(words, strings.ToLower(t.text))
+		}
+		s.key = strings.Join(words, " ")
+		out = append(out, s)
+		cur = make([]token, 0)
+	}
+	for depth := 0; ; {
+		t := p.next()
+		switch {
+		case t.kind == tokEOF:
+			return nil, fmt.Errorf("line %d: unterminated settings list", t.line)
+		case t.kind == tokNewline:
+			continue
+		case t.kind == tokPunct && (t.text == "(" || t.text == "["):
+			depth++
+		case t.kind == tokPunct && t.text == ")":
+			depth--
+		case t.kind == tokPunct && t.text == "]":
+			if depth == 0 {
+				flush()
+				return out, nil
+			}
+			depth--
+		case t.kind == tokPunct && t.text == "," && depth == 0:
+			flush()
+			continue
+		}
+		cur = append(cur, t)
+	}
+}
+
+// valueText converts setting value tokens into text; strings are unquoted
+func valueText(toks []token) string {
+	parts := make([]string, 0, len(toks))
+	for _, t := range toks {
+		parts = append(parts, t.text)
+	}
+	return strings.Join(parts, "")
+}
+
+// Top level
+
+func (p *parser) parseFile() error {
+	for {
+		p.skipNewlines()
+		t := p.peek()
+		if t.kind == tokEOF {
+			return nil
+		}
+		if t.kind != tokWord {
+			return fmt.Errorf("line %d: unexpected %s", t.line, t)
+		}
+
+		var err error
+		switch strings.ToLower(t.text) {
+		case "project":
+			err = p.parseProject()
+		case "table":
+			err = p.parseTable()
+		case "ref":
+			err = p.parseRef()
+		case "enum":
+			err = p.parseEnum()
+		case "tablegroup":
+			err = p.parseTableGroup()
+		case "note":
+			err = p.parseStickyNote()
+		case "tablepartial", "records":
+			p.warn(t.line, "%s is not supported and was skipped", t.text)
+			p.pos++
+			for !p.isPunct("{") && p.peek().kind != tokEOF {
+				p.pos++
+			}
+			err = p.skipBlock()
+		default:
+			return fmt.Errorf("line %d: unknown element %s", t.line, t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseProject() error {
+	p.pos++ // Project
+	if !p.isPunct("{") {
+		parts, err := p.name()
+		if err != nil {
+			return err
+		}
+		p.diagram.Name = strings.Join(parts, ".")
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		p.skipNewlines()
+		if p.isPunct("}") {
+			p.pos++
+			return nil
+		}
+		t := p.peek()
+		key, err := p.ident()
+		if err != nil {
+			return err
+		}
+		value, err := p.noteValue()
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(key) {
+		case "database_type":
+			if d, ok := sqlgen.ParseDialect(strings.ReplaceAll(value, " ", "_")); ok {
+				p.diagram.Content["databaseType"] = string(d)
+			} else {
+				p.warn(t.line, "unknown database_type %q", value)
+			}
+		default:
+			p.warn(t.line, "project setting %q is not stored", key)
+		}
+	}
+}
+
+// noteValue reads ": value" or "{ value }" after a Note or project key
+func (p *parser) noteValue() (string, error) {
+	if p.isPunct("{") {
+		p.pos++
+		p.skipNewlines()
+		t := p.next()
+		p.skipNewlines()
+		if err := p.expect("}"); err != nil {
+			return "", err
+		}
+		return t.text, nil
+	}
+	if err := p.expect(":"); err != nil {
+		return "", err
+	}
+	t := p.next()
+	if t.kind == tokNewline || t.kind == tokEOF {
+		return "", fmt.Errorf("line %d: missing value", t.line)
+	}
+	return t.text, nil
+}
+
+func (p *parser) parseTable() error {
+	p.pos++ // Table
+	parts, err := p.name()
+	if err != nil {
+		return err
+	}
+	d := &tableDraft{table: domain.Table{TableID: p.id("t")}}
+	d.table.Schema, d.table.Name = splitName(parts)
+
+	if p.isWord("as") {
+		p.pos++
+		if d.alias, err = p.ident(); err != nil {
+			return err
+		}
+	}
+	if p.isPunct("[") {
+		settings, err := p.settings()
+		if err != nil {
+			return err
+		}
+		for _, s := range settings {
+			switch s.key {
+			case "headercolor":
+				d.table.Color = valueText(s.value)
+			case "note":
+				d.table.Comments = valueText(s.value)
+			default:
+				p.warn(s.line, "table setting %q is not supported", s.key)
+			}
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		p.skipNewlines()
+		if p.isPunct("}") {
+			p.pos++
+			break
+		}
+		next := p.peekAt(1)
+		switch {
+		case p.isWord("indexes") && next.kind == tokPunct && next.text == "{":
+			if err := p.parseIndexes(d); err != nil {
+				return err
+			}
+		case p.isWord("note") && next.kind == tokPunct && (next.text == ":" || next.text == "{"):
+			p.pos++
+			if d.table.Comments, err = p.noteValue(); err != nil {
+				return err
+			}
+		default:
+			if err := p.parseColumn(d); err != nil {
+				return err
+			}
+		}
+	}
+
+	p.tables = append(p.tables, d)
+	return nil
+}
+
+func (p *parser) parseColumn(d *tableDraft) error {
+	line := p.peek().line
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+
+	// Type: a dotted or quoted name with optional (args)
+	typeParts, err := p.name()
+	if err != nil {
+		return fmt.Errorf("line %d: column %s has no type", line, name)
+	}
+	raw := strings.Join(typeParts, ".")
+	if p.isPunct("(") {
+		p.pos++
+		args := make([]string, 0)
+		for !p.isPunct(")") {
+			t := p.next()
+			if t.kind == tokEOF || t.kind == tokNewline {
+				return fmt.Errorf("line %d: unterminated type arguments", line)
+			}
+			if t.kind != tokPunct || t.text != "," {
+				args = append(args, t.text)
+			}
+		}
+		p.pos++
+		raw += "(" + strings.Join(args, ",") + ")"
+	}
+
+	f := domain.Field{ID: p.id("f"), Name: name, Nullable: true}
+	setType(&f, raw)
+
+	if p.isPunct("[") {
+		settings, err := p.settings()
+		if err != nil {
+			return err
+		}
+		nullSet := false
+		for _, s := range settings {
+			switch s.key {
+			case "pk", "primary key":
+				f.PrimaryKey = true
+			case "null":
+				f.Nullable, nullSet = true, true
+			case "not null":
+				f.Nullable, nullSet = false, true
+			case "unique":
+				f.Unique = true
+			case "increment":
+				f.Increment = true
+			case "default":
+				f.Default = defaultFromTokens(s.value)
+			case "note":
+				f.Comments = valueText(s.value)
+			case "ref":
+				ref, err := p.inlineRef(d, name, s)
+				if err != nil {
+					return err
+				}
+				p.refs = append(p.refs, ref)
+			default:
+				p.warn(s.line, "column setting %q is not supported", s.key)
+			}
+		}
+		if f.PrimaryKey && !nullSet {
+			f.Nullable = false
+		}
+	}
+
+	if t := p.peek(); t.kind != tokNewline && !p.isPunct("}") {
+		return fmt.Errorf("line %d: unexpected %s after column %s", t.line, t, name)
+	}
+	d.fields = append(d.fields, f)
+	return nil
+}
+
+// inlineRef turns "ref: > users.id" on a column into a pending Ref
+func (p *parser) inlineRef(d *tableDraft, column string, s setting) (pendingRef, error) {
+	sub := &parser{toks: append(append([]token{}, s.value...), token{kind: tokEOF, line: s.line})}
+	op := sub.next()
+	if op.kind != tokPunct || !isRefOp(op.text) {
+		return pendingRef{}, fmt.Errorf("line %d: invalid inline ref", s.line)
+	}
+	right, err := sub.endpoint()
+	if err != nil {
+		return pendingRef{}, err
+	}
+	left := endpoint{parts: tableParts(d.table), columns: []string{column}}
+	return pendingRef{left: left, right: right, op: op.text, line: s.line}, nil
+}
+
+func (p *parser) parseIndexes(d *tableDraft) error {
+	p.pos++ // indexes
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		p.skipNewlines()
+		if p.isPunct("}") {
+			p.pos++
+			return nil
+		}
+
+		line := p.peek().line
+		columns := make([]string, 0)
+		expression := false
+		if p.isPunct("(") {
+			p.pos++
+			for !p.isPunct(")") {
+				t := p.next()
+				switch {
+				case t.kind == tokEOF || t.kind == tokNewline:
+					return fmt.Errorf("line %d: unterminated index columns", line)
+				case t.kind == tokExpr:
+					expression = true
+				case t.kind == tokWord || t.kind == tokIdent:
+					columns = append(columns, t.text)
+				}
+			}
+			p.pos++
+		} else {
+			t := p.next()
+			switch t.kind {
+			case tokExpr:
+				expression = true
+			case tokWord, tokIdent:
+				columns = append(columns, t.text)
+			default:
+				return fmt.Errorf("line %d: invalid index %s", line, t)
+			}
+		}
+
+		idx := domain.Index{ID: p.id("i")}
+		pk := false
+		if p.isPunct("[") {
+			settings, err := p.settings()
+			if err != nil {
+				return err
+			}
+			for _, s := range settings {
+				switch s.key {
+				case "pk":
+					pk = true
+				case "unique":
+					idx.Unique = true
+				case "name":
+					idx.Name = valueText(s.value)
+				default:
+					p.warn(s.line, "index setting %q is not supported", s.key)
+				}
+			}
+		}
+		if expression {
+			p.warn(line, "expression index on %s was skipped", d.table.Name)
+			continue
+		}
+
+		for _, c := range columns {
+			found := false
+			for i := range d.fields {
+				if d.fields[i].Name == c {
+					found = true
+					if pk {
+						d.fields[i].PrimaryKey = true
+						d.fields[i].Nullable = false
+					}
+					idx.FieldIDs = append(idx.FieldIDs, d.fields[i].ID)
+				}
+			}
+			if !found {
+				p.warn(line, "index column %s.%s does not exist", d.table.Name, c)
+			}
+		}
+		if !pk && len(idx.FieldIDs) > 0 {
+			d.indexes = append(d.indexes, idx)
+		}
+	}
+}
+
+func (p *parser) parseRef() error {
+	line := p.next().line // Ref
+	name := ""
+	if !p.isPunct(":") && !p.isPunct("{") {
+		var err error
+		if name, err = p.ident(); err != nil {
+			return err
+		}
+	}
+
+	if p.isPunct(":") {
+		p.pos++
+		ref, err := p.refBody(name, line)
+		if err != nil {
+			return err
+		}
+		p.refs = append(p.refs, ref)
+		return nil
+	}
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		p.skipNewlines()
+		if p.isPunct("}") {
+			p.pos++
+			return nil
+		}
+		ref, err := p.refBody(name, p.peek().line)
+		if err != nil {
+			return err
+		}
+		p.refs = append(p.refs, ref)
+	}
+}
+
+// refBody reads "endpoint op endpoint [settings]"
+func (p *parser) refBody(name string, line int) (pendingRef, error) {
+	left, err := p.endpoint()
+	if err != nil {
+		return pendingRef{}, err
+	}
+	op := p.next()
+	if op.kind != tokPunct || !isRefOp(op.text) {
+		return pendingRef{}, fmt.Errorf("line %d: expected <, >, - or <>, found %s", op.line, op)
+	}
+	right, err := p.endpoint()
+	if err != nil {
+		return pendingRef{}, err
+	}
+	if p.isPunct("[") {
+		settings, err := p.settings()
+		if err != nil {
+			return pendingRef{}, err
+		}
+		for _, s := range settings {
+			p.warn(s.line, "ref setting %q is not stored", s.key)
+		}
+	}
+	return pendingRef{name: name, left: left, right: right, op: op.text, line: line}, nil
+}
+
+// endpoint reads table.column, schema.table.column or [schema.]table.(a, b)
+func (p *parser) endpoint() (endpoint, error) {
+	line := p.peek().line
+	parts, err := p.name()
+	if err != nil {
+		return endpoint{}, err
+	}
+	if p.isPunct(".") && p.peekAt(1).kind == tokPunct && p.peekAt(1).text == "(" {
+		p.pos += 2
+		cols := make([]string, 0)
+		for !p.isPunct(")") {
+			t := p.next()
+			if t.kind == tokEOF || t.kind == tokNewline {
+				return endpoint{}, fmt.Errorf("line %d: unterminated column list", line)
+			}
+			if t.kind == tokWord || t.kind == tokIdent {
+				cols = append(cols, t.text)
+			}
+		}
+		p.pos++
+		return endpoint{parts: parts, columns: cols}, nil
+	}
+	if len(parts) < 2 {
+		return endpoint{}, fmt.Errorf("line %d: ref endpoint needs table.column", line)
+	}
+	return endpoint{parts: parts[:len(parts)-1], columns: parts[len(parts)-1:]}, nil
+}
+
+func (p *parser) parseEnum() error {
+	p.pos++ // Enum
+	parts, err := p.name()
+	if err != nil {
+		return err
+	}
+	ct := domain.CustomType{Kind: "enum"}
+	ct.Schema, ct.Type = splitName(parts)
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, 0)
+	for {
+		p.skipNewlines()
+		if p.isPunct("}") {
+			p.pos++
+			break
+		}
+		line := p.peek().line
+		v, err := p.ident()
+		if err != nil {
+			return err
+		}
+		values = append(values, v)
+		if p.isPunct("[") {
+			if _, err := p.settings(); err != nil {
+				return err
+			}
+			p.warn(line, "note on enum value %s is not stored", v)
+		}
+	}
+	ct.Values = values
+	p.enums = append(p.enums, ct)
+	return nil
+}
+
+func (p *parser) parseTableGroup() error {
+	line := p.next().line // TableGroup
+	parts, err := p.name()
+	if err != nil {
+		return err
+	}
+	g := pendingGroup{area: domain.Area{AreaID: p.id("a"), Name: strings.Join(parts, ".")}, line: line}
+	if p.isPunct("[") {
+		settings, err := p.settings()
+		if err != nil {
+			return err
+		}
+		for _, s := range settings {
+			if s.key == "color" {
+				g.area.Color = valueText(s.value)
+			} else {
+				p.warn(s.line, "table group setting %q is not supported", s.key)
+			}
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		p.skipNewlines()
+		if p.isPunct("}") {
+			p.pos++
+			break
+		}
+		next := p.peekAt(1)
+		if p.isWord("note") && next.kind == tokPunct && (next.text == ":" || next.text == "{") {
+			p.pos++
+			if _, err := p.noteValue(); err != nil {
+				return err
+			}
+			p.warn(line, "note on table group %s is not stored", g.area.Name)
+			continue
+		}
+		member, err := p.name()
+		if err != nil {
+			return err
+		}
+		g.members = append(g.members, member)
+	}
+	p.groups = append(p.groups, g)
+	return nil
+}
+
+func (p *parser) parseStickyNote() error {
+	p.pos++ // Note
+	if !p.isPunct("{") && !p.isPunct(":") {
+		if _, err := p.name(); err != nil {
+			return err
+		}
+	}
+	content, err := p.noteValue()
+	if err != nil {
+		return err
+	}
+	p.notes = append(p.notes, domain.Note{
+		ID:      p.id("n"),
+		Content: content,
+		Width:   noteWidth,
+		Height:  noteHeight,
+	})
+	return nil
+}
+
+// Resolution
+
+func (p *parser) findTable(parts []string) *tableDraft {
+	if len(parts) == 1 {
+		for _, d := range p.tables {
+			if d.alias != "" && d.alias == parts[0] {
+				return d
+			}
+		}
+	}
+	schema, name := splitName(parts)
+	var fallback *tableDraft
+	for _, d := range p.tables {
+		if d.table.Name != name {
+			continue
+		}
+		if d.table.Schema == schema {
+			return d
+		}
+		// "users" and "public.users" name the same table
+		if (schema == "" && d.table.Schema == "public") || (schema == "public" && d.table.Schema == "") {
+			fallback = d
+		}
+	}
+	return fallback
+}
+
+func (d *tableDraft) field(name string) (domain.Field, bool) {
+	for _, f := range d.fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return domain.Field{}, false
+}
+
+func (p *parser) snapshot() *domain.DiagramSnapshot {
+	snap := &domain.DiagramSnapshot{
+		Diagram:       p.diagram,
+		Tables:        make([]domain.Table, 0, len(p.tables)),
+		Relationships: make([]domain.Relationship, 0, len(p.refs)),
+		Dependencies:  make([]domain.Dependency, 0),
+		Areas:         make([]domain.Area, 0, len(p.groups)),
+		CustomTypes:   p.enums,
+		Notes:         p.notes,
+	}
+	if snap.CustomTypes == nil {
+		snap.CustomTypes = []domain.CustomType{}
+	}
+	if snap.Notes == nil {
+		snap.Notes = []domain.Note{}
+	}
+
+	// Field types naming an enum by schema.name keep only the name, as the frontend does
+	enums := make(map[string]bool)
+	for _, ct := range p.enums {
+		enums[ct.Schema+"."+ct.Type] = true
+	}
+
+	for i, d := range p.tables {
+		d.table.Order = i
+		d.table.Fields = make([]map[string]interface{}, 0, len(d.fields))
+		for _, f := range d.fields {
+			if enums[f.Type] {
+				f.Type = f.Type[strings.Index(f.Type, ".")+1:]
+			}
+			d.table.Fields = append(d.table.Fields, f.ToMap())
+		}
+		d.table.Indexes = make([]map[string]interface{}, 0, len(d.indexes))
+		for _, idx := range d.indexes {
+			d.table.Indexes = append(d.table.Indexes, idx.ToMap())
+		}
+		snap.Tables = append(snap.Tables, d.table)
+	}
+
+	for _, ref := range p.refs {
+		left, right := p.findTable(ref.left.parts), p.findTable(ref.right.parts)
+		if left == nil || right == nil {
+			p.warn(ref.line, "ref between unknown tables %s and %s was skipped",
+				strings.Join(ref.left.parts, "."), strings.Join(ref.right.parts, "."))
+			continue
+		}
+		if len(ref.left.columns) != len(ref.right.columns) {
+			p.warn(ref.line, "ref with mismatched column counts was skipped")
+			continue
+		}
+		srcCard, tgtCard := cardinalities(ref.op)
+
+		// Composite refs become one relationship per column pair
+		for i := range ref.left.columns {
+			lf, ok1 := left.field(ref.left.columns[i])
+			rf, ok2 := right.field(ref.right.columns[i])
+			if !ok1 || !ok2 {
+				p.warn(ref.line, "ref on unknown column %s.%s or %s.%s was skipped",
+					left.table.Name, ref.left.columns[i], right.table.Name, ref.right.columns[i])
+				continue
+			}
+			snap.Relationships = append(snap.Relationships, domain.Relationship{
+				RelationshipID:    p.id("r"),
+				Name:              ref.name,
+				SourceTableID:     left.table.TableID,
+				SourceFieldID:     lf.ID,
+				TargetTableID:     right.table.TableID,
+				TargetFieldID:     rf.ID,
+				SourceCardinality: srcCard,
+				TargetCardinality: tgtCard,
+			})
+		}
+	}
+
+	for _, g := range p.groups {
+		g.area.TableIDs = make([]string, 0, len(g.members))
+		for _, m := range g.members {
+			d := p.findTable(m)
+			if d == nil {
+				p.warn(g.line, "table group %s lists unknown table %s", g.area.Name, strings.Join(m, "."))
+				continue
+			}
+			g.area.TableIDs = append(g.area.TableIDs, d.table.TableID)
+		}
+		snap.Areas = append(snap.Areas, g.area)
+	}
+	return snap
+}
+
+// Mapping helpers
+
+func isRefOp(s string) bool {
+	return s == "<" || s == ">" || s == "-" || s == "<>"
+}
+
+// cardinalities maps a ref operator to the (left, right) = (source, target) cardinalities
+func cardinalities(op string) (string, string) {
+	switch op {
+	case ">":
+		return domain.CardinalityMany, domain.CardinalityOne
+	case "<":
+		return domain.CardinalityOne, domain.CardinalityMany
+	case "<>":
+		return domain.CardinalityMany, domain.CardinalityMany
+	default:
+		return domain.CardinalityOne, domain.CardinalityOne
+	}
+}
+
+func splitName(parts []string) (schema, name string) {
+	if len(parts) == 1 {
+		return "", parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], "."), parts[len(parts)-1]
+}
+
+func tableParts(t domain.Table) []string {
+	if t.Schema == "" {
+		return []string{t.Name}
+	}
+	return []string{t.Schema, t.Name}
+}
+
+// Types whose single argument is a length rather than a precision
+var lengthTypes = map[string]bool{
+	"char": true, "character": true, "varchar": true, "character varying": true,
+	"nchar": true, "nvarchar": true, "varchar2": true, "nvarchar2": true,
+	"binary": true, "varbinary": true, "bit": true, "bit varying": true,
+}
+
+// setType splits "varchar(255)" or "decimal(10,2)" into the field's type and size attributes
+func setType(f *domain.Field, raw string) {
+	f.Type = raw
+	open := strings.Index(raw, "(")
+	if open < 0 || !strings.HasSuffix(raw, ")") {
+		return
+	}
+	base := strings.TrimSpace(raw[:open])
+	args := strings.Split(raw[open+1:len(raw)-1], ",")
+	for i := range args {
+		args[i] = strings.TrimSpace(args[i])
+	}
+
+	// Other types with integer arguments, such as timestamp(6), keep them as precision too
+	switch lower := strings.ToLower(base); {
+	case lengthTypes[lower] && len(args) == 1:
+		f.Type, f.CharacterMaximumLength = base, args[0]
+	case len(args) <= 2:
+		p, err := strconv.Atoi(args[0])
+		if err != nil {
+			return
+		}
+		f.Type, f.Precision = base, &p
+		if len(args) == 2 {
+			s, err := strconv.Atoi(args[1])
+			if err != nil {
+				f.Type, f.Precision = raw, nil
+				return
+			}
+			f.Scale = &s
+		}
+	}
+}
+
+// defaultFromTokens converts a DBML default value into SQL text: strings
+// become quoted literals, expressions and other values are kept as written
+func defaultFromTokens(toks []token) string {
+	if len(toks) == 1 && toks[0].kind == tokString {
+		return "'" + strings.ReplaceAll(toks[0].text, "'", "''") + "'"
+	}
+	return valueText(toks)
+}
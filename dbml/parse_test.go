@@ -0,0 +1,260 @@
+package dbml
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/internal/exchangetest"
+)
+
+func TestParseColumnSettings(t *testing.T) {
+	tests := []struct {
+		column string
+		want   domain.Field
+	}{
+		{"id bigint [pk, increment]", domain.Field{Name: "id", Type: "bigint", PrimaryKey: true, Increment: true}},
+		{"id int [primary key, null]", domain.Field{Name: "id", Type: "int", PrimaryKey: true, Nullable: true}},
+		{"email varchar(255) [not null, unique]", domain.Field{Name: "email", Type: "varchar", CharacterMaximumLength: "255", Unique: true}},
+		{"price decimal(10, 2) [default: 0]", domain.Field{Name: "price", Type: "decimal", Precision: exchangetest.IntPtr(10), Scale: exchangetest.IntPtr(2), Nullable: true, Default: "0"}},
+		{"at timestamp(6) [default: `now()`]", domain.Field{Name: "at", Type: "timestamp", Precision: exchangetest.IntPtr(6), Nullable: true, Default: "now()"}},
+		{`name text [default: 'it\'s', note: 'shown']`, domain.Field{Name: "name", Type: "text", Nullable: true, Default: "'it''s'", Comments: "shown"}},
+		{`"full name" "character varying"`, domain.Field{Name: "full name", Type: "character varying", Nullable: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			snap, warnings, err := Parse("Table users {\n  " + tt.column + "\n}\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(warnings) > 0 {
+				t.Errorf("warnings: %v", warnings)
+			}
+			got := domain.FieldFromMap(snap.Tables[0].Fields[0])
+			got.ID = ""
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRefs(t *testing.T) {
+	const tables = `
+Table users as U {
+  id int [pk]
+  price int
+}
+Table public.orders {
+  id int [pk]
+  user_id int [ref: > U.id]
+  a int
+  b int
+}
+Table lines {
+  order_id int
+  n int
+}
+`
+	tests := []struct {
+		name, refs string
+		want       []string
+		warnings   int
+	}{
+		{"inline only", "", nil, 0},
+		{"many to one", "Ref: orders.a > users.id", []string{"orders.a many -> users.id one"}, 0},
+		{"one to many", "Ref: users.id < orders.a", []string{"users.id one -> orders.a many"}, 0},
+		{"one to one", "Ref: orders.a - users.id", []string{"orders.a one -> users.id one"}, 0},
+		{"many to many", "Ref: orders.a <> users.price", []string{"orders.a many -> users.price many"}, 0},
+		{"schema", "Ref: public.orders.a > users.id", []string{"orders.a many -> users.id one"}, 0},
+		{"block", "Ref named {\n  orders.a > users.id\n  orders.b > users.id\n}", []string{"orders.a many -> users.id one", "orders.b many -> users.id one"}, 0},
+		{"composite", "Ref: lines.(order_id, n) > orders.(id, a)", []string{"lines.order_id many -> orders.id one", "lines.n many -> orders.a one"}, 0},
+		{"settings", "Ref: orders.a > users.id [delete: cascade]", []string{"orders.a many -> users.id one"}, 1},
+		{"unknown table", "Ref: orders.a > people.id", nil, 1},
+		{"unknown column", "Ref: orders.z > users.id", nil, 1},
+		{"mismatched columns", "Ref: lines.(order_id, n) > orders.(id)", nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap, warnings, err := Parse(tables + tt.refs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The inline ref on orders.user_id comes first in every case
+			got := exchangetest.Relationships(snap)
+			want := append([]string{"orders.user_id many -> users.id one"}, tt.want...)
+			if !slices.Equal(got, want) {
+				t.Errorf("relationships = %q, want %q", got, want)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("warnings = %q, want %d", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestParseEnums(t *testing.T) {
+	src := `
+Enum public.status {
+  active
+  "on hold" [note: 'paused']
+}
+Table users {
+  status public.status
+  plain status
+}
+`
+	snap, warnings, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "line 4: ") {
+		t.Errorf("warnings = %q, want one on line 4", warnings)
+	}
+	want := []domain.CustomType{{Schema: "public", Type: "status", Kind: "enum", Values: []interface{}{"active", "on hold"}}}
+	if !reflect.DeepEqual(snap.CustomTypes, want) {
+		t.Errorf("custom types = %+v, want %+v", snap.CustomTypes, want)
+	}
+	// Both spellings refer to the enum by its bare name
+	for _, f := range snap.Tables[0].FieldList() {
+		if f.Type != "status" {
+			t.Errorf("%s has type %q, want status", f.Name, f.Type)
+		}
+	}
+}
+
+func TestParseTableGroups(t *testing.T) {
+	src := `
+Table users {
+  id int
+}
+Table sales.orders {
+  id int
+}
+TableGroup billing [color: #ff0000] {
+  users
+  sales.orders
+  missing
+  Note: 'not stored'
+}
+`
+	snap, warnings, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Areas) != 1 {
+		t.Fatalf("areas = %+v, want one", snap.Areas)
+	}
+	a := snap.Areas[0]
+	if a.Name != "billing" || a.Color != "#ff0000" {
+		t.Errorf("area = %q %q, want billing #ff0000", a.Name, a.Color)
+	}
+	want := []string{snap.Tables[0].TableID, snap.Tables[1].TableID}
+	if !slices.Equal(a.TableIDs, want) {
+		t.Errorf("table IDs = %q, want %q", a.TableIDs, want)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("warnings = %q, want the unknown table and the note", warnings)
+	}
+}
+
+func TestParseProject(t *testing.T) {
+	tests := []struct {
+		src, name, databaseType string
+		warnings                int
+	}{
+		{"Project shop {\n  database_type: 'PostgreSQL'\n}", "shop", "postgresql", 0},
+		{"Project {\n  database_type: 'MySQL'\n  Note: 'x'\n}", "", "mysql", 1},
+		{"Project shop {\n  database_type: 'Fortran'\n}", "shop", "", 1},
+	}
+	for _, tt := range tests {
+		snap, warnings, err := Parse(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snap.Diagram.Name != tt.name || domain.MapString(snap.Diagram.Content, "databaseType") != tt.databaseType {
+			t.Errorf("%q: got %q %v", tt.src, snap.Diagram.Name, snap.Diagram.Content)
+		}
+		if len(warnings) != tt.warnings {
+			t.Errorf("%q: warnings = %q, want %d", tt.src, warnings, tt.warnings)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"Tabel users {}", `line 1: unknown element "Tabel"`},
+		{"Table users {\n  id\n}", "line 2: column id has no type"},
+		{"Table users {\n  id int pk\n}", `line 2: unexpected "pk" after column id`},
+		{"Table users {\n  id int\n  name 'x\n}", "line 3: unterminated '"},
+		{"Table users {\n  id int\n}\n\nRef: users > users.id", "line 5: ref endpoint needs table.column"},
+		{"Table users {\n  id int\n}\nRef: users.id >> users.id", `line 4: expected a name, found ">"`},
+		{"Table users {\n  id int\n}\nRef: users.id = users.id", "line 4: unexpected character '='"},
+		{"/* open\n\nTable users {}", "line 1: unterminated comment"},
+	}
+	for _, tt := range tests {
+		_, _, err := Parse(tt.src)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+// roundTripSource uses every construct the writer produces
+const roundTripSource = `
+Project shop {
+  database_type: 'PostgreSQL'
+}
+Enum public.status {
+  active
+  "on hold"
+}
+Table public.users [headercolor: #3498DB, note: 'people'] {
+  id bigint [pk, increment]
+  email varchar(255) [not null, unique, note: 'login']
+  price decimal(10,2) [default: 0]
+  status public.status [default: 'active']
+  created_at timestamp [default: ` + "`now()`" + `]
+  indexes {
+    (email, status) [unique, name: 'ix_email_status']
+    price
+  }
+}
+Table orders {
+  id int [pk]
+  user_id bigint [ref: > public.users.id]
+  tag int
+}
+Ref fk_tag: orders.tag - public.users.id
+Ref: orders.id <> public.users.price
+TableGroup sales [color: #ff0000] {
+  public.users
+  orders
+}
+Note todo {
+  'remember'
+}
+`
+
+func TestRoundTrip(t *testing.T) {
+	first, _, err := Parse(roundTripSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := exchangetest.RoundTrip(t, first, Write, Parse)
+	for i, tbl := range first.Tables {
+		if tbl.Color != second.Tables[i].Color {
+			t.Errorf("%s color %q became %q", tbl.Name, tbl.Color, second.Tables[i].Color)
+		}
+	}
+	if len(second.Areas) != 1 || second.Areas[0].Name != "sales" || len(second.Areas[0].TableIDs) != 2 {
+		t.Errorf("areas = %+v", second.Areas)
+	}
+	if len(second.Notes) != 1 || second.Notes[0].Content != "remember" {
+		t.Errorf("notes = %+v", second.Notes)
+	}
+}
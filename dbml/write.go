@@ -0,0 +1,338 @@
+package dbml
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/sqlgen"
+)
+
+var (
+	bareIdent  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	bareNumber = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+	sqlString  = regexp.MustCompile(`^'((?:[^']|'')*)'$`)
+)
+
+// DBML spellings of the frontend database types
+var databaseTypes = map[sqlgen.Dialect]string{
+	sqlgen.PostgreSQL:  "PostgreSQL",
+	sqlgen.MySQL:       "MySQL",
+	sqlgen.MariaDB:     "MariaDB",
+	sqlgen.SQLServer:   "SQL Server",
+	sqlgen.SQLite:      "SQLite",
+	sqlgen.Oracle:      "Oracle",
+	sqlgen.CockroachDB: "CockroachDB",
+	sqlgen.ClickHouse:  "ClickHouse",
+}
+
+// Write renders a snapshot as DBML: project, enums, tables, refs, table
+// groups and sticky notes, in that order
+func Write(snap *domain.DiagramSnapshot) []byte {
+	w := &writer{snap: snap, tables: make(map[string]domain.Table, len(snap.Tables))}
+	for _, t := range snap.Tables {
+		w.tables[t.TableID] = t
+	}
+	w.enums = make(map[string]string)
+	for _, ct := range snap.CustomTypes {
+		if ct.Kind == "enum" {
+			w.enums[strings.ToLower(ct.Type)] = qualified(ct.Schema, ct.Type)
+		}
+	}
+
+	w.project()
+	w.customTypes()
+	for _, t := range snap.Tables {
+		w.table(t)
+	}
+	w.refs()
+	w.tableGroups()
+	w.notes()
+	return []byte(strings.TrimRight(w.b.String(), "\n") + "\n")
+}
+
+type writer struct {
+	b      strings.Builder
+	snap   *domain.DiagramSnapshot
+	tables map[string]domain.Table
+	enums  map[string]string // lower-case type name -> qualified DBML name
+}
+
+func (w *writer) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&w.b, format, args...)
+}
+
+func (w *writer) project() {
+	d := w.snap.Diagram
+	if d == nil {
+		return
+	}
+	dbType := ""
+	if s, ok := d.Content["databaseType"].(string); ok {
+		if dialect, ok := sqlgen.ParseDialect(s); ok && databaseTypes[dialect] != "" {
+			dbType = databaseTypes[dialect]
+		}
+	}
+	if d.Name == "" && dbType == "" {
+		return
+	}
+
+	w.printf("Project %s {\n", ident(nonEmpty(d.Name, "diagram")))
+	if dbType != "" {
+		w.printf("  database_type: %s\n", str(dbType))
+	}
+	w.printf("}\n\n")
+}
+
+func (w *writer) customTypes() {
+	for _, ct := range w.snap.CustomTypes {
+		if ct.Kind != "enum" {
+			// DBML has no composite types; keep a trace for readers
+			w.printf("// %s type %s is not representable in DBML\n\n", nonEmpty(ct.Kind, "custom"), qualified(ct.Schema, ct.Type))
+			continue
+		}
+		w.printf("Enum %s {\n", qualified(ct.Schema, ct.Type))
+		for _, v := range ct.ValueList() {
+			w.printf("  %s\n", ident(v))
+		}
+		w.printf("}\n\n")
+	}
+}
+
+func (w *writer) table(t domain.Table) {
+	fields := t.FieldList()
+	pkCount := 0
+	for _, f := range fields {
+		if f.PrimaryKey {
+			pkCount++
+		}
+	}
+
+	settings := make([]string, 0)
+	if t.Color != "" {
+		settings = append(settings, "headercolor: "+t.Color)
+	}
+	w.printf("Table %s%s {\n", qualified(t.Schema, t.Name), settingList(settings))
+
+	for _, f := range fields {
+		s := make([]string, 0)
+		if f.PrimaryKey && pkCount == 1 {
+			s = append(s, "pk")
+		}
+		if f.Increment {
+			s = append(s, "increment")
+		}
+		if !f.Nullable && !(f.PrimaryKey && pkCount == 1) {
+			s = append(s, "not null")
+		}
+		if f.Unique {
+			s = append(s, "unique")
+		}
+		if f.Default != "" {
+			s = append(s, "default: "+defaultValue(f.Default))
+		}
+		if f.Comments != "" {
+			s = append(s, "note: "+str(f.Comments))
+		}
+		w.printf("  %s %s%s\n", ident(f.Name), w.fieldType(f), settingList(s))
+	}
+
+	indexes := t.IndexList()
+	if pkCount > 1 || len(indexes) > 0 {
+		names := make(map[string]string, len(fields))
+		for _, f := range fields {
+			names[f.ID] = ident(f.Name)
+		}
+		w.printf("\n  indexes {\n")
+		if pkCount > 1 {
+			cols := make([]string, 0, pkCount)
+			for _, f := range fields {
+				if f.PrimaryKey {
+					cols = append(cols, ident(f.Name))
+				}
+			}
+			w.printf("    (%s) [pk]\n", strings.Join(cols, ", "))
+		}
+		for _, idx := range indexes {
+			cols := make([]string, 0, len(idx.FieldIDs))
+			for _, id := range idx.FieldIDs {
+				if n, ok := names[id]; ok {
+					cols = append(cols, n)
+				}
+			}
+			if len(cols) == 0 {
+				continue
+			}
+			s := make([]string, 0)
+			if idx.Unique {
+				s = append(s, "unique")
+			}
+			if idx.Name != "" {
+				s = append(s, "name: "+str(idx.Name))
+			}
+			w.printf("    (%s)%s\n", strings.Join(cols, ", "), settingList(s))
+		}
+		w.printf("  }\n")
+	}
+
+	if t.Comments != "" {
+		w.printf("\n  Note: %s\n", str(t.Comments))
+	}
+	w.printf("}\n\n")
+}
+
+// fieldType writes the type with its size arguments, quoting it when it is not a plain word
+func (w *writer) fieldType(f domain.Field) string {
+	if enum, ok := w.enums[strings.ToLower(f.Type)]; ok {
+		return enum
+	}
+	f.Type = nonEmpty(f.Type, "unknown")
+	if bareIdent.MatchString(f.Type) {
+		return f.SQLType()
+	}
+	return quoteIdent(f.SQLType())
+}
+
+func (w *writer) refs() {
+	written := false
+	for _, r := range w.snap.Relationships {
+		src, ok1 := w.tables[r.SourceTableID]
+		tgt, ok2 := w.tables[r.TargetTableID]
+		if !ok1 || !ok2 {
+			continue
+		}
+		sf, ok1 := src.FieldByID(r.SourceFieldID)
+		tf, ok2 := tgt.FieldByID(r.TargetFieldID)
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		name := ""
+		if r.Name != "" {
+			name = " " + ident(r.Name)
+		}
+		w.printf("Ref%s: %s.%s %s %s.%s\n", name,
+			qualified(src.Schema, src.Name), ident(sf.Name),
+			refOp(r.SourceCardinality, r.TargetCardinality),
+			qualified(tgt.Schema, tgt.Name), ident(tf.Name))
+		written = true
+	}
+	if written {
+		w.printf("\n")
+	}
+}
+
+// tableGroups writes one group per area; a table is listed under the first area including it
+func (w *writer) tableGroups() {
+	grouped := make(map[string]bool)
+	for _, a := range w.snap.Areas {
+		members := make([]string, 0)
+		for _, t := range w.snap.Tables {
+			if !grouped[t.TableID] && a.Includes(t) {
+				grouped[t.TableID] = true
+				members = append(members, qualified(t.Schema, t.Name))
+			}
+		}
+		settings := make([]string, 0)
+		if a.Color != "" {
+			settings = append(settings, "color: "+a.Color)
+		}
+		w.printf("TableGroup %s%s {\n", ident(nonEmpty(a.Name, "area")), settingList(settings))
+		for _, m := range members {
+			w.printf("  %s\n", m)
+		}
+		w.printf("}\n\n")
+	}
+}
+
+func (w *writer) notes() {
+	for i, n := range w.snap.Notes {
+		w.printf("Note note_%d {\n  %s\n}\n\n", i+1, str(n.Content))
+	}
+}
+
+// refOp maps source and target cardinalities to the operator between them
+func refOp(source, target string) string {
+	switch {
+	case source == domain.CardinalityMany && target == domain.CardinalityMany:
+		return "<>"
+	case source == domain.CardinalityMany:
+		return ">"
+	case target == domain.CardinalityMany:
+		return "<"
+	default:
+		return "-"
+	}
+}
+
+// defaultValue converts SQL default text into a DBML value: string literals
+// and plain numbers or keywords are kept, anything else becomes an expression
+func defaultValue(def string) string {
+	if m := sqlString.FindStringSubmatch(def); m != nil {
+		return str(strings.ReplaceAll(m[1], "''", "'"))
+	}
+	switch strings.ToLower(def) {
+	case "true", "false", "null":
+		return strings.ToLower(def)
+	}
+	if bareNumber.MatchString(def) {
+		return def
+	}
+	return "`" + strings.ReplaceAll(def, "`", "\\`") + "`"
+}
+
+func settingList(settings []string) string {
+	if len(settings) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(settings, ", ") + "]"
+}
+
+func qualified(schema, name string) string {
+	if schema == "" {
+		return ident(name)
+	}
+	return ident(schema) + "." + ident(name)
+}
+
+func ident(s string) string {
+	if bareIdent.MatchString(s) {
+		return s
+	}
+	return quoteIdent(s)
+}
+
+func quoteIdent(s string) string {
+	return `"` + escape(s, '"') + `"`
+}
+
+// str writes a single-quoted DBML string
+func str(s string) string {
+	return "'" + escape(s, '\'') + "'"
+}
+
+func escape(s string, quote rune) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\', quote:
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func nonEmpty(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+// Package ddl reads and writes SQL DDL scripts.
+//
+// CREATE TABLE, CREATE INDEX, ALTER TABLE ... ADD, COMMENT ON and CREATE
+// TYPE statements from PostgreSQL, MySQL, SQL Server and SQLite dumps map
+// onto the diagram model; the dialect is guessed from quoting and syntax.
+// Other statements are skipped and reported as warnings on import.
+//
+// Export writes the dialect given by the "dialect" option, falling back to
+// the diagram's database type.
+package ddl
+
+import (
+	"fmt"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/sqlgen"
+)
+
+// Format implements domain.Importer and domain.Exporter for SQL DDL
+type Format struct{}
+
+func (Format) ContentType() string { return "application/sql; charset=utf-8" }
+
+func (Format) Extension() string { return "sql" }
+
+func (Format) Export(snap *domain.DiagramSnapshot, opts map[string]string) ([]byte, error) {
+	dialect := sqlgen.Generic
+	if s := opts["dialect"]; s != "" {
+		d, ok := sqlgen.ParseDialect(s)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown dialect %q", domain.ErrBadParamInput, s)
+		}
+		dialect = d
+	} else if snap.Diagram != nil {
+		if s, ok := snap.Diagram.Content["databaseType"].(string); ok {
+			if d, ok := sqlgen.ParseDialect(s); ok {
+				dialect = d
+			}
+		}
+	}
+	return Write(snap, dialect), nil
+}
+
+func (Format) Import(data []byte) (*domain.DiagramSnapshot, []string, error) {
+	return Parse(string(data))
+}
@@ -0,0 +1,175 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF    tokenKind = iota
+	tokWord             // Keyword, bare identifier or number
+	tokQuoted           // "quoted", `quoted` or [quoted] identifier
+	tokString           // 'string' or $$dollar quoted$$ literal
+	tokPunct            // ( ) , ; . and operators
+)
+
+type token struct {
+	kind       tokenKind
+	text       string // Unquoted contents for identifiers and strings
+	line       int
+	start, end int // Byte offsets of the token in the source
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// is reports whether the token is the given keyword, ignoring case
+func (t token) is(keyword string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, keyword)
+}
+
+// isName reports whether the token can name a table, column or type
+func (t token) isName() bool {
+	return t.kind == tokWord || t.kind == tokQuoted
+}
+
+// lex splits SQL into tokens, dropping -- and /* */ comments
+func lex(src string) ([]token, error) {
+	tokens := make([]token, 0)
+	line := 1
+	emit := func(kind tokenKind, text string, start, end int) {
+		tokens = append(tokens, token{kind: kind, text: text, line: line, start: start, end: end})
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+		case c == '-' && strings.HasPrefix(src[i:], "--"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '\'':
+			text, n, err := quoted(src[i:], '\'', '\'')
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			emit(tokString, text, i, i+n)
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+		case c == '[' && arraySuffix(src[i:]) > 0:
+			// Array types such as integer[] or text[3]
+			n := arraySuffix(src[i:])
+			emit(tokPunct, "[]", i, i+n)
+			i += n
+		case c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			text, n, err := quoted(src[i:], c, closing)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			emit(tokQuoted, text, i, i+n)
+			i += n
+		case c == '$' && dollarTag(src[i:]) != "":
+			tag := dollarTag(src[i:])
+			end := strings.Index(src[i+len(tag):], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated %s string", line, tag)
+			}
+			n := len(tag) + end + len(tag)
+			emit(tokString, src[i+len(tag):i+len(tag)+end], i, i+n)
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+		case isWordByte(c) || c >= 0x80:
+			j := i
+			for j < len(src) && (isWordByte(src[j]) || src[j] >= 0x80 ||
+				(src[j] == '.' && isDigit(src[i]) && j+1 < len(src) && isDigit(src[j+1]))) {
+				j++
+			}
+			emit(tokWord, src[i:j], i, j)
+			i = j
+		case c == ':' && strings.HasPrefix(src[i:], "::"):
+			emit(tokPunct, "::", i, i+2)
+			i += 2
+		case c < 0x80 && unicode.IsPrint(rune(c)):
+			emit(tokPunct, string(c), i, i+1)
+			i++
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, line: line, start: len(src), end: len(src)})
+	return tokens, nil
+}
+
+// quoted reads a literal enclosed in open and closing, where a doubled
+// closing character escapes itself; it returns the contents and the length
+// consumed including the quotes
+func quoted(s string, open, closing byte) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != closing {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == closing && open == closing {
+			b.WriteByte(closing)
+			i++
+			continue
+		}
+		return b.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("unterminated %c", open)
+}
+
+// dollarTag returns the opening tag of a dollar-quoted string, e.g. $$ or $body$
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '$':
+			return s[:i+1]
+		case !isWordByte(s[i]) || isDigit(s[1]):
+			return ""
+		}
+	}
+	return ""
+}
+
+// arraySuffix returns the length of a [] or [n] array suffix, or 0
+func arraySuffix(s string) int {
+	i := 1
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	if i < len(s) && s[i] == ']' {
+		return i + 1
+	}
+	return 0
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || isDigit(c)
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
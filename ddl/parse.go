@@ -0,0 +1,1292 @@
+package ddl
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/sqlgen"
+)
+
+type tableDraft struct {
+	table  domain.Table
+	cols   []domain.Field
+	byName map[string]int // Lower-case column name -> index in cols
+}
+
+func (t *tableDraft) column(name string) *domain.Field {
+	if i, ok := t.byName[strings.ToLower(name)]; ok {
+		return &t.cols[i]
+	}
+	return nil
+}
+
+// foreignKey is resolved once every table is known
+type foreignKey struct {
+	table     *tableDraft
+	name      string
+	cols      []string
+	refSchema string
+	refTable  string
+	refCols   []string // Empty means the primary key
+	line      int
+}
+
+type parser struct {
+	src      string
+	toks     []token
+	pos      int
+	warnings []string
+	nextID   int
+
+	tables  []*tableDraft
+	byName  map[string]*tableDraft // Lower-case schema.name
+	types   []domain.CustomType
+	fks     []foreignKey
+	skipped map[string]bool // Statement kinds already reported
+	hints   map[sqlgen.Dialect]int
+}
+
+// Parse reads SQL DDL into a snapshot. IDs are placeholders to be replaced
+// by the caller; canvas positions are left at zero. Statements and clauses
+// without a counterpart in the model are reported as warnings.
+func Parse(src string) (*domain.DiagramSnapshot, []string, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &parser{
+		src:     src,
+		toks:    toks,
+		byName:  make(map[string]*tableDraft),
+		skipped: make(map[string]bool),
+		hints:   make(map[sqlgen.Dialect]int),
+	}
+	for _, t := range toks {
+		switch {
+		case t.kind == tokQuoted && src[t.start] == '`':
+			p.hints[sqlgen.MySQL]++
+		case t.kind == tokQuoted && src[t.start] == '[':
+			p.hints[sqlgen.SQLServer]++
+		case t.kind == tokPunct && t.text == "::":
+			p.hints[sqlgen.PostgreSQL]++
+		}
+	}
+	if err := p.parseFile(); err != nil {
+		return nil, p.warnings, err
+	}
+	if len(p.tables) == 0 && len(p.types) == 0 {
+		return nil, p.warnings, fmt.Errorf("no CREATE TABLE statements found")
+	}
+	return p.snapshot(), p.warnings, nil
+}
+
+func (p *parser) id(prefix string) string {
+	p.nextID++
+	return fmt.Sprintf("%s%d", prefix, p.nextID)
+}
+
+func (p *parser) warn(line int, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...))
+}
+
+// Token helpers
+
+func (p *parser) peek() token { return p.peekAt(0) }
+
+func (p *parser) peekAt(n int) token {
+	if p.pos+n < len(p.toks) {
+		return p.toks[p.pos+n]
+	}
+	return p.toks[len(p.toks)-1]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isPunct(s string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.text == s
+}
+
+// accept consumes the keywords if they come next
+func (p *parser) accept(keywords ...string) bool {
+	for i, k := range keywords {
+		if !p.peekAt(i).is(k) {
+			return false
+		}
+	}
+	p.pos += len(keywords)
+	return true
+}
+
+func (p *parser) expect(s string) error {
+	if !p.isPunct(s) {
+		t := p.peek()
+		return fmt.Errorf("line %d: expected %q, found %s", t.line, s, t)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t := p.peek()
+	if !t.isName() {
+		return "", fmt.Errorf("line %d: expected a name, found %s", t.line, t)
+	}
+	p.pos++
+	return t.text, nil
+}
+
+// qualifiedName reads name, schema.name or database.schema.name
+func (p *parser) qualifiedName() (schema, name string, err error) {
+	if name, err = p.name(); err != nil {
+		return "", "", err
+	}
+	for p.isPunct(".") {
+		p.pos++
+		schema = name
+		if name, err = p.name(); err != nil {
+			return "", "", err
+		}
+	}
+	return schema, name, nil
+}
+
+// skipParens skips a ( ... ) group, including nested parentheses
+func (p *parser) skipParens() error {
+	open := p.peek()
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for depth := 1; depth > 0; {
+		t := p.next()
+		switch {
+		case t.kind == tokEOF:
+			return fmt.Errorf("line %d: unbalanced parentheses", open.line)
+		case t.kind == tokPunct && t.text == "(":
+			depth++
+		case t.kind == tokPunct && t.text == ")":
+			depth--
+		}
+	}
+	return nil
+}
+
+// statementStarts lists keywords that begin a statement when they start a line,
+// so statements without a terminating semicolon are still separated
+var statementStarts = map[string]bool{"create": true, "alter": true, "comment": true, "drop": true, "insert": true, "go": true}
+
+// skipStatement moves past the rest of the current statement
+func (p *parser) skipStatement() {
+	for depth := 0; ; {
+		t := p.peek()
+		switch {
+		case t.kind == tokEOF:
+			return
+		case t.kind == tokPunct && t.text == ";" && depth == 0:
+			p.pos++
+			return
+		case t.kind == tokPunct && t.text == "(":
+			depth++
+		case t.kind == tokPunct && t.text == ")":
+			depth--
+		case depth <= 0 && t.kind == tokWord && statementStarts[strings.ToLower(t.text)] &&
+			p.pos > 0 && p.toks[p.pos-1].line < t.line:
+			return
+		}
+		p.pos++
+	}
+}
+
+// unsupported reports a statement kind once; the caller leaves the rest of
+// the statement to skipStatement
+func (p *parser) unsupported(line int, kind string) {
+	if !p.skipped[kind] {
+		p.skipped[kind] = true
+		p.warn(line, "%s statements are not supported and were skipped", kind)
+	}
+}
+
+// Statements that carry no schema information
+var ignoredStatements = map[string]bool{
+	"set": true, "use": true, "begin": true, "start": true, "commit": true, "rollback": true,
+	"drop": true, "go": true, "select": true, "insert": true, "grant": true, "revoke": true, "pragma": true,
+}
+
+func (p *parser) parseFile() error {
+	for {
+		for p.isPunct(";") {
+			p.pos++
+		}
+		t := p.peek()
+		if t.kind == tokEOF {
+			return nil
+		}
+		if t.is("go") {
+			p.hints[sqlgen.SQLServer]++
+			p.pos++
+			continue
+		}
+
+		var err error
+		switch {
+		case t.is("create"):
+			err = p.parseCreate()
+		case t.is("alter") && p.peekAt(1).is("table"):
+			err = p.parseAlterTable()
+		case t.is("comment") && p.peekAt(1).is("on"):
+			err = p.parseComment()
+		case t.kind == tokWord && ignoredStatements[strings.ToLower(t.text)]:
+			p.pos++
+		default:
+			p.pos++
+			p.unsupported(t.line, strings.ToUpper(t.text))
+		}
+		if err != nil {
+			return err
+		}
+		p.skipStatement()
+	}
+}
+
+func (p *parser) parseCreate() error {
+	start := p.next() // CREATE
+	p.accept("or", "replace")
+	unique := false
+modifiers:
+	for {
+		t := p.peek()
+		switch {
+		case t.is("unique"):
+			unique = true
+		case t.is("temp"), t.is("temporary"), t.is("unlogged"), t.is("global"), t.is("local"),
+			t.is("clustered"), t.is("nonclustered"), t.is("virtual"):
+		default:
+			break modifiers
+		}
+		p.pos++
+	}
+	t := p.next()
+	switch {
+	case t.is("table"):
+		return p.parseCreateTable()
+	case t.is("index"):
+		return p.parseCreateIndex(unique, t.line)
+	case t.is("type"):
+		return p.parseCreateType()
+	case t.is("view"):
+		return p.parseCreateView()
+	case t.is("schema"), t.is("database"), t.is("extension"), t.is("sequence"):
+		return nil
+	default:
+		p.unsupported(start.line, "CREATE "+strings.ToUpper(t.text))
+		return nil
+	}
+}
+
+// table returns the draft for a name, creating it when create is set
+func (p *parser) table(schema, name string, create bool) *tableDraft {
+	key := strings.ToLower(schema + "." + name)
+	if t, ok := p.byName[key]; ok || !create {
+		return t
+	}
+	t := &tableDraft{
+		table: domain.Table{
+			TableID: p.id("t"),
+			Name:    name,
+			Schema:  schema,
+			Order:   len(p.tables),
+			Indexes: make([]map[string]interface{}, 0),
+		},
+		byName: make(map[string]int),
+	}
+	p.tables = append(p.tables, t)
+	p.byName[key] = t
+	return t
+}
+
+// lookup finds a referenced table, falling back to the name alone when the
+// reference has no schema or the schema differs only by being the default
+func (p *parser) lookup(schema, name string) *tableDraft {
+	if t := p.table(schema, name, false); t != nil {
+		return t
+	}
+	var found *tableDraft
+	for _, t := range p.tables {
+		if strings.EqualFold(t.table.Name, name) && (schema == "" || t.table.Schema == "") {
+			if found != nil {
+				return nil // Ambiguous
+			}
+			found = t
+		}
+	}
+	return found
+}
+
+func (p *parser) parseCreateTable() error {
+	p.accept("if", "not", "exists")
+	line := p.peek().line
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.isPunct("(") {
+		p.warn(line, "table %s is not defined by a column list and was skipped", name)
+		return nil
+	}
+	if p.table(schema, name, false) != nil {
+		p.warn(line, "table %s is defined twice; the first definition was kept", name)
+		return nil
+	}
+	t := p.table(schema, name, true)
+
+	p.pos++ // (
+	for !p.isPunct(")") {
+		if err := p.tableElement(t); err != nil {
+			return err
+		}
+		if p.isPunct(",") {
+			p.pos++
+			continue
+		}
+		if !p.isPunct(")") {
+			tok := p.peek()
+			return fmt.Errorf("line %d: expected \",\" or \")\" in table %s, found %s", tok.line, name, tok)
+		}
+	}
+	p.pos++ // )
+
+	// Table options up to the end of the statement; MySQL keeps the comment here
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokEOF, tok.kind == tokPunct && tok.text == ";":
+			return nil
+		case tok.kind == tokWord && statementStarts[strings.ToLower(tok.text)] && p.toks[p.pos-1].line < tok.line:
+			return nil
+		case tok.is("comment"):
+			p.pos++
+			if p.isPunct("=") {
+				p.pos++
+			}
+			if s := p.peek(); s.kind == tokString {
+				t.table.Comments = s.text
+			}
+		case tok.is("engine"), tok.is("auto_increment"):
+			p.hints[sqlgen.MySQL]++
+		case tok.is("without") && p.peekAt(1).is("rowid"):
+			p.hints[sqlgen.SQLite]++
+		case tok.kind == tokPunct && tok.text == "(":
+			if err := p.skipParens(); err != nil {
+				return err
+			}
+			continue
+		}
+		p.pos++
+	}
+}
+
+// tableElement reads one column or table constraint
+func (p *parser) tableElement(t *tableDraft) error {
+	tok := p.peek()
+	switch {
+	case tok.is("constraint"), tok.is("primary"), tok.is("foreign"), tok.is("check"), tok.is("exclude"),
+		tok.is("key"), tok.is("index"), tok.is("fulltext"), tok.is("spatial"), tok.is("like"),
+		tok.is("unique") && !p.peekAt(1).isName() || tok.is("unique") && (p.peekAt(1).is("key") || p.peekAt(1).is("index")):
+		return p.tableConstraint(t, "")
+	}
+	return p.columnDef(t)
+}
+
+// Keywords that end a column type or a default expression
+var columnModifiers = map[string]bool{
+	"not": true, "null": true, "primary": true, "unique": true, "default": true, "references": true,
+	"constraint": true, "check": true, "collate": true, "comment": true, "auto_increment": true,
+	"autoincrement": true, "identity": true, "generated": true, "on": true, "as": true, "charset": true,
+}
+
+func (p *parser) isModifier(t token) bool {
+	if t.kind != tokWord {
+		return false
+	}
+	k := strings.ToLower(t.text)
+	if k == "character" && p.peekAt(1).is("set") {
+		return true
+	}
+	return columnModifiers[k]
+}
+
+// Serial pseudo-types are integers with an implicit sequence
+var serialTypes = map[string]string{
+	"serial": "integer", "serial4": "integer", "bigserial": "bigint", "serial8": "bigint",
+	"smallserial": "smallint", "serial2": "smallint",
+}
+
+// lengthTypes take a maximum length rather than a precision
+var lengthTypes = map[string]bool{
+	"char": true, "character": true, "varchar": true, "character varying": true, "nchar": true,
+	"nvarchar": true, "varchar2": true, "nvarchar2": true, "binary": true, "varbinary": true,
+	"bit": true, "varbit": true, "bit varying": true, "char varying": true,
+}
+
+// columnType reads a type with its arguments and array suffix
+func (p *parser) columnType(f *domain.Field) (enumValues []string, err error) {
+	tok := p.peek()
+	if !tok.isName() {
+		return nil, fmt.Errorf("line %d: expected the type of column %s, found %s", tok.line, f.Name, tok)
+	}
+	p.pos++
+	words := []string{tok.text}
+	if tok.kind == tokWord {
+		words[0] = strings.ToLower(tok.text)
+	}
+	for p.isPunct(".") && p.peekAt(1).isName() {
+		// Schema-qualified custom type; the name alone is kept
+		p.pos++
+		words = []string{p.next().text}
+	}
+
+	var args []token
+	for {
+		next := p.peek()
+		switch {
+		case next.kind == tokPunct && next.text == "(" && len(args) == 0:
+			p.pos++
+			for !p.isPunct(")") {
+				a := p.next()
+				if a.kind == tokEOF {
+					return nil, fmt.Errorf("line %d: unterminated type of column %s", tok.line, f.Name)
+				}
+				if a.kind != tokPunct {
+					args = append(args, a)
+				}
+			}
+			p.pos++
+			continue
+		case next.kind == tokPunct && next.text == "[]":
+			p.pos++
+			words = append(words, "[]")
+			continue
+		case next.kind == tokWord && !p.isModifier(next):
+			words = append(words, strings.ToLower(next.text))
+			p.pos++
+			continue
+		}
+		break
+	}
+
+	typ := strings.Join(words, " ")
+	typ = strings.ReplaceAll(typ, " []", "[]")
+	if base, ok := serialTypes[typ]; ok {
+		typ = base
+		f.Increment = true
+		p.hints[sqlgen.PostgreSQL]++
+	}
+	f.Type = typ
+
+	base := strings.TrimSuffix(typ, "[]")
+	if base == "enum" || base == "set" {
+		for _, a := range args {
+			enumValues = append(enumValues, a.text)
+		}
+		p.hints[sqlgen.MySQL]++
+		return enumValues, nil
+	}
+	switch {
+	case len(args) == 1 && (lengthTypes[base] || strings.EqualFold(args[0].text, "max")):
+		f.CharacterMaximumLength = strings.ToLower(args[0].text)
+	case len(args) >= 1:
+		if n, err := strconv.Atoi(args[0].text); err == nil {
+			f.Precision = &n
+		}
+		if len(args) >= 2 {
+			if n, err := strconv.Atoi(args[1].text); err == nil {
+				f.Scale = &n
+			}
+		}
+	}
+	return nil, nil
+}
+
+// expression returns the source text of a default expression
+func (p *parser) expression() string {
+	start := p.peek().start
+	end := start
+	for depth := 0; ; {
+		t := p.peek()
+		switch {
+		case t.kind == tokEOF:
+			return p.src[start:end]
+		case t.kind == tokPunct && t.text == "(":
+			depth++
+		case t.kind == tokPunct && t.text == ")":
+			if depth == 0 {
+				return p.src[start:end]
+			}
+			depth--
+		case depth == 0 && t.kind == tokPunct && (t.text == "," || t.text == ";"):
+			return p.src[start:end]
+		case depth == 0 && end > start && p.isModifier(t):
+			return p.src[start:end]
+		}
+		end = t.end
+		p.pos++
+	}
+}
+
+var stringCast = regexp.MustCompile(`^('(?:[^']|'')*')::[A-Za-z0-9_." ]+(\([0-9, ]*\))?(\[\])*$`)
+
+// setDefault stores a default expression; sequences become auto-increment
+func setDefault(f *domain.Field, expr string) {
+	expr = strings.TrimSpace(expr)
+	switch {
+	case expr == "" || strings.EqualFold(expr, "null"):
+		f.Default = ""
+	case strings.HasPrefix(strings.ToLower(expr), "nextval("):
+		f.Increment = true
+		f.Default = ""
+	default:
+		if m := stringCast.FindStringSubmatch(expr); m != nil {
+			expr = m[1]
+		}
+		f.Default = expr
+	}
+}
+
+func (p *parser) columnDef(t *tableDraft) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	f := domain.Field{ID: p.id("f"), Name: name}
+	enumValues, err := p.columnType(&f)
+	if err != nil {
+		return err
+	}
+	if enumValues != nil {
+		f.Type = p.inlineEnum(t, name, enumValues)
+	}
+
+	notNull, constraint := false, ""
+	for {
+		tok := p.peek()
+		switch {
+		case tok.kind == tokEOF, tok.kind == tokPunct && (tok.text == "," || tok.text == ")" || tok.text == ";"):
+			f.Nullable = !notNull && !f.PrimaryKey
+			t.byName[strings.ToLower(name)] = len(t.cols)
+			t.cols = append(t.cols, f)
+			return nil
+		case p.accept("not", "null"):
+			notNull = true
+		case p.accept("null"):
+		case p.accept("primary", "key"):
+			f.PrimaryKey = true
+			p.accept("asc")
+			p.accept("desc")
+		case p.accept("unique"):
+			f.Unique = true
+			p.accept("key")
+		case p.accept("default"):
+			if p.peek().is("null") {
+				p.pos++
+				continue
+			}
+			setDefault(&f, p.expression())
+		case tok.is("auto_increment"):
+			p.pos++
+			f.Increment = true
+			p.hints[sqlgen.MySQL]++
+		case tok.is("autoincrement"):
+			p.pos++
+			f.Increment = true
+			p.hints[sqlgen.SQLite]++
+		case tok.is("identity"):
+			p.pos++
+			f.Increment = true
+			p.hints[sqlgen.SQLServer]++
+			if p.isPunct("(") {
+				if err := p.skipParens(); err != nil {
+					return err
+				}
+			}
+		case tok.is("generated"):
+			p.pos++
+			p.accept("always")
+			p.accept("by", "default")
+			p.accept("on", "null")
+			p.accept("as")
+			if p.accept("identity") {
+				f.Increment = true
+				p.hints[sqlgen.PostgreSQL]++
+				if p.isPunct("(") {
+					if err := p.skipParens(); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			p.warn(tok.line, "generated column %s.%s was kept without its expression", t.table.Name, name)
+			if p.isPunct("(") {
+				if err := p.skipParens(); err != nil {
+					return err
+				}
+			}
+		case tok.is("as") && p.peekAt(1).kind == tokPunct && p.peekAt(1).text == "(":
+			p.pos++
+			p.warn(tok.line, "computed column %s.%s was kept without its expression", t.table.Name, name)
+			if err := p.skipParens(); err != nil {
+				return err
+			}
+		case p.accept("references"):
+			fk := foreignKey{table: t, name: constraint, cols: []string{name}, line: tok.line}
+			if err := p.reference(&fk); err != nil {
+				return err
+			}
+			p.fks = append(p.fks, fk)
+		case p.accept("constraint"):
+			if constraint, err = p.name(); err != nil {
+				return err
+			}
+			continue
+		case p.accept("check"):
+			p.warn(tok.line, "check constraint on %s.%s is not stored", t.table.Name, name)
+			if p.isPunct("(") {
+				if err := p.skipParens(); err != nil {
+					return err
+				}
+			}
+		case p.accept("collate"):
+			if f.Collation, err = p.name(); err != nil {
+				return err
+			}
+		case p.accept("comment"):
+			if s := p.peek(); s.kind == tokString {
+				f.Comments = s.text
+				p.pos++
+			}
+		case p.accept("on", "update"):
+			p.expression()
+		case tok.kind == tokPunct && tok.text == "(":
+			if err := p.skipParens(); err != nil {
+				return err
+			}
+		default:
+			// CHARACTER SET, ASC, storage and other options without a counterpart
+			p.pos++
+		}
+		constraint = ""
+	}
+}
+
+// inlineEnum turns a MySQL ENUM(...) column type into a custom type
+func (p *parser) inlineEnum(t *tableDraft, column string, values []string) string {
+	ct := domain.CustomType{Kind: "enum", Schema: t.table.Schema, Type: t.table.Name + "_" + column}
+	list := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		list = append(list, v)
+	}
+	ct.Values = list
+	p.types = append(p.types, ct)
+	return ct.Type
+}
+
+// reference reads the target of REFERENCES and any referential actions
+func (p *parser) reference(fk *foreignKey) error {
+	var err error
+	if fk.refSchema, fk.refTable, err = p.qualifiedName(); err != nil {
+		return err
+	}
+	if p.isPunct("(") {
+		if fk.refCols, err = p.columnList(); err != nil {
+			return err
+		}
+	}
+	for {
+		switch {
+		case p.accept("on", "delete"), p.accept("on", "update"):
+			switch {
+			case p.accept("no", "action"), p.accept("set", "null"), p.accept("set", "default"):
+			default:
+				p.pos++
+			}
+		case p.accept("match"):
+			p.pos++
+		case p.accept("deferrable"), p.accept("not", "deferrable"), p.accept("initially", "deferred"),
+			p.accept("initially", "immediate"), p.accept("not", "valid"):
+		default:
+			return nil
+		}
+	}
+}
+
+// columnList reads ( a, b DESC, ... ); expressions fail so the caller can
+// skip the constraint or index
+func (p *parser) columnList() ([]string, error) {
+	open := p.peek()
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	cols := make([]string, 0)
+	expr := false
+	for {
+		tok := p.peek()
+		if !tok.isName() {
+			expr = true
+		} else {
+			cols = append(cols, tok.text)
+			p.pos++
+		}
+		// Sort order, prefix lengths, operator classes and the like
+		for depth := 0; ; {
+			t := p.peek()
+			if t.kind == tokEOF {
+				return nil, fmt.Errorf("line %d: unbalanced parentheses", open.line)
+			}
+			if depth == 0 && t.kind == tokPunct && (t.text == "," || t.text == ")") {
+				break
+			}
+			switch {
+			case t.kind == tokPunct && t.text == "(":
+				// A prefix length such as name(10) is fine; a call is an expression
+				if n, c := p.peekAt(1), p.peekAt(2); n.kind != tokWord || !isDigit(n.text[0]) || c.kind != tokPunct || c.text != ")" {
+					expr = true
+				}
+				depth++
+			case t.kind == tokPunct && t.text == ")":
+				depth--
+			case t.kind == tokPunct && depth == 0:
+				expr = true
+			}
+			p.pos++
+		}
+		if p.next().text == ")" {
+			break
+		}
+	}
+	if expr {
+		return nil, errExpression
+	}
+	return cols, nil
+}
+
+var errExpression = fmt.Errorf("expression")
+
+// tableConstraint reads a table constraint or a MySQL inline index
+func (p *parser) tableConstraint(t *tableDraft, name string) error {
+	tok := p.peek()
+	var err error
+	if p.accept("constraint") {
+		if p.peek().isName() && !p.peek().is("primary") && !p.peek().is("unique") &&
+			!p.peek().is("foreign") && !p.peek().is("check") {
+			if name, err = p.name(); err != nil {
+				return err
+			}
+		}
+		tok = p.peek()
+	}
+
+	switch {
+	case p.accept("primary", "key"):
+		p.accept("clustered")
+		p.accept("nonclustered")
+		cols, err := p.columnList()
+		if err != nil {
+			return p.constraintError(err, tok, t, "primary key")
+		}
+		for _, c := range cols {
+			if f := t.column(c); f != nil {
+				f.PrimaryKey = true
+				f.Nullable = false
+			} else {
+				p.warn(tok.line, "primary key of %s names unknown column %s", t.table.Name, c)
+			}
+		}
+	case p.accept("unique"):
+		if !p.accept("key") {
+			p.accept("index")
+		}
+		p.accept("clustered")
+		p.accept("nonclustered")
+		if !p.isPunct("(") {
+			if name, err = p.name(); err != nil {
+				return err
+			}
+		}
+		cols, err := p.columnList()
+		if err != nil {
+			return p.constraintError(err, tok, t, "unique constraint")
+		}
+		p.addIndex(t, name, cols, true, tok.line)
+	case p.accept("foreign", "key"):
+		if !p.isPunct("(") {
+			if name, err = p.name(); err != nil {
+				return err
+			}
+		}
+		cols, err := p.columnList()
+		if err != nil {
+			return p.constraintError(err, tok, t, "foreign key")
+		}
+		if !p.accept("references") {
+			return fmt.Errorf("line %d: expected REFERENCES after FOREIGN KEY", p.peek().line)
+		}
+		fk := foreignKey{table: t, name: name, cols: cols, line: tok.line}
+		if err := p.reference(&fk); err != nil {
+			return err
+		}
+		p.fks = append(p.fks, fk)
+	case p.accept("key"), p.accept("index"):
+		p.hints[sqlgen.MySQL]++
+		if !p.isPunct("(") {
+			if name, err = p.name(); err != nil {
+				return err
+			}
+		}
+		cols, err := p.columnList()
+		if err != nil {
+			return p.constraintError(err, tok, t, "index")
+		}
+		p.addIndex(t, name, cols, false, tok.line)
+	default:
+		p.warn(tok.line, "%s constraint on %s is not stored", strings.ToLower(tok.text), t.table.Name)
+	}
+
+	// Skip whatever remains of the element, e.g. USING BTREE or a CHECK body
+	for depth := 0; ; {
+		next := p.peek()
+		switch {
+		case next.kind == tokEOF:
+			return nil
+		case depth == 0 && next.kind == tokPunct && (next.text == "," || next.text == ")" || next.text == ";"):
+			return nil
+		case next.kind == tokPunct && next.text == "(":
+			depth++
+		case next.kind == tokPunct && next.text == ")":
+			depth--
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) constraintError(err error, tok token, t *tableDraft, what string) error {
+	if err != errExpression {
+		return err
+	}
+	p.warn(tok.line, "%s on an expression in %s was skipped", what, t.table.Name)
+	return nil
+}
+
+// addIndex stores an index; a unique index on one column marks the column unique
+func (p *parser) addIndex(t *tableDraft, name string, cols []string, unique bool, line int) {
+	idx := domain.Index{ID: p.id("i"), Name: name, Unique: unique}
+	for _, c := range cols {
+		f := t.column(c)
+		if f == nil {
+			p.warn(line, "index on %s names unknown column %s", t.table.Name, c)
+			return
+		}
+		idx.FieldIDs = append(idx.FieldIDs, f.ID)
+	}
+	if unique && len(cols) == 1 && name == "" {
+		t.column(cols[0]).Unique = true
+		return
+	}
+	t.table.Indexes = append(t.table.Indexes, idx.ToMap())
+}
+
+func (p *parser) parseCreateIndex(unique bool, line int) error {
+	p.accept("concurrently")
+	p.accept("if", "not", "exists")
+	name := ""
+	if !p.peek().is("on") {
+		_, n, err := p.qualifiedName()
+		if err != nil {
+			return err
+		}
+		name = n
+	}
+	if !p.accept("on") {
+		return fmt.Errorf("line %d: expected ON in CREATE INDEX", p.peek().line)
+	}
+	p.accept("only")
+	schema, table, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if p.accept("using") {
+		p.pos++
+	}
+	t := p.lookup(schema, table)
+	if t == nil {
+		p.warn(line, "index %s on unknown table %s was skipped", name, table)
+		return nil
+	}
+	cols, err := p.columnList()
+	if err != nil {
+		return p.constraintError(err, p.toks[p.pos-1], t, "index "+name)
+	}
+	p.addIndex(t, name, cols, unique, line)
+	return nil
+}
+
+func (p *parser) parseAlterTable() error {
+	p.pos += 2 // ALTER TABLE
+	p.accept("if", "exists")
+	p.accept("only")
+	line := p.peek().line
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	t := p.lookup(schema, name)
+	if t == nil {
+		p.warn(line, "ALTER TABLE on unknown table %s was skipped", name)
+		return nil
+	}
+
+	for {
+		tok := p.peek()
+		switch {
+		case p.accept("add"):
+			next := p.peek()
+			if next.is("constraint") || next.is("primary") || next.is("unique") || next.is("foreign") ||
+				next.is("check") || next.is("index") || next.is("key") {
+				if err := p.tableConstraint(t, ""); err != nil {
+					return err
+				}
+				break
+			}
+			p.accept("column")
+			p.accept("if", "not", "exists")
+			if err := p.columnDef(t); err != nil {
+				return err
+			}
+		case p.accept("alter"):
+			p.accept("column")
+			col, err := p.name()
+			if err != nil {
+				return err
+			}
+			f := t.column(col)
+			switch {
+			case f == nil:
+				p.warn(tok.line, "ALTER COLUMN on unknown column %s.%s was skipped", name, col)
+			case p.accept("set", "default"):
+				setDefault(f, p.expression())
+			case p.accept("drop", "default"):
+				f.Default = ""
+			case p.accept("set", "not", "null"):
+				f.Nullable = false
+			case p.accept("drop", "not", "null"):
+				f.Nullable = true
+			case p.accept("add", "generated"):
+				f.Increment = true
+			}
+		case tok.is("owner"):
+		default:
+			if tok.kind != tokEOF && !(tok.kind == tokPunct && tok.text == ";") {
+				p.unsupported(tok.line, "ALTER TABLE "+strings.ToUpper(tok.text))
+			}
+			return nil
+		}
+
+		// More actions may follow, separated by commas
+		for depth := 0; ; {
+			next := p.peek()
+			if next.kind == tokEOF || depth == 0 && next.kind == tokPunct && next.text == ";" {
+				return nil
+			}
+			if depth == 0 && next.kind == tokPunct && next.text == "," {
+				p.pos++
+				break
+			}
+			if next.kind == tokPunct && next.text == "(" {
+				depth++
+			} else if next.kind == tokPunct && next.text == ")" {
+				depth--
+			}
+			p.pos++
+		}
+	}
+}
+
+func (p *parser) parseComment() error {
+	p.pos += 2 // COMMENT ON
+	kind := p.next()
+	line := kind.line
+	parts := make([]string, 0, 3)
+	for {
+		n, err := p.name()
+		if err != nil {
+			return err
+		}
+		parts = append(parts, n)
+		if !p.isPunct(".") {
+			break
+		}
+		p.pos++
+	}
+	if !p.accept("is") {
+		return fmt.Errorf("line %d: expected IS in COMMENT ON", p.peek().line)
+	}
+	text := ""
+	if s := p.peek(); s.kind == tokString {
+		text = s.text
+	}
+
+	switch {
+	case kind.is("table") || kind.is("view"):
+		schema, name := "", parts[len(parts)-1]
+		if len(parts) > 1 {
+			schema = parts[len(parts)-2]
+		}
+		if t := p.lookup(schema, name); t != nil {
+			t.table.Comments = text
+		} else {
+			p.warn(line, "comment on unknown table %s was skipped", name)
+		}
+	case kind.is("column") && len(parts) >= 2:
+		schema, name, col := "", parts[len(parts)-2], parts[len(parts)-1]
+		if len(parts) > 2 {
+			schema = parts[len(parts)-3]
+		}
+		t := p.lookup(schema, name)
+		if t == nil || t.column(col) == nil {
+			p.warn(line, "comment on unknown column %s.%s was skipped", name, col)
+			return nil
+		}
+		t.column(col).Comments = text
+	}
+	return nil
+}
+
+func (p *parser) parseCreateType() error {
+	line := p.peek().line
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.accept("as") {
+		p.warn(line, "type %s was skipped", name)
+		return nil
+	}
+	ct := domain.CustomType{Schema: schema, Type: name}
+	switch {
+	case p.accept("enum"):
+		p.hints[sqlgen.PostgreSQL]++
+		ct.Kind = "enum"
+		values := make([]interface{}, 0)
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		for !p.isPunct(")") {
+			t := p.next()
+			switch {
+			case t.kind == tokString:
+				values = append(values, t.text)
+			case t.kind == tokEOF:
+				return fmt.Errorf("line %d: unterminated enum %s", line, name)
+			}
+		}
+		p.pos++
+		ct.Values = values
+	case p.isPunct("("):
+		ct.Kind = "composite"
+		p.pos++
+		fields := make([]interface{}, 0)
+		for !p.isPunct(")") {
+			fieldName, err := p.name()
+			if err != nil {
+				return err
+			}
+			start := p.peek().start
+			if _, err := p.columnType(&domain.Field{Name: fieldName}); err != nil {
+				return err
+			}
+			typ := p.src[start:p.toks[p.pos-1].end]
+			fields = append(fields, map[string]interface{}{"field": fieldName, "type": typ})
+			for !p.isPunct(",") && !p.isPunct(")") {
+				if p.next().kind == tokEOF {
+					return fmt.Errorf("line %d: unterminated type %s", line, name)
+				}
+			}
+			if p.isPunct(",") {
+				p.pos++
+			}
+		}
+		p.pos++
+		ct.Fields = fields
+	default:
+		p.warn(line, "type %s is neither an enum nor a composite type and was skipped", name)
+		return nil
+	}
+	p.types = append(p.types, ct)
+	return nil
+}
+
+func (p *parser) parseCreateView() error {
+	p.accept("if", "not", "exists")
+	line := p.peek().line
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if p.table(schema, name, false) != nil {
+		p.warn(line, "view %s is defined twice; the first definition was kept", name)
+		return nil
+	}
+	t := p.table(schema, name, true)
+	t.table.IsView = true
+	if p.isPunct("(") {
+		cols, err := p.columnList()
+		if err != nil && err != errExpression {
+			return err
+		}
+		for _, c := range cols {
+			t.byName[strings.ToLower(c)] = len(t.cols)
+			t.cols = append(t.cols, domain.Field{ID: p.id("f"), Name: c, Type: "text", Nullable: true})
+		}
+	}
+	if len(t.cols) == 0 {
+		p.warn(line, "columns of view %s are not known without a column list", name)
+	}
+	return nil
+}
+
+// snapshot resolves foreign keys and assembles the result
+func (p *parser) snapshot() *domain.DiagramSnapshot {
+	content := map[string]interface{}{}
+	best, bestHints := sqlgen.Dialect(""), 0
+	for _, d := range []sqlgen.Dialect{sqlgen.PostgreSQL, sqlgen.MySQL, sqlgen.SQLServer, sqlgen.SQLite} {
+		if p.hints[d] > bestHints {
+			best, bestHints = d, p.hints[d]
+		}
+	}
+	if best != "" {
+		content["databaseType"] = string(best)
+	}
+
+	snap := &domain.DiagramSnapshot{
+		Diagram:       &domain.Diagram{Content: content},
+		Tables:        make([]domain.Table, 0, len(p.tables)),
+		Relationships: make([]domain.Relationship, 0, len(p.fks)),
+		Dependencies:  make([]domain.Dependency, 0),
+		Areas:         make([]domain.Area, 0),
+		CustomTypes:   p.types,
+		Notes:         make([]domain.Note, 0),
+	}
+	if snap.CustomTypes == nil {
+		snap.CustomTypes = make([]domain.CustomType, 0)
+	}
+
+	// A key declared both inline and by ALTER TABLE is kept once
+	seen := make(map[string]int)
+	for _, fk := range p.fks {
+		for _, r := range p.relations(fk) {
+			key := r.SourceFieldID + ">" + r.TargetFieldID
+			if i, ok := seen[key]; ok {
+				if snap.Relationships[i].Name == "" {
+					snap.Relationships[i].Name = r.Name
+				}
+				continue
+			}
+			seen[key] = len(snap.Relationships)
+			snap.Relationships = append(snap.Relationships, r)
+		}
+	}
+	for _, t := range p.tables {
+		t.table.Fields = make([]map[string]interface{}, 0, len(t.cols))
+		for _, f := range t.cols {
+			t.table.Fields = append(t.table.Fields, f.ToMap())
+		}
+		snap.Tables = append(snap.Tables, t.table)
+	}
+	return snap
+}
+
+// relations builds one relationship per column pair of a foreign key
+func (p *parser) relations(fk foreignKey) []domain.Relationship {
+	target := p.lookup(fk.refSchema, fk.refTable)
+	if target == nil {
+		p.warn(fk.line, "foreign key of %s references unknown table %s and was skipped", fk.table.table.Name, fk.refTable)
+		return nil
+	}
+	refCols := fk.refCols
+	if len(refCols) == 0 {
+		for _, f := range target.cols {
+			if f.PrimaryKey {
+				refCols = append(refCols, f.Name)
+			}
+		}
+	}
+	if len(refCols) != len(fk.cols) {
+		p.warn(fk.line, "foreign key of %s with mismatched columns was skipped", fk.table.table.Name)
+		return nil
+	}
+
+	// Relationships of a composite key share a name so they export as one constraint
+	name := fk.name
+	if name == "" && len(fk.cols) > 1 {
+		name = "fk_" + fk.table.table.Name + "_" + strings.Join(fk.cols, "_")
+	}
+
+	childCard := domain.CardinalityMany
+	if uniqueOn(fk.table, fk.cols) {
+		childCard = domain.CardinalityOne
+	}
+	out := make([]domain.Relationship, 0, len(fk.cols))
+	for i := range fk.cols {
+		col, ref := fk.table.column(fk.cols[i]), target.column(refCols[i])
+		if col == nil || ref == nil {
+			p.warn(fk.line, "foreign key of %s on unknown column %s or %s.%s was skipped",
+				fk.table.table.Name, fk.cols[i], target.table.Name, refCols[i])
+			continue
+		}
+		out = append(out, domain.Relationship{
+			RelationshipID:    p.id("r"),
+			Name:              name,
+			SourceTableID:     fk.table.table.TableID,
+			SourceFieldID:     col.ID,
+			TargetTableID:     target.table.TableID,
+			TargetFieldID:     ref.ID,
+			SourceCardinality: childCard,
+			TargetCardinality: domain.CardinalityOne,
+		})
+	}
+	return out
+}
+
+// uniqueOn reports whether the columns are unique together, making a relation one-to-one
+func uniqueOn(t *tableDraft, cols []string) bool {
+	ids := make([]string, 0, len(cols))
+	for _, c := range cols {
+		f := t.column(c)
+		if f == nil {
+			return false
+		}
+		ids = append(ids, f.ID)
+	}
+	pk := make([]string, 0)
+	for _, f := range t.cols {
+		if f.PrimaryKey {
+			pk = append(pk, f.ID)
+		}
+	}
+	if len(ids) == 1 && t.column(cols[0]).Unique || strings.Join(pk, ",") == strings.Join(ids, ",") {
+		return true
+	}
+	for _, idx := range t.table.IndexList() {
+		if idx.Unique && strings.Join(idx.FieldIDs, ",") == strings.Join(ids, ",") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,336 @@
+package ddl
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/internal/exchangetest"
+	"github.com/iots1/vertex-diagram/sqlgen"
+)
+
+func TestParseColumnDefinitions(t *testing.T) {
+	tests := []struct {
+		column   string
+		want     domain.Field
+		warnings int
+	}{
+		{"id bigserial PRIMARY KEY", domain.Field{Name: "id", Type: "bigint", PrimaryKey: true, Increment: true}, 0},
+		{"id int GENERATED BY DEFAULT AS IDENTITY", domain.Field{Name: "id", Type: "int", Nullable: true, Increment: true}, 0},
+		{"id int NOT NULL AUTO_INCREMENT", domain.Field{Name: "id", Type: "int", Increment: true}, 0},
+		{"id int IDENTITY(1,1) NOT NULL", domain.Field{Name: "id", Type: "int", Increment: true}, 0},
+		{"email VARCHAR(255) NOT NULL UNIQUE", domain.Field{Name: "email", Type: "varchar", CharacterMaximumLength: "255", Unique: true}, 0},
+		{"bio nvarchar(max) NULL", domain.Field{Name: "bio", Type: "nvarchar", CharacterMaximumLength: "max", Nullable: true}, 0},
+		{"price numeric(10, 2) DEFAULT 0", domain.Field{Name: "price", Type: "numeric", Precision: exchangetest.IntPtr(10), Scale: exchangetest.IntPtr(2), Nullable: true, Default: "0"}, 0},
+		{"ratio double precision", domain.Field{Name: "ratio", Type: "double precision", Nullable: true}, 0},
+		{"at timestamp with time zone DEFAULT now()", domain.Field{Name: "at", Type: "timestamp with time zone", Nullable: true, Default: "now()"}, 0},
+		{"tags text[] DEFAULT '{}'::text[]", domain.Field{Name: "tags", Type: "text[]", Nullable: true, Default: "'{}'"}, 0},
+		{"name text COLLATE \"C\" COMMENT 'shown'", domain.Field{Name: "name", Type: "text", Nullable: true, Collation: "C", Comments: "shown"}, 0},
+		{"n int CHECK (n > 0)", domain.Field{Name: "n", Type: "int", Nullable: true}, 1},
+		{"total int GENERATED ALWAYS AS (n * 2) STORED", domain.Field{Name: "total", Type: "int", Nullable: true}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			snap, warnings, err := Parse("CREATE TABLE users (\n  " + tt.column + "\n);\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("warnings = %q, want %d", warnings, tt.warnings)
+			}
+			got := domain.FieldFromMap(snap.Tables[0].Fields[0])
+			got.ID = ""
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseForeignKeys(t *testing.T) {
+	const users = `
+CREATE TABLE users (
+  id int PRIMARY KEY,
+  email text UNIQUE
+);
+`
+	tests := []struct {
+		name, sql string
+		want      []string
+		warnings  int
+	}{
+		{
+			"inline",
+			"CREATE TABLE posts (id int, author_id int REFERENCES users (id) ON DELETE CASCADE);",
+			[]string{"posts.author_id many -> users.id one"}, 0,
+		},
+		{
+			"primary key by default",
+			"CREATE TABLE posts (id int, author_id int REFERENCES users);",
+			[]string{"posts.author_id many -> users.id one"}, 0,
+		},
+		{
+			"table constraint",
+			"CREATE TABLE posts (id int, author text, CONSTRAINT fk FOREIGN KEY (author) REFERENCES users (email));",
+			[]string{"posts.author many -> users.email one"}, 0,
+		},
+		{
+			"one to one",
+			"CREATE TABLE profiles (user_id int UNIQUE REFERENCES users (id));",
+			[]string{"profiles.user_id one -> users.id one"}, 0,
+		},
+		{
+			"composite",
+			"CREATE TABLE posts (a int, b text, UNIQUE (a, b), FOREIGN KEY (a, b) REFERENCES users (id, email));",
+			[]string{"posts.a one -> users.id one", "posts.b one -> users.email one"}, 0,
+		},
+		{
+			"alter table",
+			"CREATE TABLE posts (id int, author_id int);\nALTER TABLE posts ADD CONSTRAINT fk_author FOREIGN KEY (author_id) REFERENCES users (id);",
+			[]string{"posts.author_id many -> users.id one"}, 0,
+		},
+		{
+			"declared twice",
+			"CREATE TABLE posts (id int, author_id int REFERENCES users (id));\nALTER TABLE posts ADD CONSTRAINT fk_author FOREIGN KEY (author_id) REFERENCES users (id);",
+			[]string{"posts.author_id many -> users.id one"}, 0,
+		},
+		{
+			"unknown table",
+			"CREATE TABLE posts (id int, author_id int REFERENCES people (id));",
+			nil, 1,
+		},
+		{
+			"mismatched",
+			"CREATE TABLE posts (a int, FOREIGN KEY (a) REFERENCES users (id, email));",
+			nil, 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap, warnings, err := Parse(users + tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := exchangetest.Relationships(snap); !slices.Equal(got, tt.want) {
+				t.Errorf("relationships = %q, want %q", got, tt.want)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("warnings = %q, want %d", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestParseIndexesAndComments(t *testing.T) {
+	src := `
+CREATE TABLE shop.users (
+  id int PRIMARY KEY,
+  email text,
+  status text,
+  UNIQUE KEY ix_email (email)
+);
+CREATE INDEX ix_status ON shop.users (status, email);
+CREATE INDEX ix_lower ON shop.users (lower(email));
+COMMENT ON TABLE shop.users IS 'people';
+COMMENT ON COLUMN shop.users.email IS 'login';
+COMMENT ON COLUMN shop.users.missing IS 'x';
+`
+	snap, warnings, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("warnings = %q, want the expression index and the unknown column", warnings)
+	}
+	tbl := snap.Tables[0]
+	fields := tbl.FieldList()
+	if tbl.Schema != "shop" || tbl.Comments != "people" || fields[1].Comments != "login" {
+		t.Errorf("table %q.%q %q, email %q", tbl.Schema, tbl.Name, tbl.Comments, fields[1].Comments)
+	}
+	want := []domain.Index{
+		{Name: "ix_email", Unique: true, FieldIDs: []string{fields[1].ID}},
+		{Name: "ix_status", FieldIDs: []string{fields[2].ID, fields[1].ID}},
+	}
+	got := tbl.IndexList()
+	for i := range got {
+		got[i].ID = ""
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("indexes = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseEnums(t *testing.T) {
+	tests := []struct {
+		name, sql, fieldType string
+		want                 domain.CustomType
+	}{
+		{
+			"create type",
+			"CREATE TYPE shop.status AS ENUM ('active', 'on hold');\nCREATE TABLE users (status shop.status DEFAULT 'active'::shop.status);",
+			"status",
+			domain.CustomType{Schema: "shop", Type: "status", Kind: "enum", Values: []interface{}{"active", "on hold"}},
+		},
+		{
+			"inline",
+			"CREATE TABLE `users` (`status` ENUM('active', 'on hold') DEFAULT 'active');",
+			"users_status",
+			domain.CustomType{Type: "users_status", Kind: "enum", Values: []interface{}{"active", "on hold"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap, warnings, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(warnings) > 0 {
+				t.Errorf("warnings: %q", warnings)
+			}
+			if len(snap.CustomTypes) != 1 {
+				t.Fatalf("custom types = %+v, want one", snap.CustomTypes)
+			}
+			got := snap.CustomTypes[0]
+			if got.Schema != tt.want.Schema || got.Type != tt.want.Type || got.Kind != tt.want.Kind || !reflect.DeepEqual(got.Values, tt.want.Values) {
+				t.Errorf("custom type = %+v, want %+v", got, tt.want)
+			}
+			f := snap.Tables[0].FieldList()[0]
+			if f.Type != tt.fieldType || f.Default != "'active'" {
+				t.Errorf("field type %q default %q, want %s 'active'", f.Type, f.Default, tt.fieldType)
+			}
+		})
+	}
+}
+
+func TestParseDialect(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"CREATE TABLE t (id serial, at timestamp DEFAULT now()::timestamp);", "postgresql"},
+		{"CREATE TABLE `t` (`id` int AUTO_INCREMENT);", "mysql"},
+		{"CREATE TABLE [dbo].[t] ([id] int IDENTITY(1,1));", "sql_server"},
+		{"CREATE TABLE t (id integer PRIMARY KEY AUTOINCREMENT);", "sqlite"},
+		{"CREATE TABLE t (id int);", ""},
+	}
+	for _, tt := range tests {
+		snap, _, err := Parse(tt.sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := domain.MapString(snap.Diagram.Content, "databaseType"); got != tt.want {
+			t.Errorf("%s: database type = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"SELECT 1;", "no CREATE TABLE statements found"},
+		{"CREATE TABLE t (\n  id int,\n  name\n);", `line 4: expected the type of column name, found ")"`},
+		{"CREATE TABLE t (\n  id int,\n  n text DEFAULT 'x\n);", "line 3: unterminated '"},
+		{"CREATE TABLE t (\n  id int,\n  FOREIGN KEY (id) x\n);", "line 3: expected REFERENCES after FOREIGN KEY"},
+		{"CREATE TABLE t (id int);\nCREATE INDEX i t (id);", "line 2: expected ON in CREATE INDEX"},
+		{"CREATE TABLE t (id int);\n\nCOMMENT ON TABLE t 'x';", "line 3: expected IS in COMMENT ON"},
+		{"/* x\nCREATE TABLE t (id int);", "line 1: unterminated comment"},
+	}
+	for _, tt := range tests {
+		_, _, err := Parse(tt.src)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+// Each source uses only what its dialect can express, so writing it back in
+// the same dialect loses nothing
+var roundTripSources = []struct {
+	dialect sqlgen.Dialect
+	sql     string
+}{
+	{sqlgen.PostgreSQL, `
+CREATE SCHEMA shop;
+CREATE TYPE shop.status AS ENUM ('active', 'on hold');
+CREATE TABLE shop.users (
+  id bigserial PRIMARY KEY,
+  email varchar(255) NOT NULL UNIQUE,
+  price numeric(10, 2) DEFAULT 0,
+  status shop.status DEFAULT 'active'::shop.status,
+  created_at timestamp DEFAULT now()
+);
+CREATE TABLE orders (
+  id integer NOT NULL,
+  user_id bigint REFERENCES shop.users (id),
+  tag integer,
+  CONSTRAINT pk_orders PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX ix_email_status ON shop.users (email, status);
+ALTER TABLE orders ADD CONSTRAINT fk_tag FOREIGN KEY (tag) REFERENCES shop.users (id);
+COMMENT ON TABLE shop.users IS 'people';
+COMMENT ON COLUMN shop.users.email IS 'login';
+`},
+	{sqlgen.MySQL, "" +
+		"CREATE TABLE `users` (\n" +
+		"  `id` bigint NOT NULL AUTO_INCREMENT,\n" +
+		"  `email` varchar(255) NOT NULL COMMENT 'login',\n" +
+		"  `status` ENUM('active', 'on hold') DEFAULT 'active',\n" +
+		"  `price` decimal(10,2) DEFAULT 0,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `ix_email` (`email`),\n" +
+		"  KEY `ix_status_price` (`status`, `price`)\n" +
+		") ENGINE=InnoDB COMMENT='people';\n" +
+		"CREATE TABLE `orders` (\n" +
+		"  `id` int NOT NULL,\n" +
+		"  `user_id` bigint,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)\n" +
+		");\n"},
+	{sqlgen.SQLServer, `
+CREATE TABLE [dbo].[users] (
+  [id] bigint IDENTITY(1,1) NOT NULL,
+  [email] nvarchar(255) NOT NULL,
+  [bio] nvarchar(max),
+  CONSTRAINT [pk_users] PRIMARY KEY ([id])
+);
+CREATE TABLE [dbo].[profiles] (
+  [id] int NOT NULL PRIMARY KEY,
+  [user_id] bigint NOT NULL UNIQUE,
+  FOREIGN KEY ([user_id]) REFERENCES [dbo].[users] ([id])
+);
+CREATE UNIQUE INDEX [ix_email] ON [dbo].[users] ([email]);
+`},
+	{sqlgen.SQLite, `
+CREATE TABLE users (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  email text NOT NULL UNIQUE,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE posts (
+  id integer PRIMARY KEY,
+  author_id integer REFERENCES users (id),
+  reviewer_id integer REFERENCES users
+);
+`},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tt := range roundTripSources {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			first, warnings, err := Parse(tt.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(warnings) > 0 {
+				t.Errorf("warnings: %q", warnings)
+			}
+			write := func(snap *domain.DiagramSnapshot) []byte { return Write(snap, tt.dialect) }
+			second, out := exchangetest.RoundTrip(t, first, write, Parse)
+			if got := domain.MapString(second.Diagram.Content, "databaseType"); got != string(tt.dialect) {
+				t.Errorf("database type = %q\n%s", got, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,442 @@
+package ddl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/sqlgen"
+)
+
+// Write renders a snapshot as DDL for the dialect: schemas, custom types,
+// tables, indexes, comments and foreign keys, in that order
+func Write(snap *domain.DiagramSnapshot, dialect sqlgen.Dialect) []byte {
+	w := &writer{
+		snap:    snap,
+		d:       dialect,
+		tables:  make(map[string]domain.Table, len(snap.Tables)),
+		enums:   make(map[string]domain.CustomType),
+		inlined: make(map[string]bool),
+	}
+	for _, t := range snap.Tables {
+		w.tables[t.TableID] = t
+	}
+	for _, ct := range snap.CustomTypes {
+		if ct.Kind == "enum" {
+			w.enums[strings.ToLower(ct.Type)] = ct
+		}
+	}
+
+	if snap.Diagram != nil && snap.Diagram.Name != "" {
+		w.printf("-- %s\n", oneLine(snap.Diagram.Name))
+	}
+	w.printf("-- Dialect: %s\n\n", dialect)
+
+	w.schemas()
+	w.customTypes()
+	fks := w.foreignKeys()
+	for _, t := range snap.Tables {
+		w.table(t, fks)
+	}
+	for _, t := range snap.Tables {
+		w.indexes(t)
+	}
+	w.comments()
+	w.constraints(fks)
+	return []byte(strings.TrimRight(w.b.String(), "\n") + "\n")
+}
+
+type writer struct {
+	b       strings.Builder
+	snap    *domain.DiagramSnapshot
+	d       sqlgen.Dialect
+	tables  map[string]domain.Table
+	enums   map[string]domain.CustomType // Lower-case type name
+	inlined map[string]bool              // Relationship group keys written inside CREATE TABLE
+}
+
+func (w *writer) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&w.b, format, args...)
+}
+
+func (w *writer) hasTypes() bool {
+	return w.d == sqlgen.PostgreSQL || w.d == sqlgen.CockroachDB
+}
+
+func (w *writer) hasEnumColumns() bool {
+	return w.d == sqlgen.MySQL || w.d == sqlgen.MariaDB
+}
+
+// commentOn reports whether the dialect sets comments with COMMENT ON
+func (w *writer) commentOn() bool {
+	return w.d == sqlgen.PostgreSQL || w.d == sqlgen.CockroachDB || w.d == sqlgen.Oracle
+}
+
+// inlineComments reports whether columns and tables carry a COMMENT clause
+func (w *writer) inlineComments() bool {
+	return w.d == sqlgen.MySQL || w.d == sqlgen.MariaDB || w.d == sqlgen.ClickHouse
+}
+
+func (w *writer) schemas() {
+	if w.d != sqlgen.PostgreSQL && w.d != sqlgen.CockroachDB {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, t := range w.snap.Tables {
+		if t.Schema != "" && t.Schema != "public" && !seen[t.Schema] {
+			seen[t.Schema] = true
+			w.printf("CREATE SCHEMA IF NOT EXISTS %s;\n", w.d.Quote(t.Schema))
+		}
+	}
+	if len(seen) > 0 {
+		w.printf("\n")
+	}
+}
+
+func (w *writer) customTypes() {
+	written := false
+	for _, ct := range w.snap.CustomTypes {
+		name := w.d.TableName(ct.Schema, ct.Type)
+		switch {
+		case !w.hasTypes():
+			if ct.Kind != "enum" || !w.hasEnumColumns() {
+				w.printf("-- %s type %s is not supported by %s", nonEmpty(ct.Kind, "custom"), name, w.d)
+				if ct.Kind == "enum" {
+					w.printf("; its columns are checked against the values instead")
+				}
+				w.printf("\n")
+				written = true
+			}
+			continue
+		case ct.Kind == "enum":
+			values := make([]string, 0)
+			for _, v := range ct.ValueList() {
+				values = append(values, literal(v))
+			}
+			w.printf("CREATE TYPE %s AS ENUM (%s);\n", name, strings.Join(values, ", "))
+		case ct.Kind == "composite":
+			fields := make([]string, 0)
+			for _, f := range ct.FieldList() {
+				fields = append(fields, "  "+w.d.Quote(f.Field)+" "+nonEmpty(f.Type, "text"))
+			}
+			w.printf("CREATE TYPE %s AS (\n%s\n);\n", name, strings.Join(fields, ",\n"))
+		default:
+			w.printf("-- %s type %s is not supported\n", nonEmpty(ct.Kind, "custom"), name)
+		}
+		written = true
+	}
+	if written {
+		w.printf("\n")
+	}
+}
+
+func (w *writer) table(t domain.Table, fks []fkGroup) {
+	name := w.d.TableName(t.Schema, t.Name)
+	if t.IsView {
+		w.printf("-- View %s is not exported: the diagram does not store its query\n\n", name)
+		return
+	}
+
+	fields := t.FieldList()
+	pk := make([]string, 0)
+	for _, f := range fields {
+		if f.PrimaryKey {
+			pk = append(pk, w.d.Quote(f.Name))
+		}
+	}
+	// SQLite only auto-increments an INTEGER PRIMARY KEY declared on the column
+	sqliteRowID := w.d == sqlgen.SQLite && len(pk) == 1
+
+	// Without comment syntax, comments are kept as SQL comments above the table
+	if !w.commentOn() && !w.inlineComments() {
+		if t.Comments != "" {
+			w.printf("-- %s\n", oneLine(t.Comments))
+		}
+		for _, f := range fields {
+			if f.Comments != "" {
+				w.printf("-- %s: %s\n", f.Name, oneLine(f.Comments))
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(fields)+1)
+	for _, f := range fields {
+		lines = append(lines, "  "+w.column(f, sqliteRowID && f.PrimaryKey))
+	}
+	if len(pk) > 0 && !(sqliteRowID && pkIncrements(fields)) && w.d != sqlgen.ClickHouse {
+		lines = append(lines, "  PRIMARY KEY ("+strings.Join(pk, ", ")+")")
+	}
+	if w.d == sqlgen.SQLite {
+		for _, g := range fks {
+			if g.child == t.TableID {
+				lines = append(lines, "  "+w.fkClause(g))
+				w.inlined[g.key] = true
+			}
+		}
+	}
+
+	w.printf("CREATE TABLE %s (\n%s\n)", name, strings.Join(lines, ",\n"))
+	if w.d == sqlgen.ClickHouse {
+		order := "tuple()"
+		if len(pk) > 0 {
+			order = "(" + strings.Join(pk, ", ") + ")"
+		}
+		w.printf(" ENGINE = MergeTree ORDER BY %s", order)
+	}
+	if t.Comments != "" && w.inlineComments() {
+		if w.d == sqlgen.ClickHouse {
+			w.printf(" COMMENT %s", literal(t.Comments))
+		} else {
+			w.printf(" COMMENT=%s", literal(t.Comments))
+		}
+	}
+	w.printf(";\n\n")
+}
+
+func pkIncrements(fields []domain.Field) bool {
+	for _, f := range fields {
+		if f.PrimaryKey {
+			return f.Increment
+		}
+	}
+	return false
+}
+
+// column writes one column definition
+func (w *writer) column(f domain.Field, sqliteRowID bool) string {
+	parts := []string{w.d.Quote(f.Name), w.columnType(f)}
+	if sqliteRowID && f.Increment {
+		// INTEGER PRIMARY KEY AUTOINCREMENT must be spelled exactly so
+		parts[1] = "INTEGER"
+		parts = append(parts, "PRIMARY KEY AUTOINCREMENT")
+	} else if f.Increment {
+		switch w.d {
+		case sqlgen.MySQL, sqlgen.MariaDB:
+			parts = append(parts, "AUTO_INCREMENT")
+		case sqlgen.SQLServer:
+			parts = append(parts, "IDENTITY(1,1)")
+		case sqlgen.PostgreSQL, sqlgen.CockroachDB, sqlgen.Oracle, sqlgen.Generic:
+			parts = append(parts, "GENERATED BY DEFAULT AS IDENTITY")
+		}
+	}
+	if !f.Nullable || f.PrimaryKey {
+		parts = append(parts, "NOT NULL")
+	}
+	if f.Default != "" && !f.Increment {
+		parts = append(parts, "DEFAULT "+f.Default)
+	}
+	if f.Unique && !f.PrimaryKey {
+		parts = append(parts, "UNIQUE")
+	}
+	if f.Collation != "" && w.d != sqlgen.ClickHouse {
+		parts = append(parts, "COLLATE "+f.Collation)
+	}
+	if ct, ok := w.enums[strings.ToLower(f.Type)]; ok && !w.hasTypes() && !w.hasEnumColumns() {
+		values := make([]string, 0)
+		for _, v := range ct.ValueList() {
+			values = append(values, literal(v))
+		}
+		parts = append(parts, fmt.Sprintf("CHECK (%s IN (%s))", w.d.Quote(f.Name), strings.Join(values, ", ")))
+	}
+	if f.Comments != "" && w.inlineComments() {
+		parts = append(parts, "COMMENT "+literal(f.Comments))
+	}
+	return strings.Join(parts, " ")
+}
+
+// columnType writes the type with its size arguments; enums become the
+// dialect's closest equivalent
+func (w *writer) columnType(f domain.Field) string {
+	typ := nonEmpty(f.Type, "text")
+	if ct, ok := w.enums[strings.ToLower(typ)]; ok {
+		switch {
+		case w.hasTypes():
+			return w.d.TableName(ct.Schema, ct.Type)
+		case w.hasEnumColumns():
+			values := make([]string, 0)
+			for _, v := range ct.ValueList() {
+				values = append(values, literal(v))
+			}
+			return "ENUM(" + strings.Join(values, ", ") + ")"
+		default:
+			return "VARCHAR(255)"
+		}
+	}
+	if strings.Contains(typ, "(") {
+		return typ
+	}
+
+	// Size arguments go on the element type of an array
+	var array bool
+	f.Type, array = strings.CutSuffix(typ, "[]")
+	typ = f.SQLType()
+	if array {
+		typ += "[]"
+	}
+	return typ
+}
+
+func (w *writer) indexes(t domain.Table) {
+	if t.IsView || w.d == sqlgen.ClickHouse {
+		return
+	}
+	names := make(map[string]string)
+	for _, f := range t.FieldList() {
+		names[f.ID] = f.Name
+	}
+	written := false
+	for _, idx := range t.IndexList() {
+		cols := make([]string, 0, len(idx.FieldIDs))
+		quoted := make([]string, 0, len(idx.FieldIDs))
+		for _, id := range idx.FieldIDs {
+			if n, ok := names[id]; ok {
+				cols = append(cols, n)
+				quoted = append(quoted, w.d.Quote(n))
+			}
+		}
+		if len(cols) == 0 {
+			continue
+		}
+		name := idx.Name
+		if name == "" {
+			prefix := "idx"
+			if idx.Unique {
+				prefix = "uq"
+			}
+			name = prefix + "_" + t.Name + "_" + strings.Join(cols, "_")
+		}
+		unique := ""
+		if idx.Unique {
+			unique = "UNIQUE "
+		}
+		w.printf("CREATE %sINDEX %s ON %s (%s);\n", unique, w.d.Quote(name), w.d.TableName(t.Schema, t.Name), strings.Join(quoted, ", "))
+		written = true
+	}
+	if written {
+		w.printf("\n")
+	}
+}
+
+func (w *writer) comments() {
+	if !w.commentOn() {
+		return
+	}
+	written := false
+	for _, t := range w.snap.Tables {
+		if t.IsView {
+			continue
+		}
+		name := w.d.TableName(t.Schema, t.Name)
+		if t.Comments != "" {
+			w.printf("COMMENT ON TABLE %s IS %s;\n", name, literal(t.Comments))
+			written = true
+		}
+		for _, f := range t.FieldList() {
+			if f.Comments != "" {
+				w.printf("COMMENT ON COLUMN %s.%s IS %s;\n", name, w.d.Quote(f.Name), literal(f.Comments))
+				written = true
+			}
+		}
+	}
+	if written {
+		w.printf("\n")
+	}
+}
+
+// fkGroup is one foreign key constraint; relationships sharing a name and
+// tables form a composite key
+type fkGroup struct {
+	key           string
+	name          string
+	child, parent string
+	cols, refCols []string
+}
+
+func (w *writer) foreignKeys() []fkGroup {
+	groups := make([]fkGroup, 0)
+	byKey := make(map[string]int)
+	for _, r := range w.snap.Relationships {
+		childID, fkID := r.ForeignKey()
+		parentID, refID := r.TargetTableID, r.TargetFieldID
+		if childID == r.TargetTableID && fkID == r.TargetFieldID {
+			parentID, refID = r.SourceTableID, r.SourceFieldID
+		}
+		child, ok1 := w.tables[childID]
+		parent, ok2 := w.tables[parentID]
+		if !ok1 || !ok2 || child.IsView || parent.IsView {
+			continue
+		}
+		col, ok1 := child.FieldByID(fkID)
+		ref, ok2 := parent.FieldByID(refID)
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		key := r.RelationshipID
+		if r.Name != "" {
+			key = r.Name + "\x00" + childID + "\x00" + parentID
+		}
+		i, ok := byKey[key]
+		if !ok {
+			i = len(groups)
+			byKey[key] = i
+			groups = append(groups, fkGroup{key: key, name: r.Name, child: childID, parent: parentID})
+		}
+		groups[i].cols = append(groups[i].cols, col.Name)
+		groups[i].refCols = append(groups[i].refCols, ref.Name)
+	}
+	for i := range groups {
+		if groups[i].name == "" {
+			groups[i].name = "fk_" + w.tables[groups[i].child].Name + "_" + strings.Join(groups[i].cols, "_")
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return w.tables[groups[i].child].Order < w.tables[groups[j].child].Order
+	})
+	return groups
+}
+
+func (w *writer) fkClause(g fkGroup) string {
+	quote := func(names []string) string {
+		out := make([]string, 0, len(names))
+		for _, n := range names {
+			out = append(out, w.d.Quote(n))
+		}
+		return strings.Join(out, ", ")
+	}
+	parent := w.tables[g.parent]
+	return fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		w.d.Quote(g.name), quote(g.cols), w.d.TableName(parent.Schema, parent.Name), quote(g.refCols))
+}
+
+func (w *writer) constraints(fks []fkGroup) {
+	for _, g := range fks {
+		if w.inlined[g.key] {
+			continue
+		}
+		child := w.tables[g.child]
+		if w.d == sqlgen.ClickHouse {
+			w.printf("-- %s: ClickHouse has no foreign keys\n", oneLine(w.fkClause(g)))
+			continue
+		}
+		w.printf("ALTER TABLE %s ADD %s;\n", w.d.TableName(child.Schema, child.Name), w.fkClause(g))
+	}
+}
+
+// literal quotes a string for SQL
+func literal(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// oneLine keeps a comment on a single line
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func nonEmpty(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+type AreaHandler struct {
+	AreaUsecase domain.AreaUsecase
+}
+
+func NewAreaHandler(app *fiber.App, uc domain.AreaUsecase) {
+	handler := &AreaHandler{AreaUsecase: uc}
+	api := app.Group("/api")
+	api.Get("/diagrams/:id/areas", handler.FetchByDiagram)
+	api.Get("/diagrams/:id/areas/:areaId/tables", handler.FetchTables)
+	api.Post("/diagrams/:id/areas/cluster", handler.Cluster)
+}
+
+// FetchByDiagram lists a diagram's areas with their member table IDs
+func (h *AreaHandler) FetchByDiagram(c *fiber.Ctx) error {
+	areas, err := h.AreaUsecase.GetByDiagram(c.Context(), c.Params("id"))
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(areas)
+}
+
+// FetchTables returns the tables that belong to an area
+func (h *AreaHandler) FetchTables(c *fiber.Ctx) error {
+	tables, err := h.AreaUsecase.GetTables(c.Context(), c.Params("id"), c.Params("areaId"))
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(tables)
+}
+
+// Cluster replaces the diagram's areas with areas generated by schema or
+// relationship community; ?preview=true returns them without saving
+func (h *AreaHandler) Cluster(c *fiber.Ctx) error {
+	req := domain.ClusterRequest{
+		DiagramID: c.Params("id"),
+		By:        c.Query("by"),
+		MinSize:   c.QueryInt("min_size"),
+		Arrange:   c.QueryBool("arrange", true),
+		Preview:   c.QueryBool("preview"),
+	}
+
+	res, err := h.AreaUsecase.Cluster(c.Context(), req)
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(res)
+}
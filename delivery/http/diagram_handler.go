@@ -1,7 +1,9 @@
 package http
 
 import (
+	"errors"
 	"log"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/iots1/vertex-diagram/domain"
@@ -20,23 +22,98 @@ func NewDiagramHandler(app *fiber.App, us domain.DiagramUsecase) {
 	api.Get("/diagrams", handler.Fetch)
 	api.Get("/diagrams/:id", handler.GetByID)
 	api.Post("/diagrams", handler.Save)
+	api.Post("/diagrams/:id/clone", handler.Clone)
+	api.Post("/diagrams/:id/merge", handler.Merge)
+	api.Post("/diagrams/import/:format", handler.Import)
+	api.Get("/diagrams/:id/export/:format", handler.Export)
+	api.Get("/diagrams/:id/codegen/:language", handler.Codegen)
+	api.Post("/diagrams/:id/comments/:format", handler.ImportComments)
 	api.Delete("/diagrams/:id", handler.Delete)
+	api.Get("/backup", handler.Backup)
+	api.Get("/diagrams/:id/backup", handler.Backup)
+	api.Post("/restore", handler.Restore)
+	api.Get("/trash", handler.FetchTrash)
+	api.Post("/trash/:id/restore", handler.Undelete)
+	api.Get("/diagrams/:id/lint", handler.Lint)
+	api.Get("/diagrams/:id/diff/:otherId", handler.Diff)
 }
 
+// Delete moves the diagram to the trash
 func (h *DiagramHandler) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if err := h.AUsecase.Delete(c.Context(), id); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.SendStatus(204)
 }
 
+// FetchTrash lists deleted diagrams; it takes the same query parameters as
+// Fetch and also accepts sort=deleted_at, the default
+func (h *DiagramHandler) FetchTrash(c *fiber.Ctx) error {
+	q := domain.DiagramListQuery{
+		Cursor:     c.Query("cursor"),
+		Limit:      c.QueryInt("limit"),
+		Sort:       c.Query("sort"),
+		Search:     c.Query("search"),
+		SearchMode: c.Query("match"),
+		Order:      c.Query("order"),
+	}
+
+	page, err := h.AUsecase.GetTrash(c.Context(), q)
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(page)
+}
+
+// Undelete restores a diagram from the trash
+func (h *DiagramHandler) Undelete(c *fiber.Ctx) error {
+	id := c.Params("id")
+	d, err := h.AUsecase.Undelete(c.Context(), id)
+	if err != nil {
+		log.Printf("❌ Error restoring diagram %s from the trash: %v", id, err)
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(d)
+}
+
+// Lint reports schema design problems in the diagram
+func (h *DiagramHandler) Lint(c *fiber.Ctx) error {
+	id := c.Params("id")
+	report, err := h.AUsecase.Lint(c.Context(), id)
+	if err != nil {
+		log.Printf("❌ Error linting diagram %s: %v", id, err)
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
+
+// Diff lists the changes from :id to :otherId
+func (h *DiagramHandler) Diff(c *fiber.Ctx) error {
+	id, otherID := c.Params("id"), c.Params("otherId")
+	d, err := h.AUsecase.Diff(c.Context(), id, otherID)
+	if err != nil {
+		log.Printf("❌ Error comparing diagrams %s and %s: %v", id, otherID, err)
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(d)
+}
+
 func (h *DiagramHandler) Fetch(c *fiber.Ctx) error {
-	list, err := h.AUsecase.GetAll(c.Context())
+	q := domain.DiagramListQuery{
+		Cursor:     c.Query("cursor"),
+		Limit:      c.QueryInt("limit"),
+		Sort:       c.Query("sort"),
+		Search:     c.Query("search"),
+		SearchMode: c.Query("match"),
+		Order:      c.Query("order"),
+	}
+
+	page, err := h.AUsecase.GetAll(c.Context(), q)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
 	}
-	return c.JSON(list)
+	return c.JSON(page)
 }
 
 func (h *DiagramHandler) GetByID(c *fiber.Ctx) error {
@@ -61,9 +138,182 @@ func (h *DiagramHandler) Save(c *fiber.Ctx) error {
 	result, err := h.AUsecase.Save(c.Context(), &d)
 	if err != nil {
 		log.Printf("❌ Error saving diagram: %v", err)
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to save diagram: " + err.Error()})
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": "Failed to save diagram: " + err.Error()})
 	}
 
 	log.Printf("✅ Diagram saved successfully: ID=%s", result.ID)
 	return c.JSON(result)
-}
\ No newline at end of file
+}
+
+func (h *DiagramHandler) Clone(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var req struct {
+		Name string `json:"name"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid request body: " + err.Error()})
+		}
+	}
+
+	clone, err := h.AUsecase.Clone(c.Context(), id, req.Name)
+	if err != nil {
+		log.Printf("❌ Error cloning diagram %s: %v", id, err)
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(clone)
+}
+
+// Merge merges the diagram given in the body (source_id) into :id
+func (h *DiagramHandler) Merge(c *fiber.Ctx) error {
+	var req domain.MergeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body: " + err.Error()})
+	}
+	req.TargetID = c.Params("id")
+
+	report, err := h.AUsecase.Merge(c.Context(), req)
+	if err != nil {
+		log.Printf("❌ Error merging diagram %s into %s: %v", req.SourceID, req.TargetID, err)
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
+
+// Import creates a diagram from the raw request body, e.g. a DBML document
+func (h *DiagramHandler) Import(c *fiber.Ctx) error {
+	req := domain.ImportRequest{
+		Format: c.Params("format"),
+		Name:   c.Query("name"),
+		Data:   c.Body(),
+	}
+	if len(req.Data) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Request body is empty"})
+	}
+
+	result, err := h.AUsecase.Import(c.Context(), req)
+	if err != nil {
+		log.Printf("❌ Error importing %s diagram: %v", req.Format, err)
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(result)
+}
+
+// Export returns the diagram as a document; query parameters are passed on as options
+// (filter=true, area=, schema= scope every format; download=true sets an attachment name)
+func (h *DiagramHandler) Export(c *fiber.Ctx) error {
+	req := domain.ExportRequest{
+		DiagramID: c.Params("id"),
+		Format:    c.Params("format"),
+		Options:   c.Queries(),
+	}
+
+	result, err := h.AUsecase.Export(c.Context(), req)
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	if c.QueryBool("download") {
+		c.Attachment(result.FileName)
+	}
+	c.Set(fiber.HeaderContentType, result.ContentType)
+	return c.Send(result.Data)
+}
+
+// Codegen returns source code generated from the diagram's tables, e.g.
+// /codegen/go?style=gorm; like Export, query parameters are passed on as options
+func (h *DiagramHandler) Codegen(c *fiber.Ctx) error {
+	req := domain.ExportRequest{
+		DiagramID: c.Params("id"),
+		Format:    c.Params("language"),
+		Options:   c.Queries(),
+	}
+
+	result, err := h.AUsecase.Export(c.Context(), req)
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	if c.QueryBool("download") {
+		c.Attachment(result.FileName)
+	}
+	c.Set(fiber.HeaderContentType, result.ContentType)
+	return c.Send(result.Data)
+}
+
+// ImportComments applies the comments of an edited CSV or XLSX field sheet,
+// as exported by /export/csv or /export/xlsx; dry_run=true only reports
+func (h *DiagramHandler) ImportComments(c *fiber.Ctx) error {
+	req := domain.CommentImportRequest{
+		DiagramID: c.Params("id"),
+		Format:    c.Params("format"),
+		Data:      c.Body(),
+		DryRun:    c.QueryBool("dry_run"),
+	}
+	if len(req.Data) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Request body is empty"})
+	}
+
+	result, err := h.AUsecase.ImportComments(c.Context(), req)
+	if err != nil {
+		log.Printf("❌ Error importing comments into diagram %s: %v", req.DiagramID, err)
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(result)
+}
+
+// getStatusCode maps domain errors to HTTP status codes
+func getStatusCode(err error) int {
+	switch {
+	case err == nil:
+		return 200
+	case errors.Is(err, domain.ErrBadParamInput):
+		return 400
+	case errors.Is(err, domain.ErrNotFound):
+		return 404
+	case errors.Is(err, domain.ErrConflict):
+		return 409
+	default:
+		return 500
+	}
+}
+
+// Backup downloads a backup archive of :id, of the comma separated ids query
+// parameter, or of every diagram
+func (h *DiagramHandler) Backup(c *fiber.Ctx) error {
+	var ids []string
+	if id := c.Params("id"); id != "" {
+		ids = append(ids, id)
+	}
+	for _, id := range strings.Split(c.Query("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	result, err := h.AUsecase.Backup(c.Context(), ids)
+	if err != nil {
+		log.Printf("❌ Error backing up diagrams: %v", err)
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	c.Attachment(result.FileName)
+	c.Set(fiber.HeaderContentType, result.ContentType)
+	return c.Send(result.Data)
+}
+
+// Restore writes the diagrams of a backup archive sent as the request body;
+// mode=keep|regenerate|skip decides what happens to their IDs
+func (h *DiagramHandler) Restore(c *fiber.Ctx) error {
+	req := domain.RestoreRequest{
+		Data: c.Body(),
+		Mode: c.Query("mode"),
+	}
+	if len(req.Data) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Request body is empty"})
+	}
+
+	result, err := h.AUsecase.Restore(c.Context(), req)
+	if err != nil {
+		log.Printf("❌ Error restoring backup: %v", err)
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(result)
+}
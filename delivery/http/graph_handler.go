@@ -0,0 +1,90 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+type GraphHandler struct {
+	GraphUsecase domain.GraphUsecase
+}
+
+func NewGraphHandler(app *fiber.App, uc domain.GraphUsecase) {
+	handler := &GraphHandler{GraphUsecase: uc}
+	api := app.Group("/api")
+	api.Get("/diagrams/:id/graph/tables/:tableId/impact", handler.Impact)
+	api.Get("/diagrams/:id/graph/paths", handler.JoinPaths)
+	api.Get("/diagrams/:id/graph/join-query", handler.JoinQuery)
+	api.Get("/diagrams/:id/graph/cycles", handler.Cycles)
+	api.Get("/diagrams/:id/graph/orphans", handler.Orphans)
+}
+
+// Impact returns the transitive closure of a table; ?direction=upstream|downstream (default downstream)
+func (h *GraphHandler) Impact(c *fiber.Ctx) error {
+	res, err := h.GraphUsecase.Impact(c.Context(), c.Params("id"), c.Params("tableId"), c.Query("direction"))
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(res)
+}
+
+// JoinPaths returns the shortest join paths; ?from=&to=&limit=
+func (h *GraphHandler) JoinPaths(c *fiber.Ctx) error {
+	from, to := c.Query("from"), c.Query("to")
+	if from == "" || to == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "from and to are required"})
+	}
+
+	res, err := h.GraphUsecase.JoinPaths(c.Context(), c.Params("id"), from, to, c.QueryInt("limit"))
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(res)
+}
+
+// JoinQuery generates a SELECT joining ?from= to ?to=, passing through the
+// comma-separated ?via= tables in order; ?dialect= and ?join=inner|left are optional
+func (h *GraphHandler) JoinQuery(c *fiber.Ctx) error {
+	from, to := c.Query("from"), c.Query("to")
+	if from == "" || to == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "from and to are required"})
+	}
+
+	tableIDs := []string{from}
+	for _, id := range strings.Split(c.Query("via"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			tableIDs = append(tableIDs, id)
+		}
+	}
+	tableIDs = append(tableIDs, to)
+
+	req := domain.JoinQueryRequest{
+		DiagramID: c.Params("id"),
+		TableIDs:  tableIDs,
+		Dialect:   c.Query("dialect"),
+		JoinType:  c.Query("join"),
+	}
+	res, err := h.GraphUsecase.JoinQuery(c.Context(), req)
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(res)
+}
+
+func (h *GraphHandler) Cycles(c *fiber.Ctx) error {
+	res, err := h.GraphUsecase.Cycles(c.Context(), c.Params("id"))
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(res)
+}
+
+func (h *GraphHandler) Orphans(c *fiber.Ctx) error {
+	res, err := h.GraphUsecase.Orphans(c.Context(), c.Params("id"))
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(res)
+}
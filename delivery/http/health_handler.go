@@ -0,0 +1,97 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iots1/vertex-diagram/metrics"
+)
+
+// ReadinessCheck is one dependency /readyz waits for; Check returns nil once
+// the dependency can serve requests
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	Checks  []ReadinessCheck
+	Timeout time.Duration
+}
+
+// NewHealthHandler registers the probe and metrics endpoints at the root,
+// outside /api, where Kubernetes and Prometheus expect them
+func NewHealthHandler(app *fiber.App, checks ...ReadinessCheck) {
+	handler := &HealthHandler{Checks: checks, Timeout: 2 * time.Second}
+	app.Get("/healthz", handler.Healthz)
+	app.Get("/readyz", handler.Readyz)
+	app.Get("/metrics", handler.Metrics)
+}
+
+// Healthz answers as long as the process can serve HTTP
+func (h *HealthHandler) Healthz(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// Readyz runs every readiness check and answers 503 when one fails
+func (h *HealthHandler) Readyz(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.Context(), h.Timeout)
+	defer cancel()
+
+	status := fiber.StatusOK
+	checks := fiber.Map{}
+	for _, check := range h.Checks {
+		if err := check.Check(ctx); err != nil {
+			status = fiber.StatusServiceUnavailable
+			checks[check.Name] = err.Error()
+			continue
+		}
+		checks[check.Name] = "ok"
+	}
+
+	res := fiber.Map{"status": "ok", "checks": checks}
+	if status != fiber.StatusOK {
+		res["status"] = "unavailable"
+	}
+	return c.Status(status).JSON(res)
+}
+
+// Metrics writes the server's metrics in the Prometheus text format
+func (h *HealthHandler) Metrics(c *fiber.Ctx) error {
+	var buf bytes.Buffer
+	if err := metrics.WriteTo(&buf); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+	return c.Send(buf.Bytes())
+}
+
+// RequestMetrics is middleware counting requests and timing them by route
+// pattern, so /api/diagrams/:id is one series however many IDs are requested.
+// Requests matching no route are labelled "unmatched".
+func RequestMetrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		self := c.Route()
+
+		// Errors are turned into responses here rather than by the app, so
+		// the status they end up with is the one recorded
+		if err := c.Next(); err != nil {
+			if err := c.App().Config().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := c.Route().Path
+		if c.Route() == self {
+			route = "unmatched"
+		}
+		method := c.Method()
+		metrics.HTTPRequests.Inc(method, route, strconv.Itoa(c.Response().StatusCode()))
+		metrics.Since(metrics.HTTPDuration, start, method, route)
+		return nil
+	}
+}
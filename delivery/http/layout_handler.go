@@ -0,0 +1,31 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+type LayoutHandler struct {
+	LayoutUsecase domain.LayoutUsecase
+}
+
+func NewLayoutHandler(app *fiber.App, uc domain.LayoutUsecase) {
+	handler := &LayoutHandler{LayoutUsecase: uc}
+	api := app.Group("/api")
+	api.Post("/diagrams/:id/layout", handler.Layout)
+}
+
+// Layout computes table positions; ?preview=true returns them without saving
+func (h *LayoutHandler) Layout(c *fiber.Ctx) error {
+	req := domain.LayoutRequest{
+		DiagramID: c.Params("id"),
+		Algorithm: c.Query("algorithm"),
+		Preview:   c.QueryBool("preview"),
+	}
+
+	res, err := h.LayoutUsecase.Layout(c.Context(), req)
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(res)
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+type SearchHandler struct {
+	SearchUsecase domain.SearchUsecase
+}
+
+func NewSearchHandler(app *fiber.App, uc domain.SearchUsecase) {
+	handler := &SearchHandler{SearchUsecase: uc}
+	api := app.Group("/api")
+	api.Get("/search", handler.Search)
+}
+
+func (h *SearchHandler) Search(c *fiber.Ctx) error {
+	q := domain.SearchQuery{
+		Text:      c.Query("q"),
+		Schema:    c.Query("schema"),
+		DiagramID: c.Query("diagram_id"),
+		Limit:     c.QueryInt("limit"),
+	}
+
+	hits, err := h.SearchUsecase.Search(c.Context(), q)
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"query": q.Text, "hits": hits})
+}
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+type StatsHandler struct {
+	StatsUsecase domain.StatsUsecase
+}
+
+func NewStatsHandler(app *fiber.App, uc domain.StatsUsecase) {
+	handler := &StatsHandler{StatsUsecase: uc}
+	api := app.Group("/api")
+	api.Get("/stats", handler.Workspace)
+	api.Get("/diagrams/:id/stats", handler.Diagram)
+}
+
+// Diagram returns size and complexity metrics of one diagram
+func (h *StatsHandler) Diagram(c *fiber.Ctx) error {
+	stats, err := h.StatsUsecase.Diagram(c.Context(), c.Params("id"))
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(stats)
+}
+
+// Workspace returns totals across all diagrams
+func (h *StatsHandler) Workspace(c *fiber.Ctx) error {
+	stats, err := h.StatsUsecase.Workspace(c.Context())
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(stats)
+}
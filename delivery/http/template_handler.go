@@ -0,0 +1,98 @@
+package http
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+type TemplateHandler struct {
+	TemplateUsecase domain.TemplateUsecase
+}
+
+func NewTemplateHandler(app *fiber.App, uc domain.TemplateUsecase) {
+	handler := &TemplateHandler{TemplateUsecase: uc}
+	api := app.Group("/api")
+	api.Get("/templates", handler.Fetch)
+	api.Get("/templates/:id", handler.GetByID)
+	api.Post("/templates", handler.Create)
+	api.Post("/templates/from-diagram", handler.CreateFromDiagram)
+	api.Put("/templates/:id", handler.Update)
+	api.Delete("/templates/:id", handler.Delete)
+	api.Post("/templates/:id/instantiate", handler.Instantiate)
+}
+
+func (h *TemplateHandler) Fetch(c *fiber.Ctx) error {
+	list, err := h.TemplateUsecase.GetAll(c.Context())
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(list)
+}
+
+func (h *TemplateHandler) GetByID(c *fiber.Ctx) error {
+	t, err := h.TemplateUsecase.GetOne(c.Context(), c.Params("id"))
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(t)
+}
+
+func (h *TemplateHandler) Create(c *fiber.Ctx) error {
+	t := new(domain.Template)
+	if err := c.BodyParser(t); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body: " + err.Error()})
+	}
+	res, err := h.TemplateUsecase.Create(c.Context(), t)
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(res)
+}
+
+func (h *TemplateHandler) CreateFromDiagram(c *fiber.Ctx) error {
+	var req domain.TemplateFromDiagramRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body: " + err.Error()})
+	}
+	res, err := h.TemplateUsecase.CreateFromDiagram(c.Context(), req)
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(res)
+}
+
+func (h *TemplateHandler) Update(c *fiber.Ctx) error {
+	t := new(domain.Template)
+	if err := c.BodyParser(t); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body: " + err.Error()})
+	}
+	t.ID = c.Params("id")
+	res, err := h.TemplateUsecase.Update(c.Context(), t)
+	if err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(res)
+}
+
+func (h *TemplateHandler) Delete(c *fiber.Ctx) error {
+	if err := h.TemplateUsecase.Delete(c.Context(), c.Params("id")); err != nil {
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(204)
+}
+
+func (h *TemplateHandler) Instantiate(c *fiber.Ctx) error {
+	var req domain.InstantiateTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body: " + err.Error()})
+	}
+	id := c.Params("id")
+	res, err := h.TemplateUsecase.Instantiate(c.Context(), id, req)
+	if err != nil {
+		log.Printf("❌ Error instantiating template %s into diagram %s: %v", id, req.DiagramID, err)
+		return c.Status(getStatusCode(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(res)
+}
@@ -0,0 +1,353 @@
+// Package dictionary writes data dictionaries: reference documents listing
+// every table with its fields, indexes and relationships, the enum types and
+// the notes of a diagram.
+//
+// Tables are grouped by schema, then by the area holding them. A note is
+// attached to the table it overlaps most on the canvas; notes off any table
+// are listed at the end with the area they lie in.
+package dictionary
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/sqlgen"
+)
+
+// Display names of the database types
+var databaseNames = map[sqlgen.Dialect]string{
+	sqlgen.PostgreSQL:  "PostgreSQL",
+	sqlgen.MySQL:       "MySQL",
+	sqlgen.MariaDB:     "MariaDB",
+	sqlgen.SQLServer:   "SQL Server",
+	sqlgen.SQLite:      "SQLite",
+	sqlgen.Oracle:      "Oracle",
+	sqlgen.CockroachDB: "CockroachDB",
+	sqlgen.ClickHouse:  "ClickHouse",
+}
+
+// document is a snapshot arranged for writing
+type document struct {
+	Title    string
+	Database string
+	Updated  time.Time
+	Schemas  []*schemaGroup
+	Enums    []*enum
+	Notes    []note // Notes not attached to a table
+}
+
+type schemaGroup struct {
+	Name   string // Empty for tables without a schema
+	Anchor string
+	Areas  []*areaGroup
+}
+
+type areaGroup struct {
+	Name   string // Empty for tables outside every area
+	Tables []*entry
+}
+
+type entry struct {
+	Anchor   string
+	Name     string // Qualified with the schema
+	View     bool
+	Comment  string
+	Fields   []field
+	Indexes  []index
+	Outgoing []link // Foreign keys held by this table
+	Incoming []link // Foreign keys of other tables referencing this one
+	Notes    []string
+}
+
+type field struct {
+	Name       string
+	Type       string
+	TypeAnchor string // Set when the type is an enum of the diagram
+	Key        string // PK, FK, UQ, comma separated
+	Nullable   bool
+	Default    string
+	Comment    string
+}
+
+type index struct {
+	Name    string
+	Columns string
+	Unique  bool
+}
+
+// link is one relationship seen from one of its tables: Columns are local,
+// References belong to Table
+type link struct {
+	Name        string
+	Columns     string
+	Table       *entry
+	References  string
+	Cardinality string // e.g. many-to-one, read from the local side
+}
+
+type enum struct {
+	Anchor string
+	Name   string
+	Values []string
+}
+
+type note struct {
+	Area    string
+	Content string
+}
+
+// build arranges a snapshot into a document
+func build(snap *domain.DiagramSnapshot) *document {
+	doc := &document{Title: "Data dictionary"}
+	if d := snap.Diagram; d != nil {
+		if d.Name != "" {
+			doc.Title = d.Name
+		}
+		doc.Updated = d.UpdatedAt
+		if db, ok := d.Content["databaseType"].(string); ok {
+			if dialect, ok := sqlgen.ParseDialect(db); ok {
+				doc.Database = databaseNames[dialect]
+			}
+		}
+	}
+	// Every fragment identifier in the document, including the fixed sections
+	anchors := map[string]bool{"enums": true, "notes": true}
+
+	enums := make(map[string]*enum) // Lower-case type name -> enum
+	for _, ct := range snap.CustomTypes {
+		if ct.Kind != "enum" {
+			continue
+		}
+		e := &enum{Name: domain.QualifiedName(ct.Schema, ct.Type), Values: ct.ValueList()}
+		e.Anchor = anchor(anchors, "enum-"+e.Name)
+		enums[strings.ToLower(ct.Type)] = e
+		enums[strings.ToLower(e.Name)] = e
+		doc.Enums = append(doc.Enums, e)
+	}
+
+	entries := make(map[string]*entry, len(snap.Tables))
+	for _, t := range snap.Tables {
+		e := &entry{Name: domain.QualifiedName(t.Schema, t.Name), View: t.IsView, Comment: t.Comments}
+		e.Anchor = anchor(anchors, "table-"+e.Name)
+		entries[t.TableID] = e
+	}
+	for _, t := range snap.Tables {
+		e := entries[t.TableID]
+		keys := make(map[string][]string)
+		for _, f := range t.FieldList() {
+			if f.PrimaryKey {
+				keys[f.ID] = append(keys[f.ID], "PK")
+			} else if f.Unique {
+				keys[f.ID] = append(keys[f.ID], "UQ")
+			}
+		}
+		for _, r := range snap.Relationships {
+			if childID, fkID := r.ForeignKey(); childID == t.TableID && !isManyToMany(r) {
+				keys[fkID] = append(keys[fkID], "FK")
+			}
+		}
+		for _, f := range t.FieldList() {
+			fd := field{
+				Name:     f.Name,
+				Type:     f.SQLType(),
+				Key:      strings.Join(keys[f.ID], ", "),
+				Nullable: f.Nullable,
+				Default:  f.Default,
+				Comment:  f.Comments,
+			}
+			if en := enums[strings.ToLower(strings.TrimSuffix(f.Type, "[]"))]; en != nil {
+				fd.TypeAnchor = en.Anchor
+			}
+			e.Fields = append(e.Fields, fd)
+		}
+		for _, idx := range t.IndexList() {
+			names := make([]string, 0, len(idx.FieldIDs))
+			for _, id := range idx.FieldIDs {
+				if f, ok := t.FieldByID(id); ok {
+					names = append(names, f.Name)
+				}
+			}
+			e.Indexes = append(e.Indexes, index{Name: idx.Name, Columns: strings.Join(names, ", "), Unique: idx.Unique})
+		}
+	}
+
+	tables := make(map[string]domain.Table, len(snap.Tables))
+	for _, t := range snap.Tables {
+		tables[t.TableID] = t
+	}
+	for _, r := range snap.Relationships {
+		childID, fkID := r.ForeignKey()
+		parentID, refID := r.TargetTableID, r.TargetFieldID
+		childMany, parentMany := r.SourceCardinality == domain.CardinalityMany, r.TargetCardinality == domain.CardinalityMany
+		if childID == r.TargetTableID && fkID == r.TargetFieldID {
+			parentID, refID = r.SourceTableID, r.SourceFieldID
+			childMany, parentMany = parentMany, childMany
+		}
+		child, parent := entries[childID], entries[parentID]
+		if child == nil || parent == nil {
+			continue
+		}
+		fk, ref := fieldName(tables[childID], fkID), fieldName(tables[parentID], refID)
+		child.Outgoing = append(child.Outgoing, link{Name: r.Name, Columns: fk, Table: parent, References: ref, Cardinality: cardinality(childMany, parentMany)})
+		parent.Incoming = append(parent.Incoming, link{Name: r.Name, Columns: ref, Table: child, References: fk, Cardinality: cardinality(parentMany, childMany)})
+	}
+
+	doc.group(snap, entries, anchors)
+	doc.attachNotes(snap, entries)
+	return doc
+}
+
+// group sorts tables by schema, then by area in diagram order, with the
+// tables outside every area last
+func (doc *document) group(snap *domain.DiagramSnapshot, entries map[string]*entry, anchors map[string]bool) {
+	schemas := make(map[string]*schemaGroup)
+	for _, t := range snap.Tables {
+		sg := schemas[t.Schema]
+		if sg == nil {
+			sg = &schemaGroup{Name: t.Schema}
+			schemas[t.Schema] = sg
+			doc.Schemas = append(doc.Schemas, sg)
+		}
+		area := ""
+		for _, a := range snap.Areas {
+			if a.Includes(t) {
+				area = a.Name
+				break
+			}
+		}
+		var ag *areaGroup
+		for _, g := range sg.Areas {
+			if g.Name == area {
+				ag = g
+			}
+		}
+		if ag == nil {
+			ag = &areaGroup{Name: area}
+			sg.Areas = append(sg.Areas, ag)
+		}
+		ag.Tables = append(ag.Tables, entries[t.TableID])
+	}
+
+	sort.SliceStable(doc.Schemas, func(i, j int) bool { return doc.Schemas[i].Name < doc.Schemas[j].Name })
+	for _, sg := range doc.Schemas {
+		sg.Anchor = anchor(anchors, sg.Label())
+		order := make(map[string]int, len(snap.Areas))
+		for i, a := range snap.Areas {
+			if _, ok := order[a.Name]; !ok {
+				order[a.Name] = i
+			}
+		}
+		sort.SliceStable(sg.Areas, func(i, j int) bool {
+			a, b := sg.Areas[i], sg.Areas[j]
+			if (a.Name == "") != (b.Name == "") {
+				return b.Name == ""
+			}
+			return order[a.Name] < order[b.Name]
+		})
+	}
+}
+
+// attachNotes gives each note to the table it overlaps most, or keeps it
+// for the end of the document with the area it lies in
+func (doc *document) attachNotes(snap *domain.DiagramSnapshot, entries map[string]*entry) {
+	for _, n := range snap.Notes {
+		best, overlap := "", 0
+		for _, t := range snap.Tables {
+			w, h := t.Size()
+			ox := min(n.X+n.Width, t.X+w) - max(n.X, t.X)
+			oy := min(n.Y+n.Height, t.Y+h) - max(n.Y, t.Y)
+			if ox > 0 && oy > 0 && ox*oy > overlap {
+				best, overlap = t.TableID, ox*oy
+			}
+		}
+		if best != "" {
+			entries[best].Notes = append(entries[best].Notes, n.Content)
+			continue
+		}
+		area := ""
+		cx, cy := n.X+n.Width/2, n.Y+n.Height/2
+		for _, a := range snap.Areas {
+			if cx >= a.X && cx <= a.X+a.Width && cy >= a.Y && cy <= a.Y+a.Height {
+				area = a.Name
+				break
+			}
+		}
+		doc.Notes = append(doc.Notes, note{Area: area, Content: n.Content})
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+// Meta describes the database and the time of the last change
+func (doc *document) Meta() string {
+	parts := make([]string, 0, 2)
+	if doc.Database != "" {
+		parts = append(parts, "Database: "+doc.Database)
+	}
+	if !doc.Updated.IsZero() {
+		parts = append(parts, "Last updated: "+doc.Updated.UTC().Format("2006-01-02 15:04 MST"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (sg *schemaGroup) Label() string {
+	if sg.Name == "" {
+		return "Default schema"
+	}
+	return "Schema " + sg.Name
+}
+
+func (ag *areaGroup) Label() string {
+	if ag.Name == "" {
+		return "Tables outside areas"
+	}
+	return "Area " + ag.Name
+}
+
+// Describe gives the cardinality and the relationship name
+func (l link) Describe() string {
+	if l.Name == "" {
+		return l.Cardinality
+	}
+	return l.Cardinality + ", " + l.Name
+}
+
+func isManyToMany(r domain.Relationship) bool {
+	return r.SourceCardinality == domain.CardinalityMany && r.TargetCardinality == domain.CardinalityMany
+}
+
+// cardinality names a relationship from the side whose multiplicity is local
+func cardinality(local, remote bool) string {
+	name := map[bool]string{false: "one", true: "many"}
+	return name[local] + "-to-" + name[remote]
+}
+
+func fieldName(t domain.Table, id string) string {
+	if f, ok := t.FieldByID(id); ok {
+		return f.Name
+	}
+	return "?"
+}
+
+var anchorUnsafe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// anchor returns a fragment identifier derived from name that is not taken
+// yet, numbering it when it is, and marks it taken
+func anchor(taken map[string]bool, name string) string {
+	base := strings.Trim(anchorUnsafe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	id := base
+	for n := 2; taken[id]; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	taken[id] = true
+	return id
+}
@@ -0,0 +1,50 @@
+package dictionary
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+func TestAnchor(t *testing.T) {
+	taken := map[string]bool{"enums": true}
+	var got []string
+	for _, name := range []string{"x", "X", "x-2", "enums", "Order Items"} {
+		got = append(got, anchor(taken, name))
+	}
+	want := []string{"x", "x-2", "x-2-2", "enums-2", "order-items"}
+	if !slices.Equal(got, want) {
+		t.Errorf("anchors = %q, want %q", got, want)
+	}
+}
+
+func TestBuildAnchorsUnique(t *testing.T) {
+	snap := &domain.DiagramSnapshot{
+		Tables: []domain.Table{
+			{TableID: "t1", Schema: "table", Name: "notes"},
+			{TableID: "t2", Name: "table-notes"},
+			{TableID: "t3", Schema: "enums", Name: "x"},
+		},
+		CustomTypes: []domain.CustomType{{Type: "notes", Kind: "enum", Values: []interface{}{"a"}}},
+	}
+	doc := build(snap)
+	seen := map[string]bool{"enums": true, "notes": true}
+	check := func(id string) {
+		if seen[id] {
+			t.Errorf("anchor %q is used twice", id)
+		}
+		seen[id] = true
+	}
+	for _, e := range doc.Enums {
+		check(e.Anchor)
+	}
+	for _, sg := range doc.Schemas {
+		check(sg.Anchor)
+		for _, ag := range sg.Areas {
+			for _, e := range ag.Tables {
+				check(e.Anchor)
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package dictionary
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// HTML implements domain.Exporter, writing the dictionary as one
+// self-contained page with inline styles and links between tables
+type HTML struct{}
+
+func (HTML) ContentType() string { return "text/html; charset=utf-8" }
+
+func (HTML) Extension() string { return "html" }
+
+func (HTML) Export(snap *domain.DiagramSnapshot, _ map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, build(snap)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var page = template.Must(template.New("dictionary").Funcs(template.FuncMap{
+	"yesNo": yesNo,
+	"lines": func(s string) []string { return strings.Split(strings.TrimRight(s, "\n"), "\n") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} – Data dictionary</title>
+<style>
+body { font: 14px/1.5 -apple-system, "Segoe UI", Roboto, Helvetica, Arial, sans-serif; color: #1f2328; margin: 0; }
+main { max-width: 1100px; margin: 0 auto; padding: 24px 32px 64px; }
+h1 { margin-bottom: 4px; }
+h2 { border-bottom: 1px solid #d0d7de; padding-bottom: 6px; margin-top: 40px; }
+h3 { color: #57606a; margin-top: 28px; }
+h4 { margin: 28px 0 8px; font-size: 16px; }
+.meta { color: #57606a; margin-top: 0; }
+.toc ul { list-style: none; padding-left: 18px; margin: 2px 0; }
+.toc > ul { padding-left: 0; }
+table { border-collapse: collapse; width: 100%; margin: 8px 0 12px; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+code { font: 12px ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; background: #f6f8fa; padding: 1px 4px; border-radius: 4px; }
+.key { font-weight: 600; white-space: nowrap; }
+.label { font-weight: 600; margin: 12px 0 4px; }
+.tag { font-size: 11px; color: #57606a; border: 1px solid #d0d7de; border-radius: 8px; padding: 0 6px; margin-left: 6px; font-weight: normal; }
+blockquote { margin: 8px 0; padding: 4px 12px; border-left: 4px solid #d0d7de; color: #57606a; background: #fffbe6; }
+:target { scroll-margin-top: 12px; }
+h4:target { background: #fff8c5; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Title}}</h1>
+{{with .Meta}}<p class="meta">{{.}}</p>{{end}}
+
+<nav class="toc">
+<h2>Contents</h2>
+<ul>
+{{- range .Schemas}}
+<li><a href="#{{.Anchor}}">{{.Label}}</a>
+<ul>
+{{- range .Areas}}
+<li>{{.Label}}
+<ul>
+{{- range .Tables}}
+<li><a href="#{{.Anchor}}">{{.Name}}</a>{{if .View}}<span class="tag">view</span>{{end}}</li>
+{{- end}}
+</ul>
+</li>
+{{- end}}
+</ul>
+</li>
+{{- end}}
+{{- if .Enums}}
+<li><a href="#enums">Enums</a></li>
+{{- end}}
+{{- if .Notes}}
+<li><a href="#notes">Notes</a></li>
+{{- end}}
+</ul>
+</nav>
+{{range .Schemas}}
+<h2 id="{{.Anchor}}">{{.Label}}</h2>
+{{- range .Areas}}
+<h3>{{.Label}}</h3>
+{{- range .Tables}}
+<section>
+<h4 id="{{.Anchor}}">{{.Name}}{{if .View}}<span class="tag">view</span>{{end}}</h4>
+{{- with .Comment}}
+<p>{{.}}</p>
+{{- end}}
+<table>
+<tr><th>Field</th><th>Type</th><th>Key</th><th>Nullable</th><th>Default</th><th>Comment</th></tr>
+{{- range .Fields}}
+<tr><td><code>{{.Name}}</code></td><td>{{if .TypeAnchor}}<a href="#{{.TypeAnchor}}"><code>{{.Type}}</code></a>{{else}}<code>{{.Type}}</code>{{end}}</td><td class="key">{{.Key}}</td><td>{{yesNo .Nullable}}</td><td>{{with .Default}}<code>{{.}}</code>{{end}}</td><td>{{.Comment}}</td></tr>
+{{- end}}
+</table>
+{{- if .Indexes}}
+<p class="label">Indexes</p>
+<table>
+<tr><th>Name</th><th>Columns</th><th>Unique</th></tr>
+{{- range .Indexes}}
+<tr><td>{{with .Name}}<code>{{.}}</code>{{end}}</td><td>{{.Columns}}</td><td>{{yesNo .Unique}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Outgoing}}
+<p class="label">References</p>
+<ul>
+{{- range .Outgoing}}
+<li><code>{{.Columns}}</code> → <a href="#{{.Table.Anchor}}">{{.Table.Name}}</a>.<code>{{.References}}</code> ({{.Describe}})</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Incoming}}
+<p class="label">Referenced by</p>
+<ul>
+{{- range .Incoming}}
+<li><a href="#{{.Table.Anchor}}">{{.Table.Name}}</a>.<code>{{.References}}</code> → <code>{{.Columns}}</code> ({{.Describe}})</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- range .Notes}}
+<blockquote>{{range $i, $line := lines .}}{{if $i}}<br>{{end}}{{$line}}{{end}}</blockquote>
+{{- end}}
+</section>
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Enums}}
+
+<h2 id="enums">Enums</h2>
+<table>
+<tr><th>Type</th><th>Values</th></tr>
+{{- range .Enums}}
+<tr id="{{.Anchor}}"><td><code>{{.Name}}</code></td><td>{{range $i, $v := .Values}}{{if $i}}, {{end}}<code>{{$v}}</code>{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Notes}}
+
+<h2 id="notes">Notes</h2>
+{{- range .Notes}}
+<blockquote>{{with .Area}}<strong>Area {{.}}</strong><br>{{end}}{{range $i, $line := lines .Content}}{{if $i}}<br>{{end}}{{$line}}{{end}}</blockquote>
+{{- end}}
+{{- end}}
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,167 @@
+package dictionary
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Markdown implements domain.Exporter, writing the dictionary as Markdown
+// with explicit anchors so tables link to each other
+type Markdown struct{}
+
+func (Markdown) ContentType() string { return "text/markdown; charset=utf-8" }
+
+func (Markdown) Extension() string { return "md" }
+
+func (Markdown) Export(snap *domain.DiagramSnapshot, _ map[string]string) ([]byte, error) {
+	doc := build(snap)
+	var b strings.Builder
+	p := func(format string, args ...interface{}) { fmt.Fprintf(&b, format, args...) }
+
+	p("# %s\n\n", mdText(doc.Title))
+	if meta := doc.Meta(); meta != "" {
+		p("_%s_\n\n", mdText(meta))
+	}
+
+	p("## Contents\n\n")
+	for _, sg := range doc.Schemas {
+		p("- [%s](#%s)\n", mdText(sg.Label()), sg.Anchor)
+		for _, ag := range sg.Areas {
+			p("  - %s\n", mdText(ag.Label()))
+			for _, e := range ag.Tables {
+				p("    - [%s](#%s)\n", mdText(e.Name), e.Anchor)
+			}
+		}
+	}
+	if len(doc.Enums) > 0 {
+		p("- [Enums](#enums)\n")
+	}
+	if len(doc.Notes) > 0 {
+		p("- [Notes](#notes)\n")
+	}
+	b.WriteString("\n")
+
+	for _, sg := range doc.Schemas {
+		p("<a id=\"%s\"></a>\n\n## %s\n\n", sg.Anchor, mdText(sg.Label()))
+		for _, ag := range sg.Areas {
+			p("### %s\n\n", mdText(ag.Label()))
+			for _, e := range ag.Tables {
+				writeMarkdownEntry(&b, e)
+			}
+		}
+	}
+
+	if len(doc.Enums) > 0 {
+		p("<a id=\"enums\"></a>\n\n## Enums\n\n")
+		for _, e := range doc.Enums {
+			values := make([]string, 0, len(e.Values))
+			for _, v := range e.Values {
+				values = append(values, mdCode(v))
+			}
+			p("<a id=\"%s\"></a>\n\n### %s\n\n%s\n\n", e.Anchor, mdText(e.Name), strings.Join(values, ", "))
+		}
+	}
+
+	if len(doc.Notes) > 0 {
+		p("<a id=\"notes\"></a>\n\n## Notes\n\n")
+		for _, n := range doc.Notes {
+			if n.Area != "" {
+				p("**Area %s**\n\n", mdText(n.Area))
+			}
+			p("%s\n\n", mdQuote(n.Content))
+		}
+	}
+	return []byte(strings.TrimRight(b.String(), "\n") + "\n"), nil
+}
+
+func writeMarkdownEntry(b *strings.Builder, e *entry) {
+	p := func(format string, args ...interface{}) { fmt.Fprintf(b, format, args...) }
+	p("<a id=\"%s\"></a>\n\n#### %s\n\n", e.Anchor, mdText(e.title()))
+	if e.Comment != "" {
+		p("%s\n\n", mdText(e.Comment))
+	}
+
+	b.WriteString("| Field | Type | Key | Nullable | Default | Comment |\n|---|---|---|---|---|---|\n")
+	for _, f := range e.Fields {
+		typ := mdCode(f.Type)
+		if f.TypeAnchor != "" {
+			typ = "[" + typ + "](#" + f.TypeAnchor + ")"
+		}
+		p("| %s | %s | %s | %s | %s | %s |\n", mdCode(f.Name), typ, f.Key, yesNo(f.Nullable), mdCell(mdCode(f.Default)), mdCell(f.Comment))
+	}
+	b.WriteString("\n")
+
+	if len(e.Indexes) > 0 {
+		b.WriteString("**Indexes**\n\n| Name | Columns | Unique |\n|---|---|---|\n")
+		for _, idx := range e.Indexes {
+			p("| %s | %s | %s |\n", mdCell(mdCode(idx.Name)), mdCell(idx.Columns), yesNo(idx.Unique))
+		}
+		b.WriteString("\n")
+	}
+	if len(e.Outgoing) > 0 {
+		b.WriteString("**References**\n\n")
+		for _, l := range e.Outgoing {
+			p("- %s → [%s](#%s).%s (%s)\n", mdCode(l.Columns), mdText(l.Table.Name), l.Table.Anchor, mdCode(l.References), l.Describe())
+		}
+		b.WriteString("\n")
+	}
+	if len(e.Incoming) > 0 {
+		b.WriteString("**Referenced by**\n\n")
+		for _, l := range e.Incoming {
+			p("- [%s](#%s).%s → %s (%s)\n", mdText(l.Table.Name), l.Table.Anchor, mdCode(l.References), mdCode(l.Columns), l.Describe())
+		}
+		b.WriteString("\n")
+	}
+	if len(e.Notes) > 0 {
+		b.WriteString("**Notes**\n\n")
+		for _, n := range e.Notes {
+			p("%s\n\n", mdQuote(n))
+		}
+	}
+}
+
+var mdEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;", "#", `\#`, "|", `\|`)
+
+// mdText escapes inline Markdown
+func mdText(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+// mdCode writes a code span, widening the fence when s holds backticks
+func mdCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// mdCell keeps text on one table row
+func mdCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "<br>")
+}
+
+// mdQuote writes a block quote
+func mdQuote(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+mdText(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e *entry) title() string {
+	if e.View {
+		return e.Name + " (view)"
+	}
+	return e.Name
+}
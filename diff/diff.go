@@ -0,0 +1,274 @@
+// Package diff compares two diagram snapshots. The diagrams don't share IDs,
+// so tables are matched by schema and name, fields and indexes by name and
+// relationships by the columns they connect.
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Compare lists the changes that turn from into to
+func Compare(from, to *domain.DiagramSnapshot) *domain.DiagramDiff {
+	d := &domain.DiagramDiff{
+		TablesAdded:          make([]domain.TableRef, 0),
+		TablesRemoved:        make([]domain.TableRef, 0),
+		TablesChanged:        make([]domain.TableDiff, 0),
+		RelationshipsAdded:   make([]string, 0),
+		RelationshipsRemoved: make([]string, 0),
+	}
+	if from.Diagram != nil {
+		d.FromID = from.Diagram.ID
+	}
+	if to.Diagram != nil {
+		d.ToID = to.Diagram.ID
+	}
+
+	before, after := tablesByName(from.Tables), tablesByName(to.Tables)
+	for _, t := range from.Tables {
+		if _, ok := after[tableKey(t)]; !ok {
+			d.TablesRemoved = append(d.TablesRemoved, t.Ref())
+		}
+	}
+	for _, t := range to.Tables {
+		old, ok := before[tableKey(t)]
+		if !ok {
+			d.TablesAdded = append(d.TablesAdded, t.Ref())
+			continue
+		}
+		if td := compareTables(old, t); td != nil {
+			d.TablesChanged = append(d.TablesChanged, *td)
+		}
+	}
+
+	d.RelationshipsRemoved, d.RelationshipsAdded = changes(relationships(from), relationships(to))
+	d.CustomTypesRemoved, d.CustomTypesAdded = changes(typeNames(from.CustomTypes), typeNames(to.CustomTypes))
+	d.CustomTypesChanged = changedTypes(from.CustomTypes, to.CustomTypes)
+
+	d.Identical = len(d.TablesAdded) == 0 && len(d.TablesRemoved) == 0 && len(d.TablesChanged) == 0 &&
+		len(d.RelationshipsAdded) == 0 && len(d.RelationshipsRemoved) == 0 &&
+		len(d.CustomTypesAdded) == 0 && len(d.CustomTypesRemoved) == 0 && len(d.CustomTypesChanged) == 0
+	return d
+}
+
+func tableKey(t domain.Table) string {
+	return strings.ToLower(t.Schema + "." + t.Name)
+}
+
+func tablesByName(tables []domain.Table) map[string]domain.Table {
+	m := make(map[string]domain.Table, len(tables))
+	for _, t := range tables {
+		if _, dup := m[tableKey(t)]; !dup {
+			m[tableKey(t)] = t
+		}
+	}
+	return m
+}
+
+// compareTables returns nil when the table is unchanged
+func compareTables(from, to domain.Table) *domain.TableDiff {
+	td := &domain.TableDiff{
+		Schema:         to.Schema,
+		Name:           to.Name,
+		FromTableID:    from.TableID,
+		ToTableID:      to.TableID,
+		FieldsAdded:    make([]string, 0),
+		FieldsRemoved:  make([]string, 0),
+		FieldsChanged:  make([]domain.FieldDiff, 0),
+		IndexesAdded:   make([]string, 0),
+		IndexesRemoved: make([]string, 0),
+		Changes:        make([]string, 0),
+	}
+	if from.IsView != to.IsView {
+		td.Changes = append(td.Changes, fmt.Sprintf("view: %t -> %t", from.IsView, to.IsView))
+	}
+	if from.Comments != to.Comments {
+		td.Changes = append(td.Changes, fmt.Sprintf("comments: %q -> %q", from.Comments, to.Comments))
+	}
+
+	oldFields := make(map[string]domain.Field)
+	for _, f := range from.FieldList() {
+		oldFields[strings.ToLower(f.Name)] = f
+	}
+	newNames := make(map[string]bool)
+	for _, f := range to.FieldList() {
+		key := strings.ToLower(f.Name)
+		newNames[key] = true
+		old, ok := oldFields[key]
+		if !ok {
+			td.FieldsAdded = append(td.FieldsAdded, f.Name)
+			continue
+		}
+		if c := compareFields(old, f); len(c) > 0 {
+			td.FieldsChanged = append(td.FieldsChanged, domain.FieldDiff{Name: f.Name, Changes: c})
+		}
+	}
+	for _, f := range from.FieldList() {
+		if !newNames[strings.ToLower(f.Name)] {
+			td.FieldsRemoved = append(td.FieldsRemoved, f.Name)
+		}
+	}
+
+	td.IndexesRemoved, td.IndexesAdded = changes(indexes(from), indexes(to))
+
+	if len(td.Changes) == 0 && len(td.FieldsAdded) == 0 && len(td.FieldsRemoved) == 0 && len(td.FieldsChanged) == 0 &&
+		len(td.IndexesAdded) == 0 && len(td.IndexesRemoved) == 0 {
+		return nil
+	}
+	return td
+}
+
+func compareFields(from, to domain.Field) []string {
+	c := make([]string, 0)
+	add := func(property, a, b string) {
+		if a != b {
+			c = append(c, fmt.Sprintf("%s: %s -> %s", property, a, b))
+		}
+	}
+	add("type", from.SQLType(), to.SQLType())
+	add("primary key", strconv.FormatBool(from.PrimaryKey), strconv.FormatBool(to.PrimaryKey))
+	add("nullable", strconv.FormatBool(from.Nullable), strconv.FormatBool(to.Nullable))
+	add("unique", strconv.FormatBool(from.Unique), strconv.FormatBool(to.Unique))
+	add("increment", strconv.FormatBool(from.Increment), strconv.FormatBool(to.Increment))
+	add("default", quoteOrNone(from.Default), quoteOrNone(to.Default))
+	add("collation", quoteOrNone(from.Collation), quoteOrNone(to.Collation))
+	add("comments", quoteOrNone(from.Comments), quoteOrNone(to.Comments))
+	return c
+}
+
+func quoteOrNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return strconv.Quote(s)
+}
+
+// indexes describes each index by its name, uniqueness and field names
+func indexes(t domain.Table) []string {
+	names := make(map[string]string)
+	for _, f := range t.FieldList() {
+		names[f.ID] = f.Name
+	}
+	out := make([]string, 0)
+	for _, idx := range t.IndexList() {
+		cols := make([]string, 0, len(idx.FieldIDs))
+		for _, id := range idx.FieldIDs {
+			cols = append(cols, names[id])
+		}
+		s := idx.Name + " (" + strings.Join(cols, ", ") + ")"
+		if idx.Unique {
+			s = "unique " + s
+		}
+		out = append(out, strings.TrimSpace(s))
+	}
+	return out
+}
+
+// relationships describes each relationship as child.field -> parent.field
+func relationships(snap *domain.DiagramSnapshot) []string {
+	tables := make(map[string]domain.Table, len(snap.Tables))
+	for _, t := range snap.Tables {
+		tables[t.TableID] = t
+	}
+	side := func(tableID, fieldID string) string {
+		t, ok := tables[tableID]
+		if !ok {
+			return "?"
+		}
+		f, _ := t.FieldByID(fieldID)
+		return domain.QualifiedName(t.Schema, t.Name) + "." + nonEmpty(f.Name, "?")
+	}
+
+	out := make([]string, 0, len(snap.Relationships))
+	for _, r := range snap.Relationships {
+		childID, fkID := r.ForeignKey()
+		parentID, refID := r.TargetTableID, r.TargetFieldID
+		if childID == r.TargetTableID && fkID == r.TargetFieldID {
+			parentID, refID = r.SourceTableID, r.SourceFieldID
+		}
+		out = append(out, fmt.Sprintf("%s -> %s (%s:%s)", side(childID, fkID), side(parentID, refID),
+			childCardinality(r, childID), parentCardinality(r, childID)))
+	}
+	return out
+}
+
+func childCardinality(r domain.Relationship, childID string) string {
+	if childID == r.SourceTableID {
+		return r.SourceCardinality
+	}
+	return r.TargetCardinality
+}
+
+func parentCardinality(r domain.Relationship, childID string) string {
+	if childID == r.SourceTableID {
+		return r.TargetCardinality
+	}
+	return r.SourceCardinality
+}
+
+func typeNames(types []domain.CustomType) []string {
+	out := make([]string, 0, len(types))
+	for _, ct := range types {
+		out = append(out, ct.Kind+" "+domain.QualifiedName(ct.Schema, ct.Type))
+	}
+	return out
+}
+
+// changedTypes lists custom types present in both snapshots whose values or fields differ
+func changedTypes(from, to []domain.CustomType) []string {
+	old := make(map[string]domain.CustomType, len(from))
+	for _, ct := range from {
+		old[strings.ToLower(ct.Kind+" "+domain.QualifiedName(ct.Schema, ct.Type))] = ct
+	}
+	out := make([]string, 0)
+	for _, ct := range to {
+		name := ct.Kind + " " + domain.QualifiedName(ct.Schema, ct.Type)
+		prev, ok := old[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		if !slices.Equal(prev.ValueList(), ct.ValueList()) || !slices.Equal(prev.FieldList(), ct.FieldList()) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// changes returns the items only in before and only in after, sorted;
+// duplicates count separately
+func changes(before, after []string) (removed, added []string) {
+	count := make(map[string]int, len(before))
+	for _, s := range before {
+		count[s]++
+	}
+	added = make([]string, 0)
+	for _, s := range after {
+		if count[s] > 0 {
+			count[s]--
+			continue
+		}
+		added = append(added, s)
+	}
+	removed = make([]string, 0)
+	for _, s := range before {
+		if count[s] > 0 {
+			count[s]--
+			removed = append(removed, s)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return removed, added
+}
+
+func nonEmpty(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,164 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/iots1/vertex-diagram/dbml"
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+func parse(t *testing.T, src string) *domain.DiagramSnapshot {
+	t.Helper()
+	snap, _, err := dbml.Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snap
+}
+
+const base = `
+Enum status {
+  active
+  archived
+}
+Table users [note: 'people'] {
+  id int [pk]
+  email varchar(255) [not null]
+  status status
+  indexes {
+    email [unique, name: 'ix_email']
+  }
+}
+Table orders {
+  id int [pk]
+  user_id int [ref: > users.id]
+}
+`
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		to   string
+		want domain.DiagramDiff
+	}{
+		{"identical", base, domain.DiagramDiff{Identical: true}},
+		{
+			"tables and fields",
+			`
+Enum status {
+  active
+  archived
+}
+Table users [note: 'accounts'] {
+  id bigint [pk]
+  email varchar(320)
+  name text
+  indexes {
+    (email, name) [name: 'ix_email_name']
+  }
+}
+Table invoices {
+  id int [pk]
+}
+`,
+			domain.DiagramDiff{
+				TablesAdded:   []domain.TableRef{{Name: "invoices"}},
+				TablesRemoved: []domain.TableRef{{Name: "orders"}},
+				TablesChanged: []domain.TableDiff{{
+					Name:          "users",
+					FieldsAdded:   []string{"name"},
+					FieldsRemoved: []string{"status"},
+					FieldsChanged: []domain.FieldDiff{
+						{Name: "id", Changes: []string{"type: int -> bigint"}},
+						{Name: "email", Changes: []string{"type: varchar(255) -> varchar(320)", "nullable: false -> true"}},
+					},
+					IndexesAdded:   []string{"ix_email_name (email, name)"},
+					IndexesRemoved: []string{"unique ix_email (email)"},
+					Changes:        []string{`comments: "people" -> "accounts"`},
+				}},
+				RelationshipsRemoved: []string{"orders.user_id -> users.id (many:one)"},
+			},
+		},
+		{
+			"relationships and types",
+			`
+Enum status {
+  active
+  archived
+  deleted
+}
+Enum role {
+  admin
+}
+Table users [note: 'people'] {
+  id int [pk]
+  email varchar(255) [not null]
+  status status
+  indexes {
+    email [unique, name: 'ix_email']
+  }
+}
+Table orders {
+  id int [pk]
+  user_id int [ref: - users.id]
+}
+`,
+			domain.DiagramDiff{
+				RelationshipsAdded:   []string{"orders.user_id -> users.id (one:one)"},
+				RelationshipsRemoved: []string{"orders.user_id -> users.id (many:one)"},
+				CustomTypesAdded:     []string{"enum role"},
+				CustomTypesChanged:   []string{"enum status"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := parse(t, base), parse(t, tt.to)
+			got := Compare(from, to)
+
+			// IDs are placeholders; compare the changes only
+			for _, refs := range [][]domain.TableRef{got.TablesAdded, got.TablesRemoved} {
+				for i := range refs {
+					refs[i].TableID = ""
+				}
+			}
+			for i := range got.TablesChanged {
+				got.TablesChanged[i].FromTableID, got.TablesChanged[i].ToTableID = "", ""
+			}
+			want := tt.want
+			fill(&want)
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("got  %+v\nwant %+v", *got, want)
+			}
+		})
+	}
+}
+
+// fill replaces the nil slices of a wanted diff with the empty ones Compare returns
+func fill(d *domain.DiagramDiff) {
+	for _, s := range []*[]string{&d.RelationshipsAdded, &d.RelationshipsRemoved, &d.CustomTypesAdded, &d.CustomTypesRemoved, &d.CustomTypesChanged} {
+		if *s == nil {
+			*s = []string{}
+		}
+	}
+	for _, s := range []*[]domain.TableRef{&d.TablesAdded, &d.TablesRemoved} {
+		if *s == nil {
+			*s = []domain.TableRef{}
+		}
+	}
+	if d.TablesChanged == nil {
+		d.TablesChanged = []domain.TableDiff{}
+	}
+	for i := range d.TablesChanged {
+		td := &d.TablesChanged[i]
+		for _, s := range []*[]string{&td.FieldsAdded, &td.FieldsRemoved, &td.IndexesAdded, &td.IndexesRemoved, &td.Changes} {
+			if *s == nil {
+				*s = []string{}
+			}
+		}
+		if td.FieldsChanged == nil {
+			td.FieldsChanged = []domain.FieldDiff{}
+		}
+	}
+}
@@ -2,19 +2,22 @@ package domain
 
 import (
 	"context"
+	"slices"
 	"time"
 )
 
 // Area represents a visual grouping area on the canvas for organizing tables
 type Area struct {
-	ID        string    `bson:"_id,omitempty" json:"id"`
+	ID        string    `bson:"_id,omitempty" json:"mongoId"`
 	DiagramID string    `bson:"diagram_id" json:"diagram_id"` // FK to diagrams
+	AreaID    string    `bson:"area_id" json:"id"`            // ID from diagram - returned as "id" for frontend
 	Name      string    `bson:"name" json:"name"`
 	X         int       `bson:"x" json:"x"`           // Canvas position
 	Y         int       `bson:"y" json:"y"`
 	Width     int       `bson:"width" json:"width"`   // Area dimensions
 	Height    int       `bson:"height" json:"height"`
 	Color     string    `bson:"color" json:"color"`
+	TableIDs  []string  `bson:"table_ids" json:"table_ids"` // Member tables, synced by containment
 	CreatedAt time.Time `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
 }
@@ -22,8 +25,99 @@ type Area struct {
 // AreaRepository defines methods for area data access
 type AreaRepository interface {
 	Store(ctx context.Context, a *Area) error
+	Update(ctx context.Context, a *Area) error
 	StoreMultiple(ctx context.Context, areas []Area) error
 	UpdateByDiagramID(ctx context.Context, diagramID string, areas []Area) error
+	GetByID(ctx context.Context, diagramID, areaID string) (*Area, error)
 	GetByDiagramID(ctx context.Context, diagramID string) ([]Area, error)
 	DeleteByDiagramID(ctx context.Context, diagramID string) error
+	SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error
+	UndeleteByDiagramID(ctx context.Context, diagramID string) error
+	PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error
+}
+
+// Contains reports whether the table's center lies inside the area rectangle
+func (a Area) Contains(t Table) bool {
+	w, h := t.Size()
+	cx, cy := t.X+w/2, t.Y+h/2
+	return cx >= a.X && cx <= a.X+a.Width && cy >= a.Y && cy <= a.Y+a.Height
+}
+
+// Includes reports whether the table is a member of the area; areas saved
+// before membership was tracked fall back to containment
+func (a Area) Includes(t Table) bool {
+	if a.TableIDs == nil {
+		return a.Contains(t)
+	}
+	return slices.Contains(a.TableIDs, t.TableID)
+}
+
+// AssignAreaMembers recomputes every area's TableIDs by containment and
+// returns the indices of areas whose membership changed. A table inside
+// nested or overlapping areas belongs only to the smallest of them.
+func AssignAreaMembers(areas []Area, tables []Table) []int {
+	members := make([][]string, len(areas))
+	for _, t := range tables {
+		best := -1
+		for i, a := range areas {
+			if a.Contains(t) && (best < 0 || a.Width*a.Height < areas[best].Width*areas[best].Height) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			members[best] = append(members[best], t.TableID)
+		}
+	}
+
+	changed := make([]int, 0)
+	for i := range areas {
+		ids := members[i]
+		if ids == nil {
+			ids = []string{}
+		}
+		slices.Sort(ids)
+		if areas[i].TableIDs == nil || !slices.Equal(slices.Sorted(slices.Values(areas[i].TableIDs)), ids) {
+			changed = append(changed, i)
+		}
+		areas[i].TableIDs = ids
+	}
+	return changed
+}
+
+// Ways to generate areas automatically
+const (
+	ClusterBySchema    = "schema"
+	ClusterByCommunity = "community"
+)
+
+// ClusterRequest asks to group a diagram's tables into generated areas
+type ClusterRequest struct {
+	DiagramID string
+	By        string // schema | community
+	MinSize   int    // Smaller groups are left without an area
+	Arrange   bool   // Lay out members inside each area and pack areas on the canvas
+	Preview   bool   // Return the areas without saving
+}
+
+// AreaCluster is a generated area and the tables it contains
+type AreaCluster struct {
+	Area     Area     `json:"area"`
+	TableIDs []string `json:"table_ids"`
+}
+
+// ClusterResult lists generated areas; Tables holds new positions when members were arranged
+type ClusterResult struct {
+	DiagramID   string          `json:"diagram_id"`
+	By          string          `json:"by"`
+	Preview     bool            `json:"preview"`
+	Clusters    []AreaCluster   `json:"clusters"`
+	Tables      []TablePosition `json:"tables"`
+	Unclustered []string        `json:"unclustered"`
+}
+
+// AreaUsecase defines area-related business logic
+type AreaUsecase interface {
+	GetByDiagram(ctx context.Context, diagramID string) ([]Area, error)
+	GetTables(ctx context.Context, diagramID, areaID string) ([]Table, error)
+	Cluster(ctx context.Context, req ClusterRequest) (*ClusterResult, error)
 }
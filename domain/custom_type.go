@@ -18,6 +18,34 @@ type CustomType struct {
 	UpdatedAt time.Time   `bson:"updated_at" json:"updated_at"`
 }
 
+// ValueList returns the enum values of the custom type as strings
+func (ct CustomType) ValueList() []string {
+	values := make([]string, 0)
+	for _, v := range asSlice(ct.Values) {
+		if s, ok := v.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// CompositeField is one field of a composite custom type
+type CompositeField struct {
+	Field string
+	Type  string
+}
+
+// FieldList returns the fields of a composite custom type
+func (ct CustomType) FieldList() []CompositeField {
+	fields := make([]CompositeField, 0)
+	for _, v := range asSlice(ct.Fields) {
+		if m := asMap(v); m != nil {
+			fields = append(fields, CompositeField{Field: MapString(m, "field"), Type: MapString(m, "type")})
+		}
+	}
+	return fields
+}
+
 // CustomTypeRepository defines methods for custom type data access
 type CustomTypeRepository interface {
 	Store(ctx context.Context, ct *CustomType) error
@@ -25,4 +53,7 @@ type CustomTypeRepository interface {
 	UpdateByDiagramID(ctx context.Context, diagramID string, customTypes []CustomType) error
 	GetByDiagramID(ctx context.Context, diagramID string) ([]CustomType, error)
 	DeleteByDiagramID(ctx context.Context, diagramID string) error
+	SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error
+	UndeleteByDiagramID(ctx context.Context, diagramID string) error
+	PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error
 }
@@ -25,4 +25,7 @@ type DependencyRepository interface {
 	UpdateByDiagramID(ctx context.Context, diagramID string, dependencies []Dependency) error
 	GetByDiagramID(ctx context.Context, diagramID string) ([]Dependency, error)
 	DeleteByDiagramID(ctx context.Context, diagramID string) error
+	SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error
+	UndeleteByDiagramID(ctx context.Context, diagramID string) error
+	PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error
 }
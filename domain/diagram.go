@@ -9,24 +9,111 @@ import (
 type Diagram struct {
 	ID        string                 `bson:"_id,omitempty" json:"id"`
 	Name      string                 `bson:"name" json:"name"`
-	Content   map[string]interface{} `bson:"content" json:"content"` // JSON ก้อนใหญ่ของ ChartDB
+	Content   map[string]interface{} `bson:"content" json:"content"`   // JSON ก้อนใหญ่ของ ChartDB
+	Revision  int64                  `bson:"revision" json:"revision"` // Incremented on every save
 	UpdatedAt time.Time              `bson:"updated_at" json:"updated_at"`
 	CreatedAt time.Time              `bson:"created_at" json:"created_at"`
+
+	// Lineage: set when the diagram was cloned from another one
+	SourceDiagramID string `bson:"source_diagram_id,omitempty" json:"source_diagram_id,omitempty"`
+	SourceRevision  int64  `bson:"source_revision,omitempty" json:"source_revision,omitempty"`
+
+	// Set while the diagram is in the trash
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+}
+
+// DiagramSummary is a diagram listing item without the content blob
+type DiagramSummary struct {
+	ID                string     `bson:"_id" json:"id"`
+	Name              string     `bson:"name" json:"name"`
+	TableCount        int        `bson:"table_count" json:"table_count"`
+	RelationshipCount int        `bson:"relationship_count" json:"relationship_count"`
+	NoteCount         int        `bson:"note_count" json:"note_count"`
+	UpdatedAt         time.Time  `bson:"updated_at" json:"updated_at"`
+	CreatedAt         time.Time  `bson:"created_at" json:"created_at"`
+	DeletedAt         *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+}
+
+// Sort fields, orders and search modes accepted when listing diagrams
+const (
+	DiagramSortName      = "name"
+	DiagramSortUpdatedAt = "updated_at"
+	DiagramSortCreatedAt = "created_at"
+	DiagramSortDeletedAt = "deleted_at" // Trash only
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+
+	SearchModePrefix   = "prefix"
+	SearchModeContains = "contains"
+)
+
+// DiagramListQuery holds cursor pagination, sorting and name search options
+type DiagramListQuery struct {
+	Cursor     string // Opaque cursor returned as NextCursor by the previous page
+	Limit      int
+	Sort       string // name | updated_at | created_at
+	Order      string // asc | desc
+	Search     string // Case-insensitive name search
+	SearchMode string // prefix | contains
+	Deleted    bool   // List the trash instead of live diagrams
+}
+
+// DiagramPage is one page of diagram summaries
+type DiagramPage struct {
+	Items      []DiagramSummary `json:"items"`
+	NextCursor string           `json:"next_cursor,omitempty"`
 }
 
 // Repository Interface: สัญญาว่าต้องทำอะไรกับ DB ได้บ้าง
 type DiagramRepository interface {
-	Fetch(ctx context.Context) ([]Diagram, error)
+	Fetch(ctx context.Context, q DiagramListQuery) (*DiagramPage, error)
 	GetByID(ctx context.Context, id string) (*Diagram, error)
 	Store(ctx context.Context, d *Diagram) error
 	Update(ctx context.Context, d *Diagram) error
 	Delete(ctx context.Context, id string) error
+	// SoftDelete moves a live diagram to the trash; GetByID no longer finds it
+	SoftDelete(ctx context.Context, id string, at time.Time) error
+	// Undelete takes a diagram out of the trash
+	Undelete(ctx context.Context, id string) error
+	// InTrash reports whether a diagram with the ID exists in the trash
+	InTrash(ctx context.Context, id string) (bool, error)
+	// Purge permanently deletes the diagram if it was moved to the trash
+	// before the given time, and reports whether it did
+	Purge(ctx context.Context, id string, before time.Time) (bool, error)
 }
 
 // Usecase Interface: สัญญาว่า Business Logic มีอะไรบ้าง
 type DiagramUsecase interface {
-	GetAll(ctx context.Context) ([]Diagram, error)
+	GetAll(ctx context.Context, q DiagramListQuery) (*DiagramPage, error)
 	GetOne(ctx context.Context, id string) (*Diagram, error)
 	Save(ctx context.Context, d *Diagram) (*Diagram, error)
+	// Delete moves a diagram and its entities to the trash
 	Delete(ctx context.Context, id string) error
-}
\ No newline at end of file
+	// GetTrash lists deleted diagrams, most recently deleted first by default
+	GetTrash(ctx context.Context, q DiagramListQuery) (*DiagramPage, error)
+	// Undelete restores a diagram from the trash
+	Undelete(ctx context.Context, id string) (*Diagram, error)
+	// PurgeTrash permanently deletes diagrams deleted before the given time
+	PurgeTrash(ctx context.Context, before time.Time) (int, error)
+	// GetSnapshot returns the diagram with its child entities as typed slices
+	GetSnapshot(ctx context.Context, id string) (*DiagramSnapshot, error)
+	// Clone deep-copies a diagram and its entities with fresh IDs
+	Clone(ctx context.Context, id string, name string) (*Diagram, error)
+	// Merge copies a source diagram into a target diagram, resolving table collisions
+	Merge(ctx context.Context, req MergeRequest) (*MergeReport, error)
+	// Import creates a new diagram from a document in one of the supported formats
+	Import(ctx context.Context, req ImportRequest) (*ImportResult, error)
+	// Export renders a saved diagram in one of the supported formats
+	Export(ctx context.Context, req ExportRequest) (*ExportResult, error)
+	// ImportComments updates table and field comments from an edited field sheet
+	ImportComments(ctx context.Context, req CommentImportRequest) (*CommentImportResult, error)
+	// Backup archives the given diagrams, or every diagram when ids is empty
+	Backup(ctx context.Context, ids []string) (*ExportResult, error)
+	// Restore writes the diagrams of a backup archive
+	Restore(ctx context.Context, req RestoreRequest) (*RestoreResult, error)
+	// Lint checks a diagram for schema design problems
+	Lint(ctx context.Context, id string) (*LintReport, error)
+	// Diff lists the schema changes from one diagram to another
+	Diff(ctx context.Context, fromID, toID string) (*DiagramDiff, error)
+}
@@ -11,6 +11,7 @@ type DiagramFilter struct {
 	DiagramID  string        `bson:"diagram_id" json:"diagram_id"` // FK to diagrams (unique)
 	TableIDs   []string      `bson:"table_ids" json:"table_ids"`   // Filtered table IDs
 	SchemaIDs  []string      `bson:"schema_ids" json:"schema_ids"` // Filtered schema IDs
+	AreaIDs    []string      `bson:"area_ids" json:"area_ids"`     // Filtered area IDs
 	CreatedAt  time.Time     `bson:"created_at" json:"created_at"`
 	UpdatedAt  time.Time     `bson:"updated_at" json:"updated_at"`
 }
@@ -20,4 +21,15 @@ type DiagramFilterRepository interface {
 	Store(ctx context.Context, df *DiagramFilter) error
 	GetByDiagramID(ctx context.Context, diagramID string) (*DiagramFilter, error)
 	DeleteByDiagramID(ctx context.Context, diagramID string) error
+	SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error
+	UndeleteByDiagramID(ctx context.Context, diagramID string) error
+	PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error
+}
+
+// Scope returns the filter as a snapshot scope; a nil filter keeps everything
+func (f *DiagramFilter) Scope() SnapshotScope {
+	if f == nil {
+		return SnapshotScope{}
+	}
+	return SnapshotScope{TableIDs: f.TableIDs, Schemas: f.SchemaIDs, AreaIDs: f.AreaIDs}
 }
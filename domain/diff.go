@@ -0,0 +1,38 @@
+package domain
+
+// DiagramDiff lists the schema changes from one diagram to another. Tables
+// are matched by schema and name, fields by name, since the two diagrams
+// don't share IDs.
+type DiagramDiff struct {
+	FromID               string      `json:"from_id"`
+	ToID                 string      `json:"to_id"`
+	Identical            bool        `json:"identical"`
+	TablesAdded          []TableRef  `json:"tables_added"`
+	TablesRemoved        []TableRef  `json:"tables_removed"`
+	TablesChanged        []TableDiff `json:"tables_changed"`
+	RelationshipsAdded   []string    `json:"relationships_added"`
+	RelationshipsRemoved []string    `json:"relationships_removed"`
+	CustomTypesAdded     []string    `json:"custom_types_added"`
+	CustomTypesRemoved   []string    `json:"custom_types_removed"`
+	CustomTypesChanged   []string    `json:"custom_types_changed"`
+}
+
+// TableDiff lists the changes to a table present in both diagrams
+type TableDiff struct {
+	Schema         string      `json:"schema"`
+	Name           string      `json:"name"`
+	FromTableID    string      `json:"from_table_id"`
+	ToTableID      string      `json:"to_table_id"`
+	FieldsAdded    []string    `json:"fields_added"`
+	FieldsRemoved  []string    `json:"fields_removed"`
+	FieldsChanged  []FieldDiff `json:"fields_changed"`
+	IndexesAdded   []string    `json:"indexes_added"`
+	IndexesRemoved []string    `json:"indexes_removed"`
+	Changes        []string    `json:"changes"` // Table properties, e.g. "comments: a -> b"
+}
+
+// FieldDiff lists the changed properties of a field, e.g. "type: int -> bigint"
+type FieldDiff struct {
+	Name    string   `json:"name"`
+	Changes []string `json:"changes"`
+}
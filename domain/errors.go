@@ -0,0 +1,12 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrNotFound will throw if the requested item is not exists
+	ErrNotFound = errors.New("your requested item is not found")
+	// ErrConflict will throw if the current action already exists
+	ErrConflict = errors.New("your item already exist")
+	// ErrBadParamInput will throw if the given request-body or params is not valid
+	ErrBadParamInput = errors.New("given param is not valid")
+)
@@ -0,0 +1,127 @@
+package domain
+
+// Formats accepted by diagram import and export
+const (
+	FormatDBML       = "dbml"
+	FormatMermaid    = "mermaid"
+	FormatPlantUML   = "plantuml"
+	FormatSVG        = "svg"
+	FormatPNG        = "png"
+	FormatDOT        = "dot"
+	FormatGo         = "go"
+	FormatPrisma     = "prisma"
+	FormatJSONSchema = "jsonschema"
+	FormatTypeScript = "typescript"
+	FormatZod        = "zod"
+	FormatDjango     = "django"
+	FormatSQLAlchemy = "sqlalchemy"
+	FormatRails      = "rails"
+	FormatMarkdown   = "markdown"
+	FormatHTML       = "html"
+	FormatCSV        = "csv"
+	FormatXLSX       = "xlsx"
+	FormatSQL        = "sql"
+)
+
+// Exporter renders a diagram snapshot as a document
+type Exporter interface {
+	ContentType() string
+	Extension() string // File extension without the dot
+	Export(snap *DiagramSnapshot, opts map[string]string) ([]byte, error)
+}
+
+// Importer parses a document into a snapshot with placeholder IDs and no
+// canvas positions. Warnings list constructs that were dropped or changed.
+type Importer interface {
+	Import(data []byte) (*DiagramSnapshot, []string, error)
+}
+
+// ImportRequest creates a new diagram from a document
+type ImportRequest struct {
+	Format string
+	Name   string // Overrides the name found in the document
+	Data   []byte
+}
+
+// ImportResult describes the diagram created by an import
+type ImportResult struct {
+	Diagram       *Diagram `json:"diagram"`
+	Tables        int      `json:"tables"`
+	Relationships int      `json:"relationships"`
+	CustomTypes   int      `json:"custom_types"`
+	Areas         int      `json:"areas"`
+	Notes         int      `json:"notes"`
+	Warnings      []string `json:"warnings"`
+}
+
+// ExportRequest renders a saved diagram; Options are format specific
+type ExportRequest struct {
+	DiagramID string
+	Format    string
+	Options   map[string]string
+}
+
+// ExportResult is a rendered document ready to be downloaded
+type ExportResult struct {
+	ContentType string
+	FileName    string
+	Data        []byte
+}
+
+// CommentImportRequest applies the comments of an edited field sheet (CSV or
+// XLSX, as exported) to a saved diagram
+type CommentImportRequest struct {
+	DiagramID string
+	Format    string
+	Data      []byte
+	DryRun    bool // Report what would change without saving
+}
+
+// CommentImportResult counts the matched rows and lists the others
+type CommentImportResult struct {
+	DiagramID string         `json:"diagram_id"`
+	DryRun    bool           `json:"dry_run"`
+	Rows      int            `json:"rows"`
+	Updated   int            `json:"updated"`
+	Unchanged int            `json:"unchanged"`
+	Unmatched []UnmatchedRow `json:"unmatched"`
+}
+
+// UnmatchedRow is a sheet row that names no table or field of the diagram
+type UnmatchedRow struct {
+	Line   int    `json:"line"`
+	Schema string `json:"schema,omitempty"`
+	Table  string `json:"table"`
+	Field  string `json:"field,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Restore modes decide how diagram IDs found in a backup are used
+const (
+	RestoreKeepIDs       = "keep"       // Original IDs; diagrams that exist are replaced
+	RestoreRegenerateIDs = "regenerate" // Fresh IDs; every diagram is restored as a new one
+	RestoreSkipExisting  = "skip"       // Original IDs; diagrams that exist are left alone
+)
+
+// RestoreRequest writes the diagrams of a backup archive
+type RestoreRequest struct {
+	Data []byte
+	Mode string // keep | regenerate | skip (default)
+}
+
+// RestoreResult lists what a restore wrote and what it skipped
+type RestoreResult struct {
+	Version  int               `json:"version"`
+	Mode     string            `json:"mode"`
+	Restored []RestoredDiagram `json:"restored"`
+	Skipped  []RestoredDiagram `json:"skipped"`
+}
+
+// RestoredDiagram maps a diagram of the archive to the diagram written for it
+type RestoredDiagram struct {
+	SourceID      string `json:"source_id"`
+	ID            string `json:"id,omitempty"`
+	Name          string `json:"name"`
+	Tables        int    `json:"tables"`
+	Relationships int    `json:"relationships"`
+}
@@ -0,0 +1,263 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Field is a typed view over one field object stored in Table.Fields
+type Field struct {
+	ID                     string
+	Name                   string
+	Type                   string // Data type name, e.g. "varchar"
+	PrimaryKey             bool
+	Unique                 bool
+	Nullable               bool
+	Increment              bool
+	Default                string
+	Comments               string
+	Collation              string
+	CharacterMaximumLength string // Number or "max"
+	Precision              *int
+	Scale                  *int
+}
+
+// Index is a typed view over one index object stored in Table.Indexes
+type Index struct {
+	ID       string
+	Name     string
+	Unique   bool
+	FieldIDs []string
+}
+
+// FieldList returns the table's fields as typed values
+func (t Table) FieldList() []Field {
+	fields := make([]Field, 0, len(t.Fields))
+	for _, m := range t.Fields {
+		fields = append(fields, FieldFromMap(m))
+	}
+	return fields
+}
+
+// IndexList returns the table's indexes as typed values
+func (t Table) IndexList() []Index {
+	indexes := make([]Index, 0, len(t.Indexes))
+	for _, m := range t.Indexes {
+		indexes = append(indexes, IndexFromMap(m))
+	}
+	return indexes
+}
+
+// FieldByID returns the field with the given ID
+func (t Table) FieldByID(id string) (Field, bool) {
+	for _, m := range t.Fields {
+		if MapString(m, "id") == id {
+			return FieldFromMap(m), true
+		}
+	}
+	return Field{}, false
+}
+
+// SQLType returns the field type with its size arguments, e.g. varchar(255)
+// or numeric(10,2)
+func (f Field) SQLType() string {
+	t := f.Type
+	switch {
+	case f.CharacterMaximumLength != "":
+		t += "(" + f.CharacterMaximumLength + ")"
+	case f.Precision != nil && f.Scale != nil:
+		t += "(" + strconv.Itoa(*f.Precision) + "," + strconv.Itoa(*f.Scale) + ")"
+	case f.Precision != nil:
+		t += "(" + strconv.Itoa(*f.Precision) + ")"
+	}
+	return t
+}
+
+// QualifiedName joins a schema and a name with a dot, leaving out an empty schema
+func QualifiedName(schema, name string) string {
+	if schema == "" {
+		return name
+	}
+	return schema + "." + name
+}
+
+// QualifiedName returns the table name prefixed with its schema, if any
+func (t Table) QualifiedName() string {
+	return QualifiedName(t.Schema, t.Name)
+}
+
+// FieldFromMap reads a field object as sent by the frontend or decoded from Mongo
+func FieldFromMap(m map[string]interface{}) Field {
+	f := Field{
+		ID:                     MapString(m, "id"),
+		Name:                   MapString(m, "name"),
+		PrimaryKey:             MapBool(m, "primaryKey"),
+		Unique:                 MapBool(m, "unique"),
+		Nullable:               MapBool(m, "nullable"),
+		Increment:              MapBool(m, "increment"),
+		Default:                MapString(m, "default"),
+		Comments:               MapString(m, "comments"),
+		Collation:              MapString(m, "collation"),
+		CharacterMaximumLength: MapString(m, "characterMaximumLength"),
+		Precision:              mapIntPtr(m, "precision"),
+		Scale:                  mapIntPtr(m, "scale"),
+	}
+
+	// type is {id, name} from the frontend, but plain strings are accepted too
+	switch t := m["type"].(type) {
+	case string:
+		f.Type = t
+	default:
+		if tm := asMap(t); tm != nil {
+			f.Type = MapString(tm, "name")
+			if f.Type == "" {
+				f.Type = MapString(tm, "id")
+			}
+		}
+	}
+	return f
+}
+
+// ToMap converts the field into the frontend's field object shape
+func (f Field) ToMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"id":         f.ID,
+		"name":       f.Name,
+		"type":       map[string]interface{}{"id": strings.ReplaceAll(strings.ToLower(f.Type), " ", "_"), "name": f.Type},
+		"primaryKey": f.PrimaryKey,
+		"unique":     f.Unique,
+		"nullable":   f.Nullable,
+		"increment":  f.Increment,
+	}
+	if f.Default != "" {
+		m["default"] = f.Default
+	}
+	if f.Comments != "" {
+		m["comments"] = f.Comments
+	}
+	if f.Collation != "" {
+		m["collation"] = f.Collation
+	}
+	if f.CharacterMaximumLength != "" {
+		m["characterMaximumLength"] = f.CharacterMaximumLength
+	}
+	if f.Precision != nil {
+		m["precision"] = *f.Precision
+	}
+	if f.Scale != nil {
+		m["scale"] = *f.Scale
+	}
+	return m
+}
+
+// IndexFromMap reads an index object as sent by the frontend or decoded from Mongo
+func IndexFromMap(m map[string]interface{}) Index {
+	idx := Index{
+		ID:     MapString(m, "id"),
+		Name:   MapString(m, "name"),
+		Unique: MapBool(m, "unique"),
+	}
+	for _, v := range asSlice(m["fieldIds"]) {
+		if s, ok := v.(string); ok {
+			idx.FieldIDs = append(idx.FieldIDs, s)
+		}
+	}
+	return idx
+}
+
+// ToMap converts the index into the frontend's index object shape
+func (i Index) ToMap() map[string]interface{} {
+	fieldIDs := make([]interface{}, 0, len(i.FieldIDs))
+	for _, id := range i.FieldIDs {
+		fieldIDs = append(fieldIDs, id)
+	}
+	return map[string]interface{}{
+		"id":       i.ID,
+		"name":     i.Name,
+		"unique":   i.Unique,
+		"fieldIds": fieldIDs,
+	}
+}
+
+// MapString reads a string value, formatting numbers when needed
+func MapString(m map[string]interface{}, key string) string {
+	switch v := m[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// MapBool reads a boolean value
+func MapBool(m map[string]interface{}, key string) bool {
+	b, _ := m[key].(bool)
+	return b
+}
+
+func mapIntPtr(m map[string]interface{}, key string) *int {
+	var n int
+	switch v := m[key].(type) {
+	case int:
+		n = v
+	case int32:
+		n = int(v)
+	case int64:
+		n = int(v)
+	case float64:
+		n = int(v)
+	case string:
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return nil
+		}
+		n = i
+	default:
+		return nil
+	}
+	return &n
+}
+
+// asSlice converts JSON ([]interface{}) and BSON (primitive.A) arrays alike
+func asSlice(v interface{}) []interface{} {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil
+	}
+	out := make([]interface{}, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
+
+// asMap converts JSON objects and BSON documents (primitive.M) alike
+func asMap(v interface{}) map[string]interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m
+	}
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil
+	}
+	out := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		out[iter.Key().String()] = iter.Value().Interface()
+	}
+	return out
+}
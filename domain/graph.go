@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"context"
+)
+
+// Impact directions. A table depends on the tables its foreign keys reference
+// and a view depends on the tables it reads from.
+const (
+	DirectionUpstream   = "upstream"   // What the table depends on
+	DirectionDownstream = "downstream" // What depends on the table
+)
+
+// TableRef identifies a table in analytics results
+type TableRef struct {
+	TableID string `json:"table_id"`
+	Schema  string `json:"schema"`
+	Name    string `json:"name"`
+	IsView  bool   `json:"is_view"`
+}
+
+// ImpactedTable is a table in the transitive closure, with its distance and
+// the relationship or dependency it was first reached through
+type ImpactedTable struct {
+	TableRef
+	Depth int    `json:"depth"`
+	Via   string `json:"via"`
+}
+
+// ImpactResult is the transitive upstream or downstream closure of a table
+type ImpactResult struct {
+	DiagramID string          `json:"diagram_id"`
+	Table     TableRef        `json:"table"`
+	Direction string          `json:"direction"`
+	Tables    []ImpactedTable `json:"tables"`
+}
+
+// JoinStep is one relationship hop of a join path, oriented along the path.
+// A "many" ToCardinality means the join fans out.
+type JoinStep struct {
+	RelationshipID  string `json:"relationship_id"`
+	Name            string `json:"name"`
+	FromTableID     string `json:"from_table_id"`
+	FromTable       string `json:"from_table"`
+	FromFieldID     string `json:"from_field_id"`
+	FromField       string `json:"from_field"`
+	ToTableID       string `json:"to_table_id"`
+	ToTable         string `json:"to_table"`
+	ToFieldID       string `json:"to_field_id"`
+	ToField         string `json:"to_field"`
+	FromCardinality string `json:"from_cardinality"`
+	ToCardinality   string `json:"to_cardinality"`
+}
+
+// JoinPath is a sequence of relationship hops between two tables
+type JoinPath struct {
+	Length int        `json:"length"`
+	Steps  []JoinStep `json:"steps"`
+}
+
+// JoinPathResult lists the shortest join paths between two tables
+type JoinPathResult struct {
+	DiagramID string     `json:"diagram_id"`
+	From      TableRef   `json:"from"`
+	To        TableRef   `json:"to"`
+	Paths     []JoinPath `json:"paths"`
+}
+
+// Join types for generated queries
+const (
+	JoinInner = "inner"
+	JoinLeft  = "left"
+)
+
+// JoinQueryRequest asks for a SELECT joining tables along stored relationships.
+// TableIDs is the start table, optional waypoints and the end table, in order.
+type JoinQueryRequest struct {
+	DiagramID string
+	TableIDs  []string
+	Dialect   string // Defaults to the diagram's databaseType
+	JoinType  string // inner | left
+}
+
+// JoinQueryResult is a generated query with the path it follows
+type JoinQueryResult struct {
+	DiagramID string            `json:"diagram_id"`
+	Dialect   string            `json:"dialect"`
+	SQL       string            `json:"sql"`
+	Aliases   map[string]string `json:"aliases"` // Table ID -> alias
+	Steps     []JoinStep        `json:"steps"`
+	Warnings  []string          `json:"warnings"`
+}
+
+// CycleResult lists the strongly connected components of a diagram's dependency graph
+type CycleResult struct {
+	DiagramID  string       `json:"diagram_id"`
+	Components [][]TableRef `json:"components"`
+}
+
+// OrphanResult lists tables not connected to any other table
+type OrphanResult struct {
+	DiagramID string     `json:"diagram_id"`
+	Tables    []TableRef `json:"tables"`
+}
+
+// GraphUsecase answers impact-analysis questions about a diagram
+type GraphUsecase interface {
+	Impact(ctx context.Context, diagramID, tableID, direction string) (*ImpactResult, error)
+	JoinPaths(ctx context.Context, diagramID, fromTableID, toTableID string, limit int) (*JoinPathResult, error)
+	JoinQuery(ctx context.Context, req JoinQueryRequest) (*JoinQueryResult, error)
+	Cycles(ctx context.Context, diagramID string) (*CycleResult, error)
+	Orphans(ctx context.Context, diagramID string) (*OrphanResult, error)
+}
+
+// Ref returns the table's reference for analytics results
+func (t Table) Ref() TableRef {
+	return TableRef{TableID: t.TableID, Schema: t.Schema, Name: t.Name, IsView: t.IsView}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+)
+
+// Layout algorithms
+const (
+	LayoutLayered = "layered"
+	LayoutForce   = "force"
+	LayoutGrid    = "grid"
+)
+
+// LayoutRequest asks for new table positions on a diagram's canvas
+type LayoutRequest struct {
+	DiagramID string
+	Algorithm string
+	Preview   bool // Return coordinates without saving
+}
+
+// TablePosition is a table's top-left canvas position
+type TablePosition struct {
+	TableID string `json:"table_id"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+}
+
+// LayoutResult holds computed positions; areas are included when they were resized or their members changed
+type LayoutResult struct {
+	DiagramID string          `json:"diagram_id"`
+	Algorithm string          `json:"algorithm"`
+	Preview   bool            `json:"preview"`
+	Tables    []TablePosition `json:"tables"`
+	Areas     []Area          `json:"areas"`
+	Crossings int             `json:"crossings"`
+}
+
+// LayoutUsecase defines server-side automatic layout
+type LayoutUsecase interface {
+	Layout(ctx context.Context, req LayoutRequest) (*LayoutResult, error)
+}
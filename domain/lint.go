@@ -0,0 +1,27 @@
+package domain
+
+// Lint severities, most severe first
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// LintIssue is one finding of a lint rule; Table and Field are empty for
+// diagram-wide findings
+type LintIssue struct {
+	Rule     string    `json:"rule"`
+	Severity string    `json:"severity"`
+	Table    *TableRef `json:"table,omitempty"`
+	Field    string    `json:"field,omitempty"`
+	Message  string    `json:"message"`
+}
+
+// LintReport lists the issues found in a diagram, most severe first
+type LintReport struct {
+	DiagramID string      `json:"diagram_id"`
+	Errors    int         `json:"errors"`
+	Warnings  int         `json:"warnings"`
+	Infos     int         `json:"infos"`
+	Issues    []LintIssue `json:"issues"`
+}
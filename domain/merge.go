@@ -0,0 +1,51 @@
+package domain
+
+// Strategies for tables that exist in both diagrams (same schema + name)
+const (
+	MergeKeepTarget       = "keep-target"        // Drop the source table
+	MergeKeepSource       = "keep-source"        // Replace the target table's columns with the source's
+	MergeRenameWithSuffix = "rename-with-suffix" // Add the source table under a suffixed name
+	MergeFields           = "merge-fields"       // Add source columns missing from the target table
+)
+
+// MergeRequest describes merging a source diagram into a target diagram
+type MergeRequest struct {
+	SourceID string `json:"source_id"`
+	TargetID string `json:"target_id"`
+	Strategy string `json:"strategy"`
+	Suffix   string `json:"suffix"`   // Used by rename-with-suffix, defaults to "_merged"
+	OffsetX  *int   `json:"offset_x"` // Canvas offset for source entities; computed when nil
+	OffsetY  *int   `json:"offset_y"`
+	DryRun   bool   `json:"dry_run"` // Only compute the report
+}
+
+// MergeConflict records how one colliding table was resolved
+type MergeConflict struct {
+	Schema        string   `json:"schema"`
+	Name          string   `json:"name"`
+	SourceTableID string   `json:"source_table_id"`
+	TargetTableID string   `json:"target_table_id"`
+	Resolution    string   `json:"resolution"`
+	RenamedTo     string   `json:"renamed_to,omitempty"`
+	AddedFields   []string `json:"added_fields,omitempty"`
+	DroppedFields []string `json:"dropped_fields,omitempty"`
+}
+
+// MergeReport summarizes the result of a merge
+type MergeReport struct {
+	SourceID             string          `json:"source_id"`
+	TargetID             string          `json:"target_id"`
+	Strategy             string          `json:"strategy"`
+	DryRun               bool            `json:"dry_run"`
+	OffsetX              int             `json:"offset_x"`
+	OffsetY              int             `json:"offset_y"`
+	TablesAdded          int             `json:"tables_added"`
+	RelationshipsAdded   int             `json:"relationships_added"`
+	RelationshipsDropped int             `json:"relationships_dropped"`
+	DependenciesAdded    int             `json:"dependencies_added"`
+	AreasAdded           int             `json:"areas_added"`
+	NotesAdded           int             `json:"notes_added"`
+	CustomTypesAdded     int             `json:"custom_types_added"`
+	CustomTypesSkipped   []string        `json:"custom_types_skipped,omitempty"`
+	Conflicts            []MergeConflict `json:"conflicts"`
+}
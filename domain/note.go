@@ -26,4 +26,7 @@ type NoteRepository interface {
 	UpdateByDiagramID(ctx context.Context, diagramID string, notes []Note) error
 	GetByDiagramID(ctx context.Context, diagramID string) ([]Note, error)
 	DeleteByDiagramID(ctx context.Context, diagramID string) error
+	SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error
+	UndeleteByDiagramID(ctx context.Context, diagramID string) error
+	PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error
 }
@@ -22,6 +22,29 @@ type Relationship struct {
 	UpdatedAt           time.Time `bson:"updated_at" json:"updatedAt"`           // Convert to camelCase in JSON
 }
 
+// Cardinality values used by the frontend
+const (
+	CardinalityOne  = "one"
+	CardinalityMany = "many"
+)
+
+// ParentChild returns the referenced ("one" side) table and the referencing table.
+// The source side holds the foreign key unless the cardinalities say otherwise.
+func (r Relationship) ParentChild() (parentTableID, childTableID string) {
+	if r.SourceCardinality == CardinalityOne && r.TargetCardinality == CardinalityMany {
+		return r.SourceTableID, r.TargetTableID
+	}
+	return r.TargetTableID, r.SourceTableID
+}
+
+// ForeignKey returns the table and field holding the foreign key, i.e. the child side
+func (r Relationship) ForeignKey() (tableID, fieldID string) {
+	if r.SourceCardinality == CardinalityOne && r.TargetCardinality == CardinalityMany {
+		return r.TargetTableID, r.TargetFieldID
+	}
+	return r.SourceTableID, r.SourceFieldID
+}
+
 // RelationshipRepository defines methods for relationship data access
 type RelationshipRepository interface {
 	Store(ctx context.Context, r *Relationship) error
@@ -29,4 +52,7 @@ type RelationshipRepository interface {
 	UpdateByDiagramID(ctx context.Context, diagramID string, relationships []Relationship) error
 	GetByDiagramID(ctx context.Context, diagramID string) ([]Relationship, error)
 	DeleteByDiagramID(ctx context.Context, diagramID string) error
+	SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error
+	UndeleteByDiagramID(ctx context.Context, diagramID string) error
+	PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error
 }
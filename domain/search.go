@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+)
+
+// Entity types returned by the global search
+const (
+	SearchEntityTable      = "table"
+	SearchEntityField      = "field"
+	SearchEntityNote       = "note"
+	SearchEntityCustomType = "custom_type"
+)
+
+// SearchQuery is a full-text query across all diagrams
+type SearchQuery struct {
+	Text      string
+	Schema    string // Optional: only tables and custom types in this schema
+	DiagramID string // Optional: only entities of this diagram
+	Limit     int
+}
+
+// SearchHit is a ranked match pointing at an entity inside a diagram.
+// EntityID is the frontend ID of a table or field and the schema-qualified
+// name of a custom type. A note hit carries the note's document ID, which
+// changes whenever the diagram is saved since notes are stored anew each time.
+type SearchHit struct {
+	DiagramID  string  `json:"diagram_id"`
+	EntityType string  `json:"entity_type"`
+	EntityID   string  `json:"entity_id"`
+	TableID    string  `json:"table_id,omitempty"` // Owning table for field hits
+	Name       string  `json:"name"`
+	Schema     string  `json:"schema,omitempty"`
+	Snippet    string  `json:"snippet,omitempty"`
+	Score      float64 `json:"score"`
+}
+
+// SearchRepository defines full-text search over entity collections
+type SearchRepository interface {
+	Search(ctx context.Context, q SearchQuery) ([]SearchHit, error)
+}
+
+// SearchUsecase defines the global search business logic
+type SearchUsecase interface {
+	Search(ctx context.Context, q SearchQuery) ([]SearchHit, error)
+}
@@ -0,0 +1,96 @@
+package domain
+
+import "slices"
+
+// DiagramSnapshot bundles a diagram with all of its child entities
+type DiagramSnapshot struct {
+	Diagram       *Diagram       `json:"diagram"`
+	Tables        []Table        `json:"tables"`
+	Relationships []Relationship `json:"relationships"`
+	Dependencies  []Dependency   `json:"dependencies"`
+	Areas         []Area         `json:"areas"`
+	CustomTypes   []CustomType   `json:"custom_types"`
+	Notes         []Note         `json:"notes"`
+	Filter        *DiagramFilter `json:"diagram_filter,omitempty"`
+}
+
+// SnapshotScope narrows a snapshot to part of a diagram; empty lists don't restrict
+type SnapshotScope struct {
+	TableIDs []string
+	Schemas  []string
+	AreaIDs  []string
+}
+
+// IsEmpty reports whether the scope keeps everything
+func (sc SnapshotScope) IsEmpty() bool {
+	return len(sc.TableIDs) == 0 && len(sc.Schemas) == 0 && len(sc.AreaIDs) == 0
+}
+
+// Scoped returns a copy of the snapshot with only the tables in scope, the
+// relationships and dependencies between them, and the areas holding them.
+// With an area scope, notes are kept only when they lie inside a kept area.
+func (s *DiagramSnapshot) Scoped(sc SnapshotScope) *DiagramSnapshot {
+	if sc.IsEmpty() {
+		return s
+	}
+
+	out := *s
+	areas := s.Areas
+	if len(sc.AreaIDs) > 0 {
+		areas = make([]Area, 0, len(sc.AreaIDs))
+		for _, a := range s.Areas {
+			if slices.Contains(sc.AreaIDs, a.AreaID) {
+				areas = append(areas, a)
+			}
+		}
+	}
+
+	kept := make(map[string]bool)
+	out.Tables = make([]Table, 0)
+	for _, t := range s.Tables {
+		if len(sc.TableIDs) > 0 && !slices.Contains(sc.TableIDs, t.TableID) {
+			continue
+		}
+		if len(sc.Schemas) > 0 && !slices.Contains(sc.Schemas, t.Schema) {
+			continue
+		}
+		if len(sc.AreaIDs) > 0 && !slices.ContainsFunc(areas, func(a Area) bool { return a.Includes(t) }) {
+			continue
+		}
+		kept[t.TableID] = true
+		out.Tables = append(out.Tables, t)
+	}
+
+	out.Relationships = make([]Relationship, 0)
+	for _, r := range s.Relationships {
+		if kept[r.SourceTableID] && kept[r.TargetTableID] {
+			out.Relationships = append(out.Relationships, r)
+		}
+	}
+	out.Dependencies = make([]Dependency, 0)
+	for _, d := range s.Dependencies {
+		if kept[d.TableID] && kept[d.DependentTableID] {
+			out.Dependencies = append(out.Dependencies, d)
+		}
+	}
+
+	out.Areas = make([]Area, 0)
+	for _, a := range areas {
+		if len(sc.AreaIDs) > 0 || slices.ContainsFunc(out.Tables, a.Includes) {
+			out.Areas = append(out.Areas, a)
+		}
+	}
+
+	if len(sc.AreaIDs) > 0 {
+		out.Notes = make([]Note, 0)
+		for _, n := range s.Notes {
+			cx, cy := n.X+n.Width/2, n.Y+n.Height/2
+			if slices.ContainsFunc(out.Areas, func(a Area) bool {
+				return cx >= a.X && cx <= a.X+a.Width && cy >= a.Y && cy <= a.Y+a.Height
+			}) {
+				out.Notes = append(out.Notes, n)
+			}
+		}
+	}
+	return &out
+}
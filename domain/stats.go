@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"context"
+)
+
+// SchemaStats counts the entities of one schema
+type SchemaStats struct {
+	Schema        string `bson:"_id" json:"schema"`
+	Tables        int    `bson:"tables" json:"tables"`
+	Views         int    `bson:"views" json:"views"`
+	Fields        int    `bson:"fields" json:"fields"`
+	Indexes       int    `bson:"indexes" json:"indexes"`
+	Relationships int    `bson:"relationships" json:"relationships"` // Counted under the schema of the source table
+}
+
+// TableStats holds per-table size and connectivity. FanOut counts the
+// relationships in which the table holds the foreign key, FanIn those in
+// which it is referenced.
+type TableStats struct {
+	TableRef
+	Fields  int `json:"fields"`
+	Indexes int `json:"indexes"`
+	FanIn   int `json:"fan_in"`
+	FanOut  int `json:"fan_out"`
+}
+
+// CustomTypeUsage counts the fields typed with a custom type
+type CustomTypeUsage struct {
+	Schema string `json:"schema"`
+	Type   string `json:"type"`
+	Kind   string `json:"kind"`
+	Fields int    `json:"fields"`
+}
+
+// DiagramStats summarizes the size and complexity of a diagram.
+// CouplingScore is the average number of distinct tables each table is
+// related to; CrossSchemaRelationships link tables of different schemas.
+type DiagramStats struct {
+	DiagramID                string            `json:"diagram_id"`
+	Totals                   SchemaStats       `json:"totals"`
+	Schemas                  []SchemaStats     `json:"schemas"`
+	AvgFieldsPerTable        float64           `json:"avg_fields_per_table"`
+	MaxFieldsPerTable        int               `json:"max_fields_per_table"`
+	Tables                   []TableStats      `json:"tables"`
+	TablesWithoutIndexes     []TableRef        `json:"tables_without_indexes"`
+	CustomTypes              []CustomTypeUsage `json:"custom_types"`
+	CouplingScore            float64           `json:"coupling_score"`
+	CrossSchemaRelationships int               `json:"cross_schema_relationships"`
+}
+
+// KindCount is a custom type kind and how many custom types have it
+type KindCount struct {
+	Kind  string `bson:"_id" json:"kind"`
+	Count int    `bson:"count" json:"count"`
+}
+
+// WorkspaceStats aggregates all diagrams
+type WorkspaceStats struct {
+	Diagrams             int           `json:"diagrams"`
+	Totals               SchemaStats   `json:"totals"`
+	Schemas              []SchemaStats `json:"schemas"`
+	Dependencies         int           `json:"dependencies"`
+	Areas                int           `json:"areas"`
+	Notes                int           `json:"notes"`
+	CustomTypes          []KindCount   `json:"custom_types"`
+	AvgTablesPerDiagram  float64       `json:"avg_tables_per_diagram"`
+	AvgFieldsPerTable    float64       `json:"avg_fields_per_table"`
+	MaxFieldsPerTable    int           `json:"max_fields_per_table"`
+	TablesWithoutIndexes int           `json:"tables_without_indexes"`
+}
+
+// StatsRepository computes workspace-wide statistics in the database
+type StatsRepository interface {
+	Workspace(ctx context.Context) (*WorkspaceStats, error)
+}
+
+// StatsUsecase defines statistics-related business logic
+type StatsUsecase interface {
+	Diagram(ctx context.Context, diagramID string) (*DiagramStats, error)
+	Workspace(ctx context.Context) (*WorkspaceStats, error)
+}
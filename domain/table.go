@@ -15,6 +15,7 @@ type Table struct {
 	Fields    []map[string]interface{} `bson:"fields" json:"fields"`         // Array of field objects
 	Indexes   []map[string]interface{} `bson:"indexes" json:"indexes"`       // Array of index objects
 	Color     string                   `bson:"color" json:"color"`
+	Comments  string                   `bson:"comments,omitempty" json:"comments,omitempty"`
 	X         int                      `bson:"x" json:"x"`
 	Y         int                      `bson:"y" json:"y"`
 	IsView    bool                     `bson:"isView" json:"isView"`
@@ -23,6 +24,18 @@ type Table struct {
 	UpdatedAt time.Time                `bson:"updated_at" json:"updatedAt"`
 }
 
+// Approximate rendered table size on the canvas (matches the frontend defaults)
+const (
+	TableWidth        = 224
+	TableHeaderHeight = 42
+	TableFieldHeight  = 32
+)
+
+// Size returns the estimated canvas width and height of the table
+func (t Table) Size() (width, height int) {
+	return TableWidth, TableHeaderHeight + len(t.Fields)*TableFieldHeight
+}
+
 // TableRepository defines methods for table data access
 type TableRepository interface {
 	Store(ctx context.Context, t *Table) error
@@ -30,4 +43,10 @@ type TableRepository interface {
 	UpdateByDiagramID(ctx context.Context, diagramID string, tables []Table) error
 	GetByDiagramID(ctx context.Context, diagramID string) ([]Table, error)
 	DeleteByDiagramID(ctx context.Context, diagramID string) error
+	SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error
+	UndeleteByDiagramID(ctx context.Context, diagramID string) error
+	PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error
+	UpdatePositions(ctx context.Context, diagramID string, positions []TablePosition) error
+	// UpdateComments saves the comments and fields of the given tables only
+	UpdateComments(ctx context.Context, diagramID string, tables []Table) error
 }
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Template is a named, versioned set of reusable tables (audit columns, auth block, ...)
+type Template struct {
+	ID            string         `bson:"_id,omitempty" json:"id"`
+	Name          string         `bson:"name" json:"name"`
+	Description   string         `bson:"description" json:"description"`
+	Version       int            `bson:"version" json:"version"` // Incremented on every update
+	Tables        []Table        `bson:"tables" json:"tables"`
+	Relationships []Relationship `bson:"relationships" json:"relationships"`
+	CustomTypes   []CustomType   `bson:"custom_types" json:"custom_types"`
+	Areas         []Area         `bson:"areas" json:"areas"`
+	CreatedAt     time.Time      `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time      `bson:"updated_at" json:"updated_at"`
+}
+
+// TemplateFromDiagramRequest builds a template from a subset of a diagram's tables
+type TemplateFromDiagramRequest struct {
+	DiagramID   string   `json:"diagram_id"`
+	TableIDs    []string `json:"table_ids"` // Empty means every table
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+}
+
+// InstantiateTemplateRequest inserts a template into a diagram
+type InstantiateTemplateRequest struct {
+	DiagramID  string `json:"diagram_id"`
+	OffsetX    int    `json:"offset_x"` // Canvas position of the template's top-left corner
+	OffsetY    int    `json:"offset_y"`
+	Schema     string `json:"schema"`      // Optional: place all tables in this schema
+	NamePrefix string `json:"name_prefix"` // Optional: prefix for table names
+}
+
+// InstantiateTemplateResult lists what was inserted into the diagram
+type InstantiateTemplateResult struct {
+	DiagramID          string   `json:"diagram_id"`
+	TemplateID         string   `json:"template_id"`
+	TemplateVersion    int      `json:"template_version"`
+	TableIDs           []string `json:"table_ids"`
+	RelationshipsAdded int      `json:"relationships_added"`
+	CustomTypesAdded   int      `json:"custom_types_added"`
+	CustomTypesSkipped []string `json:"custom_types_skipped,omitempty"`
+	AreasAdded         int      `json:"areas_added"`
+}
+
+// TemplateRepository defines methods for template data access
+type TemplateRepository interface {
+	Fetch(ctx context.Context) ([]Template, error)
+	GetByID(ctx context.Context, id string) (*Template, error)
+	Store(ctx context.Context, t *Template) error
+	Update(ctx context.Context, t *Template) error
+	Delete(ctx context.Context, id string) error
+}
+
+// TemplateUsecase defines the template library business logic
+type TemplateUsecase interface {
+	GetAll(ctx context.Context) ([]Template, error)
+	GetOne(ctx context.Context, id string) (*Template, error)
+	Create(ctx context.Context, t *Template) (*Template, error)
+	CreateFromDiagram(ctx context.Context, req TemplateFromDiagramRequest) (*Template, error)
+	Update(ctx context.Context, t *Template) (*Template, error)
+	Delete(ctx context.Context, id string) error
+	Instantiate(ctx context.Context, id string, req InstantiateTemplateRequest) (*InstantiateTemplateResult, error)
+}
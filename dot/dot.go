@@ -0,0 +1,297 @@
+// Package dot writes diagrams in the Graphviz DOT language.
+//
+// The relationship graph draws every table as an HTML-like record listing its
+// fields, with edges between field ports ending in crow's-foot arrows. The
+// dependency graph shows which views depend on which tables. Both can group
+// nodes into clusters by area or schema.
+package dot
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Graph modes
+const (
+	ModeRelationships = "relationships"
+	ModeDependencies  = "dependencies"
+)
+
+// Cluster groupings
+const (
+	ClusterArea   = "area"
+	ClusterSchema = "schema"
+	ClusterNone   = "none"
+)
+
+// Format implements domain.Exporter. Options: mode (relationships or
+// dependencies), cluster (area, schema or none) and rankdir (LR, RL, TB, BT).
+type Format struct{}
+
+func (Format) ContentType() string { return "text/vnd.graphviz; charset=utf-8" }
+
+func (Format) Extension() string { return "dot" }
+
+func (Format) Export(snap *domain.DiagramSnapshot, opts map[string]string) ([]byte, error) {
+	mode := strings.ToLower(opts["mode"])
+	if mode == "" {
+		mode = ModeRelationships
+	}
+	cluster := strings.ToLower(opts["cluster"])
+	if cluster == "" {
+		cluster = ClusterArea
+	}
+	rankdir := strings.ToUpper(opts["rankdir"])
+	if rankdir == "" {
+		rankdir = "LR"
+	}
+
+	switch {
+	case mode != ModeRelationships && mode != ModeDependencies:
+		return nil, fmt.Errorf("%w: unknown mode %q", domain.ErrBadParamInput, mode)
+	case cluster != ClusterArea && cluster != ClusterSchema && cluster != ClusterNone:
+		return nil, fmt.Errorf("%w: unknown cluster %q", domain.ErrBadParamInput, cluster)
+	case rankdir != "LR" && rankdir != "RL" && rankdir != "TB" && rankdir != "BT":
+		return nil, fmt.Errorf("%w: unknown rankdir %q", domain.ErrBadParamInput, rankdir)
+	}
+
+	w := &writer{snap: snap, ports: make(map[string]string)}
+	name := "diagram"
+	if snap.Diagram != nil && snap.Diagram.Name != "" {
+		name = snap.Diagram.Name
+	}
+	w.printf("digraph %s {\n", quote(name))
+	w.printf("  graph [rankdir=%s, fontname=\"Helvetica\", label=%s, labelloc=t, nodesep=0.5, ranksep=1];\n", rankdir, quote(name))
+	w.printf("  node [fontname=\"Helvetica\", fontsize=11];\n")
+	w.printf("  edge [fontname=\"Helvetica\", fontsize=10, color=\"#64748b\"];\n\n")
+
+	if mode == ModeRelationships {
+		w.relationshipGraph(cluster)
+	} else {
+		w.dependencyGraph(cluster)
+	}
+	w.printf("}\n")
+	return []byte(w.b.String()), nil
+}
+
+type writer struct {
+	b     strings.Builder
+	snap  *domain.DiagramSnapshot
+	ports map[string]string // Field ID -> port name
+}
+
+func (w *writer) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&w.b, format, args...)
+}
+
+func (w *writer) relationshipGraph(cluster string) {
+	fks := make(map[string]bool)
+	for _, r := range w.snap.Relationships {
+		_, fieldID := r.ForeignKey()
+		fks[fieldID] = true
+	}
+
+	w.nodes(w.snap.Tables, cluster, func(t domain.Table, indent string) {
+		w.printf("%s%s [shape=plaintext, label=<%s>];\n", indent, quote(t.TableID), w.record(t, fks))
+	})
+
+	tables := make(map[string]bool, len(w.snap.Tables))
+	for _, t := range w.snap.Tables {
+		tables[t.TableID] = true
+	}
+	for _, r := range w.snap.Relationships {
+		if !tables[r.SourceTableID] || !tables[r.TargetTableID] {
+			continue
+		}
+		attrs := []string{
+			"dir=both",
+			"arrowtail=" + arrow(r.SourceCardinality),
+			"arrowhead=" + arrow(r.TargetCardinality),
+		}
+		if r.Name != "" {
+			attrs = append(attrs, "label="+quote(r.Name))
+		}
+		w.printf("  %s -> %s [%s];\n", w.endpoint(r.SourceTableID, r.SourceFieldID), w.endpoint(r.TargetTableID, r.TargetFieldID), strings.Join(attrs, ", "))
+	}
+}
+
+// dependencyGraph draws views and the tables they depend on; edges point from the view
+func (w *writer) dependencyGraph(cluster string) {
+	involved := make(map[string]bool, 2*len(w.snap.Dependencies))
+	for _, d := range w.snap.Dependencies {
+		involved[d.TableID] = true
+		involved[d.DependentTableID] = true
+	}
+	nodes := make([]domain.Table, 0)
+	for _, t := range w.snap.Tables {
+		if t.IsView || involved[t.TableID] {
+			nodes = append(nodes, t)
+		}
+	}
+
+	w.nodes(nodes, cluster, func(t domain.Table, indent string) {
+		style := `shape=box, style="filled", fillcolor="#e2e8f0"`
+		if t.IsView {
+			style = `shape=box, style="rounded,filled,dashed", fillcolor="#fef9c3"`
+		}
+		w.printf("%s%s [%s, label=%s];\n", indent, quote(t.TableID), style, quote(t.QualifiedName()))
+	})
+
+	known := make(map[string]bool, len(nodes))
+	for _, t := range nodes {
+		known[t.TableID] = true
+	}
+	for _, d := range w.snap.Dependencies {
+		if known[d.TableID] && known[d.DependentTableID] {
+			w.printf("  %s -> %s;\n", quote(d.DependentTableID), quote(d.TableID))
+		}
+	}
+}
+
+// nodes writes the nodes, wrapped in clusters by area or schema
+func (w *writer) nodes(tables []domain.Table, cluster string, node func(t domain.Table, indent string)) {
+	type group struct {
+		label, color string
+		tables       []domain.Table
+	}
+	groups := make([]*group, 0)
+	free := make([]domain.Table, 0)
+
+	switch cluster {
+	case ClusterArea:
+		placed := make(map[string]bool)
+		for _, a := range w.snap.Areas {
+			g := &group{label: a.Name, color: a.Color}
+			for _, t := range tables {
+				if !placed[t.TableID] && a.Includes(t) {
+					placed[t.TableID] = true
+					g.tables = append(g.tables, t)
+				}
+			}
+			if len(g.tables) > 0 {
+				groups = append(groups, g)
+			}
+		}
+		for _, t := range tables {
+			if !placed[t.TableID] {
+				free = append(free, t)
+			}
+		}
+	case ClusterSchema:
+		bySchema := make(map[string]*group)
+		for _, t := range tables {
+			if t.Schema == "" {
+				free = append(free, t)
+				continue
+			}
+			g, ok := bySchema[t.Schema]
+			if !ok {
+				g = &group{label: t.Schema}
+				bySchema[t.Schema] = g
+				groups = append(groups, g)
+			}
+			g.tables = append(g.tables, t)
+		}
+		sort.SliceStable(groups, func(i, j int) bool { return groups[i].label < groups[j].label })
+	default:
+		free = tables
+	}
+
+	for i, g := range groups {
+		w.printf("  subgraph cluster_%d {\n", i)
+		w.printf("    label=%s;\n    style=\"rounded,dashed\";\n", quote(g.label))
+		if strings.HasPrefix(g.color, "#") {
+			w.printf("    color=%s;\n    fontcolor=%s;\n", quote(g.color), quote(g.color))
+		}
+		for _, t := range g.tables {
+			node(t, "    ")
+		}
+		w.printf("  }\n\n")
+	}
+	for _, t := range free {
+		node(t, "  ")
+	}
+	w.printf("\n")
+}
+
+// record builds the HTML-like label of a table: a colored header and one row per field
+func (w *writer) record(t domain.Table, fks map[string]bool) string {
+	header := t.Color
+	if !strings.HasPrefix(header, "#") {
+		header = "#6366f1"
+	}
+
+	var b strings.Builder
+	b.WriteString(`<TABLE BORDER="0" CELLBORDER="1" CELLSPACING="0" CELLPADDING="4">`)
+	fmt.Fprintf(&b, `<TR><TD COLSPAN="3" BGCOLOR="%s"><FONT COLOR="%s"><B>%s</B></FONT></TD></TR>`,
+		html.EscapeString(header), textColor(header), html.EscapeString(t.QualifiedName()))
+
+	for i, f := range t.FieldList() {
+		port := "f" + strconv.Itoa(i)
+		w.ports[f.ID] = port
+
+		key := ""
+		switch {
+		case f.PrimaryKey && fks[f.ID]:
+			key = "PK FK"
+		case f.PrimaryKey:
+			key = "PK"
+		case fks[f.ID]:
+			key = "FK"
+		}
+		name := html.EscapeString(f.Name)
+		if f.PrimaryKey {
+			name = "<B>" + name + "</B>"
+		}
+		typ := html.EscapeString(f.Type)
+		if !f.Nullable {
+			typ += " NOT NULL"
+		}
+		if key != "" {
+			key = `<FONT POINT-SIZE="9">` + key + `</FONT>`
+		}
+		fmt.Fprintf(&b, `<TR><TD ALIGN="LEFT">%s</TD><TD ALIGN="LEFT" PORT="%s">%s</TD><TD ALIGN="LEFT"><FONT COLOR="#64748b">%s</FONT></TD></TR>`,
+			key, port, name, typ)
+	}
+	b.WriteString(`</TABLE>`)
+	return b.String()
+}
+
+// endpoint returns "table":"port", or just the table when the field is unknown
+func (w *writer) endpoint(tableID, fieldID string) string {
+	if port, ok := w.ports[fieldID]; ok {
+		return quote(tableID) + ":" + quote(port)
+	}
+	return quote(tableID)
+}
+
+// arrow maps a cardinality to a crow's-foot arrow shape
+func arrow(cardinality string) string {
+	if cardinality == domain.CardinalityMany {
+		return "crow"
+	}
+	return "tee"
+}
+
+// quote writes a DOT double-quoted string
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// textColor picks black or white text for a #rrggbb background
+func textColor(bg string) string {
+	v, err := strconv.ParseUint(strings.TrimPrefix(bg, "#"), 16, 32)
+	if err != nil || len(bg) != 7 {
+		return "#ffffff"
+	}
+	r, g, b := float64(v>>16&0xff), float64(v>>8&0xff), float64(v&0xff)
+	if 0.299*r+0.587*g+0.114*b > 160 {
+		return "#0f172a"
+	}
+	return "#ffffff"
+}
@@ -0,0 +1,169 @@
+// Package erd writes entity-relationship diagrams in text languages that
+// documentation tools render: Mermaid and PlantUML.
+//
+// Both use crow's-foot notation. The "many" side of a relationship is drawn
+// as zero-or-more; the "one" side referenced by a foreign key is mandatory
+// unless that foreign key is nullable. A relationship is identifying (solid
+// line) when its foreign key is part of the child's primary key.
+package erd
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// end is one side of a relationship
+type end struct {
+	many     bool
+	optional bool
+}
+
+// left is the crow's-foot symbol for an end written left of the line
+func (e end) left() string {
+	switch {
+	case e.many:
+		return "}o"
+	case e.optional:
+		return "|o"
+	default:
+		return "||"
+	}
+}
+
+// right is the crow's-foot symbol for an end written right of the line
+func (e end) right() string {
+	switch {
+	case e.many:
+		return "o{"
+	case e.optional:
+		return "o|"
+	default:
+		return "||"
+	}
+}
+
+// edge is a relationship resolved against its tables and fields
+type edge struct {
+	rel         domain.Relationship
+	source      domain.Table
+	target      domain.Table
+	sourceEnd   end
+	targetEnd   end
+	identifying bool
+	label       string
+}
+
+// model is a snapshot indexed for writing
+type model struct {
+	snap   *domain.DiagramSnapshot
+	tables map[string]domain.Table
+	ids    map[string]string // Table ID -> entity identifier
+	fks    map[string]bool   // Field IDs holding a foreign key
+	edges  []edge
+}
+
+func newModel(snap *domain.DiagramSnapshot) *model {
+	m := &model{
+		snap:   snap,
+		tables: make(map[string]domain.Table, len(snap.Tables)),
+		ids:    entityIDs(snap.Tables),
+		fks:    make(map[string]bool),
+		edges:  make([]edge, 0, len(snap.Relationships)),
+	}
+	for _, t := range snap.Tables {
+		m.tables[t.TableID] = t
+	}
+
+	for _, r := range snap.Relationships {
+		src, ok1 := m.tables[r.SourceTableID]
+		tgt, ok2 := m.tables[r.TargetTableID]
+		if !ok1 || !ok2 {
+			continue
+		}
+		childID, fkID := r.ForeignKey()
+		child := m.tables[childID]
+		fk, _ := child.FieldByID(fkID)
+		m.fks[fkID] = true
+
+		e := edge{
+			rel:         r,
+			source:      src,
+			target:      tgt,
+			sourceEnd:   end{many: r.SourceCardinality == domain.CardinalityMany},
+			targetEnd:   end{many: r.TargetCardinality == domain.CardinalityMany},
+			identifying: fk.PrimaryKey,
+			label:       r.Name,
+		}
+		// The parent side is optional when the foreign key may be null; a
+		// "one" child side in a one-to-one relationship is always optional
+		if childID == r.SourceTableID {
+			e.targetEnd.optional = fk.Nullable
+			e.sourceEnd.optional = !e.sourceEnd.many
+		} else {
+			e.sourceEnd.optional = fk.Nullable
+			e.targetEnd.optional = !e.targetEnd.many
+		}
+		if e.label == "" {
+			e.label = fk.Name
+		}
+		m.edges = append(m.edges, e)
+	}
+	return m
+}
+
+// keys returns the PK, FK and UK markers of a field
+func (m *model) keys(f domain.Field) []string {
+	keys := make([]string, 0, 2)
+	if f.PrimaryKey {
+		keys = append(keys, "PK")
+	}
+	if m.fks[f.ID] {
+		keys = append(keys, "FK")
+	}
+	if f.Unique && !f.PrimaryKey {
+		keys = append(keys, "UK")
+	}
+	return keys
+}
+
+var nonIdentChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// entityID returns an identifier for a table that both languages accept
+func entityID(t domain.Table) string {
+	name := t.Name
+	if t.Schema != "" {
+		name = t.Schema + "_" + t.Name
+	}
+	id := strings.Trim(nonIdentChars.ReplaceAllString(name, "_"), "_")
+	if id == "" || (id[0] >= '0' && id[0] <= '9') {
+		id = "t_" + id
+	}
+	return id
+}
+
+// entityIDs assigns unique identifiers, suffixing tables whose names collide
+func entityIDs(tables []domain.Table) map[string]string {
+	ids := make(map[string]string, len(tables))
+	used := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		id := entityID(t)
+		for n := 2; used[id]; n++ {
+			id = entityID(t) + "_" + strconv.Itoa(n)
+		}
+		used[id] = true
+		ids[t.TableID] = id
+	}
+	return ids
+}
+
+// columnType returns the field's SQL type; both notations require a type, so
+// fields without one are written as unknown
+func columnType(f domain.Field) string {
+	if f.Type == "" {
+		return "unknown" + f.SQLType()
+	}
+	return f.SQLType()
+}
@@ -0,0 +1,68 @@
+package erd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Mermaid implements domain.Exporter as a Mermaid erDiagram
+type Mermaid struct{}
+
+func (Mermaid) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (Mermaid) Extension() string { return "mmd" }
+
+// Mermaid attribute types and names are single words
+var mermaidWord = regexp.MustCompile(`[^A-Za-z0-9_\-\[\]()]+`)
+
+func (Mermaid) Export(snap *domain.DiagramSnapshot, _ map[string]string) ([]byte, error) {
+	m := newModel(snap)
+	var b strings.Builder
+
+	if snap.Diagram != nil && snap.Diagram.Name != "" {
+		fmt.Fprintf(&b, "---\ntitle: %s\n---\n", mermaidText(snap.Diagram.Name))
+	}
+	b.WriteString("erDiagram\n")
+
+	for _, t := range snap.Tables {
+		id := m.ids[t.TableID]
+		if name := t.QualifiedName(); name != id {
+			fmt.Fprintf(&b, "    %s[\"%s\"] {\n", id, mermaidText(name))
+		} else {
+			fmt.Fprintf(&b, "    %s {\n", id)
+		}
+		for _, f := range t.FieldList() {
+			// Commas would end the attribute, so decimal(10,2) is written decimal(10_2)
+			typ := mermaidWord.ReplaceAllString(strings.ReplaceAll(columnType(f), ",", "_"), "_")
+			line := "        " + typ + " " + mermaidWord.ReplaceAllString(f.Name, "_")
+			if keys := m.keys(f); len(keys) > 0 {
+				line += " " + strings.Join(keys, ", ")
+			}
+			if f.Comments != "" {
+				line += fmt.Sprintf(" \"%s\"", mermaidText(f.Comments))
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString("    }\n")
+	}
+
+	for _, e := range m.edges {
+		line := "--"
+		if !e.identifying {
+			line = ".."
+		}
+		fmt.Fprintf(&b, "    %s %s%s%s %s : \"%s\"\n",
+			m.ids[e.source.TableID], e.sourceEnd.left(), line, e.targetEnd.right(),
+			m.ids[e.target.TableID], mermaidText(e.label))
+	}
+	return []byte(b.String()), nil
+}
+
+// mermaidText makes text safe inside double quotes and on a single line
+func mermaidText(s string) string {
+	s = strings.ReplaceAll(s, `"`, "'")
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,125 @@
+package erd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// PlantUML implements domain.Exporter as a PlantUML entity diagram (IE notation)
+type PlantUML struct{}
+
+func (PlantUML) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (PlantUML) Extension() string { return "puml" }
+
+func (PlantUML) Export(snap *domain.DiagramSnapshot, _ map[string]string) ([]byte, error) {
+	m := newModel(snap)
+	var b strings.Builder
+
+	b.WriteString("@startuml\n")
+	if snap.Diagram != nil && snap.Diagram.Name != "" {
+		fmt.Fprintf(&b, "title %s\n", plantText(snap.Diagram.Name))
+	}
+	b.WriteString("hide circle\nhide empty members\nskinparam linetype ortho\n\n")
+
+	// Areas become packages; a table is drawn in the first area including it
+	drawn := make(map[string]bool, len(snap.Tables))
+	for _, a := range snap.Areas {
+		members := make([]domain.Table, 0)
+		for _, t := range snap.Tables {
+			if !drawn[t.TableID] && a.Includes(t) {
+				drawn[t.TableID] = true
+				members = append(members, t)
+			}
+		}
+		fmt.Fprintf(&b, "package \"%s\"%s {\n", plantText(a.Name), plantColor(a.Color))
+		for _, t := range members {
+			m.plantEntity(&b, t, "  ")
+		}
+		b.WriteString("}\n\n")
+	}
+	for _, t := range snap.Tables {
+		if !drawn[t.TableID] {
+			m.plantEntity(&b, t, "")
+			b.WriteString("\n")
+		}
+	}
+
+	for _, e := range m.edges {
+		line := "--"
+		if !e.identifying {
+			line = ".."
+		}
+		fmt.Fprintf(&b, "%s %s%s%s %s : %s\n",
+			m.ids[e.source.TableID], e.sourceEnd.left(), line, e.targetEnd.right(),
+			m.ids[e.target.TableID], plantText(e.label))
+	}
+
+	for _, t := range snap.Tables {
+		if t.Comments != "" {
+			fmt.Fprintf(&b, "\nnote bottom of %s\n%s\nend note\n", m.ids[t.TableID], t.Comments)
+		}
+	}
+	for i, n := range snap.Notes {
+		fmt.Fprintf(&b, "\nnote as N%d%s\n%s\nend note\n", i+1, plantColor(n.Color), n.Content)
+	}
+
+	b.WriteString("@enduml\n")
+	return []byte(b.String()), nil
+}
+
+// plantEntity writes a table with its primary key fields above the separator
+func (m *model) plantEntity(b *strings.Builder, t domain.Table, indent string) {
+	fmt.Fprintf(b, "%sentity \"%s\" as %s%s {\n", indent, plantText(t.QualifiedName()), m.ids[t.TableID], plantColor(t.Color))
+
+	fields := t.FieldList()
+	pk, rest := make([]domain.Field, 0), make([]domain.Field, 0, len(fields))
+	for _, f := range fields {
+		if f.PrimaryKey {
+			pk = append(pk, f)
+		} else {
+			rest = append(rest, f)
+		}
+	}
+	for _, f := range pk {
+		m.plantField(b, f, indent)
+	}
+	if len(pk) > 0 && len(rest) > 0 {
+		b.WriteString(indent + "  --\n")
+	}
+	for _, f := range rest {
+		m.plantField(b, f, indent)
+	}
+	b.WriteString(indent + "}\n")
+}
+
+// plantField writes "* name : type <<PK>>"; the star marks a mandatory field
+func (m *model) plantField(b *strings.Builder, f domain.Field, indent string) {
+	line := indent + "  "
+	if !f.Nullable || f.PrimaryKey {
+		line += "* "
+	}
+	line += plantText(f.Name) + " : " + plantText(columnType(f))
+	for _, k := range m.keys(f) {
+		line += " <<" + k + ">>"
+	}
+	if f.Comments != "" {
+		line += " <i>" + plantText(f.Comments) + "</i>"
+	}
+	b.WriteString(line + "\n")
+}
+
+// plantText keeps text on one line
+func plantText(s string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, `"`, "'")), " ")
+}
+
+// plantColor returns " #rrggbb" for hex colors and nothing otherwise
+func plantColor(c string) string {
+	if !strings.HasPrefix(c, "#") || len(c) < 4 {
+		return ""
+	}
+	return " " + c
+}
@@ -0,0 +1,276 @@
+// Package graph holds directed-graph algorithms used for impact analysis:
+// reachability, shortest paths, strongly connected components and isolated
+// nodes. Nodes and edges are plain string IDs, independent of the domain model.
+package graph
+
+import (
+	"sort"
+)
+
+// Edge is a directed edge: From depends on To. ID identifies the record the edge came from.
+type Edge struct {
+	ID   string
+	From string
+	To   string
+}
+
+// Step is a node reached from a start node, with its distance and the edge it was reached by
+type Step struct {
+	Node  string
+	Depth int
+	Via   string
+}
+
+// Graph is an immutable directed multigraph
+type Graph struct {
+	nodes []string
+	index map[string]int
+	edges []Edge
+	out   [][]int // Edge indices leaving each node
+	in    [][]int // Edge indices entering each node
+}
+
+// New builds a graph; edges with unknown endpoints are ignored
+func New(nodes []string, edges []Edge) *Graph {
+	g := &Graph{
+		nodes: append([]string{}, nodes...),
+		index: make(map[string]int, len(nodes)),
+		out:   make([][]int, len(nodes)),
+		in:    make([][]int, len(nodes)),
+	}
+	for i, id := range g.nodes {
+		g.index[id] = i
+	}
+	for _, e := range edges {
+		from, ok1 := g.index[e.From]
+		to, ok2 := g.index[e.To]
+		if !ok1 || !ok2 {
+			continue
+		}
+		g.edges = append(g.edges, e)
+		g.out[from] = append(g.out[from], len(g.edges)-1)
+		g.in[to] = append(g.in[to], len(g.edges)-1)
+	}
+	return g
+}
+
+// Has reports whether id is a node of the graph
+func (g *Graph) Has(id string) bool {
+	_, ok := g.index[id]
+	return ok
+}
+
+// Reachable returns every node reachable from start in breadth-first order,
+// following edges forwards, or backwards when reverse is set. The start node
+// itself is not included.
+func (g *Graph) Reachable(start string, reverse bool) []Step {
+	s, ok := g.index[start]
+	if !ok {
+		return nil
+	}
+
+	adj, next := g.out, func(e Edge) string { return e.To }
+	if reverse {
+		adj, next = g.in, func(e Edge) string { return e.From }
+	}
+
+	seen := map[int]bool{s: true}
+	queue := []Step{{Node: start}}
+	steps := make([]Step, 0)
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, ei := range adj[g.index[cur.Node]] {
+			e := g.edges[ei]
+			n := g.index[next(e)]
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			step := Step{Node: g.nodes[n], Depth: cur.Depth + 1, Via: e.ID}
+			steps = append(steps, step)
+			queue = append(queue, step)
+		}
+	}
+	return steps
+}
+
+// ShortestPaths returns up to limit shortest paths between from and to,
+// ignoring edge direction. Each path is the list of edges walked, in order.
+func (g *Graph) ShortestPaths(from, to string, limit int) [][]Edge {
+	s, ok1 := g.index[from]
+	t, ok2 := g.index[to]
+	if !ok1 || !ok2 || limit <= 0 {
+		return nil
+	}
+	if s == t {
+		return [][]Edge{{}}
+	}
+
+	// BFS from the start, recording every edge that reaches a node on a shortest route
+	dist := make([]int, len(g.nodes))
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[s] = 0
+	parents := make([][]int, len(g.nodes)) // Edge indices into each node
+	queue := []int{s}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		if dist[t] >= 0 && dist[u] >= dist[t] {
+			break
+		}
+		for _, ei := range g.incident(u) {
+			v := g.other(ei, u)
+			switch {
+			case dist[v] < 0:
+				dist[v] = dist[u] + 1
+				parents[v] = append(parents[v], ei)
+				queue = append(queue, v)
+			case dist[v] == dist[u]+1:
+				parents[v] = append(parents[v], ei)
+			}
+		}
+	}
+	if dist[t] < 0 {
+		return nil
+	}
+
+	// Walk back from the target, depth first, until enough paths are collected
+	paths := make([][]Edge, 0)
+	var walk func(v int, suffix []Edge)
+	walk = func(v int, suffix []Edge) {
+		if len(paths) >= limit {
+			return
+		}
+		if v == s {
+			path := make([]Edge, len(suffix))
+			for i := range suffix {
+				path[i] = suffix[len(suffix)-1-i]
+			}
+			paths = append(paths, path)
+			return
+		}
+		for _, ei := range parents[v] {
+			walk(g.other(ei, v), append(suffix, g.edges[ei]))
+		}
+	}
+	walk(t, nil)
+	return paths
+}
+
+// StronglyConnected returns the components that contain a cycle: two or more
+// nodes, or a single node with an edge to itself. Components are sorted by
+// size, largest first, with members in input order.
+func (g *Graph) StronglyConnected() [][]string {
+	n := len(g.nodes)
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
+	}
+	stack := make([]int, 0)
+	counter := 0
+	components := make([][]int, 0)
+
+	// Iterative Tarjan: frames hold the node and the next out-edge to visit
+	type frame struct{ v, next int }
+	for root := 0; root < n; root++ {
+		if index[root] >= 0 {
+			continue
+		}
+		call := []frame{{v: root}}
+		index[root], low[root] = counter, counter
+		counter++
+		stack = append(stack, root)
+		onStack[root] = true
+
+		for len(call) > 0 {
+			top := &call[len(call)-1]
+			v := top.v
+			if top.next < len(g.out[v]) {
+				w := g.index[g.edges[g.out[v][top.next]].To]
+				top.next++
+				if index[w] < 0 {
+					index[w], low[w] = counter, counter
+					counter++
+					stack = append(stack, w)
+					onStack[w] = true
+					call = append(call, frame{v: w})
+				} else if onStack[w] {
+					low[v] = min(low[v], index[w])
+				}
+				continue
+			}
+
+			call = call[:len(call)-1]
+			if len(call) > 0 {
+				parent := call[len(call)-1].v
+				low[parent] = min(low[parent], low[v])
+			}
+			if low[v] == index[v] {
+				comp := make([]int, 0)
+				for {
+					w := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[w] = false
+					comp = append(comp, w)
+					if w == v {
+						break
+					}
+				}
+				if len(comp) > 1 || g.hasSelfLoop(v) {
+					components = append(components, comp)
+				}
+			}
+		}
+	}
+
+	out := make([][]string, 0, len(components))
+	for _, comp := range components {
+		sort.Ints(comp)
+		ids := make([]string, 0, len(comp))
+		for _, v := range comp {
+			ids = append(ids, g.nodes[v])
+		}
+		out = append(out, ids)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i]) > len(out[j]) })
+	return out
+}
+
+// Isolated returns the nodes without any edge, in input order
+func (g *Graph) Isolated() []string {
+	out := make([]string, 0)
+	for i, id := range g.nodes {
+		if len(g.out[i]) == 0 && len(g.in[i]) == 0 {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// incident returns all edge indices touching v, outgoing first
+func (g *Graph) incident(v int) []int {
+	return append(append([]int{}, g.out[v]...), g.in[v]...)
+}
+
+// other returns the endpoint of edge ei that is not v
+func (g *Graph) other(ei, v int) int {
+	e := g.edges[ei]
+	if from := g.index[e.From]; from != v {
+		return from
+	}
+	return g.index[e.To]
+}
+
+func (g *Graph) hasSelfLoop(v int) bool {
+	for _, ei := range g.out[v] {
+		if g.index[g.edges[ei].To] == v {
+			return true
+		}
+	}
+	return false
+}
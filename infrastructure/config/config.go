@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +12,11 @@ type Config struct {
 	Port     string
 	MongoURI string
 	DBName   string
+
+	// Deleted diagrams are purged once they have been in the trash for
+	// TrashRetention; 0 keeps them forever
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
 }
 
 // LoadConfig อ่านค่าจาก .env และ Environment Variables
@@ -24,6 +30,9 @@ func LoadConfig() *Config {
 		Port:     getEnv("PORT", "8080"),
 		MongoURI: getEnv("MONGO_URI", "mongodb://localhost:27017"),
 		DBName:   getEnv("DB_NAME", "vertex_db"),
+
+		TrashRetention:     getDuration("TRASH_RETENTION", 30*24*time.Hour),
+		TrashPurgeInterval: getDuration("TRASH_PURGE_INTERVAL", time.Hour),
 	}
 }
 
@@ -33,4 +42,18 @@ func getEnv(key, fallback string) string {
 		return value
 	}
 	return fallback
-}
\ No newline at end of file
+}
+
+// getDuration อ่านค่าแบบ time.ParseDuration เช่น "720h" หรือ "15m"
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Printf("⚠️  Invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
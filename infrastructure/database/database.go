@@ -2,11 +2,16 @@ package database
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/iots1/vertex-diagram/metrics"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -24,7 +29,7 @@ func GetMongoClient(uri string) (*mongo.Client, error) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		clientOptions := options.Client().ApplyURI(uri)
+		clientOptions := options.Client().ApplyURI(uri).SetMonitor(commandMonitor())
 		client, err := mongo.Connect(ctx, clientOptions)
 		if err != nil {
 			mongoError = err
@@ -45,25 +50,159 @@ func GetMongoClient(uri string) (*mongo.Client, error) {
 	return clientInstance, mongoError
 }
 
+// collectionIndex is an index CreateIndexes maintains on a collection
+type collectionIndex struct {
+	collection string
+	model      mongo.IndexModel
+}
+
+// indexes lists every index the repositories' queries rely on
+func indexes() []collectionIndex {
+	var out []collectionIndex
+
+	// Every collection with a diagram_id foreign key
+	for _, name := range []string{"tables", "relationships", "dependencies", "areas", "custom_types", "notes", "diagram_filters"} {
+		out = append(out, collectionIndex{name, mongo.IndexModel{Keys: bson.D{{Key: "diagram_id", Value: 1}}}})
+	}
+
+	// Text indexes for global search (one text index per collection)
+	textIndexes := []struct {
+		collection string
+		keys       bson.D
+	}{
+		{"tables", bson.D{
+			{Key: "name", Value: "text"},
+			{Key: "schema", Value: "text"},
+			{Key: "fields.name", Value: "text"},
+			{Key: "fields.comments", Value: "text"},
+		}},
+		{"notes", bson.D{
+			{Key: "content", Value: "text"},
+		}},
+		{"custom_types", bson.D{
+			{Key: "type", Value: "text"},
+			{Key: "schema", Value: "text"},
+			{Key: "values", Value: "text"},
+			{Key: "fields.field", Value: "text"},
+		}},
+	}
+	for _, ti := range textIndexes {
+		out = append(out, collectionIndex{ti.collection, mongo.IndexModel{
+			Keys:    ti.keys,
+			Options: options.Index().SetName(ti.collection + "_text"),
+		}})
+	}
+
+	// Workspace statistics look up relationship endpoints by table ID
+	out = append(out, collectionIndex{"tables", mongo.IndexModel{Keys: bson.D{{Key: "table_id", Value: 1}, {Key: "diagram_id", Value: 1}}}})
+
+	// Diagram listing sorts by these fields with _id as tie-breaker
+	for _, keys := range []bson.D{
+		{{Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}},
+		{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+		{{Key: "name", Value: 1}, {Key: "_id", Value: 1}},
+	} {
+		out = append(out, collectionIndex{"diagrams", mongo.IndexModel{Keys: keys}})
+	}
+	return out
+}
+
+// name returns the index name, which MongoDB derives from the keys unless set
+func (ci collectionIndex) name() string {
+	if ci.model.Options != nil && ci.model.Options.Name != nil {
+		return *ci.model.Options.Name
+	}
+	parts := make([]string, 0, len(ci.model.Keys.(bson.D)))
+	for _, k := range ci.model.Keys.(bson.D) {
+		parts = append(parts, fmt.Sprintf("%s_%v", k.Key, k.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
 // CreateIndexes creates indexes for all collections with diagram_id foreign key
 func CreateIndexes(db *mongo.Database) error {
-	collections := []string{"tables", "relationships", "dependencies", "areas", "custom_types", "notes", "diagram_filters"}
-
-	for _, collectionName := range collections {
-		_, err := db.Collection(collectionName).Indexes().CreateOne(
-			context.Background(),
-			mongo.IndexModel{
-				Keys: bson.D{{Key: "diagram_id", Value: 1}},
-			},
-		)
-		if err != nil {
+	for _, ci := range indexes() {
+		if _, err := db.Collection(ci.collection).Indexes().CreateOne(context.Background(), ci.model); err != nil {
 			return err
 		}
 	}
-
 	return nil
 }
 
+// CheckIndexes returns a readiness check that fails while any index
+// CreateIndexes maintains is missing, e.g. before it has run against db
+func CheckIndexes(db *mongo.Database) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		existing := make(map[string]map[string]bool)
+		for _, ci := range indexes() {
+			names, ok := existing[ci.collection]
+			if !ok {
+				specs, err := db.Collection(ci.collection).Indexes().ListSpecifications(ctx)
+				if err != nil {
+					return err
+				}
+				names = make(map[string]bool, len(specs))
+				for _, spec := range specs {
+					names[spec.Name] = true
+				}
+				existing[ci.collection] = names
+			}
+			if !names[ci.name()] {
+				return fmt.Errorf("index %s on %s is missing", ci.name(), ci.collection)
+			}
+		}
+		return nil
+	}
+}
+
+// Ping checks that the singleton client is connected and MongoDB answers
+func Ping(ctx context.Context) error {
+	if clientInstance == nil {
+		if mongoError != nil {
+			return mongoError
+		}
+		return errors.New("MongoDB client is not connected")
+	}
+	return clientInstance.Ping(ctx, nil)
+}
+
+// commandMonitor records the duration and failures of every command sent to
+// a collection. The collection is read from the command document when it
+// starts, since the finished events only carry the command name
+func commandMonitor() *event.CommandMonitor {
+	var collections sync.Map // Request ID -> collection name
+
+	finished := func(evt event.CommandFinishedEvent, failed bool) {
+		name, ok := collections.LoadAndDelete(evt.RequestID)
+		if !ok {
+			return
+		}
+		metrics.MongoDuration.Observe(evt.Duration.Seconds(), name.(string), evt.CommandName)
+		if failed {
+			metrics.MongoErrors.Inc(name.(string), evt.CommandName)
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			key := evt.CommandName
+			if key == "getMore" {
+				key = "collection"
+			}
+			// Commands such as ping and hello have no collection and aren't recorded
+			if name, ok := evt.Command.Lookup(key).StringValueOK(); ok && name != "" {
+				collections.Store(evt.RequestID, name)
+			}
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			finished(evt.CommandFinishedEvent, false)
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			finished(evt.CommandFinishedEvent, true)
+		},
+	}
+}
+
 // CloseMongoDB ปิด Connection เมื่อจบโปรแกรม
 func CloseMongoDB() {
 	if clientInstance != nil {
@@ -0,0 +1,62 @@
+// Package exchangetest holds the checks shared by the tests of the schema
+// import and export packages (dbml, prisma and ddl).
+package exchangetest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/iots1/vertex-diagram/diff"
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// IntPtr returns a pointer to n, for the optional precision and scale of a field
+func IntPtr(n int) *int { return &n }
+
+// Relationships describes each relationship as
+// source.field card -> target.field card, so cases don't depend on IDs
+func Relationships(snap *domain.DiagramSnapshot) []string {
+	name := func(tableID, fieldID string) string {
+		for _, t := range snap.Tables {
+			if t.TableID == tableID {
+				f, _ := t.FieldByID(fieldID)
+				return t.Name + "." + f.Name
+			}
+		}
+		return "?"
+	}
+	out := make([]string, 0, len(snap.Relationships))
+	for _, r := range snap.Relationships {
+		out = append(out, fmt.Sprintf("%s %s -> %s %s", name(r.SourceTableID, r.SourceFieldID), r.SourceCardinality,
+			name(r.TargetTableID, r.TargetFieldID), r.TargetCardinality))
+	}
+	return out
+}
+
+// RoundTrip writes first, parses the output again and reports warnings and
+// any change to the schema, the relationships or the database type. It
+// returns the second snapshot and the output for format-specific checks.
+func RoundTrip(t *testing.T, first *domain.DiagramSnapshot, write func(*domain.DiagramSnapshot) []byte,
+	parse func(string) (*domain.DiagramSnapshot, []string, error)) (*domain.DiagramSnapshot, []byte) {
+	t.Helper()
+	out := write(first)
+	second, warnings, err := parse(string(out))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("warnings: %q\n%s", warnings, out)
+	}
+
+	if d := diff.Compare(first, second); !d.Identical {
+		t.Errorf("round trip changed the schema: %+v\n%s", d, out)
+	}
+	if from, to := Relationships(first), Relationships(second); !slices.Equal(from, to) {
+		t.Errorf("relationships %q became %q", from, to)
+	}
+	if from, to := domain.MapString(first.Diagram.Content, "databaseType"), domain.MapString(second.Diagram.Content, "databaseType"); from != to {
+		t.Errorf("database type %q became %q\n%s", from, to, out)
+	}
+	return second, out
+}
@@ -0,0 +1,143 @@
+package layout
+
+import (
+	"sort"
+)
+
+// Communities groups nodes with the Louvain method: nodes move to the
+// neighboring community with the best modularity gain, then communities are
+// merged into single nodes and the process repeats until nothing moves.
+// Edges are undirected; parallel edges add weight. Groups are returned
+// largest first, members in input order.
+func Communities(ids []string, edges []Edge) [][]string {
+	index := make(map[string]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	// Symmetric weighted adjacency of the current level
+	adj := make([]map[int]float64, len(ids))
+	for i := range adj {
+		adj[i] = make(map[int]float64)
+	}
+	for _, e := range edges {
+		a, ok1 := index[e.From]
+		b, ok2 := index[e.To]
+		if !ok1 || !ok2 || a == b {
+			continue
+		}
+		adj[a][b]++
+		adj[b][a]++
+	}
+
+	// member[i] is the current-level node that original node i belongs to
+	member := make([]int, len(ids))
+	for i := range member {
+		member[i] = i
+	}
+
+	for {
+		community, moved := louvainPass(adj)
+		if !moved {
+			break
+		}
+
+		// Renumber communities densely in order of first appearance
+		renumber := make(map[int]int)
+		for _, c := range community {
+			if _, ok := renumber[c]; !ok {
+				renumber[c] = len(renumber)
+			}
+		}
+		for i := range member {
+			member[i] = renumber[community[member[i]]]
+		}
+
+		// Aggregate: one node per community, internal edges become self loops
+		next := make([]map[int]float64, len(renumber))
+		for i := range next {
+			next[i] = make(map[int]float64)
+		}
+		for i, neighbors := range adj {
+			ci := renumber[community[i]]
+			for j, w := range neighbors {
+				next[ci][renumber[community[j]]] += w
+			}
+		}
+		adj = next
+	}
+
+	groups := make(map[int][]string)
+	order := make([]int, 0)
+	for i, id := range ids {
+		c := member[i]
+		if _, ok := groups[c]; !ok {
+			order = append(order, c)
+		}
+		groups[c] = append(groups[c], id)
+	}
+
+	out := make([][]string, 0, len(order))
+	for _, c := range order {
+		out = append(out, groups[c])
+	}
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i]) > len(out[j]) })
+	return out
+}
+
+// louvainPass runs local moves until no node changes community
+func louvainPass(adj []map[int]float64) ([]int, bool) {
+	n := len(adj)
+	community := make([]int, n)
+	degree := make([]float64, n)
+	total := make([]float64, n) // Sum of degrees per community
+	m2 := 0.0
+	for i, neighbors := range adj {
+		community[i] = i
+		for _, w := range neighbors {
+			degree[i] += w
+		}
+		total[i] = degree[i]
+		m2 += degree[i]
+	}
+	if m2 == 0 {
+		return community, false
+	}
+
+	moved := false
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < n; i++ {
+			current := community[i]
+			total[current] -= degree[i]
+
+			// Weight from i into each neighboring community
+			links := make(map[int]float64)
+			for j, w := range adj[i] {
+				if j != i {
+					links[community[j]] += w
+				}
+			}
+
+			best, bestGain := current, links[current]-total[current]*degree[i]/m2
+			candidates := make([]int, 0, len(links))
+			for c := range links {
+				candidates = append(candidates, c)
+			}
+			sort.Ints(candidates)
+			for _, c := range candidates {
+				if gain := links[c] - total[c]*degree[i]/m2; gain > bestGain+1e-12 {
+					best, bestGain = c, gain
+				}
+			}
+
+			total[best] += degree[i]
+			if best != current {
+				community[i] = best
+				improved = true
+				moved = true
+			}
+		}
+	}
+	return community, moved
+}
@@ -0,0 +1,158 @@
+package layout
+
+import (
+	"math"
+)
+
+const (
+	forceIterations   = 300
+	overlapIterations = 100
+	forceGravity      = 0.5
+)
+
+// Force is a Fruchterman-Reingold layout: connected nodes attract, all nodes
+// repel and a weak gravity pulls towards the center, followed by an overlap
+// removal pass for the table rectangles
+func Force(nodes []Node, edges []Edge) Result {
+	res := Result{Positions: make(map[string]Point, len(nodes))}
+	n := len(nodes)
+	if n == 0 {
+		return res
+	}
+
+	_, adj := indexGraph(nodes, edges)
+
+	// Ideal edge length from the average node footprint
+	avg := 0.0
+	for _, node := range nodes {
+		avg += math.Max(float64(node.Width), float64(node.Height))
+	}
+	avg /= float64(n)
+	k := avg + HorizontalGap
+
+	// Deterministic start on a circle
+	px := make([]float64, n)
+	py := make([]float64, n)
+	radius := k * math.Sqrt(float64(n))
+	for i := range nodes {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		px[i] = radius * math.Cos(angle)
+		py[i] = radius * math.Sin(angle)
+	}
+
+	dx := make([]float64, n)
+	dy := make([]float64, n)
+	temperature := radius / 2
+	cooling := temperature / float64(forceIterations+1)
+
+	for iter := 0; iter < forceIterations; iter++ {
+		for i := range dx {
+			dx[i], dy[i] = 0, 0
+		}
+
+		// Repulsion between every pair
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				ddx, ddy := px[i]-px[j], py[i]-py[j]
+				dist := math.Hypot(ddx, ddy)
+				if dist < 1 {
+					// Separate coincident nodes in a fixed direction
+					ddx, ddy, dist = float64(j-i), 1, math.Hypot(float64(j-i), 1)
+				}
+				f := k * k / dist
+				dx[i] += ddx / dist * f
+				dy[i] += ddy / dist * f
+				dx[j] -= ddx / dist * f
+				dy[j] -= ddy / dist * f
+			}
+		}
+
+		// Attraction along edges
+		for u := range adj {
+			for _, v := range adj[u] {
+				ddx, ddy := px[u]-px[v], py[u]-py[v]
+				dist := math.Hypot(ddx, ddy)
+				if dist < 1 {
+					continue
+				}
+				f := dist * dist / k
+				dx[u] -= ddx / dist * f
+				dy[u] -= ddy / dist * f
+				dx[v] += ddx / dist * f
+				dy[v] += ddy / dist * f
+			}
+		}
+
+		// Gravity keeps disconnected components close to each other
+		for i := 0; i < n; i++ {
+			dx[i] -= px[i] * forceGravity
+			dy[i] -= py[i] * forceGravity
+		}
+
+		// Move, limited by the temperature
+		for i := 0; i < n; i++ {
+			disp := math.Hypot(dx[i], dy[i])
+			if disp < 1e-9 {
+				continue
+			}
+			step := math.Min(disp, temperature)
+			px[i] += dx[i] / disp * step
+			py[i] += dy[i] / disp * step
+		}
+		temperature -= cooling
+	}
+
+	// Positions are centers so far; convert to top-left corners
+	for i := range nodes {
+		px[i] -= float64(nodes[i].Width) / 2
+		py[i] -= float64(nodes[i].Height) / 2
+	}
+	removeOverlaps(nodes, px, py)
+
+	for i, node := range nodes {
+		res.Positions[node.ID] = Point{X: int(math.Round(px[i])), Y: int(math.Round(py[i]))}
+	}
+	normalize(nodes, res.Positions)
+	res.Width, res.Height = extent(nodes, res.Positions)
+	return res
+}
+
+// removeOverlaps pushes overlapping rectangles apart along the axis of least overlap
+func removeOverlaps(nodes []Node, px, py []float64) {
+	const margin = HorizontalGap / 2
+	for iter := 0; iter < overlapIterations; iter++ {
+		moved := false
+		for i := range nodes {
+			for j := i + 1; j < len(nodes); j++ {
+				ox := math.Min(px[i]+float64(nodes[i].Width), px[j]+float64(nodes[j].Width)) - math.Max(px[i], px[j]) + margin
+				oy := math.Min(py[i]+float64(nodes[i].Height), py[j]+float64(nodes[j].Height)) - math.Max(py[i], py[j]) + margin
+				if ox <= 0 || oy <= 0 {
+					continue
+				}
+				moved = true
+				if ox < oy {
+					shift := ox / 2
+					if px[i] < px[j] || (px[i] == px[j] && i < j) {
+						px[i] -= shift
+						px[j] += shift
+					} else {
+						px[i] += shift
+						px[j] -= shift
+					}
+				} else {
+					shift := oy / 2
+					if py[i] < py[j] || (py[i] == py[j] && i < j) {
+						py[i] -= shift
+						py[j] += shift
+					} else {
+						py[i] += shift
+						py[j] -= shift
+					}
+				}
+			}
+		}
+		if !moved {
+			return
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package layout
+
+import (
+	"sort"
+)
+
+const (
+	orderingSweeps  = 12
+	placementPasses = 8
+	dummyWidth      = 20
+	dummyGap        = 20
+)
+
+// vertex is a node of the layered graph; dummies (node < 0) route long edges
+type vertex struct {
+	node   int
+	width  int
+	height int
+	layer  int
+	pred   []int
+	succ   []int
+}
+
+// Layered is a Sugiyama-style layout: parents above children, with layers
+// ordered by the barycenter heuristic to minimize edge crossings
+func Layered(nodes []Node, edges []Edge) Result {
+	res := Result{Positions: make(map[string]Point, len(nodes))}
+	if len(nodes) == 0 {
+		return res
+	}
+
+	_, adj := indexGraph(nodes, edges)
+	adj = removeCycles(adj)
+	layerOf := longestPathLayers(adj)
+
+	// 1. Build the layered graph, splitting edges that span several layers
+	verts := make([]vertex, len(nodes))
+	for i, n := range nodes {
+		verts[i] = vertex{node: i, width: n.Width, height: n.Height, layer: layerOf[i]}
+	}
+	link := func(u, v int) {
+		verts[u].succ = append(verts[u].succ, v)
+		verts[v].pred = append(verts[v].pred, u)
+	}
+	for u := range adj {
+		for _, v := range adj[u] {
+			prev := u
+			for l := layerOf[u] + 1; l < layerOf[v]; l++ {
+				verts = append(verts, vertex{node: -1, width: dummyWidth, layer: l})
+				d := len(verts) - 1
+				link(prev, d)
+				prev = d
+			}
+			link(prev, v)
+		}
+	}
+
+	depth := 0
+	for _, v := range verts {
+		if v.layer+1 > depth {
+			depth = v.layer + 1
+		}
+	}
+	layers := make([][]int, depth)
+	for i, v := range verts {
+		layers[v.layer] = append(layers[v.layer], i)
+	}
+
+	// 2. Order vertices inside layers
+	layers, res.Crossings = orderLayers(verts, layers)
+
+	// 3. Assign coordinates
+	xs := placeLayers(verts, layers)
+	y := 0
+	for _, layer := range layers {
+		height := 0
+		for _, v := range layer {
+			if verts[v].node >= 0 {
+				res.Positions[nodes[verts[v].node].ID] = Point{X: xs[v], Y: y}
+			}
+			if verts[v].height > height {
+				height = verts[v].height
+			}
+		}
+		y += height + VerticalGap
+	}
+
+	normalize(nodes, res.Positions)
+	res.Width, res.Height = extent(nodes, res.Positions)
+	return res
+}
+
+// removeCycles reverses DFS back edges so the graph becomes acyclic
+func removeCycles(adj [][]int) [][]int {
+	const (
+		white = iota
+		gray
+		black
+	)
+	color := make([]int, len(adj))
+	out := make([][]int, len(adj))
+	seen := make(map[[2]int]bool)
+	add := func(u, v int) {
+		if !seen[[2]int{u, v}] {
+			seen[[2]int{u, v}] = true
+			out[u] = append(out[u], v)
+		}
+	}
+
+	type frame struct{ v, next int }
+	for root := range adj {
+		if color[root] != white {
+			continue
+		}
+		stack := []frame{{v: root}}
+		color[root] = gray
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.next == len(adj[top.v]) {
+				color[top.v] = black
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			u, v := top.v, adj[top.v][top.next]
+			top.next++
+			switch color[v] {
+			case gray:
+				add(v, u) // back edge
+			case white:
+				add(u, v)
+				color[v] = gray
+				stack = append(stack, frame{v: v})
+			default:
+				add(u, v)
+			}
+		}
+	}
+	return out
+}
+
+// longestPathLayers puts every vertex one layer below its deepest predecessor
+func longestPathLayers(adj [][]int) []int {
+	indeg := make([]int, len(adj))
+	for u := range adj {
+		for _, v := range adj[u] {
+			indeg[v]++
+		}
+	}
+	queue := make([]int, 0, len(adj))
+	for v, d := range indeg {
+		if d == 0 {
+			queue = append(queue, v)
+		}
+	}
+
+	layer := make([]int, len(adj))
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range adj[u] {
+			if layer[u]+1 > layer[v] {
+				layer[v] = layer[u] + 1
+			}
+			indeg[v]--
+			if indeg[v] == 0 {
+				queue = append(queue, v)
+			}
+		}
+	}
+	return layer
+}
+
+// orderLayers runs alternating barycenter sweeps and keeps the ordering with the fewest crossings
+func orderLayers(verts []vertex, layers [][]int) ([][]int, int) {
+	pos := make([]float64, len(verts))
+	for _, layer := range layers {
+		for i, v := range layer {
+			pos[v] = float64(i)
+		}
+	}
+
+	best := copyLayers(layers)
+	bestCrossings := countCrossings(verts, layers)
+
+	sweep := func(l int, neighbors func(v int) []int) {
+		bary := make(map[int]float64, len(layers[l]))
+		for _, v := range layers[l] {
+			ns := neighbors(v)
+			if len(ns) == 0 {
+				bary[v] = pos[v]
+				continue
+			}
+			sum := 0.0
+			for _, n := range ns {
+				sum += pos[n]
+			}
+			bary[v] = sum / float64(len(ns))
+		}
+		sort.SliceStable(layers[l], func(i, j int) bool {
+			return bary[layers[l][i]] < bary[layers[l][j]]
+		})
+		for i, v := range layers[l] {
+			pos[v] = float64(i)
+		}
+	}
+
+	for iter := 0; iter < orderingSweeps && bestCrossings > 0; iter++ {
+		if iter%2 == 0 {
+			for l := 1; l < len(layers); l++ {
+				sweep(l, func(v int) []int { return verts[v].pred })
+			}
+		} else {
+			for l := len(layers) - 2; l >= 0; l-- {
+				sweep(l, func(v int) []int { return verts[v].succ })
+			}
+		}
+		if c := countCrossings(verts, layers); c < bestCrossings {
+			bestCrossings = c
+			best = copyLayers(layers)
+		}
+	}
+	return best, bestCrossings
+}
+
+// countCrossings counts crossing edge pairs between every pair of adjacent layers
+func countCrossings(verts []vertex, layers [][]int) int {
+	pos := make([]int, len(verts))
+	for _, layer := range layers {
+		for i, v := range layer {
+			pos[v] = i
+		}
+	}
+
+	total := 0
+	for l := 0; l+1 < len(layers); l++ {
+		type segment struct{ a, b int }
+		segs := make([]segment, 0)
+		for _, u := range layers[l] {
+			for _, v := range verts[u].succ {
+				segs = append(segs, segment{pos[u], pos[v]})
+			}
+		}
+		for i := 0; i < len(segs); i++ {
+			for j := i + 1; j < len(segs); j++ {
+				if (segs[i].a-segs[j].a)*(segs[i].b-segs[j].b) < 0 {
+					total++
+				}
+			}
+		}
+	}
+	return total
+}
+
+// placeLayers assigns x coordinates: vertices are pulled towards the average
+// center of their neighbors while keeping layer order and spacing
+func placeLayers(verts []vertex, layers [][]int) []int {
+	xs := make([]int, len(verts))
+	gap := func(a, b int) int {
+		if verts[a].node < 0 || verts[b].node < 0 {
+			return dummyGap
+		}
+		return HorizontalGap
+	}
+	center := func(v int) float64 { return float64(xs[v]) + float64(verts[v].width)/2 }
+
+	// Start packed from the left
+	for _, layer := range layers {
+		x := 0
+		for i, v := range layer {
+			if i > 0 {
+				x += gap(layer[i-1], v)
+			}
+			xs[v] = x
+			x += verts[v].width
+		}
+	}
+
+	place := func(layer []int, neighbors func(v int) []int) {
+		desired := make([]float64, len(layer))
+		for i, v := range layer {
+			ns := neighbors(v)
+			if len(ns) == 0 {
+				desired[i] = center(v)
+				continue
+			}
+			sum := 0.0
+			for _, n := range ns {
+				sum += center(n)
+			}
+			desired[i] = sum / float64(len(ns))
+		}
+
+		// Left to right without overlaps, then shift the layer by the mean error
+		shift := 0.0
+		for i, v := range layer {
+			x := int(desired[i] - float64(verts[v].width)/2)
+			if i > 0 {
+				prev := layer[i-1]
+				if minX := xs[prev] + verts[prev].width + gap(prev, v); x < minX {
+					x = minX
+				}
+			}
+			xs[v] = x
+			shift += desired[i] - center(v)
+		}
+		delta := int(shift / float64(len(layer)))
+		for _, v := range layer {
+			xs[v] += delta
+		}
+	}
+
+	for pass := 0; pass < placementPasses; pass++ {
+		if pass%2 == 0 {
+			for l := 1; l < len(layers); l++ {
+				place(layers[l], func(v int) []int { return verts[v].pred })
+			}
+		} else {
+			for l := len(layers) - 2; l >= 0; l-- {
+				place(layers[l], func(v int) []int { return verts[v].succ })
+			}
+		}
+	}
+	return xs
+}
+
+func copyLayers(layers [][]int) [][]int {
+	out := make([][]int, len(layers))
+	for i, l := range layers {
+		out[i] = append([]int{}, l...)
+	}
+	return out
+}
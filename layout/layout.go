@@ -0,0 +1,138 @@
+// Package layout computes canvas positions for diagram tables.
+//
+// All algorithms are deterministic and return top-left positions relative to
+// the origin of the laid-out block; callers translate them onto the canvas.
+package layout
+
+import (
+	"math"
+	"sort"
+)
+
+// Spacing between laid-out tables
+const (
+	HorizontalGap = 80
+	VerticalGap   = 120
+)
+
+// Node is a box to place on the canvas
+type Node struct {
+	ID     string
+	Width  int
+	Height int
+}
+
+// Edge connects two nodes; for the layered layout From is placed above To
+type Edge struct {
+	From string
+	To   string
+}
+
+// Point is a top-left canvas position
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Result holds node positions and the size of the laid-out block
+type Result struct {
+	Positions map[string]Point
+	Width     int
+	Height    int
+	Crossings int // Edge crossings between adjacent layers (layered layout only)
+}
+
+// Grid places nodes row by row in the given order on a roughly square grid
+func Grid(nodes []Node) Result {
+	res := Result{Positions: make(map[string]Point, len(nodes))}
+	if len(nodes) == 0 {
+		return res
+	}
+
+	cols := int(math.Ceil(math.Sqrt(float64(len(nodes)))))
+	colWidth := 0
+	for _, n := range nodes {
+		if n.Width > colWidth {
+			colWidth = n.Width
+		}
+	}
+
+	y := 0
+	for start := 0; start < len(nodes); start += cols {
+		end := start + cols
+		if end > len(nodes) {
+			end = len(nodes)
+		}
+		rowHeight := 0
+		for i, n := range nodes[start:end] {
+			res.Positions[n.ID] = Point{X: i * (colWidth + HorizontalGap), Y: y}
+			if n.Height > rowHeight {
+				rowHeight = n.Height
+			}
+		}
+		y += rowHeight + VerticalGap
+	}
+
+	res.Width, res.Height = extent(nodes, res.Positions)
+	return res
+}
+
+// extent returns the width and height covered by positioned nodes
+func extent(nodes []Node, pos map[string]Point) (int, int) {
+	w, h := 0, 0
+	for _, n := range nodes {
+		p := pos[n.ID]
+		if p.X+n.Width > w {
+			w = p.X + n.Width
+		}
+		if p.Y+n.Height > h {
+			h = p.Y + n.Height
+		}
+	}
+	return w, h
+}
+
+// normalize shifts positions so the block starts at (0, 0)
+func normalize(nodes []Node, pos map[string]Point) {
+	if len(nodes) == 0 {
+		return
+	}
+	minX, minY := math.MaxInt, math.MaxInt
+	for _, n := range nodes {
+		p := pos[n.ID]
+		if p.X < minX {
+			minX = p.X
+		}
+		if p.Y < minY {
+			minY = p.Y
+		}
+	}
+	for id, p := range pos {
+		pos[id] = Point{X: p.X - minX, Y: p.Y - minY}
+	}
+}
+
+// indexGraph maps node IDs to indexes and returns deduplicated adjacency,
+// dropping self loops and edges to unknown nodes
+func indexGraph(nodes []Node, edges []Edge) (map[string]int, [][]int) {
+	index := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		index[n.ID] = i
+	}
+
+	adj := make([][]int, len(nodes))
+	seen := make(map[[2]int]bool)
+	for _, e := range edges {
+		from, ok1 := index[e.From]
+		to, ok2 := index[e.To]
+		if !ok1 || !ok2 || from == to || seen[[2]int{from, to}] {
+			continue
+		}
+		seen[[2]int{from, to}] = true
+		adj[from] = append(adj[from], to)
+	}
+	for i := range adj {
+		sort.Ints(adj[i])
+	}
+	return index, adj
+}
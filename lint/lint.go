@@ -0,0 +1,244 @@
+// Package lint checks a diagram snapshot for schema design problems: tables
+// without primary keys, foreign keys whose types don't match the key they
+// reference, relationships and indexes pointing at missing fields, duplicate
+// names and the like.
+//
+// Each rule reports LintIssues with a fixed severity; Check returns them
+// sorted with the most severe first.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Rule names
+const (
+	RuleNoPrimaryKey         = "no-primary-key"
+	RuleNullablePrimaryKey   = "nullable-primary-key"
+	RuleEmptyTable           = "empty-table"
+	RuleDuplicateTable       = "duplicate-table"
+	RuleDuplicateField       = "duplicate-field"
+	RuleDanglingRelationship = "dangling-relationship"
+	RuleForeignKeyType       = "foreign-key-type"
+	RuleForeignKeyTarget     = "foreign-key-target"
+	RuleForeignKeyIndex      = "foreign-key-index"
+	RuleUnknownIndexField    = "unknown-index-field"
+	RuleDuplicateIndex       = "duplicate-index"
+)
+
+var severityRank = map[string]int{
+	domain.SeverityError:   0,
+	domain.SeverityWarning: 1,
+	domain.SeverityInfo:    2,
+}
+
+// Check runs every rule against the snapshot
+func Check(snap *domain.DiagramSnapshot) []domain.LintIssue {
+	c := &checker{tables: make(map[string]domain.Table, len(snap.Tables))}
+	for _, t := range snap.Tables {
+		c.tables[t.TableID] = t
+	}
+
+	c.duplicateTables(snap.Tables)
+	for _, t := range snap.Tables {
+		c.table(t)
+	}
+	for _, r := range snap.Relationships {
+		c.relationship(r)
+	}
+
+	sort.SliceStable(c.issues, func(i, j int) bool {
+		a, b := c.issues[i], c.issues[j]
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] < severityRank[b.Severity]
+		}
+		return tableName(a.Table) < tableName(b.Table)
+	})
+	return c.issues
+}
+
+type checker struct {
+	tables map[string]domain.Table
+	issues []domain.LintIssue
+}
+
+func (c *checker) report(rule, severity string, t *domain.Table, field, format string, args ...interface{}) {
+	issue := domain.LintIssue{Rule: rule, Severity: severity, Field: field, Message: fmt.Sprintf(format, args...)}
+	if t != nil {
+		ref := t.Ref()
+		issue.Table = &ref
+	}
+	c.issues = append(c.issues, issue)
+}
+
+func (c *checker) duplicateTables(tables []domain.Table) {
+	seen := make(map[string]bool, len(tables))
+	for i := range tables {
+		t := &tables[i]
+		key := strings.ToLower(t.Schema + "." + t.Name)
+		if seen[key] {
+			c.report(RuleDuplicateTable, domain.SeverityError, t, "", "%s is defined more than once", domain.QualifiedName(t.Schema, t.Name))
+		}
+		seen[key] = true
+	}
+}
+
+func (c *checker) table(t domain.Table) {
+	fields := t.FieldList()
+	if len(fields) == 0 {
+		c.report(RuleEmptyTable, domain.SeverityWarning, &t, "", "%s has no fields", domain.QualifiedName(t.Schema, t.Name))
+		return
+	}
+
+	hasPK := false
+	names := make(map[string]bool, len(fields))
+	byID := make(map[string]domain.Field, len(fields))
+	for _, f := range fields {
+		byID[f.ID] = f
+		if names[strings.ToLower(f.Name)] {
+			c.report(RuleDuplicateField, domain.SeverityError, &t, f.Name, "%s has more than one field named %s", t.Name, f.Name)
+		}
+		names[strings.ToLower(f.Name)] = true
+		if f.PrimaryKey {
+			hasPK = true
+			if f.Nullable {
+				c.report(RuleNullablePrimaryKey, domain.SeverityWarning, &t, f.Name, "primary key %s.%s is marked nullable", t.Name, f.Name)
+			}
+		}
+	}
+	if !hasPK && !t.IsView {
+		c.report(RuleNoPrimaryKey, domain.SeverityWarning, &t, "", "%s has no primary key", domain.QualifiedName(t.Schema, t.Name))
+	}
+
+	seen := make(map[string]string)
+	for _, idx := range t.IndexList() {
+		label := nonEmpty(idx.Name, "unnamed index")
+		cols := make([]string, 0, len(idx.FieldIDs))
+		for _, id := range idx.FieldIDs {
+			f, ok := byID[id]
+			if !ok {
+				c.report(RuleUnknownIndexField, domain.SeverityError, &t, "", "%s on %s refers to a field that does not exist", label, t.Name)
+				cols = nil
+				break
+			}
+			cols = append(cols, strings.ToLower(f.Name))
+		}
+		if cols == nil {
+			continue
+		}
+		key := fmt.Sprintf("%t:%s", idx.Unique, strings.Join(cols, ","))
+		if other, ok := seen[key]; ok {
+			c.report(RuleDuplicateIndex, domain.SeverityWarning, &t, "", "%s on %s duplicates %s", label, t.Name, other)
+			continue
+		}
+		seen[key] = label
+	}
+}
+
+func (c *checker) relationship(r domain.Relationship) {
+	label := nonEmpty(r.Name, r.RelationshipID)
+	childID, fkID := r.ForeignKey()
+	parentID, refID := r.TargetTableID, r.TargetFieldID
+	if childID == r.TargetTableID && fkID == r.TargetFieldID {
+		parentID, refID = r.SourceTableID, r.SourceFieldID
+	}
+
+	child, ok1 := c.tables[childID]
+	parent, ok2 := c.tables[parentID]
+	if !ok1 || !ok2 {
+		c.report(RuleDanglingRelationship, domain.SeverityError, nil, "", "relationship %s refers to a table that does not exist", label)
+		return
+	}
+	fk, ok1 := child.FieldByID(fkID)
+	ref, ok2 := parent.FieldByID(refID)
+	if !ok1 || !ok2 {
+		c.report(RuleDanglingRelationship, domain.SeverityError, &child, "", "relationship %s refers to a field that does not exist", label)
+		return
+	}
+
+	if a, b := normalizeType(fk.Type), normalizeType(ref.Type); a != "" && b != "" && a != b {
+		c.report(RuleForeignKeyType, domain.SeverityWarning, &child, fk.Name, "%s.%s is %s but references %s.%s of type %s",
+			child.Name, fk.Name, fk.SQLType(), parent.Name, ref.Name, ref.SQLType())
+	}
+	if !ref.PrimaryKey && !ref.Unique && !leadsUniqueIndex(parent, ref.ID) {
+		c.report(RuleForeignKeyTarget, domain.SeverityWarning, &child, fk.Name, "%s.%s references %s.%s, which is neither a primary key nor unique",
+			child.Name, fk.Name, parent.Name, ref.Name)
+	}
+	if !child.IsView && !fk.PrimaryKey && !fk.Unique && !leadsIndex(child, fk.ID) {
+		c.report(RuleForeignKeyIndex, domain.SeverityInfo, &child, fk.Name, "foreign key %s.%s has no index", child.Name, fk.Name)
+	}
+}
+
+// leadsIndex reports whether an index starts with the field, so lookups by
+// the field can use it
+func leadsIndex(t domain.Table, fieldID string) bool {
+	for _, idx := range t.IndexList() {
+		if len(idx.FieldIDs) > 0 && idx.FieldIDs[0] == fieldID {
+			return true
+		}
+	}
+	for _, f := range t.FieldList() {
+		if f.PrimaryKey {
+			return f.ID == fieldID // The primary key index starts with its first field
+		}
+	}
+	return false
+}
+
+// leadsUniqueIndex reports whether a single-field unique index covers the field
+func leadsUniqueIndex(t domain.Table, fieldID string) bool {
+	for _, idx := range t.IndexList() {
+		if idx.Unique && len(idx.FieldIDs) == 1 && idx.FieldIDs[0] == fieldID {
+			return true
+		}
+	}
+	return false
+}
+
+// typeAliases maps spellings of the same type onto one name
+var typeAliases = map[string]string{
+	"int":               "integer",
+	"int4":              "integer",
+	"serial":            "integer",
+	"serial4":           "integer",
+	"int8":              "bigint",
+	"bigserial":         "bigint",
+	"serial8":           "bigint",
+	"int2":              "smallint",
+	"smallserial":       "smallint",
+	"character varying": "varchar",
+	"char varying":      "varchar",
+	"character":         "char",
+	"bool":              "boolean",
+	"decimal":           "numeric",
+	"float8":            "double precision",
+	"double":            "double precision",
+	"float4":            "real",
+	"timestamptz":       "timestamp with time zone",
+}
+
+func normalizeType(t string) string {
+	t = strings.Join(strings.Fields(strings.ToLower(t)), " ")
+	if alias, ok := typeAliases[t]; ok {
+		return alias
+	}
+	return t
+}
+
+func tableName(ref *domain.TableRef) string {
+	if ref == nil {
+		return ""
+	}
+	return strings.ToLower(domain.QualifiedName(ref.Schema, ref.Name))
+}
+
+func nonEmpty(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
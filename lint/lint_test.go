@@ -0,0 +1,129 @@
+package lint
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/iots1/vertex-diagram/dbml"
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// issueText describes each issue as "rule table.field" so cases stay short
+func issueText(issues []domain.LintIssue) []string {
+	out := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		s := issue.Rule
+		if issue.Table != nil {
+			s += " " + issue.Table.Name
+			if issue.Field != "" {
+				s += "." + issue.Field
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name, src string
+		want      []string
+	}{
+		{
+			"clean",
+			"Table users {\n  id int [pk]\n}\nTable orders {\n  id int [pk]\n  user_id int [ref: > users.id]\n  indexes {\n    user_id\n  }\n}",
+			nil,
+		},
+		{
+			"no primary key",
+			"Table users {\n  id int\n}",
+			[]string{RuleNoPrimaryKey + " users"},
+		},
+		{
+			"nullable primary key",
+			"Table users {\n  id int [pk, null]\n}",
+			[]string{RuleNullablePrimaryKey + " users.id"},
+		},
+		{
+			"empty table",
+			"Table users {\n}",
+			[]string{RuleEmptyTable + " users"},
+		},
+		{
+			"duplicates",
+			"Table users {\n  id int [pk]\n  ID int\n  indexes {\n    id [name: 'a']\n    id [name: 'b']\n  }\n}\nTable Users {\n  id int [pk]\n}",
+			[]string{RuleDuplicateTable + " Users", RuleDuplicateField + " users.ID", RuleDuplicateIndex + " users"},
+		},
+		{
+			"foreign key type",
+			"Table users {\n  id bigint [pk]\n}\nTable orders {\n  id int [pk]\n  user_id int [ref: > users.id]\n  indexes {\n    user_id\n  }\n}",
+			[]string{RuleForeignKeyType + " orders.user_id"},
+		},
+		{
+			"type aliases",
+			"Table users {\n  id int4 [pk]\n  code \"character varying\" [unique]\n}\nTable orders {\n  id serial [pk]\n  user_id integer [ref: > users.id]\n  code varchar [ref: > users.code]\n  indexes {\n    user_id\n    code\n  }\n}",
+			nil,
+		},
+		{
+			"foreign key target",
+			"Table users {\n  id int [pk]\n  email text\n}\nTable orders {\n  id int [pk]\n  email text [ref: > users.email]\n  indexes {\n    email\n  }\n}",
+			[]string{RuleForeignKeyTarget + " orders.email"},
+		},
+		{
+			"foreign key index",
+			"Table users {\n  id int [pk]\n}\nTable orders {\n  id int [pk]\n  user_id int [ref: > users.id]\n}",
+			[]string{RuleForeignKeyIndex + " orders.user_id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap, _, err := dbml.Parse(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := issueText(Check(snap)); !slices.Equal(got, tt.want) {
+				t.Errorf("issues = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDanglingReferences(t *testing.T) {
+	snap := &domain.DiagramSnapshot{
+		Tables: []domain.Table{{
+			TableID: "t1",
+			Name:    "users",
+			Fields:  []map[string]interface{}{{"id": "f1", "name": "id", "type": "int", "primaryKey": true}},
+			Indexes: []map[string]interface{}{{"id": "i1", "name": "ix_gone", "fieldIds": []interface{}{"gone"}}},
+		}},
+		Relationships: []domain.Relationship{
+			{RelationshipID: "r1", SourceTableID: "t1", SourceFieldID: "f1", SourceCardinality: domain.CardinalityMany,
+				TargetTableID: "t9", TargetFieldID: "f9", TargetCardinality: domain.CardinalityOne},
+			{RelationshipID: "r2", SourceTableID: "t1", SourceFieldID: "gone", SourceCardinality: domain.CardinalityMany,
+				TargetTableID: "t1", TargetFieldID: "f1", TargetCardinality: domain.CardinalityOne},
+		},
+	}
+	want := []string{RuleDanglingRelationship, RuleUnknownIndexField + " users", RuleDanglingRelationship + " users"}
+	if got := issueText(Check(snap)); !slices.Equal(got, want) {
+		t.Errorf("issues = %q, want %q", got, want)
+	}
+}
+
+func TestCheckOrder(t *testing.T) {
+	src := "Table b {\n  id int\n}\nTable a {\n  id int [pk]\n  ID int\n}\nTable c {\n  id int [pk]\n  a_id int [ref: > a.id]\n}\nTable d {\n}"
+	snap, _, err := dbml.Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := Check(snap)
+	for i := 1; i < len(issues); i++ {
+		a, b := issues[i-1], issues[i]
+		if severityRank[a.Severity] > severityRank[b.Severity] ||
+			severityRank[a.Severity] == severityRank[b.Severity] && tableName(a.Table) > tableName(b.Table) {
+			t.Errorf("%q sorts before %q", issueText(issues[i-1:i]), issueText(issues[i:i+1]))
+		}
+	}
+	if len(issues) != 4 {
+		t.Errorf("issues = %q, want one of each severity and table", issueText(issues))
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	"github.com/gofiber/fiber/v2"
@@ -38,6 +39,7 @@ func main() {
 	noteCol := db.Collection("notes")
 	diagramFilterCol := db.Collection("diagram_filters")
 	configCol := db.Collection("config")
+	templateCol := db.Collection("templates")
 
 	// Create indexes for tables and relationships collections
 	if err := database.CreateIndexes(db); err != nil {
@@ -55,6 +57,15 @@ func main() {
 		AllowHeaders: "Origin, Content-Type, Accept",
 	}))
 
+	// Request counts and latencies for /metrics
+	app.Use(http.RequestMetrics())
+
+	// Kubernetes probes and Prometheus metrics
+	http.NewHealthHandler(app,
+		http.ReadinessCheck{Name: "mongodb", Check: database.Ping},
+		http.ReadinessCheck{Name: "indexes", Check: database.CheckIndexes(db)},
+	)
+
 	// 4. Clean Architecture Wiring
 	// Repo -> Usecase -> Handler
 	diagramRepo := repository.NewMongoRepository(diagramCol)
@@ -69,6 +80,38 @@ func main() {
 	uc := usecase.NewDiagramUsecase(diagramRepo, tableRepo, relationshipRepo, dependencyRepo, areaRepo, customTypeRepo, noteRepo, diagramFilterRepo, 5*time.Second)
 	http.NewDiagramHandler(app, uc)
 
+	// Permanently delete diagrams that stayed in the trash past the retention window
+	if cfg.TrashRetention > 0 && cfg.TrashPurgeInterval > 0 {
+		go usecase.RunTrashPurger(context.Background(), uc, cfg.TrashRetention, cfg.TrashPurgeInterval)
+	}
+
+	// Automatic layout
+	layoutUc := usecase.NewLayoutUsecase(diagramRepo, tableRepo, relationshipRepo, areaRepo, 5*time.Second)
+	http.NewLayoutHandler(app, layoutUc)
+
+	// Automatic areas
+	areaUc := usecase.NewAreaUsecase(diagramRepo, tableRepo, relationshipRepo, areaRepo, 5*time.Second)
+	http.NewAreaHandler(app, areaUc)
+
+	// Graph analytics
+	graphUc := usecase.NewGraphUsecase(diagramRepo, tableRepo, relationshipRepo, dependencyRepo, 5*time.Second)
+	http.NewGraphHandler(app, graphUc)
+
+	// Statistics
+	statsRepo := repository.NewMongoStatsRepository(db)
+	statsUc := usecase.NewStatsUsecase(statsRepo, diagramRepo, tableRepo, relationshipRepo, customTypeRepo, 5*time.Second)
+	http.NewStatsHandler(app, statsUc)
+
+	// Template library
+	templateRepo := repository.NewMongoTemplateRepository(templateCol)
+	templateUc := usecase.NewTemplateUsecase(templateRepo, diagramRepo, tableRepo, relationshipRepo, customTypeRepo, areaRepo, 5*time.Second)
+	http.NewTemplateHandler(app, templateUc)
+
+	// Global search
+	searchRepo := repository.NewMongoSearchRepository(tableCol, noteCol, customTypeCol)
+	searchUc := usecase.NewSearchUsecase(searchRepo, 5*time.Second)
+	http.NewSearchHandler(app, searchUc)
+
 	// Global Config
 	configRepo := repository.NewMongoConfigRepository(configCol)
 	configUc := usecase.NewConfigUsecase(configRepo, 5*time.Second)
@@ -0,0 +1,206 @@
+// Package metrics keeps the server's Prometheus metrics in memory and writes
+// them in the Prometheus text exposition format. It implements just the
+// counters and histograms the server needs, labelled by a fixed set of names.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of latency histograms
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics the server records
+var (
+	HTTPRequests = NewCounter("vertex_http_requests_total",
+		"HTTP requests handled, by method, route and status code.", "method", "route", "status")
+	HTTPDuration = NewHistogram("vertex_http_request_duration_seconds",
+		"Time spent handling HTTP requests, by method and route.", "method", "route")
+	MongoDuration = NewHistogram("vertex_mongo_operation_duration_seconds",
+		"Duration of MongoDB commands, by collection and command.", "collection", "operation")
+	MongoErrors = NewCounter("vertex_mongo_operation_errors_total",
+		"MongoDB commands that failed, by collection and command.", "collection", "operation")
+	SaveStageDuration = NewHistogram("vertex_diagram_save_stage_duration_seconds",
+		"Time spent in each stage of saving a diagram; stage=\"total\" covers the whole save.", "stage")
+)
+
+var registry = []collector{HTTPRequests, HTTPDuration, MongoDuration, MongoErrors, SaveStageDuration}
+
+type collector interface {
+	write(w io.Writer) error
+}
+
+// Since records the time elapsed since start in a histogram; use it with
+// defer: defer metrics.Since(metrics.SaveStageDuration, time.Now(), "tables")
+func Since(h *Histogram, start time.Time, labels ...string) {
+	h.Observe(time.Since(start).Seconds(), labels...)
+}
+
+// WriteTo writes every metric in the text exposition format
+func WriteTo(w io.Writer) error {
+	for _, c := range registry {
+		if err := c.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Counter is a monotonically increasing count per combination of label values
+type Counter struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{name: name, help: help, labels: labels, values: make(map[string]float64)}
+}
+
+// Inc adds one to the counter for the label values, given in label order
+func (c *Counter) Inc(labels ...string) {
+	key := labelKey(labels, len(c.labels))
+	c.mu.Lock()
+	c.values[key]++
+	c.mu.Unlock()
+}
+
+func (c *Counter) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return err
+	}
+	for _, key := range sortedKeys(c.values) {
+		_, err := fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, key, ""), formatFloat(c.values[key]))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Histogram counts observations into cumulative buckets per combination of
+// label values
+type Histogram struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64 // Per bucket, not cumulative; the last one is +Inf
+	sum    float64
+	count  uint64
+}
+
+func NewHistogram(name, help string, labels ...string) *Histogram {
+	return &Histogram{name: name, help: help, labels: labels, buckets: DefaultBuckets, series: make(map[string]*histogramSeries)}
+}
+
+// Observe records a value for the label values, given in label order
+func (h *Histogram) Observe(v float64, labels ...string) {
+	key := labelKey(labels, len(h.labels))
+	i := sort.SearchFloat64s(h.buckets, v) // First bucket whose bound is >= v
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets)+1)}
+		h.series[key] = s
+	}
+	s.counts[i]++
+	s.sum += v
+	s.count++
+}
+
+func (h *Histogram) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return err
+	}
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			le := `le="` + formatFloat(bound) + `"`
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, key, le), cumulative); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, key, `le="+Inf"`), s.count); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, key, ""), formatFloat(s.sum)); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, key, ""), s.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelSep joins label values into a map key; it can't occur in UTF-8 text
+const labelSep = "\xff"
+
+// labelKey joins the label values, padding or truncating them to n
+func labelKey(values []string, n int) string {
+	v := make([]string, n)
+	copy(v, values)
+	return strings.Join(v, labelSep)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels renders {name="value",...} for a series key, followed by an
+// extra pre-rendered pair such as le="0.5"
+func formatLabels(names []string, key, extra string) string {
+	pairs := make([]string, 0, len(names)+1)
+	if len(names) > 0 {
+		for i, v := range strings.Split(key, labelSep) {
+			pairs = append(pairs, names[i]+`="`+labelEscaper.Replace(v)+`"`)
+		}
+	}
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
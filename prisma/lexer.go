@@ -0,0 +1,112 @@
+package prisma
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF     tokenKind = iota
+	tokNewline           // Fields and block attributes end at a newline
+	tokDoc               // /// documentation comment
+	tokWord              // Identifier, keyword or number
+	tokString            // "double quoted" string
+	tokPunct             // { } ( ) [ ] , : = ? . @ @@
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of input"
+	case tokNewline:
+		return "end of line"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex splits a Prisma schema into tokens, dropping // comments but keeping /// ones
+func lex(src string) ([]token, error) {
+	tokens := make([]token, 0)
+	rs := []rune(src)
+	line := 1
+	emit := func(kind tokenKind, text string) {
+		tokens = append(tokens, token{kind: kind, text: text, line: line})
+	}
+
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case r == '\n':
+			emit(tokNewline, "\n")
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			start := i
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+			if text := string(rs[start:i]); strings.HasPrefix(text, "///") {
+				emit(tokDoc, strings.TrimSpace(text[3:]))
+			}
+		case r == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(rs) && rs[j] != '"'; j++ {
+				if rs[j] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				if rs[j] == '\\' && j+1 < len(rs) {
+					j++
+					switch rs[j] {
+					case 'n':
+						b.WriteRune('\n')
+					case 't':
+						b.WriteRune('\t')
+					default:
+						b.WriteRune(rs[j])
+					}
+					continue
+				}
+				b.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			emit(tokString, b.String())
+			i = j + 1
+		case r == '@' && i+1 < len(rs) && rs[i+1] == '@':
+			emit(tokPunct, "@@")
+			i += 2
+		case strings.ContainsRune("{}()[],:=?.@", r):
+			emit(tokPunct, string(r))
+			i++
+		case isWordRune(r) || r == '-':
+			j := i + 1
+			for j < len(rs) && (isWordRune(rs[j]) || (rs[j] == '.' && unicode.IsDigit(rs[i]) && j+1 < len(rs) && unicode.IsDigit(rs[j+1]))) {
+				j++
+			}
+			emit(tokWord, string(rs[i:j]))
+			i = j
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, r)
+		}
+	}
+	emit(tokNewline, "\n")
+	emit(tokEOF, "")
+	return tokens, nil
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
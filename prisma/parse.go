@@ -0,0 +1,866 @@
+package prisma
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/sqlgen"
+)
+
+type valueKind int
+
+const (
+	valString valueKind = iota
+	valWord             // Identifier, number or boolean
+	valCall             // Function call such as now() or dbgenerated("...")
+	valList             // [a, b]
+)
+
+// value is an attribute argument
+type value struct {
+	kind  valueKind
+	text  string // String contents, word or function name
+	args  []arg  // Function arguments
+	items []value
+}
+
+// arg is a positional (empty name) or named argument
+type arg struct {
+	name string
+	val  value
+}
+
+// attribute is a field (@name) or block (@@name) attribute
+type attribute struct {
+	name string // e.g. "id", "relation", "db.VarChar"
+	args []arg
+	line int
+}
+
+// arg returns the named argument, or else the positional one at index pos
+func (a attribute) arg(name string, pos int) (value, bool) {
+	n := 0
+	for _, x := range a.args {
+		if x.name == name && name != "" {
+			return x.val, true
+		}
+	}
+	for _, x := range a.args {
+		if x.name != "" {
+			continue
+		}
+		if n == pos {
+			return x.val, true
+		}
+		n++
+	}
+	return value{}, false
+}
+
+// names returns the identifiers of a list argument such as [a, b]
+func (v value) names() []string {
+	if v.kind != valList {
+		return []string{v.text}
+	}
+	names := make([]string, 0, len(v.items))
+	for _, item := range v.items {
+		// Index fields may carry arguments, e.g. title(sort: Desc)
+		names = append(names, item.text)
+	}
+	return names
+}
+
+type fieldDraft struct {
+	name     string
+	typ      string // Prisma type name
+	typeArg  string // Argument of Unsupported("...")
+	optional bool
+	list     bool
+	attrs    []attribute
+	doc      string
+	line     int
+}
+
+type modelDraft struct {
+	name   string
+	view   bool
+	fields []fieldDraft
+	attrs  []attribute
+	doc    string
+	line   int
+
+	table domain.Table
+	cols  map[string]domain.Field // Prisma field name -> column
+	order []string
+}
+
+type enumDraft struct {
+	name   string
+	values []string
+	attrs  []attribute
+	line   int
+}
+
+type parser struct {
+	toks     []token
+	pos      int
+	warnings []string
+	nextID   int
+
+	diagram *domain.Diagram
+	models  []*modelDraft
+	enums   []*enumDraft
+}
+
+// Parse reads a Prisma schema into a snapshot. IDs are placeholders to be
+// replaced by the caller; canvas positions are left at zero. Constructs
+// without a counterpart in the model are reported as warnings.
+func Parse(src string) (*domain.DiagramSnapshot, []string, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &parser{
+		toks:    toks,
+		diagram: &domain.Diagram{Content: map[string]interface{}{}},
+	}
+	if err := p.parseFile(); err != nil {
+		return nil, p.warnings, err
+	}
+	return p.snapshot(), p.warnings, nil
+}
+
+func (p *parser) id(prefix string) string {
+	p.nextID++
+	return fmt.Sprintf("%s%d", prefix, p.nextID)
+}
+
+func (p *parser) warn(line int, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...))
+}
+
+// Token helpers
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) skipNewlines() {
+	for p.peek().kind == tokNewline {
+		p.pos++
+	}
+}
+
+func (p *parser) isPunct(s string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.text == s
+}
+
+func (p *parser) expect(s string) error {
+	if !p.isPunct(s) {
+		t := p.peek()
+		return fmt.Errorf("line %d: expected %q, found %s", t.line, s, t)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) word() (string, error) {
+	t := p.peek()
+	if t.kind != tokWord {
+		return "", fmt.Errorf("line %d: expected a name, found %s", t.line, t)
+	}
+	p.pos++
+	return t.text, nil
+}
+
+// docs collects /// comments before a declaration
+func (p *parser) docs() string {
+	lines := make([]string, 0)
+	for {
+		switch t := p.peek(); t.kind {
+		case tokNewline:
+			p.pos++
+		case tokDoc:
+			lines = append(lines, t.text)
+			p.pos++
+		default:
+			return strings.Join(lines, "\n")
+		}
+	}
+}
+
+// skipBlock skips a { ... } block, including nested braces
+func (p *parser) skipBlock() error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for depth := 1; depth > 0; {
+		t := p.next()
+		switch {
+		case t.kind == tokEOF:
+			return fmt.Errorf("line %d: unterminated block", t.line)
+		case t.kind == tokPunct && t.text == "{":
+			depth++
+		case t.kind == tokPunct && t.text == "}":
+			depth--
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseFile() error {
+	for {
+		doc := p.docs()
+		t := p.peek()
+		if t.kind == tokEOF {
+			return nil
+		}
+		if t.kind != tokWord {
+			return fmt.Errorf("line %d: expected a declaration, found %s", t.line, t)
+		}
+		var err error
+		switch t.text {
+		case "model", "view":
+			err = p.parseModel(doc)
+		case "enum":
+			err = p.parseEnum()
+		case "datasource":
+			err = p.parseDatasource()
+		case "generator":
+			p.pos += 2
+			err = p.skipBlock()
+		default:
+			p.pos++
+			if _, err = p.word(); err != nil {
+				return err
+			}
+			p.warn(t.line, "%s blocks are not supported and were skipped", t.text)
+			err = p.skipBlock()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseDatasource takes the database type from the provider
+func (p *parser) parseDatasource() error {
+	p.pos++ // datasource
+	if _, err := p.word(); err != nil {
+		return err
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		p.skipNewlines()
+		if p.isPunct("}") {
+			p.pos++
+			return nil
+		}
+		t := p.peek()
+		key, err := p.word()
+		if err != nil {
+			return err
+		}
+		if err := p.expect("="); err != nil {
+			return err
+		}
+		v, err := p.value()
+		if err != nil {
+			return err
+		}
+		if key != "provider" {
+			continue
+		}
+		if d, ok := sqlgen.ParseDialect(v.text); ok {
+			p.diagram.Content["databaseType"] = string(d)
+		} else {
+			p.warn(t.line, "unknown datasource provider %q", v.text)
+		}
+	}
+}
+
+func (p *parser) parseModel(doc string) error {
+	kind := p.next()
+	name, err := p.word()
+	if err != nil {
+		return err
+	}
+	m := &modelDraft{name: name, view: kind.text == "view", doc: doc, line: kind.line}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		fieldDoc := p.docs()
+		if p.isPunct("}") {
+			p.pos++
+			break
+		}
+		if p.isPunct("@@") {
+			a, err := p.attribute()
+			if err != nil {
+				return err
+			}
+			m.attrs = append(m.attrs, a)
+			continue
+		}
+		f, err := p.field()
+		if err != nil {
+			return err
+		}
+		f.doc = fieldDoc
+		m.fields = append(m.fields, f)
+	}
+	p.models = append(p.models, m)
+	return nil
+}
+
+// field reads "name Type[?|[]] @attr..." up to the end of the line
+func (p *parser) field() (fieldDraft, error) {
+	line := p.peek().line
+	name, err := p.word()
+	if err != nil {
+		return fieldDraft{}, err
+	}
+	typ, err := p.word()
+	if err != nil {
+		return fieldDraft{}, err
+	}
+	f := fieldDraft{name: name, typ: typ, line: line}
+	if typ == "Unsupported" && p.isPunct("(") {
+		p.pos++
+		v, err := p.value()
+		if err != nil {
+			return f, err
+		}
+		f.typeArg = v.text
+		if err := p.expect(")"); err != nil {
+			return f, err
+		}
+	}
+	switch {
+	case p.isPunct("?"):
+		p.pos++
+		f.optional = true
+	case p.isPunct("["):
+		p.pos++
+		if err := p.expect("]"); err != nil {
+			return f, err
+		}
+		f.list = true
+	}
+	for p.isPunct("@") {
+		a, err := p.attribute()
+		if err != nil {
+			return f, err
+		}
+		f.attrs = append(f.attrs, a)
+	}
+	if t := p.peek(); t.kind != tokNewline && t.kind != tokDoc && !p.isPunct("}") {
+		return f, fmt.Errorf("line %d: unexpected %s after field %s", t.line, t, name)
+	}
+	return f, nil
+}
+
+// attribute reads @name(args) or @@name(args); names may be dotted, as in @db.VarChar
+func (p *parser) attribute() (attribute, error) {
+	at := p.next()
+	name, err := p.word()
+	if err != nil {
+		return attribute{}, err
+	}
+	for p.isPunct(".") {
+		p.pos++
+		part, err := p.word()
+		if err != nil {
+			return attribute{}, err
+		}
+		name += "." + part
+	}
+	a := attribute{name: name, line: at.line}
+	if p.isPunct("(") {
+		if a.args, err = p.args(); err != nil {
+			return a, err
+		}
+	}
+	return a, nil
+}
+
+// args reads a parenthesized argument list
+func (p *parser) args() ([]arg, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	args := make([]arg, 0)
+	for !p.isPunct(")") {
+		var a arg
+		if t := p.peek(); t.kind == tokWord && p.toks[p.pos+1].kind == tokPunct && p.toks[p.pos+1].text == ":" {
+			a.name = t.text
+			p.pos += 2
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		a.val = v
+		args = append(args, a)
+		if !p.isPunct(",") {
+			break
+		}
+		p.pos++
+	}
+	return args, p.expect(")")
+}
+
+// value reads a string, word, function call or list
+func (p *parser) value() (value, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokString:
+		p.pos++
+		return value{kind: valString, text: t.text}, nil
+	case t.kind == tokWord:
+		p.pos++
+		v := value{kind: valWord, text: t.text}
+		if p.isPunct("(") {
+			args, err := p.args()
+			if err != nil {
+				return v, err
+			}
+			v.kind, v.args = valCall, args
+		}
+		return v, nil
+	case p.isPunct("["):
+		p.pos++
+		v := value{kind: valList}
+		for !p.isPunct("]") {
+			item, err := p.value()
+			if err != nil {
+				return v, err
+			}
+			v.items = append(v.items, item)
+			if !p.isPunct(",") {
+				break
+			}
+			p.pos++
+		}
+		return v, p.expect("]")
+	}
+	return value{}, fmt.Errorf("line %d: expected a value, found %s", t.line, t)
+}
+
+func (p *parser) parseEnum() error {
+	line := p.next().line // enum
+	name, err := p.word()
+	if err != nil {
+		return err
+	}
+	e := &enumDraft{name: name, line: line}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		p.docs()
+		if p.isPunct("}") {
+			p.pos++
+			break
+		}
+		if p.isPunct("@@") {
+			a, err := p.attribute()
+			if err != nil {
+				return err
+			}
+			e.attrs = append(e.attrs, a)
+			continue
+		}
+		v, err := p.word()
+		if err != nil {
+			return err
+		}
+		// The stored value is the database name given by @map
+		for p.isPunct("@") {
+			a, err := p.attribute()
+			if err != nil {
+				return err
+			}
+			if mapped, ok := a.arg("name", 0); a.name == "map" && ok {
+				v = mapped.text
+			} else {
+				p.warn(a.line, "attribute @%s on enum value %s is not supported", a.name, v)
+			}
+		}
+		e.values = append(e.values, v)
+	}
+	p.enums = append(p.enums, e)
+	return nil
+}
+
+// mappedName returns the @map/@@map name, or the declared one
+func mappedName(name string, attrs []attribute) string {
+	for _, a := range attrs {
+		if a.name == "map" {
+			if v, ok := a.arg("name", 0); ok {
+				return v.text
+			}
+		}
+	}
+	return name
+}
+
+func (p *parser) snapshot() *domain.DiagramSnapshot {
+	snap := &domain.DiagramSnapshot{
+		Diagram:       p.diagram,
+		Tables:        make([]domain.Table, 0, len(p.models)),
+		Relationships: make([]domain.Relationship, 0),
+		Dependencies:  make([]domain.Dependency, 0),
+		Areas:         make([]domain.Area, 0),
+		CustomTypes:   make([]domain.CustomType, 0, len(p.enums)),
+		Notes:         make([]domain.Note, 0),
+	}
+
+	enums := make(map[string]string, len(p.enums)) // Prisma name -> database name
+	for _, e := range p.enums {
+		ct := domain.CustomType{Kind: "enum", Type: mappedName(e.name, e.attrs)}
+		values := make([]interface{}, 0, len(e.values))
+		for _, v := range e.values {
+			values = append(values, v)
+		}
+		ct.Values = values
+		for _, a := range e.attrs {
+			switch a.name {
+			case "map":
+			case "schema":
+				v, _ := a.arg("", 0)
+				ct.Schema = v.text
+			default:
+				p.warn(a.line, "attribute @@%s on enum %s is not supported", a.name, e.name)
+			}
+		}
+		enums[e.name] = ct.Type
+		snap.CustomTypes = append(snap.CustomTypes, ct)
+	}
+
+	models := make(map[string]*modelDraft, len(p.models))
+	for _, m := range p.models {
+		models[m.name] = m
+	}
+	for i, m := range p.models {
+		p.buildTable(m, models, enums)
+		m.table.Order = i
+	}
+	for _, m := range p.models {
+		snap.Relationships = append(snap.Relationships, p.relations(m, models)...)
+	}
+	for _, m := range p.models {
+		m.table.Fields = make([]map[string]interface{}, 0, len(m.order))
+		for _, name := range m.order {
+			f := m.cols[name].ToMap()
+			if name != m.cols[name].Name {
+				f[fieldNameKey] = name
+			}
+			m.table.Fields = append(m.table.Fields, f)
+		}
+		snap.Tables = append(snap.Tables, m.table)
+	}
+	return snap
+}
+
+// buildTable converts the scalar fields and block attributes of a model
+func (p *parser) buildTable(m *modelDraft, models map[string]*modelDraft, enums map[string]string) {
+	m.table = domain.Table{
+		TableID:  p.id("t"),
+		Name:     mappedName(m.name, m.attrs),
+		IsView:   m.view,
+		Comments: m.doc,
+		Indexes:  make([]map[string]interface{}, 0),
+	}
+	m.cols = make(map[string]domain.Field)
+
+	for _, fd := range m.fields {
+		if _, isModel := models[fd.typ]; isModel {
+			continue // Relation fields become relationships
+		}
+		f := domain.Field{
+			ID:       p.id("f"),
+			Name:     mappedName(fd.name, fd.attrs),
+			Nullable: fd.optional,
+			Comments: fd.doc,
+		}
+		switch {
+		case enums[fd.typ] != "":
+			f.Type = enums[fd.typ]
+		case fd.typ == "Unsupported":
+			f.Type = fd.typeArg
+		default:
+			f.Type = scalarTypes[fd.typ]
+			if f.Type == "" {
+				p.warn(fd.line, "unknown type %s of field %s.%s was kept as text", fd.typ, m.name, fd.name)
+				f.Type = "text"
+			}
+		}
+
+		for _, a := range fd.attrs {
+			switch {
+			case a.name == "map":
+			case a.name == "id":
+				f.PrimaryKey = true
+			case a.name == "unique":
+				f.Unique = true
+			case a.name == "default":
+				v, _ := a.arg("value", 0)
+				p.setDefault(&f, v, enums[fd.typ] != "", fd.line)
+			case strings.Contains(a.name, "."):
+				setNativeType(&f, a)
+			default:
+				p.warn(a.line, "attribute @%s on field %s.%s is not stored", a.name, m.name, fd.name)
+			}
+		}
+		if fd.list {
+			f.Type += "[]"
+		}
+		m.cols[fd.name] = f
+		m.order = append(m.order, fd.name)
+	}
+
+	for _, a := range m.attrs {
+		switch a.name {
+		case "map":
+		case "schema":
+			v, _ := a.arg("", 0)
+			m.table.Schema = v.text
+		case "id":
+			v, _ := a.arg("fields", 0)
+			for _, name := range v.names() {
+				if f, ok := m.cols[name]; ok {
+					f.PrimaryKey = true
+					m.cols[name] = f
+				} else {
+					p.warn(a.line, "@@id of %s names unknown field %s", m.name, name)
+				}
+			}
+		case "unique", "index":
+			v, _ := a.arg("fields", 0)
+			idx := domain.Index{ID: p.id("i"), Unique: a.name == "unique"}
+			if n, ok := a.arg("map", -1); ok {
+				idx.Name = n.text
+			} else if n, ok := a.arg("name", -1); ok {
+				idx.Name = n.text
+			}
+			for _, name := range v.names() {
+				if f, ok := m.cols[name]; ok {
+					idx.FieldIDs = append(idx.FieldIDs, f.ID)
+				} else {
+					p.warn(a.line, "@@%s of %s names unknown field %s", a.name, m.name, name)
+				}
+			}
+			if len(idx.FieldIDs) > 0 {
+				m.table.Indexes = append(m.table.Indexes, idx.ToMap())
+			}
+		default:
+			p.warn(a.line, "attribute @@%s on %s is not supported", a.name, m.name)
+		}
+	}
+}
+
+// relations builds one relationship per column pair of each @relation(fields, references)
+func (p *parser) relations(m *modelDraft, models map[string]*modelDraft) []domain.Relationship {
+	out := make([]domain.Relationship, 0)
+	for _, fd := range m.fields {
+		target, isModel := models[fd.typ]
+		if !isModel {
+			continue
+		}
+		var rel *attribute
+		for i := range fd.attrs {
+			if fd.attrs[i].name == "relation" {
+				rel = &fd.attrs[i]
+			} else {
+				p.warn(fd.attrs[i].line, "attribute @%s on relation field %s.%s is not supported", fd.attrs[i].name, m.name, fd.name)
+			}
+		}
+		fields, hasFields := value{}, false
+		if rel != nil {
+			fields, hasFields = rel.arg("fields", -1)
+		}
+		if !hasFields {
+			// The other side holds the foreign key, unless neither does
+			if fd.list && !p.holdsForeignKey(target, m.name) {
+				p.warn(fd.line, "implicit many-to-many relation %s.%s is not supported", m.name, fd.name)
+			}
+			continue
+		}
+		refs, _ := rel.arg("references", -1)
+		name := ""
+		if v, ok := rel.arg("name", 0); ok && v.kind == valString {
+			name = v.text
+		}
+		for _, a := range rel.args {
+			if a.name == "onDelete" || a.name == "onUpdate" {
+				p.warn(rel.line, "referential action %s of %s.%s is not stored", a.name, m.name, fd.name)
+			}
+		}
+
+		fkNames, refNames := fields.names(), refs.names()
+		if len(fkNames) != len(refNames) {
+			p.warn(rel.line, "relation %s.%s with mismatched fields and references was skipped", m.name, fd.name)
+			continue
+		}
+		childCard := domain.CardinalityMany
+		if m.uniqueOn(fkNames) {
+			childCard = domain.CardinalityOne
+		}
+		for i := range fkNames {
+			fk, ok1 := m.cols[fkNames[i]]
+			ref, ok2 := target.cols[refNames[i]]
+			if !ok1 || !ok2 {
+				p.warn(rel.line, "relation %s.%s on unknown field %s or %s.%s was skipped",
+					m.name, fd.name, fkNames[i], target.name, refNames[i])
+				continue
+			}
+			out = append(out, domain.Relationship{
+				RelationshipID:    p.id("r"),
+				Name:              name,
+				SourceTableID:     m.table.TableID,
+				SourceFieldID:     fk.ID,
+				TargetTableID:     target.table.TableID,
+				TargetFieldID:     ref.ID,
+				SourceCardinality: childCard,
+				TargetCardinality: domain.CardinalityOne,
+			})
+		}
+	}
+	return out
+}
+
+// holdsForeignKey reports whether model m has a @relation(fields: ...) pointing to the named model
+func (p *parser) holdsForeignKey(m *modelDraft, target string) bool {
+	for _, fd := range m.fields {
+		if fd.typ != target {
+			continue
+		}
+		for _, a := range fd.attrs {
+			if _, ok := a.arg("fields", -1); a.name == "relation" && ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// uniqueOn reports whether the given fields are unique together, making a relation one-to-one
+func (m *modelDraft) uniqueOn(names []string) bool {
+	if len(names) == 1 {
+		if f := m.cols[names[0]]; f.Unique || (f.PrimaryKey && m.pkCount() == 1) {
+			return true
+		}
+	}
+	key := strings.Join(names, ",")
+	for _, a := range m.attrs {
+		if a.name == "unique" || a.name == "id" {
+			if v, ok := a.arg("fields", 0); ok && strings.Join(v.names(), ",") == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *modelDraft) pkCount() int {
+	n := 0
+	for _, f := range m.cols {
+		if f.PrimaryKey {
+			n++
+		}
+	}
+	return n
+}
+
+// setDefault converts a Prisma default into SQL text
+func (p *parser) setDefault(f *domain.Field, v value, enum bool, line int) {
+	switch {
+	case v.kind == valCall && v.text == "autoincrement":
+		f.Increment = true
+	case v.kind == valCall && v.text == "dbgenerated":
+		if expr, ok := (attribute{args: v.args}).arg("", 0); ok {
+			f.Default = expr.text
+		}
+	case v.kind == valCall && v.text == "now":
+		f.Default = "now()"
+	case v.kind == valCall:
+		// uuid(), cuid() and nanoid() are generated by the Prisma client
+		f.Default = v.text + "()"
+		p.warn(line, "default %s() of field %s is generated by the client", v.text, f.Name)
+	case v.kind == valString, v.kind == valWord && enum:
+		f.Default = "'" + strings.ReplaceAll(v.text, "'", "''") + "'"
+	case v.kind == valList:
+		p.warn(line, "list default of field %s is not stored", f.Name)
+	default:
+		f.Default = v.text
+	}
+}
+
+// scalarTypes maps Prisma scalar types to SQL types
+var scalarTypes = map[string]string{
+	"String":   "text",
+	"Boolean":  "boolean",
+	"Int":      "integer",
+	"BigInt":   "bigint",
+	"Float":    "double precision",
+	"Decimal":  "decimal",
+	"DateTime": "timestamp",
+	"Json":     "json",
+	"Bytes":    "bytea",
+}
+
+// nativeTypes spells native type attributes whose SQL name is not simply lower case
+var nativeTypes = map[string]string{
+	"DoublePrecision":   "double precision",
+	"UnsignedInt":       "int unsigned",
+	"UnsignedBigInt":    "bigint unsigned",
+	"UnsignedSmallInt":  "smallint unsigned",
+	"UnsignedTinyInt":   "tinyint unsigned",
+	"UnsignedMediumInt": "mediumint unsigned",
+}
+
+// setNativeType applies @db.VarChar(255) and similar attributes
+func setNativeType(f *domain.Field, a attribute) {
+	native := a.name[strings.LastIndex(a.name, ".")+1:]
+	typ, ok := nativeTypes[native]
+	if !ok {
+		typ = strings.ToLower(native)
+	}
+	f.Type = typ
+	args := make([]string, 0, len(a.args))
+	for _, x := range a.args {
+		args = append(args, x.val.text)
+	}
+	switch {
+	case len(args) == 1 && lengthTypes[typ]:
+		f.CharacterMaximumLength = args[0]
+	case len(args) >= 1:
+		if n, err := strconv.Atoi(args[0]); err == nil {
+			f.Precision = &n
+		}
+		if len(args) == 2 {
+			if n, err := strconv.Atoi(args[1]); err == nil {
+				f.Scale = &n
+			}
+		}
+	}
+}
+
+// lengthTypes take a maximum length rather than a precision
+var lengthTypes = map[string]bool{
+	"char": true, "varchar": true, "nchar": true, "nvarchar": true,
+	"binary": true, "varbinary": true, "bit": true, "varbit": true,
+}
@@ -0,0 +1,293 @@
+package prisma
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/internal/exchangetest"
+)
+
+func TestParseFieldAttributes(t *testing.T) {
+	tests := []struct {
+		field    string
+		want     domain.Field
+		warnings int
+	}{
+		{"id Int @id @default(autoincrement())", domain.Field{Name: "id", Type: "integer", PrimaryKey: true, Increment: true}, 0},
+		{"email String @unique @db.VarChar(255)", domain.Field{Name: "email", Type: "varchar", CharacterMaximumLength: "255", Unique: true}, 0},
+		{"name String? @map(\"full_name\")", domain.Field{Name: "full_name", Type: "text", Nullable: true}, 0},
+		{"balance Decimal @default(0) @db.Decimal(10, 2)", domain.Field{Name: "balance", Type: "decimal", Precision: exchangetest.IntPtr(10), Scale: exchangetest.IntPtr(2), Default: "0"}, 0},
+		{"ratio Float @db.DoublePrecision", domain.Field{Name: "ratio", Type: "double precision"}, 0},
+		{"at DateTime @default(now())", domain.Field{Name: "at", Type: "timestamp", Default: "now()"}, 0},
+		{"title String @default(\"it's\")", domain.Field{Name: "title", Type: "text", Default: "'it''s'"}, 0},
+		{"seq BigInt @default(dbgenerated(\"nextval('s')\"))", domain.Field{Name: "seq", Type: "bigint", Default: "nextval('s')"}, 0},
+		{"geo Unsupported(\"geometry\")?", domain.Field{Name: "geo", Type: "geometry", Nullable: true}, 0},
+		{"tags String[]", domain.Field{Name: "tags", Type: "text[]"}, 0},
+		{"key String @default(uuid())", domain.Field{Name: "key", Type: "text", Default: "uuid()"}, 1},
+		{"odd Money", domain.Field{Name: "odd", Type: "text"}, 1},
+		{"note String @ignore", domain.Field{Name: "note", Type: "text"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			snap, warnings, err := Parse("model User {\n  " + tt.field + "\n}\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("warnings = %q, want %d", warnings, tt.warnings)
+			}
+			got := domain.FieldFromMap(snap.Tables[0].Fields[0])
+			got.ID = ""
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBlockAttributes(t *testing.T) {
+	src := `
+/// Memberships of users in teams
+model Membership {
+  userId Int
+  teamId Int
+  role   String
+
+  @@id([userId, teamId])
+  @@unique([teamId, role], map: "uq_role")
+  @@index([role])
+  @@map("memberships")
+  @@schema("auth")
+}
+`
+	snap, warnings, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("warnings: %q", warnings)
+	}
+	tbl := snap.Tables[0]
+	if tbl.Name != "memberships" || tbl.Schema != "auth" || tbl.Comments != "Memberships of users in teams" {
+		t.Errorf("table = %q.%q %q", tbl.Schema, tbl.Name, tbl.Comments)
+	}
+	fields := tbl.FieldList()
+	if !fields[0].PrimaryKey || !fields[1].PrimaryKey || fields[2].PrimaryKey {
+		t.Errorf("primary key = %v %v %v, want userId and teamId", fields[0].PrimaryKey, fields[1].PrimaryKey, fields[2].PrimaryKey)
+	}
+	want := []domain.Index{
+		{Name: "uq_role", Unique: true, FieldIDs: []string{fields[1].ID, fields[2].ID}},
+		{FieldIDs: []string{fields[2].ID}},
+	}
+	got := tbl.IndexList()
+	for i := range got {
+		got[i].ID = ""
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("indexes = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseRelations(t *testing.T) {
+	const models = `
+model User {
+  id    Int    @id
+  email String @unique
+  posts Post[]
+}
+`
+	tests := []struct {
+		name, model string
+		want        []string
+		warnings    int
+	}{
+		{
+			"many to one",
+			"model Post {\n  id Int @id\n  authorId Int\n  author User @relation(fields: [authorId], references: [id])\n}",
+			[]string{"Post.authorId many -> User.id one"}, 0,
+		},
+		{
+			"one to one",
+			"model Post {\n  id Int @id\n  authorId Int @unique\n  author User @relation(fields: [authorId], references: [id])\n}",
+			[]string{"Post.authorId one -> User.id one"}, 0,
+		},
+		{
+			"mapped columns",
+			"model Post {\n  id Int @id\n  authorEmail String @map(\"author_email\")\n  author User @relation(\"ByEmail\", fields: [authorEmail], references: [email], onDelete: Cascade)\n}",
+			[]string{"Post.author_email many -> User.email one"}, 1,
+		},
+		{
+			"composite",
+			"model Post {\n  id Int @id\n  a Int\n  b String\n  author User @relation(fields: [a, b], references: [id, email])\n  @@unique([a, b])\n}",
+			[]string{"Post.a one -> User.id one", "Post.b one -> User.email one"}, 0,
+		},
+		{
+			"mismatched",
+			"model Post {\n  id Int @id\n  a Int\n  author User @relation(fields: [a], references: [id, email])\n}",
+			nil, 1,
+		},
+		{
+			"unknown field",
+			"model Post {\n  id Int @id\n  author User @relation(fields: [missing], references: [id])\n}",
+			nil, 1,
+		},
+		{
+			"implicit many to many",
+			"model Post {\n  id Int @id\n  users User[]\n}",
+			nil, 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap, warnings, err := Parse(models + tt.model)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := exchangetest.Relationships(snap); !slices.Equal(got, tt.want) {
+				t.Errorf("relationships = %q, want %q", got, tt.want)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("warnings = %q, want %d", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestParseEnums(t *testing.T) {
+	src := `
+enum Role {
+  USER
+  ADMIN @map("admin")
+}
+model User {
+  id   Int  @id
+  role Role @default(USER)
+}
+`
+	snap, warnings, err := Parse(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("warnings: %q", warnings)
+	}
+	want := []domain.CustomType{{Type: "Role", Kind: "enum", Values: []interface{}{"USER", "admin"}}}
+	if !reflect.DeepEqual(snap.CustomTypes, want) {
+		t.Errorf("custom types = %+v, want %+v", snap.CustomTypes, want)
+	}
+	role := snap.Tables[0].FieldList()[1]
+	if role.Type != "Role" || role.Default != "'USER'" {
+		t.Errorf("role = %q default %q, want Role default 'USER'", role.Type, role.Default)
+	}
+}
+
+func TestParseDatasource(t *testing.T) {
+	tests := []struct {
+		provider, databaseType string
+		warnings               int
+	}{
+		{"postgresql", "postgresql", 0},
+		{"mysql", "mysql", 0},
+		{"sqlite", "sqlite", 0},
+		{"mongodb", "", 1},
+	}
+	for _, tt := range tests {
+		snap, warnings, err := Parse("datasource db {\n  provider = \"" + tt.provider + "\"\n  url = env(\"DATABASE_URL\")\n}\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := domain.MapString(snap.Diagram.Content, "databaseType"); got != tt.databaseType {
+			t.Errorf("%s: database type = %q, want %q", tt.provider, got, tt.databaseType)
+		}
+		if len(warnings) != tt.warnings {
+			t.Errorf("%s: warnings = %q, want %d", tt.provider, warnings, tt.warnings)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"model User {\n  id\n}", "line 2: expected a name, found end of line"},
+		{"model User {\n  id Int @id extra\n}", `line 2: unexpected "extra" after field id`},
+		{"model User {\n  id Int @id\n  name String @default(\n}", "line 3: expected a value, found end of line"},
+		{"model User {\n  id Int @id\n  name String @default(\"x)\n}", "line 3: unterminated string"},
+		{"model User {\n  id Int @id\n", "line 3: expected a name, found end of input"},
+		{"\n\n{}", `line 3: expected a declaration, found "{"`},
+	}
+	for _, tt := range tests {
+		_, _, err := Parse(tt.src)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+// roundTripSource uses every construct the writer produces
+const roundTripSource = `
+datasource db {
+  provider = "postgresql"
+  url      = env("DATABASE_URL")
+}
+
+enum Role {
+  USER
+  ADMIN
+}
+
+/// Accounts
+model User {
+  id        Int      @id @default(autoincrement())
+  email     String   @unique @db.VarChar(255)
+  /// Shown on the profile
+  name      String?  @map("full_name")
+  role      Role     @default(USER)
+  balance   Decimal  @default(0) @db.Decimal(10, 2)
+  createdAt DateTime @default(now()) @map("created_at")
+  profile   Profile?
+  posts     Post[]
+
+  @@index([name, createdAt], map: "ix_name_created")
+  @@map("users")
+}
+
+model Profile {
+  id     Int  @id @default(autoincrement())
+  userId Int  @unique @map("user_id")
+  user   User @relation(fields: [userId], references: [id])
+}
+
+model Post {
+  id       Int    @id @default(autoincrement())
+  title    String @default("it's")
+  authorId Int    @map("author_id")
+  author   User   @relation(fields: [authorId], references: [id])
+}
+
+model Membership {
+  userId Int
+  postId Int
+
+  @@id([userId, postId])
+  @@unique([postId, userId], map: "uq_member")
+}
+`
+
+func TestRoundTrip(t *testing.T) {
+	first, _, err := Parse(roundTripSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := exchangetest.RoundTrip(t, first, Write, Parse)
+	// Fields renamed with @map keep their Prisma names
+	for i, tbl := range first.Tables {
+		for j, f := range tbl.Fields {
+			if got, want := domain.MapString(second.Tables[i].Fields[j], fieldNameKey), domain.MapString(f, fieldNameKey); got != want {
+				t.Errorf("%s.%s: Prisma name %q became %q", tbl.Name, f["name"], want, got)
+			}
+		}
+	}
+}
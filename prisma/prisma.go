@@ -0,0 +1,31 @@
+// Package prisma reads and writes Prisma schema files (schema.prisma).
+//
+// Models and views become tables, scalar fields become columns with their
+// @id, @unique, @default, @map and @db attributes, @relation(fields,
+// references) becomes a relationship and enums become custom types. Other
+// constructs are reported as warnings on import.
+package prisma
+
+import (
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// fieldNameKey holds, in the field objects of an imported diagram, the
+// Prisma name of a field whose column was renamed with @map; the writer uses
+// it so the generated client keeps its property names
+const fieldNameKey = "prismaName"
+
+// Format implements domain.Importer and domain.Exporter for Prisma schemas
+type Format struct{}
+
+func (Format) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (Format) Extension() string { return "prisma" }
+
+func (Format) Export(snap *domain.DiagramSnapshot, _ map[string]string) ([]byte, error) {
+	return Write(snap), nil
+}
+
+func (Format) Import(data []byte) (*domain.DiagramSnapshot, []string, error) {
+	return Parse(string(data))
+}
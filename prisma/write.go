@@ -0,0 +1,525 @@
+package prisma
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/iots1/vertex-diagram/codegen"
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/sqlgen"
+)
+
+// providers maps dialects to datasource providers; others fall back to postgresql
+var providers = map[sqlgen.Dialect]string{
+	sqlgen.PostgreSQL:  "postgresql",
+	sqlgen.MySQL:       "mysql",
+	sqlgen.MariaDB:     "mysql",
+	sqlgen.SQLServer:   "sqlserver",
+	sqlgen.SQLite:      "sqlite",
+	sqlgen.CockroachDB: "cockroachdb",
+}
+
+// model is a table with the names it gets in the schema
+type model struct {
+	table     domain.Table
+	name      string
+	fields    []domain.Field
+	names     map[string]string // Field ID -> Prisma field name
+	taken     map[string]bool
+	relations []string // Relation field lines and comments
+}
+
+type writer struct {
+	b        strings.Builder
+	snap     *domain.DiagramSnapshot
+	provider string
+	models   []*model
+	byID     map[string]*model
+	enums    map[string]string // lower-case type name -> Prisma enum name
+}
+
+// Write renders a snapshot as a Prisma schema: datasource, generator, enums
+// and one model per table with relation fields for every relationship
+func Write(snap *domain.DiagramSnapshot) []byte {
+	w := &writer{snap: snap, provider: "postgresql", byID: make(map[string]*model), enums: make(map[string]string)}
+	if d := snap.Diagram; d != nil {
+		if s, ok := d.Content["databaseType"].(string); ok {
+			if dialect, ok := sqlgen.ParseDialect(s); ok && providers[dialect] != "" {
+				w.provider = providers[dialect]
+			}
+		}
+	}
+
+	declared := make(map[string]bool)
+	for _, ct := range snap.CustomTypes {
+		if ct.Kind == "enum" {
+			w.enums[strings.ToLower(ct.Type)] = uniqueName(declared, codegen.Pascal(ident(ct.Type)))
+		}
+	}
+	for _, t := range snap.Tables {
+		m := &model{
+			table:  t,
+			name:   uniqueName(declared, codegen.Pascal(codegen.Singular(ident(t.Name)))),
+			fields: t.FieldList(),
+			names:  make(map[string]string),
+			taken:  make(map[string]bool),
+		}
+		for i, f := range m.fields {
+			name := ident(f.Name)
+			if prismaName := domain.MapString(t.Fields[i], fieldNameKey); prismaName != "" && ident(prismaName) == prismaName {
+				name = prismaName
+			}
+			m.names[f.ID] = uniqueName(m.taken, name)
+		}
+		w.models = append(w.models, m)
+		w.byID[t.TableID] = m
+	}
+	w.relations()
+
+	w.header()
+	for _, ct := range snap.CustomTypes {
+		w.enum(ct)
+	}
+	for _, m := range w.models {
+		w.model(m)
+	}
+	return []byte(strings.TrimRight(w.b.String(), "\n") + "\n")
+}
+
+func (w *writer) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&w.b, format, args...)
+}
+
+func (w *writer) header() {
+	schemas := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, t := range w.snap.Tables {
+		if t.Schema != "" && !seen[t.Schema] {
+			seen[t.Schema] = true
+			schemas = append(schemas, strconv.Quote(t.Schema))
+		}
+	}
+	sort.Strings(schemas)
+
+	w.printf("datasource db {\n  provider = %q\n  url      = env(\"DATABASE_URL\")\n", w.provider)
+	if len(schemas) > 0 {
+		w.printf("  schemas  = [%s]\n", strings.Join(schemas, ", "))
+	}
+	w.printf("}\n\ngenerator client {\n  provider = \"prisma-client-js\"\n}\n\n")
+}
+
+func (w *writer) enum(ct domain.CustomType) {
+	name, ok := w.enums[strings.ToLower(ct.Type)]
+	if !ok {
+		w.printf("// %s type %s is not representable in Prisma\n\n", nonEmpty(ct.Kind, "custom"), ct.Type)
+		return
+	}
+	w.printf("enum %s {\n", name)
+	taken := make(map[string]bool)
+	for _, v := range ct.ValueList() {
+		id := uniqueName(taken, ident(v))
+		if id == v {
+			w.printf("  %s\n", id)
+		} else {
+			w.printf("  %s @map(%s)\n", id, strconv.Quote(v))
+		}
+	}
+	if name != ct.Type || ct.Schema != "" {
+		w.printf("\n")
+	}
+	if name != ct.Type {
+		w.printf("  @@map(%s)\n", strconv.Quote(ct.Type))
+	}
+	if ct.Schema != "" {
+		w.printf("  @@schema(%s)\n", strconv.Quote(ct.Schema))
+	}
+	w.printf("}\n\n")
+}
+
+func (w *writer) model(m *model) {
+	writeDoc(&w.b, "", m.table.Comments)
+	kind := "model"
+	if m.table.IsView {
+		kind = "view"
+	}
+	w.printf("%s %s {\n", kind, m.name)
+
+	pks := make([]string, 0)
+	for _, f := range m.fields {
+		if f.PrimaryKey {
+			pks = append(pks, m.names[f.ID])
+		}
+	}
+
+	lines := make([][3]string, 0, len(m.fields))
+	docs := make([]string, 0, len(m.fields))
+	for _, f := range m.fields {
+		typ, def, native := w.fieldType(f)
+		attrs := make([]string, 0)
+		if f.PrimaryKey && len(pks) == 1 {
+			attrs = append(attrs, "@id")
+		}
+		if def != "" {
+			attrs = append(attrs, "@default("+def+")")
+		}
+		if f.Unique && !f.PrimaryKey {
+			attrs = append(attrs, "@unique")
+		}
+		if native != "" {
+			attrs = append(attrs, native)
+		}
+		if name := m.names[f.ID]; name != f.Name {
+			attrs = append(attrs, "@map("+strconv.Quote(f.Name)+")")
+		}
+		lines = append(lines, [3]string{m.names[f.ID], typ, strings.Join(attrs, " ")})
+		docs = append(docs, f.Comments)
+	}
+	comments := make([]string, 0)
+	for _, r := range m.relations {
+		if strings.HasPrefix(r, "//") {
+			comments = append(comments, r)
+			continue
+		}
+		parts := strings.SplitN(r, " ", 3)
+		lines = append(lines, [3]string{parts[0], parts[1], strings.Join(parts[2:], "")})
+		docs = append(docs, "")
+	}
+
+	// Columns are aligned the way prisma format does
+	nameWidth, typeWidth := 0, 0
+	for _, l := range lines {
+		nameWidth, typeWidth = max(nameWidth, len(l[0])), max(typeWidth, len(l[1]))
+	}
+	for i, l := range lines {
+		writeDoc(&w.b, "  ", docs[i])
+		w.printf("%s\n", strings.TrimRight(fmt.Sprintf("  %-*s %-*s %s", nameWidth, l[0], typeWidth, l[1], l[2]), " "))
+	}
+	for _, c := range comments {
+		w.printf("  %s\n", c)
+	}
+
+	block := make([]string, 0)
+	if len(pks) > 1 {
+		block = append(block, "@@id(["+strings.Join(pks, ", ")+"])")
+	}
+	for _, idx := range m.table.IndexList() {
+		names := make([]string, 0, len(idx.FieldIDs))
+		for _, id := range idx.FieldIDs {
+			if n, ok := m.names[id]; ok {
+				names = append(names, n)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		attr := "@@index"
+		if idx.Unique {
+			attr = "@@unique"
+		}
+		attr += "([" + strings.Join(names, ", ") + "]"
+		if idx.Name != "" {
+			attr += ", map: " + strconv.Quote(idx.Name)
+		}
+		block = append(block, attr+")")
+	}
+	if m.name != m.table.Name {
+		block = append(block, "@@map("+strconv.Quote(m.table.Name)+")")
+	}
+	if m.table.Schema != "" {
+		block = append(block, "@@schema("+strconv.Quote(m.table.Schema)+")")
+	}
+	if len(block) > 0 {
+		w.printf("\n")
+		for _, a := range block {
+			w.printf("  %s\n", a)
+		}
+	}
+	w.printf("}\n\n")
+}
+
+// relationGroup is one foreign key: the column pairs of relationships
+// sharing tables and name
+type relationGroup struct {
+	name          string
+	child, parent *model
+	fks, refs     []domain.Field
+	one           bool // One-to-one
+}
+
+// relations adds a relation field holding the foreign key to the child
+// model and a back-relation field to the parent model
+func (w *writer) relations() {
+	groups := make([]*relationGroup, 0)
+	byKey := make(map[string]*relationGroup)
+	pairs := make(map[string]int)
+
+	for _, r := range w.snap.Relationships {
+		childID, fkID := r.ForeignKey()
+		parentID, refID := r.TargetTableID, r.TargetFieldID
+		childCard, parentCard := r.SourceCardinality, r.TargetCardinality
+		if childID == r.TargetTableID && fkID == r.TargetFieldID {
+			parentID, refID = r.SourceTableID, r.SourceFieldID
+			childCard, parentCard = parentCard, childCard
+		}
+		child, parent := w.byID[childID], w.byID[parentID]
+		if child == nil || parent == nil {
+			continue
+		}
+		if childCard == domain.CardinalityMany && parentCard == domain.CardinalityMany {
+			child.relations = append(child.relations,
+				fmt.Sprintf("// many-to-many relationship %s needs an explicit join model", nonEmpty(r.Name, child.table.Name+" - "+parent.table.Name)))
+			continue
+		}
+		fk, ok1 := child.table.FieldByID(fkID)
+		ref, ok2 := parent.table.FieldByID(refID)
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		key := childID + "\x00" + parentID + "\x00" + r.Name
+		g, ok := byKey[key]
+		if !ok || r.Name == "" {
+			g = &relationGroup{name: r.Name, child: child, parent: parent, one: childCard != domain.CardinalityMany}
+			byKey[key] = g
+			groups = append(groups, g)
+			pairs[pairKey(childID, parentID)]++
+		}
+		g.fks = append(g.fks, fk)
+		g.refs = append(g.refs, ref)
+	}
+
+	relationNames := make(map[string]bool)
+	for _, g := range groups {
+		name := ""
+		if pairs[pairKey(g.child.table.TableID, g.parent.table.TableID)] > 1 || g.child == g.parent {
+			name = g.name
+			if name == "" {
+				name = g.child.name + "_" + g.child.names[g.fks[0].ID]
+			}
+			name = uniqueName(relationNames, name)
+		}
+
+		fks, refs := make([]string, 0, len(g.fks)), make([]string, 0, len(g.refs))
+		optional := ""
+		for i := range g.fks {
+			fks = append(fks, g.child.names[g.fks[i].ID])
+			refs = append(refs, g.parent.names[g.refs[i].ID])
+			if g.fks[i].Nullable {
+				optional = "?"
+			}
+		}
+		args := fmt.Sprintf("fields: [%s], references: [%s]", strings.Join(fks, ", "), strings.Join(refs, ", "))
+		if name != "" {
+			args = strconv.Quote(name) + ", " + args
+		}
+
+		field := relationFieldName(g.child.names[g.fks[0].ID])
+		if field == "" {
+			field = lowerFirst(g.parent.name)
+		}
+		field = uniqueName(g.child.taken, field)
+		g.child.relations = append(g.child.relations, fmt.Sprintf("%s %s%s @relation(%s)", field, g.parent.name, optional, args))
+
+		back, typ := lowerFirst(codegen.Plural(g.child.name)), g.child.name+"[]"
+		if g.one {
+			back, typ = lowerFirst(g.child.name), g.child.name+"?"
+		}
+		back = uniqueName(g.parent.taken, back)
+		if name != "" {
+			typ += " @relation(" + strconv.Quote(name) + ")"
+		}
+		g.parent.relations = append(g.parent.relations, back+" "+typ)
+	}
+}
+
+// fieldType returns the Prisma type of a column, its @default argument and
+// its native type attribute
+func (w *writer) fieldType(f domain.Field) (typ, def, native string) {
+	raw := strings.TrimSpace(f.Type)
+	list := strings.HasSuffix(raw, "[]")
+	raw = strings.TrimSuffix(raw, "[]")
+	base := strings.ToLower(raw)
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		base = strings.TrimSpace(base[:i])
+	}
+
+	typ, enum := scalarFor(base), false
+	if name, ok := w.enums[base]; ok {
+		typ, enum = name, true
+	} else if typ == "" {
+		typ = "Unsupported(" + strconv.Quote(raw) + ")"
+	} else {
+		native = w.nativeType(base, f)
+	}
+	def = w.defaultValue(f, typ, base, enum)
+
+	switch {
+	case list:
+		typ += "[]"
+	case f.Nullable && !f.PrimaryKey:
+		typ += "?"
+	}
+	return typ, def, native
+}
+
+// scalarFor maps a lower-case SQL type to a Prisma scalar type
+func scalarFor(base string) string {
+	switch base {
+	case "int", "integer", "int4", "serial", "mediumint", "smallint", "int2", "smallserial", "tinyint":
+		return "Int"
+	case "bigint", "int8", "bigserial":
+		return "BigInt"
+	case "bool", "boolean", "bit":
+		return "Boolean"
+	case "real", "float", "float4", "float8", "double", "double precision":
+		return "Float"
+	case "decimal", "numeric", "money", "number":
+		return "Decimal"
+	case "date", "time", "timetz", "timestamp", "timestamptz", "datetime", "datetime2", "smalldatetime",
+		"timestamp with time zone", "timestamp without time zone":
+		return "DateTime"
+	case "json", "jsonb":
+		return "Json"
+	case "bytea", "blob", "longblob", "binary", "varbinary", "image":
+		return "Bytes"
+	case "text", "char", "character", "varchar", "character varying", "nchar", "nvarchar", "varchar2",
+		"nvarchar2", "uuid", "citext", "string", "tinytext", "mediumtext", "longtext", "ntext", "clob":
+		return "String"
+	}
+	return ""
+}
+
+// nativeType returns the @db attribute preserving a length, precision or
+// specific type, limited to those the provider supports
+func (w *writer) nativeType(base string, f domain.Field) string {
+	if w.provider == "sqlite" {
+		return ""
+	}
+	pg := w.provider == "postgresql" || w.provider == "cockroachdb"
+	switch {
+	case (base == "varchar" || base == "character varying") && f.CharacterMaximumLength != "" && f.CharacterMaximumLength != "max":
+		return "@db.VarChar(" + f.CharacterMaximumLength + ")"
+	case (base == "char" || base == "character") && f.CharacterMaximumLength != "":
+		return "@db.Char(" + f.CharacterMaximumLength + ")"
+	case (base == "decimal" || base == "numeric") && f.Precision != nil:
+		if f.Scale != nil {
+			return fmt.Sprintf("@db.Decimal(%d, %d)", *f.Precision, *f.Scale)
+		}
+		return fmt.Sprintf("@db.Decimal(%d, 0)", *f.Precision)
+	case base == "smallint" || base == "int2":
+		return "@db.SmallInt"
+	case base == "date":
+		return "@db.Date"
+	case base == "uuid" && pg:
+		return "@db.Uuid"
+	case base == "timestamptz" && pg:
+		return "@db.Timestamptz"
+	case base == "text" && w.provider == "mysql":
+		return "@db.Text"
+	}
+	return ""
+}
+
+var numberLiteral = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// defaultValue converts a SQL default into a Prisma @default argument
+func (w *writer) defaultValue(f domain.Field, typ, base string, enum bool) string {
+	d := strings.TrimSpace(f.Default)
+	lower := strings.ToLower(d)
+	switch {
+	case f.Increment || base == "serial" || base == "bigserial" || base == "smallserial":
+		return "autoincrement()"
+	case d == "":
+		return ""
+	case lower == "now()" || lower == "current_timestamp" || lower == "current_timestamp()" || lower == "getdate()":
+		return "now()"
+	case lower == "uuid()" || lower == "cuid()" || lower == "nanoid()":
+		return lower
+	case len(d) >= 2 && d[0] == '\'' && d[len(d)-1] == '\'':
+		s := strings.ReplaceAll(d[1:len(d)-1], "''", "'")
+		if enum && ident(s) == s {
+			return s
+		}
+		return strconv.Quote(s)
+	case typ == "Boolean" && (lower == "true" || lower == "false"):
+		return lower
+	case numberLiteral.MatchString(d) && (typ == "Int" || typ == "BigInt" || typ == "Float" || typ == "Decimal"):
+		return d
+	case enum && ident(d) == d:
+		return d
+	}
+	return "dbgenerated(" + strconv.Quote(d) + ")"
+}
+
+// relationFieldName derives a relation field from its foreign key, e.g. author_id -> author
+func relationFieldName(fk string) string {
+	for _, suffix := range []string{"_id", "Id", "ID", "_fk"} {
+		if strings.HasSuffix(fk, suffix) && len(fk) > len(suffix) {
+			return strings.TrimSuffix(fk, suffix)
+		}
+	}
+	return ""
+}
+
+// ident makes a valid Prisma identifier: letters, digits and underscores, starting with a letter
+func ident(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	out := b.String()
+	if out == "" || !unicode.IsLetter(rune(out[0])) {
+		out = "x" + out
+	}
+	return out
+}
+
+// uniqueName suffixes name with 2, 3, ... until it is not taken
+func uniqueName(taken map[string]bool, name string) string {
+	candidate := name
+	for n := 2; taken[candidate]; n++ {
+		candidate = name + strconv.Itoa(n)
+	}
+	taken[candidate] = true
+	return candidate
+}
+
+func pairKey(a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+	return a + "\x00" + b
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	rs := []rune(s)
+	rs[0] = unicode.ToLower(rs[0])
+	return string(rs)
+}
+
+func writeDoc(b *strings.Builder, indent, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(b, "%s/// %s\n", indent, strings.TrimRight(line, " "))
+	}
+}
+
+func nonEmpty(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
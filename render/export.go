@@ -0,0 +1,64 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Accepted range of the scale option
+const (
+	MinScale = 0.1
+	MaxScale = 4.0
+)
+
+// SVG implements domain.Exporter; options: scale (default 1) and theme (light or dark)
+type SVG struct{}
+
+func (SVG) ContentType() string { return "image/svg+xml" }
+
+func (SVG) Extension() string { return "svg" }
+
+func (SVG) Export(snap *domain.DiagramSnapshot, opts map[string]string) ([]byte, error) {
+	scale, theme, err := parseOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return Build(snap, theme).SVG(scale), nil
+}
+
+// PNG implements domain.Exporter with the same options as SVG
+type PNG struct{}
+
+func (PNG) ContentType() string { return "image/png" }
+
+func (PNG) Extension() string { return "png" }
+
+func (PNG) Export(snap *domain.DiagramSnapshot, opts map[string]string) ([]byte, error) {
+	scale, theme, err := parseOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	data, err := Build(snap, theme).PNG(scale)
+	if err == ErrTooLarge {
+		return nil, fmt.Errorf("%w: image exceeds %d pixels, lower the scale or narrow the scope", domain.ErrBadParamInput, MaxPixels)
+	}
+	return data, err
+}
+
+func parseOptions(opts map[string]string) (float64, Theme, error) {
+	scale := 1.0
+	if s := opts["scale"]; s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < MinScale || v > MaxScale {
+			return 0, Theme{}, fmt.Errorf("%w: scale must be a number between %g and %g", domain.ErrBadParamInput, MinScale, MaxScale)
+		}
+		scale = v
+	}
+	theme, ok := ThemeByName(opts["theme"])
+	if !ok {
+		return 0, Theme{}, fmt.Errorf("%w: unknown theme %q", domain.ErrBadParamInput, opts["theme"])
+	}
+	return scale, theme, nil
+}
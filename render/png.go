@@ -0,0 +1,153 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+)
+
+// MaxPixels bounds the size of a rasterized image
+const MaxPixels = 64 << 20
+
+// ErrTooLarge is returned when the scaled scene exceeds MaxPixels
+var ErrTooLarge = errors.New("image too large")
+
+// PNG rasterizes the scene at the given scale. Shapes are drawn without
+// anti-aliasing and rounded corners are left square; text uses the built-in
+// bitmap font scaled to the font size.
+func (s *Scene) PNG(scale float64) ([]byte, error) {
+	w, h := int(math.Ceil(s.Width*scale)), int(math.Ceil(s.Height*scale))
+	if w <= 0 || h <= 0 || w*h > MaxPixels {
+		return nil, ErrTooLarge
+	}
+	c := &canvas{img: image.NewRGBA(image.Rect(0, 0, w, h)), scale: scale}
+	if bg, ok := parseColor(s.Background); ok {
+		draw.Draw(c.img, c.img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	}
+
+	for _, shape := range s.Shapes {
+		switch sh := shape.(type) {
+		case Rect:
+			if fill, ok := parseColor(sh.Fill); ok {
+				if sh.FillOpacity > 0 {
+					fill.A = uint8(255 * sh.FillOpacity)
+				}
+				c.fillRect(sh.X, sh.Y, sh.W, sh.H, fill)
+			}
+			if stroke, ok := parseColor(sh.Stroke); ok && sh.StrokeWidth > 0 {
+				p := []Point{{sh.X, sh.Y}, {sh.X + sh.W, sh.Y}, {sh.X + sh.W, sh.Y + sh.H}, {sh.X, sh.Y + sh.H}, {sh.X, sh.Y}}
+				c.polyline(p, sh.StrokeWidth, stroke, sh.Dashed)
+			}
+		case Line:
+			if stroke, ok := parseColor(sh.Stroke); ok {
+				c.polyline(sh.Points, sh.Width, stroke, sh.Dashed)
+			}
+		case Text:
+			if col, ok := parseColor(sh.Color); ok {
+				c.text(sh, col)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type canvas struct {
+	img   *image.RGBA
+	scale float64
+}
+
+// fillRect fills a rectangle given in scene units, blending translucent colors
+func (c *canvas) fillRect(x, y, w, h float64, col color.NRGBA) {
+	r := image.Rect(
+		int(math.Round(x*c.scale)), int(math.Round(y*c.scale)),
+		int(math.Round((x+w)*c.scale)), int(math.Round((y+h)*c.scale)),
+	)
+	if r.Dx() == 0 && w > 0 {
+		r.Max.X++
+	}
+	if r.Dy() == 0 && h > 0 {
+		r.Max.Y++
+	}
+	draw.Draw(c.img, r, image.NewUniform(col), image.Point{}, draw.Over)
+}
+
+// polyline strokes connected segments by stamping squares along them
+func (c *canvas) polyline(pts []Point, width float64, col color.NRGBA, dashed bool) {
+	if len(pts) < 2 {
+		return
+	}
+	width = math.Max(width, 1/c.scale)
+	half := width / 2
+	step := 0.5 / c.scale
+	travelled := 0.0
+	for i := 1; i < len(pts); i++ {
+		a, b := pts[i-1], pts[i]
+		length := math.Hypot(b.X-a.X, b.Y-a.Y)
+		for d := 0.0; d <= length; d += step {
+			if !dashed || math.Mod(travelled+d, 10) < 6 {
+				t := 0.0
+				if length > 0 {
+					t = d / length
+				}
+				x, y := a.X+(b.X-a.X)*t, a.Y+(b.Y-a.Y)*t
+				c.stamp(x-half, y-half, width, col)
+			}
+		}
+		travelled += length
+	}
+}
+
+// stamp fills a square pen mark, at least one pixel wide
+func (c *canvas) stamp(x, y, size float64, col color.NRGBA) {
+	r := image.Rect(
+		int(math.Round(x*c.scale)), int(math.Round(y*c.scale)),
+		int(math.Round((x+size)*c.scale)), int(math.Round((y+size)*c.scale)),
+	)
+	if r.Dx() == 0 {
+		r.Max.X++
+	}
+	if r.Dy() == 0 {
+		r.Max.Y++
+	}
+	draw.Draw(c.img, r, image.NewUniform(col), image.Point{}, draw.Over)
+}
+
+// text draws a line with the bitmap font; one font pixel is a tenth of the size
+func (c *canvas) text(t Text, col color.NRGBA) {
+	unit := t.Size / 10
+	width := textWidth(t.Value, t.Size)
+	x := t.X
+	switch t.Anchor {
+	case "middle":
+		x -= width / 2
+	case "end":
+		x -= width
+	}
+	top := t.Y - 3.5*unit
+
+	for _, r := range t.Value {
+		g := glyph(r)
+		for col5, bits := range g {
+			for row := 0; row < 7; row++ {
+				if bits&(1<<row) == 0 {
+					continue
+				}
+				px, py := x+float64(col5)*unit, top+float64(row)*unit
+				c.fillRect(px, py, unit, unit, col)
+				if t.Bold {
+					c.fillRect(px+unit/2, py, unit, unit, col)
+				}
+			}
+		}
+		x += t.Size * charWidth
+	}
+}
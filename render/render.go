@@ -0,0 +1,315 @@
+// Package render draws diagrams as images without a browser.
+//
+// Build turns a snapshot into a Scene of simple shapes at the stored canvas
+// coordinates; the scene is then written as SVG or rasterized to PNG. Text
+// is measured as a monospace font so that both outputs lay out the same.
+package render
+
+import (
+	"math"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Geometry of the drawing, in canvas units
+const (
+	margin       = 40
+	fontSize     = 13
+	smallSize    = 11
+	charWidth    = 0.6 // Advance of one character, relative to the font size
+	textPadding  = 12
+	keyColumn    = 26
+	glyphLength  = 12
+	loopDistance = 32
+)
+
+// Point is a scene position
+type Point struct{ X, Y float64 }
+
+// Rect is a filled and/or stroked rectangle
+type Rect struct {
+	X, Y, W, H  float64
+	Fill        string
+	FillOpacity float64 // 0 means opaque
+	Stroke      string
+	StrokeWidth float64
+	Radius      float64
+	Dashed      bool
+}
+
+// Line is an open polyline
+type Line struct {
+	Points []Point
+	Stroke string
+	Width  float64
+	Dashed bool
+}
+
+// Text is one line of text vertically centered on Y
+type Text struct {
+	X, Y   float64
+	Size   float64
+	Color  string
+	Bold   bool
+	Anchor string // "start", "middle" or "end"
+	Value  string
+}
+
+// Scene is a list of shapes drawn in order
+type Scene struct {
+	Width, Height float64
+	Background    string
+	Shapes        []interface{} // Rect, Line or Text
+}
+
+func (s *Scene) add(shape interface{}) { s.Shapes = append(s.Shapes, shape) }
+
+// Build lays out the snapshot's areas, relationships, tables and notes
+func Build(snap *domain.DiagramSnapshot, theme Theme) *Scene {
+	minX, minY, maxX, maxY := bounds(snap)
+	dx, dy := margin-minX, margin-minY
+	sc := &Scene{
+		Width:      maxX - minX + 2*margin,
+		Height:     maxY - minY + 2*margin,
+		Background: theme.Background,
+	}
+	b := &builder{scene: sc, theme: theme, dx: dx, dy: dy, tables: make(map[string]domain.Table), fks: make(map[string]bool)}
+	for _, t := range snap.Tables {
+		b.tables[t.TableID] = t
+	}
+	for _, r := range snap.Relationships {
+		_, fieldID := r.ForeignKey()
+		b.fks[fieldID] = true
+	}
+
+	for _, a := range snap.Areas {
+		b.area(a)
+	}
+	for _, r := range snap.Relationships {
+		b.relationship(r)
+	}
+	for _, t := range snap.Tables {
+		b.table(t)
+	}
+	for _, n := range snap.Notes {
+		b.note(n)
+	}
+	return sc
+}
+
+// bounds returns the canvas extent of everything drawn
+func bounds(snap *domain.DiagramSnapshot) (minX, minY, maxX, maxY float64) {
+	first := true
+	grow := func(x, y, w, h int) {
+		x0, y0, x1, y1 := float64(x), float64(y), float64(x+w), float64(y+h)
+		if first {
+			minX, minY, maxX, maxY = x0, y0, x1, y1
+			first = false
+			return
+		}
+		minX, minY = math.Min(minX, x0), math.Min(minY, y0)
+		maxX, maxY = math.Max(maxX, x1), math.Max(maxY, y1)
+	}
+	for _, t := range snap.Tables {
+		w, h := t.Size()
+		grow(t.X, t.Y, w, h)
+	}
+	for _, a := range snap.Areas {
+		grow(a.X, a.Y, a.Width, a.Height)
+	}
+	for _, n := range snap.Notes {
+		grow(n.X, n.Y, n.Width, n.Height)
+	}
+	// Self-referencing edges loop to the right of their table
+	if len(snap.Relationships) > 0 {
+		maxX += loopDistance
+	}
+	return minX, minY, maxX, maxY
+}
+
+type builder struct {
+	scene  *Scene
+	theme  Theme
+	dx, dy float64
+	tables map[string]domain.Table
+	fks    map[string]bool // Field IDs holding a foreign key
+}
+
+func (b *builder) area(a domain.Area) {
+	x, y := float64(a.X)+b.dx, float64(a.Y)+b.dy
+	c := a.Color
+	if _, ok := parseColor(c); !ok {
+		c = b.theme.Muted
+	}
+	b.scene.add(Rect{X: x, Y: y, W: float64(a.Width), H: float64(a.Height), Fill: c, FillOpacity: 0.08, Stroke: c, StrokeWidth: 1.5, Radius: 8, Dashed: true})
+	b.scene.add(Text{X: x + textPadding, Y: y + 20, Size: fontSize, Color: c, Bold: true, Anchor: "start",
+		Value: fit(a.Name, float64(a.Width)-2*textPadding, fontSize)})
+}
+
+func (b *builder) table(t domain.Table) {
+	w, h := t.Size()
+	x, y := float64(t.X)+b.dx, float64(t.Y)+b.dy
+	header := t.Color
+	if _, ok := parseColor(header); !ok {
+		header = b.theme.Header
+	}
+
+	b.scene.add(Rect{X: x, Y: y, W: float64(w), H: float64(h), Fill: b.theme.TableFill, Stroke: b.theme.Border, StrokeWidth: 1, Radius: 6})
+	b.scene.add(Rect{X: x, Y: y, W: float64(w), H: domain.TableHeaderHeight, Fill: header, Radius: 6})
+	b.scene.add(Text{X: x + float64(w)/2, Y: y + domain.TableHeaderHeight/2, Size: fontSize, Color: contrastText(header), Bold: true, Anchor: "middle",
+		Value: fit(t.QualifiedName(), float64(w)-2*textPadding, fontSize)})
+
+	for i, f := range t.FieldList() {
+		ry := y + domain.TableHeaderHeight + float64(i*domain.TableFieldHeight)
+		if i%2 == 1 {
+			b.scene.add(Rect{X: x + 1, Y: ry, W: float64(w) - 2, H: domain.TableFieldHeight, Fill: b.theme.RowStripe})
+		}
+		cy := ry + domain.TableFieldHeight/2
+
+		key := ""
+		switch {
+		case f.PrimaryKey:
+			key = "PK"
+		case b.fks[f.ID]:
+			key = "FK"
+		}
+		if key != "" {
+			b.scene.add(Text{X: x + 8, Y: cy, Size: smallSize - 2, Color: b.theme.Muted, Bold: true, Anchor: "start", Value: key})
+		}
+
+		typ := f.SQLType()
+		typeWidth := math.Min(textWidth(typ, smallSize), float64(w)/2-textPadding)
+		nameWidth := float64(w) - keyColumn - typeWidth - 2*textPadding
+		b.scene.add(Text{X: x + keyColumn, Y: cy, Size: fontSize, Color: b.theme.Text, Bold: f.PrimaryKey, Anchor: "start", Value: fit(f.Name, nameWidth, fontSize)})
+		b.scene.add(Text{X: x + float64(w) - textPadding, Y: cy, Size: smallSize, Color: b.theme.Muted, Anchor: "end", Value: fit(typ, typeWidth, smallSize)})
+	}
+}
+
+func (b *builder) relationship(r domain.Relationship) {
+	src, ok1 := b.tables[r.SourceTableID]
+	tgt, ok2 := b.tables[r.TargetTableID]
+	if !ok1 || !ok2 {
+		return
+	}
+
+	sx0, sx1, sy := b.anchor(src, r.SourceFieldID)
+	tx0, tx1, ty := b.anchor(tgt, r.TargetFieldID)
+
+	// Leave from the facing sides; tables in the same column loop around the right
+	var start, end Point
+	var sd, td float64 // Outward direction of each end: -1 left, +1 right
+	var pts []Point
+	switch {
+	case sx1 < tx0:
+		start, end, sd, td = Point{sx1, sy}, Point{tx0, ty}, 1, -1
+		mid := (sx1 + tx0) / 2
+		pts = []Point{start, {mid, sy}, {mid, ty}, end}
+	case tx1 < sx0:
+		start, end, sd, td = Point{sx0, sy}, Point{tx1, ty}, -1, 1
+		mid := (tx1 + sx0) / 2
+		pts = []Point{start, {mid, sy}, {mid, ty}, end}
+	default:
+		start, end, sd, td = Point{sx1, sy}, Point{tx1, ty}, 1, 1
+		x := math.Max(sx1, tx1) + loopDistance
+		pts = []Point{start, {x, sy}, {x, ty}, end}
+	}
+
+	b.scene.add(Line{Points: pts, Stroke: b.theme.Edge, Width: 1.5})
+	b.glyph(start, sd, r.SourceCardinality == domain.CardinalityMany)
+	b.glyph(end, td, r.TargetCardinality == domain.CardinalityMany)
+}
+
+// anchor returns the left and right x of a table and the y of a field's row
+func (b *builder) anchor(t domain.Table, fieldID string) (float64, float64, float64) {
+	w, _ := t.Size()
+	x, y := float64(t.X)+b.dx, float64(t.Y)+b.dy
+	cy := y + domain.TableHeaderHeight/2
+	for i, f := range t.FieldList() {
+		if f.ID == fieldID {
+			cy = y + domain.TableHeaderHeight + float64(i*domain.TableFieldHeight) + domain.TableFieldHeight/2
+			break
+		}
+	}
+	return x, x + float64(w), cy
+}
+
+// glyph draws crow's-foot notation at an edge end: a bar for "one" and a
+// three-pronged foot for "many"; dir points away from the table
+func (b *builder) glyph(p Point, dir float64, many bool) {
+	if many {
+		tip := Point{p.X + dir*glyphLength, p.Y}
+		for _, dy := range []float64{-7, 0, 7} {
+			b.scene.add(Line{Points: []Point{tip, {p.X, p.Y + dy}}, Stroke: b.theme.Edge, Width: 1.5})
+		}
+		return
+	}
+	x := p.X + dir*glyphLength*0.6
+	b.scene.add(Line{Points: []Point{{x, p.Y - 7}, {x, p.Y + 7}}, Stroke: b.theme.Edge, Width: 1.5})
+}
+
+func (b *builder) note(n domain.Note) {
+	x, y := float64(n.X)+b.dx, float64(n.Y)+b.dy
+	fill, border := b.theme.NoteFill, b.theme.NoteBorder
+	if _, ok := parseColor(n.Color); ok {
+		fill, border = n.Color, n.Color
+	}
+	b.scene.add(Rect{X: x, Y: y, W: float64(n.Width), H: float64(n.Height), Fill: fill, FillOpacity: 0.9, Stroke: border, StrokeWidth: 1, Radius: 4})
+
+	lineHeight := fontSize * 1.4
+	maxLines := int((float64(n.Height) - textPadding) / lineHeight)
+	lines := wrap(n.Content, float64(n.Width)-2*textPadding, fontSize)
+	if len(lines) > maxLines {
+		lines = lines[:max(maxLines, 0)]
+	}
+	for i, l := range lines {
+		b.scene.add(Text{X: x + textPadding, Y: y + textPadding + lineHeight*(float64(i)+0.5), Size: fontSize, Color: contrastText(fill), Anchor: "start", Value: l})
+	}
+}
+
+// textWidth estimates the advance of s at the given font size
+func textWidth(s string, size float64) float64 {
+	return float64(len([]rune(s))) * size * charWidth
+}
+
+// fit truncates s with an ellipsis so that it fits in width
+func fit(s string, width, size float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+	n := int(width/(size*charWidth)) - 1
+	if n <= 0 {
+		return ""
+	}
+	return string([]rune(s)[:n]) + "…"
+}
+
+// wrap breaks text into lines of at most width, keeping explicit line breaks
+func wrap(s string, width, size float64) []string {
+	perLine := max(1, int(width/(size*charWidth)))
+	lines := make([]string, 0)
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			for len([]rune(word)) > perLine {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				lines = append(lines, string([]rune(word)[:perLine]))
+				word = string([]rune(word)[perLine:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case len([]rune(line))+1+len([]rune(word)) <= perLine:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
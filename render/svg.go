@@ -0,0 +1,79 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+const svgFont = `ui-monospace, SFMono-Regular, Menlo, Consolas, "Liberation Mono", monospace`
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&#39;")
+
+// SVG writes the scene as a standalone SVG document, scaled by scale
+func (s *Scene) SVG(scale float64) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" font-family="%s">`+"\n",
+		num(s.Width*scale), num(s.Height*scale), num(s.Width), num(s.Height), xmlEscaper.Replace(svgFont))
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", xmlEscaper.Replace(s.Background))
+
+	for _, shape := range s.Shapes {
+		switch sh := shape.(type) {
+		case Rect:
+			fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s"`, num(sh.X), num(sh.Y), num(sh.W), num(sh.H))
+			if sh.Radius > 0 {
+				fmt.Fprintf(&b, ` rx="%s"`, num(sh.Radius))
+			}
+			b.WriteString(paint("fill", sh.Fill))
+			if sh.FillOpacity > 0 {
+				fmt.Fprintf(&b, ` fill-opacity="%s"`, num(sh.FillOpacity))
+			}
+			if sh.Stroke != "" {
+				b.WriteString(paint("stroke", sh.Stroke))
+				fmt.Fprintf(&b, ` stroke-width="%s"`, num(sh.StrokeWidth))
+				if sh.Dashed {
+					b.WriteString(` stroke-dasharray="6 4"`)
+				}
+			}
+			b.WriteString("/>\n")
+		case Line:
+			pts := make([]string, 0, len(sh.Points))
+			for _, p := range sh.Points {
+				pts = append(pts, num(p.X)+","+num(p.Y))
+			}
+			fmt.Fprintf(&b, `<polyline points="%s" fill="none"%s stroke-width="%s"`, strings.Join(pts, " "), paint("stroke", sh.Stroke), num(sh.Width))
+			if sh.Dashed {
+				b.WriteString(` stroke-dasharray="6 4"`)
+			}
+			b.WriteString("/>\n")
+		case Text:
+			if sh.Value == "" {
+				continue
+			}
+			fmt.Fprintf(&b, `<text x="%s" y="%s" font-size="%s" text-anchor="%s" dominant-baseline="central"%s`,
+				num(sh.X), num(sh.Y), num(sh.Size), sh.Anchor, paint("fill", sh.Color))
+			if sh.Bold {
+				b.WriteString(` font-weight="bold"`)
+			}
+			fmt.Fprintf(&b, ">%s</text>\n", xmlEscaper.Replace(sh.Value))
+		}
+	}
+	b.WriteString("</svg>\n")
+	return []byte(b.String())
+}
+
+func paint(attr, c string) string {
+	if c == "" {
+		return fmt.Sprintf(` %s="none"`, attr)
+	}
+	return fmt.Sprintf(` %s="%s"`, attr, xmlEscaper.Replace(c))
+}
+
+// num formats a coordinate with at most two decimals
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
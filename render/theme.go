@@ -0,0 +1,92 @@
+package render
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Theme holds the colors of a rendered diagram
+type Theme struct {
+	Name       string
+	Background string
+	TableFill  string
+	RowStripe  string
+	Border     string
+	Text       string
+	Muted      string
+	Edge       string
+	Header     string // Used when a table has no color
+	NoteFill   string
+	NoteBorder string
+}
+
+// Built-in themes
+var (
+	Light = Theme{
+		Name:       "light",
+		Background: "#ffffff",
+		TableFill:  "#ffffff",
+		RowStripe:  "#f8fafc",
+		Border:     "#cbd5e1",
+		Text:       "#0f172a",
+		Muted:      "#64748b",
+		Edge:       "#64748b",
+		Header:     "#6366f1",
+		NoteFill:   "#fef9c3",
+		NoteBorder: "#eab308",
+	}
+	Dark = Theme{
+		Name:       "dark",
+		Background: "#0f172a",
+		TableFill:  "#1e293b",
+		RowStripe:  "#243247",
+		Border:     "#334155",
+		Text:       "#e2e8f0",
+		Muted:      "#94a3b8",
+		Edge:       "#94a3b8",
+		Header:     "#6366f1",
+		NoteFill:   "#422006",
+		NoteBorder: "#ca8a04",
+	}
+)
+
+// ThemeByName returns a built-in theme; an empty name selects the light theme
+func ThemeByName(name string) (Theme, bool) {
+	switch strings.ToLower(name) {
+	case "", Light.Name:
+		return Light, true
+	case Dark.Name:
+		return Dark, true
+	}
+	return Theme{}, false
+}
+
+// parseColor reads #rgb or #rrggbb; ok is false for anything else
+func parseColor(s string) (color.NRGBA, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return color.NRGBA{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, true
+}
+
+// contrastText picks black or white text for a background color
+func contrastText(bg string) string {
+	c, ok := parseColor(bg)
+	if !ok {
+		return "#ffffff"
+	}
+	// Relative luminance with the usual sRGB weights
+	if 0.299*float64(c.R)+0.587*float64(c.G)+0.114*float64(c.B) > 160 {
+		return "#0f172a"
+	}
+	return "#ffffff"
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/iots1/vertex-diagram/domain"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -26,13 +27,42 @@ func (m *mongoAreaRepository) Store(ctx context.Context, a *domain.Area) error {
 
 	res, err := m.Conn.InsertOne(ctx, a)
 	if err == nil {
-		if oid, ok := res.InsertedID.(string); ok {
-			a.ID = oid
+		switch id := res.InsertedID.(type) {
+		case string:
+			a.ID = id
+		case primitive.ObjectID:
+			a.ID = id.Hex()
 		}
 	}
 	return err
 }
 
+func (m *mongoAreaRepository) Update(ctx context.Context, a *domain.Area) error {
+	a.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":       a.Name,
+			"x":          a.X,
+			"y":          a.Y,
+			"width":      a.Width,
+			"height":     a.Height,
+			"color":      a.Color,
+			"table_ids":  a.TableIDs,
+			"area_id":    a.AreaID,
+			"updated_at": a.UpdatedAt,
+		},
+	}
+	res, err := m.Conn.UpdateOne(ctx, areaFilter(a.DiagramID, a.AreaID), update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
 func (m *mongoAreaRepository) StoreMultiple(ctx context.Context, areas []domain.Area) error {
 	if len(areas) == 0 {
 		return nil
@@ -50,6 +80,35 @@ func (m *mongoAreaRepository) StoreMultiple(ctx context.Context, areas []domain.
 	return err
 }
 
+func (m *mongoAreaRepository) GetByID(ctx context.Context, diagramID, areaID string) (*domain.Area, error) {
+	var a domain.Area
+	err := m.Conn.FindOne(ctx, areaFilter(diagramID, areaID)).Decode(&a)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	legacyAreaID(&a)
+	return &a, nil
+}
+
+// areaFilter matches an area by its frontend ID within a diagram. Areas saved
+// before area_id existed are listed with their _id as the ID, which may be an
+// ObjectID, so they are matched on that instead
+func areaFilter(diagramID, areaID string) bson.M {
+	legacy := idFilter(areaID)
+	legacy["area_id"] = bson.M{"$exists": false}
+	return bson.M{"diagram_id": diagramID, "$or": bson.A{bson.M{"area_id": areaID}, legacy}}
+}
+
+// legacyAreaID fills AreaID for areas stored before it had its own field
+func legacyAreaID(a *domain.Area) {
+	if a.AreaID == "" {
+		a.AreaID = a.ID
+	}
+}
+
 func (m *mongoAreaRepository) GetByDiagramID(ctx context.Context, diagramID string) ([]domain.Area, error) {
 	cursor, err := m.Conn.Find(ctx, bson.M{"diagram_id": diagramID})
 	if err != nil {
@@ -61,6 +120,9 @@ func (m *mongoAreaRepository) GetByDiagramID(ctx context.Context, diagramID stri
 	if err = cursor.All(ctx, &areas); err != nil {
 		return nil, err
 	}
+	for i := range areas {
+		legacyAreaID(&areas[i])
+	}
 	return areas, nil
 }
 
@@ -76,3 +138,15 @@ func (m *mongoAreaRepository) DeleteByDiagramID(ctx context.Context, diagramID s
 	_, err := m.Conn.DeleteMany(ctx, bson.M{"diagram_id": diagramID})
 	return err
 }
+
+func (m *mongoAreaRepository) SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error {
+	return markDeleted(ctx, m.Conn, diagramID, at)
+}
+
+func (m *mongoAreaRepository) UndeleteByDiagramID(ctx context.Context, diagramID string) error {
+	return unmarkDeleted(ctx, m.Conn, diagramID)
+}
+
+func (m *mongoAreaRepository) PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error {
+	return purgeDeleted(ctx, m.Conn, diagramID, before)
+}
@@ -76,3 +76,15 @@ func (m *mongoCustomTypeRepository) DeleteByDiagramID(ctx context.Context, diagr
 	_, err := m.Conn.DeleteMany(ctx, bson.M{"diagram_id": diagramID})
 	return err
 }
+
+func (m *mongoCustomTypeRepository) SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error {
+	return markDeleted(ctx, m.Conn, diagramID, at)
+}
+
+func (m *mongoCustomTypeRepository) UndeleteByDiagramID(ctx context.Context, diagramID string) error {
+	return unmarkDeleted(ctx, m.Conn, diagramID)
+}
+
+func (m *mongoCustomTypeRepository) PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error {
+	return purgeDeleted(ctx, m.Conn, diagramID, before)
+}
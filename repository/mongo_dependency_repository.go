@@ -76,3 +76,15 @@ func (m *mongoDependencyRepository) DeleteByDiagramID(ctx context.Context, diagr
 	_, err := m.Conn.DeleteMany(ctx, bson.M{"diagram_id": diagramID})
 	return err
 }
+
+func (m *mongoDependencyRepository) SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error {
+	return markDeleted(ctx, m.Conn, diagramID, at)
+}
+
+func (m *mongoDependencyRepository) UndeleteByDiagramID(ctx context.Context, diagramID string) error {
+	return unmarkDeleted(ctx, m.Conn, diagramID)
+}
+
+func (m *mongoDependencyRepository) PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error {
+	return purgeDeleted(ctx, m.Conn, diagramID, before)
+}
@@ -66,3 +66,15 @@ func (m *mongoDiagramFilterRepository) DeleteByDiagramID(ctx context.Context, di
 	_, err := m.Conn.DeleteOne(ctx, bson.M{"diagram_id": diagramID})
 	return err
 }
+
+func (m *mongoDiagramFilterRepository) SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error {
+	return markDeleted(ctx, m.Conn, diagramID, at)
+}
+
+func (m *mongoDiagramFilterRepository) UndeleteByDiagramID(ctx context.Context, diagramID string) error {
+	return unmarkDeleted(ctx, m.Conn, diagramID)
+}
+
+func (m *mongoDiagramFilterRepository) PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error {
+	return purgeDeleted(ctx, m.Conn, diagramID, before)
+}
@@ -2,6 +2,10 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/iots1/vertex-diagram/domain"
@@ -20,36 +24,204 @@ func NewMongoRepository(Conn *mongo.Collection) domain.DiagramRepository {
 	return &mongoRepository{Conn}
 }
 
-func (m *mongoRepository) Fetch(ctx context.Context) ([]domain.Diagram, error) {
-	// ดึงข้อมูลไม่เอา Content (เพื่อความเร็ว)
-	opts := options.Find()
-	cursor, err := m.Conn.Find(ctx, bson.M{}, opts)
+func (m *mongoRepository) Fetch(ctx context.Context, q domain.DiagramListQuery) (*domain.DiagramPage, error) {
+	// ดึงข้อมูลไม่เอา Content (เพื่อความเร็ว) และนับจำนวน entity ด้วย aggregation
+	dir := 1
+	if q.Order == domain.SortDesc {
+		dir = -1
+	}
+
+	match := notDeleted()
+	if q.Deleted {
+		match = bson.M{"deleted_at": bson.M{"$exists": true}}
+	}
+	if q.Search != "" {
+		pattern := regexp.QuoteMeta(q.Search)
+		if q.SearchMode != domain.SearchModeContains {
+			pattern = "^" + pattern
+		}
+		match["name"] = primitive.Regex{Pattern: pattern, Options: "i"}
+	}
+
+	// Filter and sort on the stored fields first so the listing indexes on
+	// (sort field, _id) serve the query; id_str is only needed by the lookups
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match}}}
+
+	if q.Cursor != "" {
+		after, err := decodeDiagramCursor(q.Cursor, q.Sort)
+		if err != nil {
+			return nil, err
+		}
+		op := "$gt"
+		if dir < 0 {
+			op = "$lt"
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{q.Sort: bson.M{op: after.value}},
+			bson.M{q.Sort: after.value, "$or": afterID(after.id, dir)},
+		}}}})
+	}
+
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: bson.D{{Key: q.Sort, Value: dir}, {Key: "_id", Value: dir}}}},
+		// Fetch one extra item to know whether another page exists
+		bson.D{{Key: "$limit", Value: q.Limit + 1}},
+		bson.D{{Key: "$project", Value: bson.M{"content": 0}}},
+		// _id may be an ObjectID or a frontend string ID; child collections store it as a string
+		bson.D{{Key: "$addFields", Value: bson.M{"id_str": bson.M{"$toString": "$_id"}}}},
+		countLookup("tables", "table_stats"),
+		countLookup("relationships", "relationship_stats"),
+		countLookup("notes", "note_stats"),
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":                "$id_str",
+			"object_id":          bson.M{"$eq": bson.A{bson.M{"$type": "$_id"}, "objectId"}},
+			"name":               1,
+			"created_at":         1,
+			"updated_at":         1,
+			"deleted_at":         1,
+			"table_count":        bson.M{"$ifNull": bson.A{bson.M{"$first": "$table_stats.n"}, 0}},
+			"relationship_count": bson.M{"$ifNull": bson.A{bson.M{"$first": "$relationship_stats.n"}, 0}},
+			"note_count":         bson.M{"$ifNull": bson.A{bson.M{"$first": "$note_stats.n"}, 0}},
+		}}},
+	)
+
+	cursor, err := m.Conn.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
 	}
-	diagrams := make([]domain.Diagram, 0)
-	if err = cursor.All(ctx, &diagrams); err != nil {
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		domain.DiagramSummary `bson:",inline"`
+		ObjectID              bool `bson:"object_id"`
+	}
+	if err = cursor.All(ctx, &rows); err != nil {
 		return nil, err
 	}
-	return diagrams, nil
+	items := make([]domain.DiagramSummary, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, r.DiagramSummary)
+	}
+
+	page := &domain.DiagramPage{Items: items}
+	if len(items) > q.Limit {
+		page.Items = items[:q.Limit]
+		page.NextCursor = encodeDiagramCursor(page.Items[q.Limit-1], rows[q.Limit-1].ObjectID, q.Sort)
+	}
+	return page, nil
+}
+
+// countLookup joins a child collection by diagram_id and keeps only the document count
+func countLookup(from, as string) bson.D {
+	return bson.D{{Key: "$lookup", Value: bson.M{
+		"from":         from,
+		"localField":   "id_str",
+		"foreignField": "diagram_id",
+		"pipeline":     bson.A{bson.M{"$count": "n"}},
+		"as":           as,
+	}}}
+}
+
+// diagramCursor is the keyset position of the last item on a page
+type diagramCursor struct {
+	value interface{}
+	id    interface{} // string or primitive.ObjectID, as stored
+}
+
+type diagramCursorPayload struct {
+	Value    string `json:"v"`
+	ID       string `json:"id"`
+	ObjectID bool   `json:"oid,omitempty"`
+}
+
+// afterID matches _id values past id in the sort direction. Mongo compares
+// only values of the same type, and orders all strings before all ObjectIDs,
+// so crossing from one type to the other needs its own clause
+func afterID(id interface{}, dir int) bson.A {
+	if _, isOID := id.(primitive.ObjectID); isOID == (dir > 0) {
+		op := "$gt"
+		if dir < 0 {
+			op = "$lt"
+		}
+		return bson.A{bson.M{"_id": bson.M{op: id}}}
+	}
+	if dir > 0 {
+		return bson.A{bson.M{"_id": bson.M{"$gt": id}}, bson.M{"_id": bson.M{"$type": "objectId"}}}
+	}
+	return bson.A{bson.M{"_id": bson.M{"$lt": id}}, bson.M{"_id": bson.M{"$type": "string"}}}
+}
+
+func encodeDiagramCursor(s domain.DiagramSummary, objectID bool, sort string) string {
+	p := diagramCursorPayload{ID: s.ID, ObjectID: objectID}
+	switch sort {
+	case domain.DiagramSortName:
+		p.Value = s.Name
+	case domain.DiagramSortCreatedAt:
+		p.Value = s.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case domain.DiagramSortDeletedAt:
+		if s.DeletedAt != nil {
+			p.Value = s.DeletedAt.UTC().Format(time.RFC3339Nano)
+		}
+	default:
+		p.Value = s.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	raw, _ := json.Marshal(p)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeDiagramCursor(cursor, sort string) (*diagramCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", domain.ErrBadParamInput)
+	}
+	var p diagramCursorPayload
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", domain.ErrBadParamInput)
+	}
+
+	c := &diagramCursor{value: p.Value, id: p.ID}
+	if p.ObjectID {
+		oid, err := primitive.ObjectIDFromHex(p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed cursor", domain.ErrBadParamInput)
+		}
+		c.id = oid
+	}
+	if sort == domain.DiagramSortName {
+		return c, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, p.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor does not match sort %q", domain.ErrBadParamInput, sort)
+	}
+	c.value = t
+	return c, nil
 }
 
 func (m *mongoRepository) GetByID(ctx context.Context, id string) (*domain.Diagram, error) {
 	var d domain.Diagram
+	// Diagrams in the trash are not found
+	filter := notDeleted()
+
 	// 1. Try string ID
-	err := m.Conn.FindOne(ctx, bson.M{"_id": id}).Decode(&d)
+	filter["_id"] = id
+	err := m.Conn.FindOne(ctx, filter).Decode(&d)
 	if err == nil {
 		return &d, nil
 	}
 
 	// 2. Try ObjectID
 	if oid, oerr := primitive.ObjectIDFromHex(id); oerr == nil {
-		err = m.Conn.FindOne(ctx, bson.M{"_id": oid}).Decode(&d)
+		filter["_id"] = oid
+		err = m.Conn.FindOne(ctx, filter).Decode(&d)
 		if err == nil {
 			return &d, nil
 		}
 	}
 
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNotFound
+	}
 	return nil, err
 }
 
@@ -96,12 +268,14 @@ func (m *mongoRepository) Store(ctx context.Context, d *domain.Diagram) error {
 func (m *mongoRepository) Update(ctx context.Context, d *domain.Diagram) error {
 	d.UpdatedAt = time.Now()
 
-	filter := bson.M{"_id": d.ID}
+	// A diagram in the trash is never updated; the upsert then collides with its _id
+	filter := notDeleted()
+	filter["_id"] = d.ID
 	if oid, oerr := primitive.ObjectIDFromHex(d.ID); oerr == nil {
-		filter = bson.M{"_id": oid}
+		filter["_id"] = oid
 	}
 
-	// Use UpdateOne with $set to avoid replacing _id field
+	// Use $set to avoid replacing _id field; every update is a new revision
 	update := bson.M{
 		"$set": bson.M{
 			"name":       d.Name,
@@ -109,11 +283,25 @@ func (m *mongoRepository) Update(ctx context.Context, d *domain.Diagram) error {
 			"created_at": d.CreatedAt,
 			"updated_at": d.UpdatedAt,
 		},
+		"$inc": bson.M{"revision": 1},
 	}
 
-	opts := options.Update().SetUpsert(true)
-	_, err := m.Conn.UpdateOne(ctx, filter, update, opts)
-	return err
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"revision": 1})
+
+	var updated struct {
+		Revision int64 `bson:"revision"`
+	}
+	if err := m.Conn.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("%w: diagram %s is in the trash", domain.ErrConflict, d.ID)
+		}
+		return err
+	}
+	d.Revision = updated.Revision
+	return nil
 }
 
 func (m *mongoRepository) Delete(ctx context.Context, id string) error {
@@ -130,4 +318,53 @@ func (m *mongoRepository) Delete(ctx context.Context, id string) error {
 	}
 	
 	return err
-}
\ No newline at end of file
+}
+
+func (m *mongoRepository) SoftDelete(ctx context.Context, id string, at time.Time) error {
+	return m.updateInState(ctx, id, notDeleted(), bson.M{"$set": bson.M{"deleted_at": at}})
+}
+
+func (m *mongoRepository) Undelete(ctx context.Context, id string) error {
+	return m.updateInState(ctx, id, bson.M{"deleted_at": bson.M{"$exists": true}}, bson.M{"$unset": bson.M{"deleted_at": ""}})
+}
+
+func (m *mongoRepository) InTrash(ctx context.Context, id string) (bool, error) {
+	keys := bson.A{id}
+	if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+		keys = append(keys, oid)
+	}
+	n, err := m.Conn.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": keys}, "deleted_at": bson.M{"$exists": true}})
+	return n > 0, err
+}
+
+func (m *mongoRepository) Purge(ctx context.Context, id string, before time.Time) (bool, error) {
+	keys := bson.A{id}
+	if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+		keys = append(keys, oid)
+	}
+	res, err := m.Conn.DeleteOne(ctx, bson.M{"_id": bson.M{"$in": keys}, "deleted_at": bson.M{"$lt": before}})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// updateInState updates the diagram if it matches state, trying the string ID
+// and then the ObjectID; ErrNotFound means no diagram in that state exists
+func (m *mongoRepository) updateInState(ctx context.Context, id string, state bson.M, update bson.M) error {
+	keys := []interface{}{id}
+	if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+		keys = append(keys, oid)
+	}
+	for _, key := range keys {
+		state["_id"] = key
+		res, err := m.Conn.UpdateOne(ctx, state, update)
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount > 0 {
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
@@ -76,3 +76,15 @@ func (m *mongoNoteRepository) DeleteByDiagramID(ctx context.Context, diagramID s
 	_, err := m.Conn.DeleteMany(ctx, bson.M{"diagram_id": diagramID})
 	return err
 }
+
+func (m *mongoNoteRepository) SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error {
+	return markDeleted(ctx, m.Conn, diagramID, at)
+}
+
+func (m *mongoNoteRepository) UndeleteByDiagramID(ctx context.Context, diagramID string) error {
+	return unmarkDeleted(ctx, m.Conn, diagramID)
+}
+
+func (m *mongoNoteRepository) PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error {
+	return purgeDeleted(ctx, m.Conn, diagramID, before)
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoSearchRepository struct {
+	TableConn      *mongo.Collection
+	NoteConn       *mongo.Collection
+	CustomTypeConn *mongo.Collection
+}
+
+// NewMongoSearchRepository creates a search repository over the text-indexed collections
+func NewMongoSearchRepository(tables, notes, customTypes *mongo.Collection) domain.SearchRepository {
+	return &mongoSearchRepository{
+		TableConn:      tables,
+		NoteConn:       notes,
+		CustomTypeConn: customTypes,
+	}
+}
+
+type scoredTable struct {
+	domain.Table `bson:",inline"`
+	Score        float64 `bson:"score"`
+}
+
+type scoredNote struct {
+	domain.Note `bson:",inline"`
+	Score       float64 `bson:"score"`
+}
+
+type scoredCustomType struct {
+	domain.CustomType `bson:",inline"`
+	Score             float64 `bson:"score"`
+}
+
+func (m *mongoSearchRepository) Search(ctx context.Context, q domain.SearchQuery) ([]domain.SearchHit, error) {
+	terms := searchTerms(q.Text)
+	hits := make([]domain.SearchHit, 0)
+
+	tableHits, err := m.searchTables(ctx, q, terms)
+	if err != nil {
+		return nil, err
+	}
+	hits = append(hits, tableHits...)
+
+	// Notes have no schema, so a schema filter excludes them
+	if q.Schema == "" {
+		noteHits, err := m.searchNotes(ctx, q, terms)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, noteHits...)
+	}
+
+	customTypeHits, err := m.searchCustomTypes(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	hits = append(hits, customTypeHits...)
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits, nil
+}
+
+func (m *mongoSearchRepository) searchTables(ctx context.Context, q domain.SearchQuery, terms []string) ([]domain.SearchHit, error) {
+	filter := textFilter(q)
+	if q.Schema != "" {
+		filter["schema"] = q.Schema
+	}
+
+	var docs []scoredTable
+	if err := findByTextScore(ctx, m.TableConn, filter, q.Limit, &docs); err != nil {
+		return nil, fmt.Errorf("search tables: %w", err)
+	}
+
+	hits := make([]domain.SearchHit, 0, len(docs))
+	for _, t := range docs {
+		tableMatched := containsAnyTerm(t.Name, terms) || containsAnyTerm(t.Schema, terms)
+		fieldMatched := false
+
+		// $text only tells us the document matched, so find the fields that did
+		for _, f := range t.Fields {
+			name, _ := f["name"].(string)
+			comments, _ := f["comments"].(string)
+			if !containsAnyTerm(name, terms) && !containsAnyTerm(comments, terms) {
+				continue
+			}
+			fieldMatched = true
+			id, _ := f["id"].(string)
+			hits = append(hits, domain.SearchHit{
+				DiagramID:  t.DiagramID,
+				EntityType: domain.SearchEntityField,
+				EntityID:   id,
+				TableID:    t.TableID,
+				Name:       t.Name + "." + name,
+				Schema:     t.Schema,
+				Snippet:    snippet(comments, terms),
+				Score:      t.Score,
+			})
+		}
+
+		if tableMatched || !fieldMatched {
+			hits = append(hits, domain.SearchHit{
+				DiagramID:  t.DiagramID,
+				EntityType: domain.SearchEntityTable,
+				EntityID:   t.TableID,
+				TableID:    t.TableID,
+				Name:       t.Name,
+				Schema:     t.Schema,
+				Score:      t.Score,
+			})
+		}
+	}
+	return hits, nil
+}
+
+func (m *mongoSearchRepository) searchNotes(ctx context.Context, q domain.SearchQuery, terms []string) ([]domain.SearchHit, error) {
+	var docs []scoredNote
+	if err := findByTextScore(ctx, m.NoteConn, textFilter(q), q.Limit, &docs); err != nil {
+		return nil, fmt.Errorf("search notes: %w", err)
+	}
+
+	hits := make([]domain.SearchHit, 0, len(docs))
+	for _, n := range docs {
+		hits = append(hits, domain.SearchHit{
+			DiagramID:  n.DiagramID,
+			EntityType: domain.SearchEntityNote,
+			EntityID:   n.ID, // Only valid until the diagram is saved again
+			Name:       firstLine(n.Content),
+			Snippet:    snippet(n.Content, terms),
+			Score:      n.Score,
+		})
+	}
+	return hits, nil
+}
+
+func (m *mongoSearchRepository) searchCustomTypes(ctx context.Context, q domain.SearchQuery) ([]domain.SearchHit, error) {
+	filter := textFilter(q)
+	if q.Schema != "" {
+		filter["schema"] = q.Schema
+	}
+
+	var docs []scoredCustomType
+	if err := findByTextScore(ctx, m.CustomTypeConn, filter, q.Limit, &docs); err != nil {
+		return nil, fmt.Errorf("search custom types: %w", err)
+	}
+
+	hits := make([]domain.SearchHit, 0, len(docs))
+	for _, ct := range docs {
+		hits = append(hits, domain.SearchHit{
+			DiagramID:  ct.DiagramID,
+			EntityType: domain.SearchEntityCustomType,
+			EntityID:   domain.QualifiedName(ct.Schema, ct.Type),
+			Name:       ct.Type,
+			Schema:     ct.Schema,
+			Score:      ct.Score,
+		})
+	}
+	return hits, nil
+}
+
+func textFilter(q domain.SearchQuery) bson.M {
+	filter := bson.M{"$text": bson.M{"$search": q.Text}, "deleted_at": bson.M{"$exists": false}}
+	if q.DiagramID != "" {
+		filter["diagram_id"] = q.DiagramID
+	}
+	return filter
+}
+
+func findByTextScore(ctx context.Context, col *mongo.Collection, filter bson.M, limit int, out interface{}) error {
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.M{"score": score}).
+		SetLimit(int64(limit))
+
+	cursor, err := col.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, out)
+}
+
+// searchTerms splits a $text query into lowercase words, ignoring negations and quotes
+func searchTerms(text string) []string {
+	terms := make([]string, 0)
+	for _, w := range strings.Fields(strings.ToLower(text)) {
+		if strings.HasPrefix(w, "-") {
+			continue
+		}
+		w = strings.Trim(w, `"`)
+		if w != "" {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+func containsAnyTerm(s string, terms []string) bool {
+	if s == "" {
+		return false
+	}
+	lower := strings.ToLower(s)
+	for _, t := range terms {
+		if strings.Contains(lower, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// snippet returns a short excerpt of s around the first matching term
+func snippet(s string, terms []string) string {
+	const radius = 40
+	if s == "" {
+		return ""
+	}
+
+	lower := strings.ToLower(s)
+	pos := -1
+	for _, t := range terms {
+		if i := strings.Index(lower, t); i >= 0 && (pos < 0 || i < pos) {
+			pos = i
+		}
+	}
+	if pos < 0 {
+		pos = 0
+	}
+
+	runes := []rune(s)
+	center := len([]rune(lower[:pos]))
+	start, end := center-radius, center+radius
+	prefix, suffix := "…", "…"
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(runes) {
+		end, suffix = len(runes), ""
+	}
+	return prefix + strings.TrimSpace(string(runes[start:end])) + suffix
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if r := []rune(s); len(r) > 60 {
+		return string(r[:60]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoStatsRepository struct {
+	DB *mongo.Database
+}
+
+// NewMongoStatsRepository creates a repository that aggregates across all diagram collections
+func NewMongoStatsRepository(db *mongo.Database) domain.StatsRepository {
+	return &mongoStatsRepository{DB: db}
+}
+
+// tableSchemaStats is one row of the per-schema table aggregation
+type tableSchemaStats struct {
+	domain.SchemaStats `bson:",inline"`
+	MaxFields          int `bson:"max_fields"`
+	WithoutIndexes     int `bson:"without_indexes"`
+}
+
+func (m *mongoStatsRepository) Workspace(ctx context.Context) (*domain.WorkspaceStats, error) {
+	stats := &domain.WorkspaceStats{
+		Schemas:     make([]domain.SchemaStats, 0),
+		CustomTypes: make([]domain.KindCount, 0),
+	}
+
+	// 1. Tables, views, fields and indexes per schema
+	fieldCount := bson.M{"$size": bson.M{"$ifNull": bson.A{"$fields", bson.A{}}}}
+	indexCount := bson.M{"$size": bson.M{"$ifNull": bson.A{"$indexes", bson.A{}}}}
+	tableRows := make([]tableSchemaStats, 0)
+	if err := m.aggregate(ctx, "tables", mongo.Pipeline{
+		{{Key: "$match", Value: notDeleted()}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        bson.M{"$ifNull": bson.A{"$schema", ""}},
+			"tables":     bson.M{"$sum": bson.M{"$cond": bson.A{"$isView", 0, 1}}},
+			"views":      bson.M{"$sum": bson.M{"$cond": bson.A{"$isView", 1, 0}}},
+			"fields":     bson.M{"$sum": fieldCount},
+			"indexes":    bson.M{"$sum": indexCount},
+			"max_fields": bson.M{"$max": fieldCount},
+			"without_indexes": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{bson.M{"$not": bson.A{"$isView"}}, bson.M{"$eq": bson.A{indexCount, 0}}}}, 1, 0,
+			}}},
+		}}},
+	}, &tableRows); err != nil {
+		return nil, err
+	}
+
+	// 2. Relationships per schema of their source table
+	relRows := make([]domain.SchemaStats, 0)
+	if err := m.aggregate(ctx, "relationships", mongo.Pipeline{
+		{{Key: "$match", Value: notDeleted()}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "tables",
+			"localField":   "source_table_id",
+			"foreignField": "table_id",
+			"let":          bson.M{"diagram": "$diagram_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$diagram_id", "$$diagram"}}}},
+				bson.M{"$project": bson.M{"schema": 1}},
+			},
+			"as": "source",
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           bson.M{"$ifNull": bson.A{bson.M{"$first": "$source.schema"}, ""}},
+			"relationships": bson.M{"$sum": 1},
+		}}},
+	}, &relRows); err != nil {
+		return nil, err
+	}
+
+	bySchema := make(map[string]*domain.SchemaStats)
+	schemaOf := func(name string) *domain.SchemaStats {
+		if s, ok := bySchema[name]; ok {
+			return s
+		}
+		s := &domain.SchemaStats{Schema: name}
+		bySchema[name] = s
+		return s
+	}
+	for _, row := range tableRows {
+		s := schemaOf(row.Schema)
+		s.Tables, s.Views, s.Fields, s.Indexes = row.Tables, row.Views, row.Fields, row.Indexes
+		stats.MaxFieldsPerTable = max(stats.MaxFieldsPerTable, row.MaxFields)
+		stats.TablesWithoutIndexes += row.WithoutIndexes
+	}
+	for _, row := range relRows {
+		schemaOf(row.Schema).Relationships = row.Relationships
+	}
+	for _, s := range bySchema {
+		stats.Schemas = append(stats.Schemas, *s)
+		stats.Totals.Tables += s.Tables
+		stats.Totals.Views += s.Views
+		stats.Totals.Fields += s.Fields
+		stats.Totals.Indexes += s.Indexes
+		stats.Totals.Relationships += s.Relationships
+	}
+	sort.Slice(stats.Schemas, func(i, j int) bool { return stats.Schemas[i].Schema < stats.Schemas[j].Schema })
+
+	// 3. Custom types by kind
+	if err := m.aggregate(ctx, "custom_types", mongo.Pipeline{
+		{{Key: "$match", Value: notDeleted()}},
+		{{Key: "$group", Value: bson.M{"_id": bson.M{"$ifNull": bson.A{"$kind", ""}}, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}, &stats.CustomTypes); err != nil {
+		return nil, err
+	}
+
+	// 4. Plain collection counts
+	counts := []struct {
+		collection string
+		dst        *int
+	}{
+		{"diagrams", &stats.Diagrams},
+		{"dependencies", &stats.Dependencies},
+		{"areas", &stats.Areas},
+		{"notes", &stats.Notes},
+	}
+	for _, c := range counts {
+		n, err := m.DB.Collection(c.collection).CountDocuments(ctx, notDeleted())
+		if err != nil {
+			return nil, err
+		}
+		*c.dst = int(n)
+	}
+
+	if stats.Diagrams > 0 {
+		stats.AvgTablesPerDiagram = float64(stats.Totals.Tables+stats.Totals.Views) / float64(stats.Diagrams)
+	}
+	if n := stats.Totals.Tables + stats.Totals.Views; n > 0 {
+		stats.AvgFieldsPerTable = float64(stats.Totals.Fields) / float64(n)
+	}
+	return stats, nil
+}
+
+func (m *mongoStatsRepository) aggregate(ctx context.Context, collection string, pipeline mongo.Pipeline, out interface{}) error {
+	cursor, err := m.DB.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, out)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/iots1/vertex-diagram/domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type mongoTableRepository struct {
@@ -76,3 +77,49 @@ func (m *mongoTableRepository) DeleteByDiagramID(ctx context.Context, diagramID
 	_, err := m.Conn.DeleteMany(ctx, bson.M{"diagram_id": diagramID})
 	return err
 }
+
+func (m *mongoTableRepository) UpdatePositions(ctx context.Context, diagramID string, positions []domain.TablePosition) error {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, 0, len(positions))
+	for _, p := range positions {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"diagram_id": diagramID, "table_id": p.TableID}).
+			SetUpdate(bson.M{"$set": bson.M{"x": p.X, "y": p.Y, "updated_at": now}}))
+	}
+
+	_, err := m.Conn.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (m *mongoTableRepository) UpdateComments(ctx context.Context, diagramID string, tables []domain.Table) error {
+	if len(tables) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, 0, len(tables))
+	for _, t := range tables {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"diagram_id": diagramID, "table_id": t.TableID}).
+			SetUpdate(bson.M{"$set": bson.M{"comments": t.Comments, "fields": t.Fields, "updated_at": now}}))
+	}
+
+	_, err := m.Conn.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (m *mongoTableRepository) SoftDeleteByDiagramID(ctx context.Context, diagramID string, at time.Time) error {
+	return markDeleted(ctx, m.Conn, diagramID, at)
+}
+
+func (m *mongoTableRepository) UndeleteByDiagramID(ctx context.Context, diagramID string) error {
+	return unmarkDeleted(ctx, m.Conn, diagramID)
+}
+
+func (m *mongoTableRepository) PurgeByDiagramID(ctx context.Context, diagramID string, before time.Time) error {
+	return purgeDeleted(ctx, m.Conn, diagramID, before)
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoTemplateRepository struct {
+	Conn *mongo.Collection
+}
+
+// NewMongoTemplateRepository creates a new template repository
+func NewMongoTemplateRepository(Conn *mongo.Collection) domain.TemplateRepository {
+	return &mongoTemplateRepository{Conn}
+}
+
+func (m *mongoTemplateRepository) Fetch(ctx context.Context) ([]domain.Template, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := m.Conn.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	templates := make([]domain.Template, 0)
+	if err = cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (m *mongoTemplateRepository) GetByID(ctx context.Context, id string) (*domain.Template, error) {
+	var t domain.Template
+	err := m.Conn.FindOne(ctx, idFilter(id)).Decode(&t)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (m *mongoTemplateRepository) Store(ctx context.Context, t *domain.Template) error {
+	now := time.Now()
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	t.Version = 1
+
+	res, err := m.Conn.InsertOne(ctx, t)
+	if err == nil {
+		if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
+			t.ID = oid.Hex()
+		}
+	}
+	return err
+}
+
+func (m *mongoTemplateRepository) Update(ctx context.Context, t *domain.Template) error {
+	t.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":          t.Name,
+			"description":   t.Description,
+			"tables":        t.Tables,
+			"relationships": t.Relationships,
+			"custom_types":  t.CustomTypes,
+			"areas":         t.Areas,
+			"updated_at":    t.UpdatedAt,
+		},
+		"$inc": bson.M{"version": 1},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err := m.Conn.FindOneAndUpdate(ctx, idFilter(t.ID), update, opts).Decode(t)
+	if err == mongo.ErrNoDocuments {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+func (m *mongoTemplateRepository) Delete(ctx context.Context, id string) error {
+	res, err := m.Conn.DeleteOne(ctx, idFilter(id))
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// idFilter matches server-generated ObjectIDs given as hex, or plain string IDs
+func idFilter(id string) bson.M {
+	if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+		return bson.M{"_id": bson.M{"$in": bson.A{oid, id}}}
+	}
+	return bson.M{"_id": id}
+}
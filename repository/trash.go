@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Deleted diagrams and their entities stay in their collections with a
+// deleted_at timestamp until they are purged
+
+// notDeleted matches documents that are not in the trash
+func notDeleted() bson.M {
+	return bson.M{"deleted_at": bson.M{"$exists": false}}
+}
+
+// markDeleted moves every entity of a diagram to the trash
+func markDeleted(ctx context.Context, col *mongo.Collection, diagramID string, at time.Time) error {
+	_, err := col.UpdateMany(ctx, bson.M{"diagram_id": diagramID}, bson.M{"$set": bson.M{"deleted_at": at}})
+	return err
+}
+
+// unmarkDeleted takes every entity of a diagram out of the trash
+func unmarkDeleted(ctx context.Context, col *mongo.Collection, diagramID string) error {
+	_, err := col.UpdateMany(ctx, bson.M{"diagram_id": diagramID}, bson.M{"$unset": bson.M{"deleted_at": ""}})
+	return err
+}
+
+// purgeDeleted permanently deletes the entities of a diagram that were moved
+// to the trash before the given time; entities restored in the meantime no
+// longer match
+func purgeDeleted(ctx context.Context, col *mongo.Collection, diagramID string, before time.Time) error {
+	_, err := col.DeleteMany(ctx, bson.M{"diagram_id": diagramID, "deleted_at": bson.M{"$lt": before}})
+	return err
+}
@@ -0,0 +1,51 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// CSV implements domain.Exporter and reads the rows back. The export starts
+// with a UTF-8 byte order mark so spreadsheet programs detect the encoding.
+type CSV struct{}
+
+func (CSV) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (CSV) Extension() string { return "csv" }
+
+func (CSV) Export(snap *domain.DiagramSnapshot, _ map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(bom)
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for _, r := range Rows(snap) {
+		if err := w.Write(r.cells()); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// Read parses a CSV sheet; semicolon separated files, as written by
+// spreadsheet programs in some locales, are accepted too
+func (CSV) Read(data []byte) ([]Row, error) {
+	data = bytes.TrimPrefix(data, []byte(bom))
+	r := csv.NewReader(bytes.NewReader(data))
+	if line, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	return parseRows(records)
+}
+
+const bom = "\ufeff"
@@ -0,0 +1,192 @@
+// Package spreadsheet exports every table and field of a diagram as one
+// spreadsheet row each, as CSV or as an XLSX workbook, and reads such sheets
+// back so edited comments can be applied to the diagram.
+//
+// Sheets are matched by their header row, so columns may be reordered or
+// removed as long as table, field and comment remain. A row without a field
+// name describes the table itself.
+//
+// Text cells starting with a formula character get a ' prefix so spreadsheet
+// programs don't evaluate them; reading strips it again.
+package spreadsheet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Row is one field of a table, or the table itself
+type Row struct {
+	Line       int // 1-based sheet row, set when reading
+	Diagram    string
+	Schema     string
+	Table      string
+	Field      string // Empty for a row about the table
+	Type       string
+	Nullable   bool
+	PrimaryKey bool
+	ForeignKey string // Referenced schema.table.field, comma separated
+	Comment    string
+}
+
+// Header names, in export order
+var header = []string{"Diagram", "Schema", "Table", "Field", "Type", "Nullable", "Primary key", "Foreign key", "Comment"}
+
+// Accepted header spellings, lower-case, by column
+var aliases = map[string]string{
+	"diagram":     "diagram",
+	"schema":      "schema",
+	"table":       "table",
+	"table name":  "table",
+	"field":       "field",
+	"field name":  "field",
+	"column":      "field",
+	"column name": "field",
+	"type":        "type",
+	"data type":   "type",
+	"nullable":    "nullable",
+	"null":        "nullable",
+	"primary key": "pk",
+	"pk":          "pk",
+	"foreign key": "fk",
+	"fk":          "fk",
+	"fk target":   "fk",
+	"references":  "fk",
+	"comment":     "comment",
+	"comments":    "comment",
+	"description": "comment",
+}
+
+// Rows lists every table in diagram order, each followed by its fields
+func Rows(snap *domain.DiagramSnapshot) []Row {
+	name := ""
+	if snap.Diagram != nil {
+		name = snap.Diagram.Name
+	}
+	tables := make(map[string]domain.Table, len(snap.Tables))
+	for _, t := range snap.Tables {
+		tables[t.TableID] = t
+	}
+	targets := make(map[string][]string) // Field ID -> referenced fields
+	for _, r := range snap.Relationships {
+		childID, fkID := r.ForeignKey()
+		parentID, refID := r.TargetTableID, r.TargetFieldID
+		if childID == r.TargetTableID && fkID == r.TargetFieldID {
+			parentID, refID = r.SourceTableID, r.SourceFieldID
+		}
+		parent, ok := tables[parentID]
+		if !ok {
+			continue
+		}
+		if f, ok := parent.FieldByID(refID); ok {
+			targets[childID+"/"+fkID] = append(targets[childID+"/"+fkID], domain.QualifiedName(parent.Schema, parent.Name)+"."+f.Name)
+		}
+	}
+
+	rows := make([]Row, 0)
+	for _, t := range snap.Tables {
+		rows = append(rows, Row{Diagram: name, Schema: t.Schema, Table: t.Name, Comment: t.Comments})
+		for _, f := range t.FieldList() {
+			rows = append(rows, Row{
+				Diagram:    name,
+				Schema:     t.Schema,
+				Table:      t.Name,
+				Field:      f.Name,
+				Type:       f.SQLType(),
+				Nullable:   f.Nullable,
+				PrimaryKey: f.PrimaryKey,
+				ForeignKey: strings.Join(targets[t.TableID+"/"+f.ID], ", "),
+				Comment:    f.Comments,
+			})
+		}
+	}
+	return rows
+}
+
+// cells returns the row in header order
+func (r Row) cells() []string {
+	return []string{escape(r.Diagram), escape(r.Schema), escape(r.Table), escape(r.Field), escape(r.Type),
+		yesNo(r.Nullable), yesNo(r.PrimaryKey), escape(r.ForeignKey), escape(r.Comment)}
+}
+
+// escape prefixes text that a spreadsheet program would take for a formula
+func escape(s string) string {
+	if s != "" && strings.ContainsRune(formulaStart, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// unescape removes the prefix added by escape
+func unescape(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(formulaStart, rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+const formulaStart = "=+-@"
+
+// parseRows reads records whose first one is the header. Blank records are
+// skipped; line numbers count from the header.
+func parseRows(records [][]string) ([]Row, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("the sheet is empty")
+	}
+	columns := make(map[string]int)
+	for i, h := range records[0] {
+		if key, ok := aliases[strings.ToLower(strings.TrimSpace(h))]; ok {
+			if _, dup := columns[key]; !dup {
+				columns[key] = i
+			}
+		}
+	}
+	for _, required := range []string{"table", "field", "comment"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing %s column in the header row", required)
+		}
+	}
+
+	rows := make([]Row, 0, len(records)-1)
+	for i, rec := range records[1:] {
+		cell := func(key string) string {
+			if c, ok := columns[key]; ok && c < len(rec) {
+				return unescape(rec[c])
+			}
+			return ""
+		}
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue
+		}
+		rows = append(rows, Row{
+			Line:       i + 2,
+			Diagram:    strings.TrimSpace(cell("diagram")),
+			Schema:     strings.TrimSpace(cell("schema")),
+			Table:      strings.TrimSpace(cell("table")),
+			Field:      strings.TrimSpace(cell("field")),
+			Type:       strings.TrimSpace(cell("type")),
+			Nullable:   parseBool(cell("nullable")),
+			PrimaryKey: parseBool(cell("pk")),
+			ForeignKey: strings.TrimSpace(cell("fk")),
+			Comment:    strings.TrimRight(strings.ReplaceAll(cell("comment"), "\r\n", "\n"), " \n"),
+		})
+	}
+	return rows, nil
+}
+
+func parseBool(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "yes", "y", "true", "1", "x":
+		return true
+	}
+	return false
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
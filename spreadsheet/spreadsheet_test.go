@@ -0,0 +1,75 @@
+package spreadsheet
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+func TestRoundTrip(t *testing.T) {
+	snap := &domain.DiagramSnapshot{
+		Diagram: &domain.Diagram{Name: "=shop"},
+		Tables: []domain.Table{{
+			TableID:  "t1",
+			Schema:   "public",
+			Name:     "users",
+			Comments: "=HYPERLINK(\"http://example.com\")",
+			Fields: []map[string]interface{}{
+				{"id": "f1", "name": "id", "type": "int", "primaryKey": true},
+				{"id": "f2", "name": "balance", "type": "int", "nullable": true, "comments": "-1 when closed"},
+				{"id": "f3", "name": "handle", "type": "text", "comments": "@name, or 'anonymous'"},
+			},
+		}},
+	}
+	want := Rows(snap)
+	if len(want) != 4 || want[0].Field != "" || want[0].Comment != snap.Tables[0].Comments {
+		t.Fatalf("rows = %+v, want the table row first", want)
+	}
+
+	for _, format := range []struct {
+		name string
+		exp  domain.Exporter
+		read func([]byte) ([]Row, error)
+	}{
+		{"csv", CSV{}, CSV{}.Read},
+		{"xlsx", XLSX{}, XLSX{}.Read},
+	} {
+		t.Run(format.name, func(t *testing.T) {
+			data, err := format.exp.Export(snap, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := format.read(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range got {
+				got[i].Line = 0
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got  %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"plain", "plain"},
+		{"=1+1", "'=1+1"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@x", "'@x"},
+		{"'quoted'", "'quoted'"},
+	}
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if got := unescape(escape(tt.in)); got != tt.in {
+			t.Errorf("unescape(escape(%q)) = %q", tt.in, got)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// XLSX implements domain.Exporter with a single-sheet Office Open XML
+// workbook: a bold, frozen header row with filters and inline strings. Read
+// accepts workbooks saved by spreadsheet programs, using their first sheet.
+type XLSX struct{}
+
+func (XLSX) ContentType() string {
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+func (XLSX) Extension() string { return "xlsx" }
+
+// Column widths in characters, in header order
+var widths = []int{20, 14, 28, 28, 18, 10, 12, 36, 60}
+
+const (
+	nsMain          = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	nsRelationships = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	xmlHeader       = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+)
+
+// Package parts that do not depend on the rows
+var xlsxParts = map[string]string{
+	"[Content_Types].xml": `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`,
+	"_rels/.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + nsRelationships + `/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`,
+	"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="` + nsRelationships + `/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="` + nsRelationships + `/styles" Target="styles.xml"/>` +
+		`</Relationships>`,
+	// Cell styles: 0 normal, 1 bold header, 2 wrapped text aligned to the top
+	"xl/styles.xml": `<styleSheet xmlns="` + nsMain + `">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0" applyAlignment="1"><alignment vertical="top" wrapText="1"/></xf></cellXfs>` +
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+		`</styleSheet>`,
+}
+
+func (XLSX) Export(snap *domain.DiagramSnapshot, _ map[string]string) ([]byte, error) {
+	rows := Rows(snap)
+	last := cellRef(len(header)-1, len(rows)+1)
+
+	var sheet strings.Builder
+	sheet.WriteString(`<worksheet xmlns="` + nsMain + `">`)
+	sheet.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	sheet.WriteString(`<cols>`)
+	for i, w := range widths {
+		fmt.Fprintf(&sheet, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, w)
+	}
+	sheet.WriteString(`</cols><sheetData>`)
+	writeRow(&sheet, 1, header, 1)
+	for i, r := range rows {
+		writeRow(&sheet, i+2, r.cells(), 2)
+	}
+	sheet.WriteString(`</sheetData><autoFilter ref="A1:` + last + `"/></worksheet>`)
+
+	workbook := `<workbook xmlns="` + nsMain + `" xmlns:r="` + nsRelationships + `">` +
+		`<sheets><sheet name="Fields" sheetId="1" r:id="rId1"/></sheets>` +
+		`<definedNames><definedName name="_xlnm._FilterDatabase" localSheetId="0" hidden="1">Fields!$A$1:$` +
+		strings.TrimRightFunc(last, isDigit) + `$` + strconv.Itoa(len(rows)+1) + `</definedName></definedNames>` +
+		`</workbook>`
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxParts["[Content_Types].xml"]},
+		{"_rels/.rels", xlsxParts["_rels/.rels"]},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", xlsxParts["xl/_rels/workbook.xml.rels"]},
+		{"xl/styles.xml", xlsxParts["xl/styles.xml"]},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+	for _, p := range parts {
+		w, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(w, xmlHeader+p.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeRow writes cells as inline strings with the given style
+func writeRow(b *strings.Builder, line int, cells []string, style int) {
+	fmt.Fprintf(b, `<row r="%d">`, line)
+	for i, v := range cells {
+		if v == "" {
+			continue
+		}
+		fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, cellRef(i, line), style)
+		xml.EscapeText(b, []byte(v))
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+}
+
+// cellRef returns the A1 reference of a zero-based column and a row
+func cellRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+// columnIndex returns the zero-based column of an A1 reference
+func columnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
+
+func isDigit(r rune) bool { return r >= '0' && r <= '9' }
+
+// maxPartSize bounds the uncompressed size of one workbook part
+const maxPartSize = 64 << 20
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+// Read parses the first sheet of a workbook
+func (XLSX) Read(data []byte) ([]Row, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX: %v", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+	part := func(name string, v interface{}) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("invalid XLSX: missing %s", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("invalid XLSX: %v", err)
+		}
+		defer rc.Close()
+		if err := xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+			return fmt.Errorf("invalid XLSX: %s: %v", name, err)
+		}
+		return nil
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := part("xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("invalid XLSX: the workbook has no sheets")
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := part("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	sheetPath := ""
+	for _, r := range rels.Relationships {
+		if r.ID == workbook.Sheets[0].RID {
+			sheetPath = r.Target
+			if strings.HasPrefix(sheetPath, "/") {
+				sheetPath = strings.TrimPrefix(sheetPath, "/")
+			} else {
+				sheetPath = path.Join("xl", sheetPath)
+			}
+		}
+	}
+	if sheetPath == "" {
+		return nil, fmt.Errorf("invalid XLSX: the first sheet has no part")
+	}
+
+	var shared struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := part("xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				R      string   `xml:"r,attr"`
+				T      string   `xml:"t,attr"`
+				V      string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := part(sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	// Records are indexed by sheet row so line numbers survive gaps
+	records := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		line := row.R
+		if line <= len(records) {
+			line = len(records) + 1
+		}
+		for len(records) < line {
+			records = append(records, nil)
+		}
+		rec := make([]string, 0, len(row.Cells))
+		for i, c := range row.Cells {
+			col := i
+			if c.R != "" {
+				col = columnIndex(c.R)
+			}
+			if col < 0 || col > 1000 {
+				continue
+			}
+			for len(rec) <= col {
+				rec = append(rec, "")
+			}
+			switch c.T {
+			case "s":
+				if n, err := strconv.Atoi(c.V); err == nil && n >= 0 && n < len(shared.Items) {
+					rec[col] = shared.Items[n].String()
+				}
+			case "inlineStr":
+				rec[col] = c.Inline.String()
+			default:
+				rec[col] = c.V
+			}
+		}
+		records[line-1] = rec
+	}
+	return parseRows(records)
+}
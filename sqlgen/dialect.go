@@ -0,0 +1,70 @@
+// Package sqlgen writes SQL text for the database dialects the frontend supports
+package sqlgen
+
+import (
+	"strings"
+)
+
+// Dialect is a database type, using the frontend's databaseType values
+type Dialect string
+
+const (
+	PostgreSQL  Dialect = "postgresql"
+	MySQL       Dialect = "mysql"
+	MariaDB     Dialect = "mariadb"
+	SQLServer   Dialect = "sql_server"
+	SQLite      Dialect = "sqlite"
+	Oracle      Dialect = "oracle"
+	CockroachDB Dialect = "cockroachdb"
+	ClickHouse  Dialect = "clickhouse"
+	Generic     Dialect = "generic"
+)
+
+var dialectAliases = map[string]Dialect{
+	"postgres":  PostgreSQL,
+	"pg":        PostgreSQL,
+	"sqlserver": SQLServer,
+	"mssql":     SQLServer,
+	"tsql":      SQLServer,
+	"sqlite3":   SQLite,
+	"cockroach": CockroachDB,
+}
+
+// ParseDialect accepts a frontend databaseType or a common alias
+func ParseDialect(s string) (Dialect, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch d := Dialect(s); d {
+	case PostgreSQL, MySQL, MariaDB, SQLServer, SQLite, Oracle, CockroachDB, ClickHouse, Generic:
+		return d, true
+	}
+	d, ok := dialectAliases[s]
+	return d, ok
+}
+
+// Quote quotes an identifier, escaping the quote character inside it
+func (d Dialect) Quote(ident string) string {
+	switch d {
+	case MySQL, MariaDB, ClickHouse:
+		return "`" + strings.ReplaceAll(ident, "`", "``") + "`"
+	case SQLServer:
+		return "[" + strings.ReplaceAll(ident, "]", "]]") + "]"
+	default:
+		return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+	}
+}
+
+// TableName returns the quoted, schema-qualified table name
+func (d Dialect) TableName(schema, name string) string {
+	if schema == "" {
+		return d.Quote(name)
+	}
+	return d.Quote(schema) + "." + d.Quote(name)
+}
+
+// TableAlias returns the " AS alias" suffix for a FROM or JOIN item; Oracle doesn't allow AS there
+func (d Dialect) TableAlias(alias string) string {
+	if d == Oracle {
+		return " " + alias
+	}
+	return " AS " + alias
+}
@@ -0,0 +1,330 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/layout"
+)
+
+// Area colors, cycled in order; more clusters than colors get generated hues
+var areaPalette = []string{
+	"#b067e9", "#ff6363", "#4dee8a", "#8eb7ff", "#ffe374", "#ff9f74",
+	"#42e0c0", "#ff6b8a", "#7175fa", "#c2e066", "#e085ff", "#7c9cbf",
+}
+
+type areaUsecase struct {
+	diagramRepo      domain.DiagramRepository
+	tableRepo        domain.TableRepository
+	relationshipRepo domain.RelationshipRepository
+	areaRepo         domain.AreaRepository
+	contextTimeout   time.Duration
+}
+
+func NewAreaUsecase(
+	d domain.DiagramRepository,
+	t domain.TableRepository,
+	r domain.RelationshipRepository,
+	area domain.AreaRepository,
+	timeout time.Duration,
+) domain.AreaUsecase {
+	return &areaUsecase{
+		diagramRepo:      d,
+		tableRepo:        t,
+		relationshipRepo: r,
+		areaRepo:         area,
+		contextTimeout:   timeout,
+	}
+}
+
+// Cluster replaces the diagram's areas with generated ones
+func (u *areaUsecase) Cluster(c context.Context, req domain.ClusterRequest) (*domain.ClusterResult, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	switch req.By {
+	case "", domain.ClusterBySchema:
+		req.By = domain.ClusterBySchema
+		if req.MinSize <= 0 {
+			req.MinSize = 1
+		}
+	case domain.ClusterByCommunity:
+		if req.MinSize <= 0 {
+			req.MinSize = 2
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported clustering %q", domain.ErrBadParamInput, req.By)
+	}
+
+	diagram, err := u.diagramRepo.GetByID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := u.tableRepo.GetByDiagramID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := u.relationshipRepo.GetByDiagramID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(tables, func(i, j int) bool {
+		if tables[i].Schema != tables[j].Schema {
+			return tables[i].Schema < tables[j].Schema
+		}
+		return tables[i].Name < tables[j].Name
+	})
+	byID := make(map[string]domain.Table, len(tables))
+	for _, t := range tables {
+		byID[t.TableID] = t
+	}
+
+	edges := make([]layout.Edge, 0, len(relationships))
+	degree := make(map[string]int)
+	for _, r := range relationships {
+		parent, child := r.ParentChild()
+		edges = append(edges, layout.Edge{From: parent, To: child})
+		degree[parent]++
+		degree[child]++
+	}
+
+	// 1. Group tables
+	type group struct {
+		name   string
+		tables []domain.Table
+	}
+	groups := make([]group, 0)
+	switch req.By {
+	case domain.ClusterBySchema:
+		index := make(map[string]int)
+		for _, t := range tables {
+			i, ok := index[t.Schema]
+			if !ok {
+				name := t.Schema
+				if name == "" {
+					name = "default"
+				}
+				i = len(groups)
+				index[t.Schema] = i
+				groups = append(groups, group{name: name})
+			}
+			groups[i].tables = append(groups[i].tables, t)
+		}
+	case domain.ClusterByCommunity:
+		ids := make([]string, 0, len(tables))
+		for _, t := range tables {
+			ids = append(ids, t.TableID)
+		}
+		for _, members := range layout.Communities(ids, edges) {
+			g := group{}
+			hub := ""
+			for _, id := range members {
+				g.tables = append(g.tables, byID[id])
+				if hub == "" || degree[id] > degree[hub] {
+					hub = id
+				}
+			}
+			g.name = byID[hub].Name + " cluster"
+			groups = append(groups, g)
+		}
+	}
+
+	result := &domain.ClusterResult{
+		DiagramID:   req.DiagramID,
+		By:          req.By,
+		Preview:     req.Preview,
+		Clusters:    make([]domain.AreaCluster, 0, len(groups)),
+		Tables:      make([]domain.TablePosition, 0),
+		Unclustered: make([]string, 0),
+	}
+
+	// 2. Size and position an area around each group
+	shelf := newShelfPacker(len(groups))
+	unclustered := make([]domain.Table, 0)
+	for _, g := range groups {
+		if len(g.tables) < req.MinSize {
+			for _, t := range g.tables {
+				result.Unclustered = append(result.Unclustered, t.TableID)
+				unclustered = append(unclustered, t)
+			}
+			continue
+		}
+
+		area := domain.Area{
+			DiagramID: req.DiagramID,
+			AreaID:    generateID(),
+			Name:      g.name,
+			Color:     areaColor(len(result.Clusters)),
+		}
+		cluster := domain.AreaCluster{TableIDs: make([]string, 0, len(g.tables))}
+		for _, t := range g.tables {
+			cluster.TableIDs = append(cluster.TableIDs, t.TableID)
+		}
+
+		if req.Arrange {
+			res := layout.Layered(layoutNodes(g.tables), edges)
+			area.Width = res.Width + 2*areaPadding
+			area.Height = res.Height + areaHeaderHeight + areaPadding
+			area.X, area.Y = shelf.place(area.Width, area.Height)
+			appendClusterPositions(result, res, area.X+areaPadding, area.Y+areaHeaderHeight)
+		} else {
+			b, _ := snapshotBounds(&domain.DiagramSnapshot{Tables: g.tables})
+			area.X, area.Y = b.minX-areaPadding, b.minY-areaHeaderHeight
+			area.Width = b.maxX - b.minX + 2*areaPadding
+			area.Height = b.maxY - b.minY + areaHeaderHeight + areaPadding
+		}
+
+		area.TableIDs = cluster.TableIDs
+		cluster.Area = area
+		result.Clusters = append(result.Clusters, cluster)
+	}
+
+	// Unclustered tables go in a grid below the packed areas
+	if req.Arrange && len(unclustered) > 0 {
+		res := layout.Grid(layoutNodes(unclustered))
+		appendClusterPositions(result, res, 0, shelf.bottom()+layout.VerticalGap)
+	}
+
+	if req.Preview {
+		return result, nil
+	}
+
+	areas := make([]domain.Area, 0, len(result.Clusters))
+	for _, cl := range result.Clusters {
+		areas = append(areas, cl.Area)
+	}
+	if err := u.areaRepo.DeleteByDiagramID(ctx, req.DiagramID); err != nil {
+		return nil, err
+	}
+	if err := u.areaRepo.StoreMultiple(ctx, areas); err != nil {
+		return nil, err
+	}
+	if err := u.tableRepo.UpdatePositions(ctx, req.DiagramID, result.Tables); err != nil {
+		return nil, err
+	}
+	if err := u.diagramRepo.Update(ctx, diagram); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🗂️  Generated %d areas by %s for diagram %s (%d tables unclustered)",
+		len(result.Clusters), req.By, req.DiagramID, len(result.Unclustered))
+	return result, nil
+}
+
+func (u *areaUsecase) GetByDiagram(c context.Context, diagramID string) ([]domain.Area, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.diagramRepo.GetByID(ctx, diagramID); err != nil {
+		return nil, err
+	}
+	return u.areaRepo.GetByDiagramID(ctx, diagramID)
+}
+
+// GetTables returns the member tables of an area, ordered by schema and name
+func (u *areaUsecase) GetTables(c context.Context, diagramID, areaID string) ([]domain.Table, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	area, err := u.areaRepo.GetByID(ctx, diagramID, areaID)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := u.tableRepo.GetByDiagramID(ctx, diagramID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]domain.Table, 0)
+	for _, t := range tables {
+		if area.Includes(t) {
+			members = append(members, t)
+		}
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].Schema != members[j].Schema {
+			return members[i].Schema < members[j].Schema
+		}
+		return members[i].Name < members[j].Name
+	})
+	return members, nil
+}
+
+func appendClusterPositions(result *domain.ClusterResult, res layout.Result, x, y int) {
+	ids := make([]string, 0, len(res.Positions))
+	for id := range res.Positions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		p := res.Positions[id]
+		result.Tables = append(result.Tables, domain.TablePosition{TableID: id, X: x + p.X, Y: y + p.Y})
+	}
+}
+
+// areaColor returns a palette color, then golden-angle hues once the palette is used up
+func areaColor(i int) string {
+	if i < len(areaPalette) {
+		return areaPalette[i]
+	}
+	hue := math.Mod(float64(i)*137.508, 360)
+	r, g, b := hslToRGB(hue, 0.65, 0.65)
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+func hslToRGB(h, s, l float64) (uint8, uint8, uint8) {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return uint8((r + m) * 255), uint8((g + m) * 255), uint8((b + m) * 255)
+}
+
+// shelfPacker places rectangles left to right in rows of a bounded width
+type shelfPacker struct {
+	maxWidth  int
+	x, y      int
+	rowHeight int
+}
+
+// newShelfPacker sizes rows so that n areas end up on a roughly square canvas
+func newShelfPacker(n int) *shelfPacker {
+	perRow := max(1, int(math.Ceil(math.Sqrt(float64(n)))))
+	return &shelfPacker{maxWidth: perRow * 3 * (domain.TableWidth + layout.HorizontalGap)}
+}
+
+func (p *shelfPacker) place(w, h int) (int, int) {
+	if p.x > 0 && p.x+w > p.maxWidth {
+		p.x = 0
+		p.y += p.rowHeight + 2*layout.VerticalGap
+		p.rowHeight = 0
+	}
+	x, y := p.x, p.y
+	p.x += w + 2*layout.HorizontalGap
+	p.rowHeight = max(p.rowHeight, h)
+	return x, y
+}
+
+func (p *shelfPacker) bottom() int {
+	return p.y + p.rowHeight
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/iots1/vertex-diagram/backup"
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+// Backup and restore handle many diagrams, so every diagram gets its own
+// contextTimeout instead of the whole call sharing one
+func (u *diagramUsecase) Backup(c context.Context, ids []string) (*domain.ExportResult, error) {
+	if len(ids) == 0 {
+		var err error
+		if ids, err = u.allDiagramIDs(c); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("🗄️  Backing up %d diagrams", len(ids))
+	snaps := make([]*domain.DiagramSnapshot, 0, len(ids))
+	for _, id := range ids {
+		ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+		snap, err := u.loadSnapshot(ctx, id)
+		cancel()
+		if err != nil {
+			log.Printf("  ❌ Error loading diagram %s: %v", id, err)
+			return nil, err
+		}
+		snaps = append(snaps, snap)
+	}
+
+	now := time.Now()
+	data, err := backup.Write(snaps, now)
+	if err != nil {
+		return nil, err
+	}
+
+	name := "vertex-backup-" + now.UTC().Format("20060102-150405")
+	if len(snaps) == 1 {
+		name = exportFileName(snaps[0].Diagram.Name) + "-backup-" + now.UTC().Format("20060102-150405")
+	}
+	log.Printf("✅ Backup written: %d diagrams, %d bytes", len(snaps), len(data))
+	return &domain.ExportResult{
+		ContentType: backup.ContentType,
+		FileName:    name + ".zip",
+		Data:        data,
+	}, nil
+}
+
+// allDiagramIDs pages through every diagram, oldest first
+func (u *diagramUsecase) allDiagramIDs(c context.Context) ([]string, error) {
+	ids := make([]string, 0)
+	q := domain.DiagramListQuery{
+		Limit:      maxListLimit,
+		Sort:       domain.DiagramSortCreatedAt,
+		Order:      domain.SortAsc,
+		SearchMode: domain.SearchModeContains,
+	}
+	for {
+		ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+		page, err := u.diagramRepo.Fetch(ctx, q)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range page.Items {
+			ids = append(ids, d.ID)
+		}
+		if page.NextCursor == "" {
+			return ids, nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+func (u *diagramUsecase) Restore(c context.Context, req domain.RestoreRequest) (*domain.RestoreResult, error) {
+	mode := strings.ToLower(req.Mode)
+	switch mode {
+	case "":
+		mode = domain.RestoreSkipExisting
+	case domain.RestoreKeepIDs, domain.RestoreRegenerateIDs, domain.RestoreSkipExisting:
+	default:
+		return nil, fmt.Errorf("%w: unsupported restore mode %q", domain.ErrBadParamInput, req.Mode)
+	}
+
+	// The whole archive is verified before the first write
+	manifest, snaps, err := backup.Read(req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+	}
+
+	log.Printf("📥 Restoring backup v%d from %s: %d diagrams, mode=%s",
+		manifest.Version, manifest.CreatedAt.Format(time.RFC3339), len(snaps), mode)
+	result := &domain.RestoreResult{
+		Version:  manifest.Version,
+		Mode:     mode,
+		Restored: make([]domain.RestoredDiagram, 0, len(snaps)),
+		Skipped:  make([]domain.RestoredDiagram, 0),
+	}
+	for _, snap := range snaps {
+		ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+		restored, err := u.restoreSnapshot(ctx, snap, mode)
+		cancel()
+		if err != nil {
+			log.Printf("  ❌ Error restoring diagram %s: %v", snap.Diagram.ID, err)
+			return nil, err
+		}
+		if restored == nil {
+			result.Skipped = append(result.Skipped, domain.RestoredDiagram{SourceID: snap.Diagram.ID, Name: snap.Diagram.Name})
+			continue
+		}
+		result.Restored = append(result.Restored, *restored)
+	}
+
+	log.Printf("✅ Backup restored: %d diagrams, %d skipped", len(result.Restored), len(result.Skipped))
+	return result, nil
+}
+
+// restoreSnapshot writes one diagram of a backup; it returns nil when the
+// diagram exists and mode skips existing diagrams
+func (u *diagramUsecase) restoreSnapshot(ctx context.Context, snap *domain.DiagramSnapshot, mode string) (*domain.RestoredDiagram, error) {
+	src := snap.Diagram
+	restored := &domain.RestoredDiagram{
+		SourceID:      src.ID,
+		Name:          src.Name,
+		Tables:        len(snap.Tables),
+		Relationships: len(snap.Relationships),
+	}
+	if src.Content == nil {
+		src.Content = make(map[string]interface{})
+	}
+
+	if mode == domain.RestoreRegenerateIDs {
+		diagram := &domain.Diagram{
+			Name:     src.Name,
+			Content:  src.Content,
+			Revision: 1,
+		}
+		if err := u.diagramRepo.Store(ctx, diagram); err != nil {
+			return nil, err
+		}
+		if err := u.storeSnapshot(ctx, remapSnapshot(snap, diagram.ID, newIDRemapper())); err != nil {
+			return nil, err
+		}
+		restored.ID = diagram.ID
+		return restored, nil
+	}
+
+	_, err := u.diagramRepo.GetByID(ctx, src.ID)
+	exists := err == nil
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	// A diagram in the trash still exists: skip leaves it there, keep takes
+	// it out of the trash before replacing it
+	trashed, err := u.diagramRepo.InTrash(ctx, src.ID)
+	if err != nil {
+		return nil, err
+	}
+	if (exists || trashed) && mode == domain.RestoreSkipExisting {
+		return nil, nil
+	}
+	if trashed {
+		if err := u.diagramRepo.Undelete(ctx, src.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Entities keep their IDs; the diagram document is upserted last so a
+	// replaced diagram gets a new revision
+	if err := u.replaceSnapshot(ctx, snap); err != nil {
+		return nil, err
+	}
+	if err := u.diagramRepo.Update(ctx, src); err != nil {
+		return nil, err
+	}
+	restored.ID = src.ID
+	return restored, nil
+}
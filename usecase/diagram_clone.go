@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+func (u *diagramUsecase) Clone(c context.Context, id string, name string) (*domain.Diagram, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	src, err := u.loadSnapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = src.Diagram.Name + " (copy)"
+	}
+
+	log.Printf("🧬 Cloning diagram: ID=%s, Revision=%d -> %s", src.Diagram.ID, src.Diagram.Revision, name)
+
+	// 1. Store the new diagram document to get its ID
+	clone := &domain.Diagram{
+		Name:            name,
+		Content:         deepCopyMap(src.Diagram.Content),
+		SourceDiagramID: src.Diagram.ID,
+		SourceRevision:  src.Diagram.Revision,
+		Revision:        1,
+	}
+	if err := u.diagramRepo.Store(ctx, clone); err != nil {
+		return nil, err
+	}
+
+	// 2. Copy every child entity with fresh IDs
+	snap := remapSnapshot(src, clone.ID, newIDRemapper())
+	if err := u.storeSnapshot(ctx, snap); err != nil {
+		log.Printf("  ❌ Error storing cloned entities: %v", err)
+		return nil, err
+	}
+
+	log.Printf("✅ Diagram cloned: %s -> %s (%d tables, %d relationships)",
+		src.Diagram.ID, clone.ID, len(snap.Tables), len(snap.Relationships))
+	return clone, nil
+}
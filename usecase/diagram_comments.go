@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/spreadsheet"
+)
+
+// Sheet formats field comments can be imported from
+var commentReaders = map[string]interface {
+	Read(data []byte) ([]spreadsheet.Row, error)
+}{
+	domain.FormatCSV:  spreadsheet.CSV{},
+	domain.FormatXLSX: spreadsheet.XLSX{},
+}
+
+func (u *diagramUsecase) ImportComments(c context.Context, req domain.CommentImportRequest) (*domain.CommentImportResult, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	reader, ok := commentReaders[strings.ToLower(req.Format)]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported comment import format %q", domain.ErrBadParamInput, req.Format)
+	}
+	rows, err := reader.Read(req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+	}
+	snap, err := u.loadSnapshot(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, changed := applyComments(snap.Tables, rows)
+	result.DiagramID, result.DryRun = req.DiagramID, req.DryRun
+	log.Printf("📝 Importing comments into diagram %s: %d rows, %d updated, %d unmatched",
+		req.DiagramID, result.Rows, result.Updated, len(result.Unmatched))
+	if req.DryRun || len(changed) == 0 {
+		return result, nil
+	}
+
+	if err := u.tableRepo.UpdateComments(ctx, req.DiagramID, changed); err != nil {
+		log.Printf("  ❌ Error saving comments: %v", err)
+		return nil, err
+	}
+	if err := u.diagramRepo.Update(ctx, snap.Diagram); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// applyComments sets the comments of the tables and fields the rows name,
+// matching schema, table and field names case-insensitively, and returns the
+// tables that changed. A row without a field sets the table comment.
+func applyComments(tables []domain.Table, rows []spreadsheet.Row) (*domain.CommentImportResult, []domain.Table) {
+	result := &domain.CommentImportResult{Rows: len(rows), Unmatched: make([]domain.UnmatchedRow, 0)}
+	byKey := make(map[string]int, len(tables))
+	for i, t := range tables {
+		byKey[strings.ToLower(tableKey(t.Schema, t.Name))] = i
+	}
+
+	changed := make(map[int]bool)
+	for _, r := range rows {
+		unmatched := domain.UnmatchedRow{Line: r.Line, Schema: r.Schema, Table: r.Table, Field: r.Field}
+		ti, ok := byKey[strings.ToLower(tableKey(r.Schema, r.Table))]
+		if !ok {
+			unmatched.Reason = "table not found"
+			result.Unmatched = append(result.Unmatched, unmatched)
+			continue
+		}
+		t := &tables[ti]
+
+		if r.Field == "" {
+			if t.Comments == r.Comment {
+				result.Unchanged++
+				continue
+			}
+			t.Comments = r.Comment
+			changed[ti] = true
+			result.Updated++
+			continue
+		}
+
+		fi := -1
+		for i, f := range t.Fields {
+			if strings.EqualFold(domain.MapString(f, "name"), r.Field) {
+				fi = i
+				break
+			}
+		}
+		if fi < 0 {
+			unmatched.Reason = "field not found"
+			result.Unmatched = append(result.Unmatched, unmatched)
+			continue
+		}
+		field := t.Fields[fi]
+		if domain.MapString(field, "comments") == r.Comment {
+			result.Unchanged++
+			continue
+		}
+		if r.Comment == "" {
+			delete(field, "comments")
+		} else {
+			field["comments"] = r.Comment
+		}
+		changed[ti] = true
+		result.Updated++
+	}
+
+	out := make([]domain.Table, 0, len(changed))
+	for i, t := range tables {
+		if changed[i] {
+			out = append(out, t)
+		}
+	}
+	return result, out
+}
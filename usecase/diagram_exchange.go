@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/iots1/vertex-diagram/codegen"
+	"github.com/iots1/vertex-diagram/dbml"
+	"github.com/iots1/vertex-diagram/ddl"
+	"github.com/iots1/vertex-diagram/dictionary"
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/dot"
+	"github.com/iots1/vertex-diagram/erd"
+	"github.com/iots1/vertex-diagram/layout"
+	"github.com/iots1/vertex-diagram/prisma"
+	"github.com/iots1/vertex-diagram/render"
+	"github.com/iots1/vertex-diagram/spreadsheet"
+)
+
+// Formats a diagram can be imported from or exported to
+var (
+	importers = map[string]domain.Importer{
+		domain.FormatDBML:   dbml.Format{},
+		domain.FormatPrisma: prisma.Format{},
+		domain.FormatSQL:    ddl.Format{},
+	}
+	exporters = map[string]domain.Exporter{
+		domain.FormatDBML:       dbml.Format{},
+		domain.FormatMermaid:    erd.Mermaid{},
+		domain.FormatPlantUML:   erd.PlantUML{},
+		domain.FormatSVG:        render.SVG{},
+		domain.FormatPNG:        render.PNG{},
+		domain.FormatDOT:        dot.Format{},
+		domain.FormatGo:         codegen.Go{},
+		domain.FormatPrisma:     prisma.Format{},
+		domain.FormatJSONSchema: codegen.JSONSchema{},
+		domain.FormatTypeScript: codegen.TypeScript{},
+		domain.FormatZod:        codegen.Zod{},
+		domain.FormatDjango:     codegen.Archive{Generator: codegen.Django{}, Name: "django"},
+		domain.FormatSQLAlchemy: codegen.Archive{Generator: codegen.SQLAlchemy{}, Name: "sqlalchemy"},
+		domain.FormatRails:      codegen.Archive{Generator: codegen.Rails{}, Name: "rails"},
+		domain.FormatMarkdown:   dictionary.Markdown{},
+		domain.FormatHTML:       dictionary.HTML{},
+		domain.FormatCSV:        spreadsheet.CSV{},
+		domain.FormatXLSX:       spreadsheet.XLSX{},
+		domain.FormatSQL:        ddl.Format{},
+	}
+)
+
+func (u *diagramUsecase) Import(c context.Context, req domain.ImportRequest) (*domain.ImportResult, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	importer, ok := importers[strings.ToLower(req.Format)]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported import format %q", domain.ErrBadParamInput, req.Format)
+	}
+	parsed, warnings, err := importer.Import(req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+	}
+
+	name := req.Name
+	if name == "" {
+		name = parsed.Diagram.Name
+	}
+	if name == "" {
+		name = "Imported " + strings.ToUpper(req.Format) + " diagram"
+	}
+
+	log.Printf("📥 Importing %s diagram %q: %d tables, %d relationships, %d warnings",
+		req.Format, name, len(parsed.Tables), len(parsed.Relationships), len(warnings))
+
+	// 1. Store the diagram document to get its ID
+	diagram := &domain.Diagram{
+		Name:     name,
+		Content:  parsed.Diagram.Content,
+		Revision: 1,
+	}
+	if diagram.Content == nil {
+		diagram.Content = make(map[string]interface{})
+	}
+	if err := u.diagramRepo.Store(ctx, diagram); err != nil {
+		return nil, err
+	}
+
+	// 2. Replace the placeholder IDs and lay the tables out before storing them
+	snap := remapSnapshot(parsed, diagram.ID, newIDRemapper())
+	arrangeImported(snap)
+	if err := u.storeSnapshot(ctx, snap); err != nil {
+		log.Printf("  ❌ Error storing imported entities: %v", err)
+		return nil, err
+	}
+
+	if warnings == nil {
+		warnings = make([]string, 0)
+	}
+	log.Printf("✅ Diagram imported: %s", diagram.ID)
+	return &domain.ImportResult{
+		Diagram:       diagram,
+		Tables:        len(snap.Tables),
+		Relationships: len(snap.Relationships),
+		CustomTypes:   len(snap.CustomTypes),
+		Areas:         len(snap.Areas),
+		Notes:         len(snap.Notes),
+		Warnings:      warnings,
+	}, nil
+}
+
+func (u *diagramUsecase) Export(c context.Context, req domain.ExportRequest) (*domain.ExportResult, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	exporter, ok := exporters[strings.ToLower(req.Format)]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported export format %q", domain.ErrBadParamInput, req.Format)
+	}
+	snap, err := u.loadSnapshot(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	if snap, err = exportScope(snap, req.Options); err != nil {
+		return nil, err
+	}
+
+	data, err := exporter.Export(snap, req.Options)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ExportResult{
+		ContentType: exporter.ContentType(),
+		FileName:    exportFileName(snap.Diagram.Name) + "." + exporter.Extension(),
+		Data:        data,
+	}, nil
+}
+
+// exportScope applies the scope options shared by all formats: filter=true
+// honors the saved diagram filter, area (ID or name) and schema (comma
+// separated) restrict the output further
+func exportScope(snap *domain.DiagramSnapshot, opts map[string]string) (*domain.DiagramSnapshot, error) {
+	if b, _ := strconv.ParseBool(opts["filter"]); b {
+		snap = snap.Scoped(snap.Filter.Scope())
+	}
+
+	var sc domain.SnapshotScope
+	if area := opts["area"]; area != "" {
+		i := slices.IndexFunc(snap.Areas, func(a domain.Area) bool {
+			return a.AreaID == area || strings.EqualFold(a.Name, area)
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("%w: area %s", domain.ErrNotFound, area)
+		}
+		sc.AreaIDs = []string{snap.Areas[i].AreaID}
+	}
+	if schema := opts["schema"]; schema != "" {
+		for _, s := range strings.Split(schema, ",") {
+			sc.Schemas = append(sc.Schemas, strings.TrimSpace(s))
+		}
+	}
+	return snap.Scoped(sc), nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// exportFileName turns a diagram name into a safe download file name
+func exportFileName(name string) string {
+	name = strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_.")
+	if name == "" {
+		return "diagram"
+	}
+	return name
+}
+
+// arrangeImported places imported tables, which carry no positions: each area
+// gets a layered block of its members, areas are packed in rows, the remaining
+// tables are laid out below them and notes are stacked on the right
+func arrangeImported(snap *domain.DiagramSnapshot) {
+	edges := make([]layout.Edge, 0, len(snap.Relationships))
+	for _, r := range snap.Relationships {
+		parent, child := r.ParentChild()
+		edges = append(edges, layout.Edge{From: parent, To: child})
+	}
+
+	placed := make(map[string]layout.Point, len(snap.Tables))
+	place := func(res layout.Result, x, y int) {
+		for id, p := range res.Positions {
+			placed[id] = layout.Point{X: x + p.X, Y: y + p.Y}
+		}
+	}
+
+	grouped := make(map[string]bool)
+	shelf := newShelfPacker(len(snap.Areas))
+	right, bottom := 0, 0
+	for i := range snap.Areas {
+		a := &snap.Areas[i]
+		members := make([]domain.Table, 0)
+		for _, t := range snap.Tables {
+			if !grouped[t.TableID] && a.Includes(t) {
+				grouped[t.TableID] = true
+				members = append(members, t)
+			}
+		}
+
+		res := layout.Layered(layoutNodes(members), edges)
+		a.Width = max(res.Width, domain.TableWidth) + 2*areaPadding
+		a.Height = res.Height + areaHeaderHeight + areaPadding
+		a.X, a.Y = shelf.place(a.Width, a.Height)
+		if a.Color == "" {
+			a.Color = areaColor(i)
+		}
+		place(res, a.X+areaPadding, a.Y+areaHeaderHeight)
+		right, bottom = max(right, a.X+a.Width), shelf.bottom()
+	}
+
+	free := make([]domain.Table, 0)
+	for _, t := range snap.Tables {
+		if !grouped[t.TableID] {
+			free = append(free, t)
+		}
+	}
+	if len(free) > 0 {
+		y := 0
+		if len(snap.Areas) > 0 {
+			y = bottom + layout.VerticalGap
+		}
+		res := layout.Layered(layoutNodes(free), edges)
+		place(res, 0, y)
+		right = max(right, res.Width)
+	}
+
+	for i := range snap.Tables {
+		p := placed[snap.Tables[i].TableID]
+		snap.Tables[i].X, snap.Tables[i].Y = p.X, p.Y
+	}
+
+	y := 0
+	for i := range snap.Notes {
+		n := &snap.Notes[i]
+		n.X, n.Y = right+layout.HorizontalGap, y
+		y += n.Height + layout.HorizontalGap
+	}
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/iots1/vertex-diagram/diff"
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/lint"
+)
+
+func (u *diagramUsecase) Lint(c context.Context, id string) (*domain.LintReport, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	snap, err := u.loadSnapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.LintReport{DiagramID: id, Issues: lint.Check(snap)}
+	if report.Issues == nil {
+		report.Issues = make([]domain.LintIssue, 0)
+	}
+	for _, issue := range report.Issues {
+		switch issue.Severity {
+		case domain.SeverityError:
+			report.Errors++
+		case domain.SeverityWarning:
+			report.Warnings++
+		default:
+			report.Infos++
+		}
+	}
+	return report, nil
+}
+
+func (u *diagramUsecase) Diff(c context.Context, fromID, toID string) (*domain.DiagramDiff, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	from, err := u.loadSnapshot(ctx, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := u.loadSnapshot(ctx, toID)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Compare(from, to), nil
+}
@@ -0,0 +1,369 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+const (
+	defaultMergeSuffix = "_merged"
+	// Horizontal gap between the target's content and the merged source content
+	mergeGap = 200
+)
+
+func (u *diagramUsecase) Merge(c context.Context, req domain.MergeRequest) (*domain.MergeReport, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	switch req.Strategy {
+	case "":
+		req.Strategy = domain.MergeKeepTarget
+	case domain.MergeKeepTarget, domain.MergeKeepSource, domain.MergeRenameWithSuffix, domain.MergeFields:
+	default:
+		return nil, fmt.Errorf("%w: unsupported merge strategy %q", domain.ErrBadParamInput, req.Strategy)
+	}
+	if req.SourceID == "" || req.TargetID == "" {
+		return nil, fmt.Errorf("%w: source and target diagrams are required", domain.ErrBadParamInput)
+	}
+	if req.SourceID == req.TargetID {
+		return nil, fmt.Errorf("%w: cannot merge a diagram into itself", domain.ErrBadParamInput)
+	}
+	if req.Suffix == "" {
+		req.Suffix = defaultMergeSuffix
+	}
+
+	source, err := u.loadSnapshot(ctx, req.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := u.loadSnapshot(ctx, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔀 Merging diagram %s into %s (strategy=%s)", req.SourceID, req.TargetID, req.Strategy)
+
+	merged, report := mergeSnapshots(source, target, req)
+	if req.DryRun {
+		return report, nil
+	}
+
+	if err := u.replaceSnapshot(ctx, merged); err != nil {
+		log.Printf("  ❌ Error storing merged entities: %v", err)
+		return nil, err
+	}
+	if err := u.diagramRepo.Update(ctx, merged.Diagram); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Merge done: +%d tables, %d conflicts, +%d relationships",
+		report.TablesAdded, len(report.Conflicts), report.RelationshipsAdded)
+	return report, nil
+}
+
+// mergeSnapshots returns the target snapshot with the source merged in, plus the report
+func mergeSnapshots(source, target *domain.DiagramSnapshot, req domain.MergeRequest) (*domain.DiagramSnapshot, *domain.MergeReport) {
+	report := &domain.MergeReport{
+		SourceID:  req.SourceID,
+		TargetID:  req.TargetID,
+		Strategy:  req.Strategy,
+		DryRun:    req.DryRun,
+		Conflicts: make([]domain.MergeConflict, 0),
+	}
+
+	report.OffsetX, report.OffsetY = mergeOffset(source, target)
+	if req.OffsetX != nil {
+		report.OffsetX = *req.OffsetX
+	}
+	if req.OffsetY != nil {
+		report.OffsetY = *req.OffsetY
+	}
+
+	targetByKey := make(map[string]int, len(target.Tables))
+	names := make(map[string]bool, len(target.Tables))
+	for i, t := range target.Tables {
+		targetByKey[tableKey(t.Schema, t.Name)] = i
+		names[tableKey(t.Schema, t.Name)] = true
+	}
+	// Renamed tables must not take the name of a source table added as is
+	for _, t := range source.Tables {
+		names[tableKey(t.Schema, t.Name)] = true
+	}
+
+	// Colliding tables either map onto the target table or get renamed
+	ids := newIDRemapper()
+	conflicts := make(map[string]int)  // target table ID -> index in report.Conflicts
+	renamed := make(map[string]string) // source table ID -> new name
+	for _, st := range source.Tables {
+		ti, ok := targetByKey[tableKey(st.Schema, st.Name)]
+		if !ok {
+			continue
+		}
+		tt := target.Tables[ti]
+		conflict := domain.MergeConflict{
+			Schema:        st.Schema,
+			Name:          st.Name,
+			SourceTableID: st.TableID,
+			TargetTableID: tt.TableID,
+			Resolution:    req.Strategy,
+		}
+
+		if req.Strategy == domain.MergeRenameWithSuffix {
+			name := st.Name + req.Suffix
+			for n := 2; names[tableKey(st.Schema, name)]; n++ {
+				name = fmt.Sprintf("%s%s%d", st.Name, req.Suffix, n)
+			}
+			names[tableKey(st.Schema, name)] = true
+			renamed[st.TableID] = name
+			conflict.RenamedTo = name
+			report.Conflicts = append(report.Conflicts, conflict)
+			continue
+		}
+
+		ids.set(st.TableID, tt.TableID)
+		targetFields := fieldIDsByName(tt.Fields)
+		for _, f := range st.Fields {
+			sid, _ := f["id"].(string)
+			name, _ := f["name"].(string)
+			if tid, ok := targetFields[name]; ok && sid != "" {
+				ids.set(sid, tid)
+			}
+		}
+		conflicts[tt.TableID] = len(report.Conflicts)
+		report.Conflicts = append(report.Conflicts, conflict)
+	}
+
+	src := remapSnapshot(source, target.Diagram.ID, ids)
+	merged := &domain.DiagramSnapshot{
+		Diagram:      target.Diagram,
+		Tables:       append([]domain.Table{}, target.Tables...),
+		Areas:        append([]domain.Area{}, target.Areas...),
+		Notes:        append([]domain.Note{}, target.Notes...),
+		CustomTypes:  append([]domain.CustomType{}, target.CustomTypes...),
+		Dependencies: append([]domain.Dependency{}, target.Dependencies...),
+		Filter:       target.Filter,
+	}
+
+	// Tables
+	for i, st := range src.Tables {
+		orig := source.Tables[i]
+		if ci, ok := conflicts[st.TableID]; ok {
+			ti := targetByKey[tableKey(orig.Schema, orig.Name)]
+			merged.Tables[ti] = resolveTableConflict(merged.Tables[ti], st, req.Strategy, &report.Conflicts[ci])
+			continue
+		}
+		if name, ok := renamed[orig.TableID]; ok {
+			st.Name = name
+		}
+		st.X += report.OffsetX
+		st.Y += report.OffsetY
+		merged.Tables = append(merged.Tables, st)
+		report.TablesAdded++
+	}
+
+	// Relationships: keep only those whose endpoints still exist, without duplicates
+	fields := make(map[string]bool)
+	for _, t := range merged.Tables {
+		fields[t.TableID] = true
+		for id := range fieldIDsByID(t.Fields) {
+			fields[t.TableID+"/"+id] = true
+		}
+	}
+	seen := make(map[string]bool)
+	merged.Relationships = make([]domain.Relationship, 0, len(target.Relationships)+len(src.Relationships))
+	keep := func(r domain.Relationship) bool {
+		key := r.SourceTableID + "/" + r.SourceFieldID + ">" + r.TargetTableID + "/" + r.TargetFieldID
+		if seen[key] || !fields[r.SourceTableID+"/"+r.SourceFieldID] || !fields[r.TargetTableID+"/"+r.TargetFieldID] {
+			return false
+		}
+		seen[key] = true
+		return true
+	}
+	for _, r := range target.Relationships {
+		if keep(r) {
+			merged.Relationships = append(merged.Relationships, r)
+		} else {
+			report.RelationshipsDropped++
+		}
+	}
+	for _, r := range src.Relationships {
+		if keep(r) {
+			merged.Relationships = append(merged.Relationships, r)
+			report.RelationshipsAdded++
+		} else {
+			report.RelationshipsDropped++
+		}
+	}
+
+	// Dependencies
+	deps := make(map[string]bool)
+	for _, d := range merged.Dependencies {
+		deps[d.TableID+">"+d.DependentTableID] = true
+	}
+	for _, d := range src.Dependencies {
+		key := d.TableID + ">" + d.DependentTableID
+		if deps[key] || !fields[d.TableID] || !fields[d.DependentTableID] {
+			continue
+		}
+		deps[key] = true
+		merged.Dependencies = append(merged.Dependencies, d)
+		report.DependenciesAdded++
+	}
+
+	// Custom types: the target's definition wins on name collisions
+	types := make(map[string]bool)
+	for _, ct := range merged.CustomTypes {
+		types[tableKey(ct.Schema, ct.Type)] = true
+	}
+	for _, ct := range src.CustomTypes {
+		if types[tableKey(ct.Schema, ct.Type)] {
+			report.CustomTypesSkipped = append(report.CustomTypesSkipped, tableKey(ct.Schema, ct.Type))
+			continue
+		}
+		merged.CustomTypes = append(merged.CustomTypes, ct)
+		report.CustomTypesAdded++
+	}
+
+	// Areas and notes move with the source tables
+	for _, a := range src.Areas {
+		a.X += report.OffsetX
+		a.Y += report.OffsetY
+		merged.Areas = append(merged.Areas, a)
+		report.AreasAdded++
+	}
+	for _, n := range src.Notes {
+		n.X += report.OffsetX
+		n.Y += report.OffsetY
+		merged.Notes = append(merged.Notes, n)
+		report.NotesAdded++
+	}
+
+	return merged, report
+}
+
+// resolveTableConflict applies a mapping strategy; source fields sharing a name
+// with a target field already carry the target field's ID
+func resolveTableConflict(tt, st domain.Table, strategy string, conflict *domain.MergeConflict) domain.Table {
+	targetFields := fieldIDsByID(tt.Fields)
+	sourceFields := fieldIDsByID(st.Fields)
+
+	switch strategy {
+	case domain.MergeKeepSource:
+		for _, f := range tt.Fields {
+			if id, _ := f["id"].(string); sourceFields[id] == "" {
+				conflict.DroppedFields = append(conflict.DroppedFields, targetFields[id])
+			}
+		}
+		for _, f := range st.Fields {
+			if id, _ := f["id"].(string); targetFields[id] == "" {
+				conflict.AddedFields = append(conflict.AddedFields, sourceFields[id])
+			}
+		}
+		tt.Fields = st.Fields
+		tt.Indexes = st.Indexes
+		tt.IsView = st.IsView
+
+	case domain.MergeFields:
+		for _, f := range st.Fields {
+			id, _ := f["id"].(string)
+			if _, ok := targetFields[id]; ok {
+				continue
+			}
+			tt.Fields = append(tt.Fields, f)
+			conflict.AddedFields = append(conflict.AddedFields, sourceFields[id])
+		}
+		indexNames := make(map[string]bool)
+		for _, idx := range tt.Indexes {
+			name, _ := idx["name"].(string)
+			indexNames[name] = true
+		}
+		for _, idx := range st.Indexes {
+			if name, _ := idx["name"].(string); !indexNames[name] {
+				tt.Indexes = append(tt.Indexes, idx)
+			}
+		}
+	}
+	return tt
+}
+
+// mergeOffset places the source content to the right of the target content, top-aligned
+func mergeOffset(source, target *domain.DiagramSnapshot) (int, int) {
+	sb, sok := snapshotBounds(source)
+	tb, tok := snapshotBounds(target)
+	if !sok || !tok {
+		return 0, 0
+	}
+	return tb.maxX + mergeGap - sb.minX, tb.minY - sb.minY
+}
+
+type bounds struct {
+	minX, minY, maxX, maxY int
+}
+
+func (b *bounds) add(x, y, w, h int, first bool) {
+	if first || x < b.minX {
+		b.minX = x
+	}
+	if first || y < b.minY {
+		b.minY = y
+	}
+	if first || x+w > b.maxX {
+		b.maxX = x + w
+	}
+	if first || y+h > b.maxY {
+		b.maxY = y + h
+	}
+}
+
+// snapshotBounds returns the canvas rectangle covering tables, areas and notes
+func snapshotBounds(s *domain.DiagramSnapshot) (bounds, bool) {
+	var b bounds
+	n := 0
+	for _, t := range s.Tables {
+		w, h := t.Size()
+		b.add(t.X, t.Y, w, h, n == 0)
+		n++
+	}
+	for _, a := range s.Areas {
+		b.add(a.X, a.Y, a.Width, a.Height, n == 0)
+		n++
+	}
+	for _, note := range s.Notes {
+		b.add(note.X, note.Y, note.Width, note.Height, n == 0)
+		n++
+	}
+	return b, n > 0
+}
+
+func tableKey(schema, name string) string {
+	return schema + "." + name
+}
+
+// fieldIDsByName maps field name -> field ID
+func fieldIDsByName(fields []map[string]interface{}) map[string]string {
+	out := make(map[string]string, len(fields))
+	for _, f := range fields {
+		id, _ := f["id"].(string)
+		name, _ := f["name"].(string)
+		if id != "" {
+			out[name] = id
+		}
+	}
+	return out
+}
+
+// fieldIDsByID maps field ID -> field name
+func fieldIDsByID(fields []map[string]interface{}) map[string]string {
+	out := make(map[string]string, len(fields))
+	for _, f := range fields {
+		id, _ := f["id"].(string)
+		name, _ := f["name"].(string)
+		if id != "" {
+			out[id] = name
+		}
+	}
+	return out
+}
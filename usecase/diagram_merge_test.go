@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+func TestMergeSnapshotsRenameWithSuffix(t *testing.T) {
+	snapshot := func(id string, names ...string) *domain.DiagramSnapshot {
+		s := &domain.DiagramSnapshot{Diagram: &domain.Diagram{ID: id}}
+		for _, name := range names {
+			s.Tables = append(s.Tables, domain.Table{TableID: id + "-" + name, Schema: "public", Name: name})
+		}
+		return s
+	}
+	tests := []struct {
+		name           string
+		source, target []string
+		want           []string
+	}{
+		{"suffix", []string{"users"}, []string{"users"}, []string{"users", "users_merged"}},
+		{"suffix taken in target", []string{"users"}, []string{"users", "users_merged"}, []string{"users", "users_merged", "users_merged2"}},
+		{"suffix taken in source", []string{"users", "users_merged"}, []string{"users"}, []string{"users", "users_merged2", "users_merged"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := domain.MergeRequest{SourceID: "s", TargetID: "t", Strategy: domain.MergeRenameWithSuffix, Suffix: defaultMergeSuffix}
+			merged, report := mergeSnapshots(snapshot("s", tt.source...), snapshot("t", tt.target...), req)
+
+			got := make([]string, 0, len(merged.Tables))
+			for _, tbl := range merged.Tables {
+				got = append(got, tbl.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("tables = %q, want %q", got, tt.want)
+			}
+			if report.TablesAdded != len(tt.source) {
+				t.Errorf("tables added = %d, want %d", report.TablesAdded, len(tt.source))
+			}
+		})
+	}
+}
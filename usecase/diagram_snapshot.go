@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+func (u *diagramUsecase) GetSnapshot(c context.Context, id string) (*domain.DiagramSnapshot, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+	return u.loadSnapshot(ctx, id)
+}
+
+// loadSnapshot reads a diagram and all of its child entities
+func (u *diagramUsecase) loadSnapshot(ctx context.Context, id string) (*domain.DiagramSnapshot, error) {
+	var err error
+	snap := &domain.DiagramSnapshot{}
+
+	if snap.Diagram, err = u.diagramRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if snap.Tables, err = u.tableRepo.GetByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	if snap.Relationships, err = u.relationshipRepo.GetByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	if snap.Dependencies, err = u.dependencyRepo.GetByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	if snap.Areas, err = u.areaRepo.GetByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	if snap.CustomTypes, err = u.customTypeRepo.GetByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	if snap.Notes, err = u.noteRepo.GetByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	if snap.Filter, err = u.diagramFilterRepo.GetByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// storeSnapshot inserts all child entities of a snapshot; their DiagramID must
+// already be set. Area membership is recomputed from the table positions.
+func (u *diagramUsecase) storeSnapshot(ctx context.Context, snap *domain.DiagramSnapshot) error {
+	domain.AssignAreaMembers(snap.Areas, snap.Tables)
+	if err := u.tableRepo.StoreMultiple(ctx, snap.Tables); err != nil {
+		return err
+	}
+	if err := u.relationshipRepo.StoreMultiple(ctx, snap.Relationships); err != nil {
+		return err
+	}
+	if err := u.dependencyRepo.StoreMultiple(ctx, snap.Dependencies); err != nil {
+		return err
+	}
+	if err := u.areaRepo.StoreMultiple(ctx, snap.Areas); err != nil {
+		return err
+	}
+	if err := u.customTypeRepo.StoreMultiple(ctx, snap.CustomTypes); err != nil {
+		return err
+	}
+	if err := u.noteRepo.StoreMultiple(ctx, snap.Notes); err != nil {
+		return err
+	}
+	if snap.Filter != nil {
+		return u.diagramFilterRepo.Store(ctx, snap.Filter)
+	}
+	return nil
+}
+
+// replaceSnapshot deletes all child entities of snap.Diagram and stores the snapshot's instead
+func (u *diagramUsecase) replaceSnapshot(ctx context.Context, snap *domain.DiagramSnapshot) error {
+	id := snap.Diagram.ID
+	if err := u.tableRepo.DeleteByDiagramID(ctx, id); err != nil {
+		return err
+	}
+	if err := u.relationshipRepo.DeleteByDiagramID(ctx, id); err != nil {
+		return err
+	}
+	if err := u.dependencyRepo.DeleteByDiagramID(ctx, id); err != nil {
+		return err
+	}
+	if err := u.areaRepo.DeleteByDiagramID(ctx, id); err != nil {
+		return err
+	}
+	if err := u.customTypeRepo.DeleteByDiagramID(ctx, id); err != nil {
+		return err
+	}
+	if err := u.noteRepo.DeleteByDiagramID(ctx, id); err != nil {
+		return err
+	}
+	if err := u.diagramFilterRepo.DeleteByDiagramID(ctx, id); err != nil {
+		return err
+	}
+	return u.storeSnapshot(ctx, snap)
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+func (u *diagramUsecase) GetTrash(c context.Context, q domain.DiagramListQuery) (*domain.DiagramPage, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	q.Deleted = true
+	if q.Sort == "" {
+		q.Sort = domain.DiagramSortDeletedAt
+	}
+	return u.fetch(ctx, q)
+}
+
+// Undelete takes the diagram out of the trash first, so a diagram that is not
+// in the trash is reported as not found before any entity is touched
+func (u *diagramUsecase) Undelete(c context.Context, id string) (*domain.Diagram, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	log.Printf("♻️  Restoring diagram %s from the trash", id)
+	if err := u.diagramRepo.Undelete(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := u.tableRepo.UndeleteByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := u.relationshipRepo.UndeleteByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := u.dependencyRepo.UndeleteByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := u.areaRepo.UndeleteByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := u.customTypeRepo.UndeleteByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := u.noteRepo.UndeleteByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := u.diagramFilterRepo.UndeleteByDiagramID(ctx, id); err != nil {
+		return nil, err
+	}
+	return u.diagramRepo.GetByID(ctx, id)
+}
+
+// PurgeTrash collects the expired diagrams first and then deletes them one
+// by one, each with its own timeout. A diagram restored after it was listed
+// is skipped.
+func (u *diagramUsecase) PurgeTrash(c context.Context, before time.Time) (int, error) {
+	ids := make([]string, 0)
+	q := domain.DiagramListQuery{
+		Limit:      maxListLimit,
+		Sort:       domain.DiagramSortDeletedAt,
+		Order:      domain.SortAsc,
+		SearchMode: domain.SearchModeContains,
+		Deleted:    true,
+	}
+	for done := false; !done; {
+		ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+		page, err := u.diagramRepo.Fetch(ctx, q)
+		cancel()
+		if err != nil {
+			return 0, err
+		}
+		for _, d := range page.Items {
+			if d.DeletedAt == nil || !d.DeletedAt.Before(before) {
+				done = true
+				break
+			}
+			ids = append(ids, d.ID)
+		}
+		if page.NextCursor == "" {
+			done = true
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	purged := 0
+	for _, id := range ids {
+		ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+		ok, err := u.purge(ctx, id, before)
+		cancel()
+		if ok {
+			purged++
+		}
+		if err != nil {
+			log.Printf("  ❌ Error purging diagram %s: %v", id, err)
+			return purged, err
+		}
+	}
+	if purged > 0 {
+		log.Printf("🧹 Purged %d diagrams deleted before %s", purged, before.Format(time.RFC3339))
+	}
+	return purged, nil
+}
+
+// purge permanently deletes a diagram that is still in the trash and all of
+// its entities. The diagram goes first: once it is gone it can no longer be
+// restored, and if it was restored it no longer matches and nothing is
+// deleted. Entities only match while they are in the trash too.
+func (u *diagramUsecase) purge(ctx context.Context, id string, before time.Time) (bool, error) {
+	ok, err := u.diagramRepo.Purge(ctx, id, before)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	// Cascade delete all associated entities
+	if err := u.tableRepo.PurgeByDiagramID(ctx, id, before); err != nil {
+		return true, err
+	}
+	if err := u.relationshipRepo.PurgeByDiagramID(ctx, id, before); err != nil {
+		return true, err
+	}
+	if err := u.dependencyRepo.PurgeByDiagramID(ctx, id, before); err != nil {
+		return true, err
+	}
+	if err := u.areaRepo.PurgeByDiagramID(ctx, id, before); err != nil {
+		return true, err
+	}
+	if err := u.customTypeRepo.PurgeByDiagramID(ctx, id, before); err != nil {
+		return true, err
+	}
+	if err := u.noteRepo.PurgeByDiagramID(ctx, id, before); err != nil {
+		return true, err
+	}
+	return true, u.diagramFilterRepo.PurgeByDiagramID(ctx, id, before)
+}
+
+// RunTrashPurger permanently deletes diagrams that have been in the trash
+// longer than retention, checking every interval until ctx is done
+func RunTrashPurger(ctx context.Context, uc domain.DiagramUsecase, retention, interval time.Duration) {
+	log.Printf("🧹 Trash purger started: retention %s, checking every %s", retention, interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := uc.PurgeTrash(ctx, time.Now().Add(-retention)); err != nil {
+			log.Printf("❌ Error purging the trash: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
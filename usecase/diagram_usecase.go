@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/metrics"
 )
 
 type diagramUsecase struct {
@@ -45,87 +46,98 @@ func NewDiagramUsecase(
 	}
 }
 
-func (u *diagramUsecase) GetAll(c context.Context) ([]domain.Diagram, error) {
-	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
-	defer cancel()
-	return u.diagramRepo.Fetch(ctx)
-}
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
 
-func (u *diagramUsecase) GetOne(c context.Context, id string) (*domain.Diagram, error) {
+func (u *diagramUsecase) GetAll(c context.Context, q domain.DiagramListQuery) (*domain.DiagramPage, error) {
 	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
 	defer cancel()
 
-	// 1. Get diagram
-	diagram, err := u.diagramRepo.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
+	q.Deleted = false
+	if q.Sort == "" {
+		q.Sort = domain.DiagramSortUpdatedAt
 	}
+	return u.fetch(ctx, q)
+}
 
-	// 2. Get tables
-	tables, err := u.tableRepo.GetByDiagramID(ctx, id)
-	if err != nil {
-		return nil, err
+// fetch validates the list options, fills in defaults and reads one page
+func (u *diagramUsecase) fetch(ctx context.Context, q domain.DiagramListQuery) (*domain.DiagramPage, error) {
+	switch q.Sort {
+	case domain.DiagramSortName, domain.DiagramSortUpdatedAt, domain.DiagramSortCreatedAt:
+	case domain.DiagramSortDeletedAt:
+		if !q.Deleted {
+			return nil, fmt.Errorf("%w: sort %q only applies to the trash", domain.ErrBadParamInput, q.Sort)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported sort %q", domain.ErrBadParamInput, q.Sort)
 	}
 
-	// 3. Get relationships
-	relationships, err := u.relationshipRepo.GetByDiagramID(ctx, id)
-	if err != nil {
-		return nil, err
+	// Newest first for timestamps, alphabetical for names
+	switch q.Order {
+	case "":
+		q.Order = domain.SortDesc
+		if q.Sort == domain.DiagramSortName {
+			q.Order = domain.SortAsc
+		}
+	case domain.SortAsc, domain.SortDesc:
+	default:
+		return nil, fmt.Errorf("%w: unsupported order %q", domain.ErrBadParamInput, q.Order)
 	}
 
-	// 4. Get dependencies
-	dependencies, err := u.dependencyRepo.GetByDiagramID(ctx, id)
-	if err != nil {
-		return nil, err
+	switch q.SearchMode {
+	case "":
+		q.SearchMode = domain.SearchModeContains
+	case domain.SearchModePrefix, domain.SearchModeContains:
+	default:
+		return nil, fmt.Errorf("%w: unsupported search mode %q", domain.ErrBadParamInput, q.SearchMode)
 	}
 
-	// 5. Get areas
-	areas, err := u.areaRepo.GetByDiagramID(ctx, id)
-	if err != nil {
-		return nil, err
+	if q.Limit <= 0 {
+		q.Limit = defaultListLimit
 	}
-
-	// 6. Get custom types
-	customTypes, err := u.customTypeRepo.GetByDiagramID(ctx, id)
-	if err != nil {
-		return nil, err
+	if q.Limit > maxListLimit {
+		q.Limit = maxListLimit
 	}
 
-	// 7. Get notes
-	notes, err := u.noteRepo.GetByDiagramID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
+	return u.diagramRepo.Fetch(ctx, q)
+}
+
+func (u *diagramUsecase) GetOne(c context.Context, id string) (*domain.Diagram, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
 
-	// 8. Get diagram filter
-	diagramFilter, err := u.diagramFilterRepo.GetByDiagramID(ctx, id)
+	snap, err := u.loadSnapshot(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	diagram := snap.Diagram
 
-	// 9. Merge all entities back into content
+	// Merge all entities back into content
 	if diagram.Content == nil {
 		diagram.Content = make(map[string]interface{})
 	}
 
 	log.Printf("  📦 Merging %d tables, %d relationships, %d dependencies, %d areas, %d custom types, %d notes",
-		len(tables), len(relationships), len(dependencies), len(areas), len(customTypes), len(notes))
+		len(snap.Tables), len(snap.Relationships), len(snap.Dependencies), len(snap.Areas), len(snap.CustomTypes), len(snap.Notes))
 
-	diagram.Content["tables"] = tables
-	diagram.Content["relationships"] = relationships
-	diagram.Content["dependencies"] = dependencies
-	diagram.Content["areas"] = areas
-	diagram.Content["customTypes"] = customTypes
-	diagram.Content["notes"] = notes
-	if diagramFilter != nil {
-		diagram.Content["diagramFilter"] = diagramFilter
+	diagram.Content["tables"] = snap.Tables
+	diagram.Content["relationships"] = snap.Relationships
+	diagram.Content["dependencies"] = snap.Dependencies
+	diagram.Content["areas"] = snap.Areas
+	diagram.Content["customTypes"] = snap.CustomTypes
+	diagram.Content["notes"] = snap.Notes
+	if snap.Filter != nil {
+		diagram.Content["diagramFilter"] = snap.Filter
 	}
 
 	// Debug: log first relationship if exists
-	if len(relationships) > 0 {
+	if len(snap.Relationships) > 0 {
+		first := snap.Relationships[0]
 		log.Printf("    First relationship: ID=%s, sourceTableId=%s, targetTableId=%s, sourceCard=%s, targetCard=%s",
-			relationships[0].RelationshipID, relationships[0].SourceTableID, relationships[0].TargetTableID,
-			relationships[0].SourceCardinality, relationships[0].TargetCardinality)
+			first.RelationshipID, first.SourceTableID, first.TargetTableID,
+			first.SourceCardinality, first.TargetCardinality)
 	}
 
 	return diagram, nil
@@ -140,6 +152,7 @@ func (u *diagramUsecase) Save(c context.Context, d *domain.Diagram) (*domain.Dia
 	}
 
 	log.Printf("💾 Saving diagram: ID=%s, Name=%s", d.ID, d.Name)
+	defer metrics.Since(metrics.SaveStageDuration, time.Now(), "total")
 
 	// 1. Save diagram first to get ID
 	if d.ID == "" {
@@ -147,59 +160,65 @@ func (u *diagramUsecase) Save(c context.Context, d *domain.Diagram) (*domain.Dia
 			d.Name = "Untitled Diagram"
 		}
 		log.Printf("  📌 Creating new diagram: %s", d.Name)
-		err := u.diagramRepo.Store(ctx, d)
+		err := timeStage("diagram", func() error { return u.diagramRepo.Store(ctx, d) })
 		if err != nil {
 			log.Printf("  ❌ Error storing diagram: %v", err)
 			return nil, err
 		}
 	} else {
-		log.Printf("  📌 Updating existing diagram: ID=%s", d.ID)
-		err := u.diagramRepo.Update(ctx, d)
+		// Saving would rewrite the entities of a trashed diagram as live ones
+		trashed, err := u.diagramRepo.InTrash(ctx, d.ID)
 		if err != nil {
-			log.Printf("  ❌ Error updating diagram: %v", err)
+			log.Printf("  ❌ Error checking trash: %v", err)
 			return nil, err
 		}
+		if trashed {
+			return nil, fmt.Errorf("%w: diagram %s is in the trash; restore it before saving", domain.ErrConflict, d.ID)
+		}
+		// The diagram document is upserted once below, after entities are extracted,
+		// so each save bumps the revision exactly once
+		log.Printf("  📌 Updating existing diagram: ID=%s", d.ID)
 	}
 
 	// 2. Extract and save all entities BEFORE cleaning up content
 	log.Printf("  📋 Saving tables...")
-	if err := u.saveTables(ctx, d); err != nil {
+	if err := timeStage("tables", func() error { return u.saveTables(ctx, d) }); err != nil {
 		log.Printf("  ❌ Error saving tables: %v", err)
 		return nil, err
 	}
 
 	log.Printf("  🔗 Saving relationships...")
-	if err := u.saveRelationships(ctx, d); err != nil {
+	if err := timeStage("relationships", func() error { return u.saveRelationships(ctx, d) }); err != nil {
 		log.Printf("  ❌ Error saving relationships: %v", err)
 		return nil, err
 	}
 
 	log.Printf("  ⛓️  Saving dependencies...")
-	if err := u.saveDependencies(ctx, d); err != nil {
+	if err := timeStage("dependencies", func() error { return u.saveDependencies(ctx, d) }); err != nil {
 		log.Printf("  ❌ Error saving dependencies: %v", err)
 		return nil, err
 	}
 
 	log.Printf("  📦 Saving areas...")
-	if err := u.saveAreas(ctx, d); err != nil {
+	if err := timeStage("areas", func() error { return u.saveAreas(ctx, d) }); err != nil {
 		log.Printf("  ❌ Error saving areas: %v", err)
 		return nil, err
 	}
 
 	log.Printf("  🎨 Saving custom types...")
-	if err := u.saveCustomTypes(ctx, d); err != nil {
+	if err := timeStage("custom_types", func() error { return u.saveCustomTypes(ctx, d) }); err != nil {
 		log.Printf("  ❌ Error saving custom types: %v", err)
 		return nil, err
 	}
 
 	log.Printf("  📝 Saving notes...")
-	if err := u.saveNotes(ctx, d); err != nil {
+	if err := timeStage("notes", func() error { return u.saveNotes(ctx, d) }); err != nil {
 		log.Printf("  ❌ Error saving notes: %v", err)
 		return nil, err
 	}
 
 	log.Printf("  🔍 Saving diagram filter...")
-	if err := u.saveDiagramFilter(ctx, d); err != nil {
+	if err := timeStage("diagram_filter", func() error { return u.saveDiagramFilter(ctx, d) }); err != nil {
 		log.Printf("  ❌ Error saving diagram filter: %v", err)
 		return nil, err
 	}
@@ -210,7 +229,7 @@ func (u *diagramUsecase) Save(c context.Context, d *domain.Diagram) (*domain.Dia
 
 	// 4. Update diagram with cleaned content (no entity arrays)
 	log.Printf("  💾 Saving cleaned diagram content...")
-	err := u.diagramRepo.Update(ctx, d)
+	err := timeStage("content", func() error { return u.diagramRepo.Update(ctx, d) })
 	if err != nil {
 		log.Printf("  ❌ Error saving cleaned diagram: %v", err)
 		return nil, err
@@ -220,6 +239,12 @@ func (u *diagramUsecase) Save(c context.Context, d *domain.Diagram) (*domain.Dia
 	return d, nil
 }
 
+// timeStage runs one stage of Save and records how long it took
+func timeStage(stage string, save func() error) error {
+	defer metrics.Since(metrics.SaveStageDuration, time.Now(), stage)
+	return save()
+}
+
 func (u *diagramUsecase) cleanupContent(d *domain.Diagram) {
 	if d.Content == nil {
 		return
@@ -269,6 +294,7 @@ func (u *diagramUsecase) saveTables(ctx context.Context, d *domain.Diagram) erro
 			Fields:    fields,
 			Indexes:   indexes,
 			Color:     getStringValue(tableMap, "color"),
+			Comments:  getStringValue(tableMap, "comments"),
 			X:         getIntValue(tableMap, "x"),
 			Y:         getIntValue(tableMap, "y"),
 			IsView:    getBoolValue(tableMap, "isView"),
@@ -394,7 +420,14 @@ func (u *diagramUsecase) saveAreas(ctx context.Context, d *domain.Diagram) error
 			continue
 		}
 
+		// Keep the frontend's ID so filters and exports can refer to the area
+		id := getStringValue(areaMap, "id")
+		if id == "" {
+			id = generateID()
+		}
+
 		area := domain.Area{
+			AreaID:    id,
 			DiagramID: d.ID,
 			Name:      getStringValue(areaMap, "name"),
 			X:         getIntValue(areaMap, "x"),
@@ -406,10 +439,17 @@ func (u *diagramUsecase) saveAreas(ctx context.Context, d *domain.Diagram) error
 		areas = append(areas, area)
 	}
 
-	if len(areas) > 0 {
-		return u.areaRepo.StoreMultiple(ctx, areas)
+	if len(areas) == 0 {
+		return nil
 	}
-	return nil
+
+	// Membership follows the tables saved above
+	tables, err := u.tableRepo.GetByDiagramID(ctx, d.ID)
+	if err != nil {
+		return err
+	}
+	domain.AssignAreaMembers(areas, tables)
+	return u.areaRepo.StoreMultiple(ctx, areas)
 }
 
 func (u *diagramUsecase) saveCustomTypes(ctx context.Context, d *domain.Diagram) error {
@@ -528,8 +568,18 @@ func (u *diagramUsecase) saveDiagramFilter(ctx context.Context, d *domain.Diagra
 		}
 	}
 
+	// Extract area IDs
+	areaIDs := make([]string, 0)
+	if areaIDsData, ok := filterData["areaIds"].([]interface{}); ok {
+		for _, id := range areaIDsData {
+			if str, ok := id.(string); ok {
+				areaIDs = append(areaIDs, str)
+			}
+		}
+	}
+
 	// If no actual filter data, delete the filter
-	if len(tableIDs) == 0 && len(schemaIDs) == 0 {
+	if len(tableIDs) == 0 && len(schemaIDs) == 0 && len(areaIDs) == 0 {
 		return u.diagramFilterRepo.DeleteByDiagramID(ctx, d.ID)
 	}
 
@@ -537,6 +587,7 @@ func (u *diagramUsecase) saveDiagramFilter(ctx context.Context, d *domain.Diagra
 		DiagramID: d.ID,
 		TableIDs:  tableIDs,
 		SchemaIDs: schemaIDs,
+		AreaIDs:   areaIDs,
 	}
 
 	return u.diagramFilterRepo.Store(ctx, &filter)
@@ -602,39 +653,34 @@ func getStringValueWithDefault(m map[string]interface{}, key string, defaultValu
 	return defaultValue
 }
 
+// Delete moves the diagram and every entity that belongs to it to the trash;
+// PurgeTrash removes them for good
 func (u *diagramUsecase) Delete(c context.Context, id string) error {
 	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
 	defer cancel()
 
-	// Cascade delete all associated entities
-	if err := u.tableRepo.DeleteByDiagramID(ctx, id); err != nil {
+	now := time.Now()
+	log.Printf("🗑️  Moving diagram %s to the trash", id)
+	if err := u.diagramRepo.SoftDelete(ctx, id, now); err != nil {
 		return err
 	}
-
-	if err := u.relationshipRepo.DeleteByDiagramID(ctx, id); err != nil {
+	if err := u.tableRepo.SoftDeleteByDiagramID(ctx, id, now); err != nil {
 		return err
 	}
-
-	if err := u.dependencyRepo.DeleteByDiagramID(ctx, id); err != nil {
+	if err := u.relationshipRepo.SoftDeleteByDiagramID(ctx, id, now); err != nil {
 		return err
 	}
-
-	if err := u.areaRepo.DeleteByDiagramID(ctx, id); err != nil {
+	if err := u.dependencyRepo.SoftDeleteByDiagramID(ctx, id, now); err != nil {
 		return err
 	}
-
-	if err := u.customTypeRepo.DeleteByDiagramID(ctx, id); err != nil {
+	if err := u.areaRepo.SoftDeleteByDiagramID(ctx, id, now); err != nil {
 		return err
 	}
-
-	if err := u.noteRepo.DeleteByDiagramID(ctx, id); err != nil {
+	if err := u.customTypeRepo.SoftDeleteByDiagramID(ctx, id, now); err != nil {
 		return err
 	}
-
-	if err := u.diagramFilterRepo.DeleteByDiagramID(ctx, id); err != nil {
+	if err := u.noteRepo.SoftDeleteByDiagramID(ctx, id, now); err != nil {
 		return err
 	}
-
-	// Finally, delete the diagram
-	return u.diagramRepo.Delete(ctx, id)
-}
\ No newline at end of file
+	return u.diagramFilterRepo.SoftDeleteByDiagramID(ctx, id, now)
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/sqlgen"
+)
+
+// Short aliases that are also SQL keywords
+var reservedAliases = map[string]bool{
+	"as": true, "at": true, "by": true, "do": true, "go": true, "if": true,
+	"in": true, "is": true, "no": true, "of": true, "on": true, "or": true, "to": true,
+	"all": true, "and": true, "any": true, "asc": true, "end": true, "for": true,
+	"key": true, "not": true, "set": true, "top": true, "use": true,
+}
+
+// JoinQuery finds the shortest relationship path through the requested tables
+// and writes it as a SELECT with one aliased JOIN per hop
+func (u *graphUsecase) JoinQuery(c context.Context, req domain.JoinQueryRequest) (*domain.JoinQueryResult, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if len(req.TableIDs) < 2 {
+		return nil, fmt.Errorf("%w: at least two tables are required", domain.ErrBadParamInput)
+	}
+	joinType := "INNER JOIN"
+	switch req.JoinType {
+	case "", domain.JoinInner:
+	case domain.JoinLeft:
+		joinType = "LEFT JOIN"
+	default:
+		return nil, fmt.Errorf("%w: unsupported join type %q", domain.ErrBadParamInput, req.JoinType)
+	}
+
+	dg, err := u.load(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range req.TableIDs {
+		if _, err := dg.table(id); err != nil {
+			return nil, err
+		}
+	}
+
+	// Dialect from the request, then the diagram's database type
+	name := req.Dialect
+	if name == "" {
+		name, _ = dg.diagram.Content["databaseType"].(string)
+	}
+	dialect := sqlgen.PostgreSQL
+	if name != "" {
+		d, ok := sqlgen.ParseDialect(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported dialect %q", domain.ErrBadParamInput, name)
+		}
+		dialect = d
+	}
+
+	// 1. Chain the shortest path between each consecutive pair of tables
+	steps := make([]domain.JoinStep, 0)
+	for i := 0; i+1 < len(req.TableIDs); i++ {
+		from, to := req.TableIDs[i], req.TableIDs[i+1]
+		paths := dg.joins.ShortestPaths(from, to, 1)
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("%w: no relationship path from %s to %s",
+				domain.ErrBadParamInput, dg.tables[from].Name, dg.tables[to].Name)
+		}
+		steps = append(steps, dg.joinPath(from, paths[0]).Steps...)
+	}
+
+	result := &domain.JoinQueryResult{
+		DiagramID: req.DiagramID,
+		Dialect:   string(dialect),
+		Aliases:   make(map[string]string),
+		Steps:     make([]domain.JoinStep, 0, len(steps)),
+		Warnings:  make([]string, 0),
+	}
+
+	// 2. Alias every table once; a path coming back to a joined table adds nothing
+	used := make(map[string]bool)
+	alias := func(id string) string {
+		if a, ok := result.Aliases[id]; ok {
+			return a
+		}
+		a := uniqueAlias(tableAlias(dg.tables[id].Name), used)
+		result.Aliases[id] = a
+		return a
+	}
+
+	start := dg.tables[req.TableIDs[0]]
+	order := []string{start.TableID}
+	alias(start.TableID)
+	joins := make([]string, 0, len(steps))
+	for _, s := range steps {
+		if _, joined := result.Aliases[s.ToTableID]; joined {
+			continue
+		}
+		if s.FromField == "" || s.ToField == "" {
+			return nil, fmt.Errorf("%w: relationship %s between %s and %s has no field mapping",
+				domain.ErrBadParamInput, s.RelationshipID, s.FromTable, s.ToTable)
+		}
+
+		to := dg.tables[s.ToTableID]
+		fromAlias, toAlias := result.Aliases[s.FromTableID], alias(s.ToTableID)
+		joins = append(joins, fmt.Sprintf("%s %s%s ON %s.%s = %s.%s",
+			joinType, dialect.TableName(to.Schema, to.Name), dialect.TableAlias(toAlias),
+			toAlias, dialect.Quote(s.ToField), fromAlias, dialect.Quote(s.FromField)))
+		order = append(order, s.ToTableID)
+		result.Steps = append(result.Steps, s)
+	}
+	result.Warnings = fanOutWarnings(result.Steps)
+
+	// 3. Assemble the statement
+	var b strings.Builder
+	b.WriteString("SELECT\n")
+	for i, id := range order {
+		b.WriteString("  " + result.Aliases[id] + ".*")
+		if i < len(order)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("FROM " + dialect.TableName(start.Schema, start.Name) + dialect.TableAlias(result.Aliases[start.TableID]) + "\n")
+	for _, j := range joins {
+		b.WriteString(j + "\n")
+	}
+	result.SQL = strings.TrimSuffix(b.String(), "\n") + ";"
+	return result, nil
+}
+
+// fanOutWarnings flags hops that multiply rows in a many-to-many way: a
+// relationship that is many on both sides, or a junction table entered from
+// the one side and left towards another one side
+func fanOutWarnings(steps []domain.JoinStep) []string {
+	warnings := make([]string, 0)
+	for i, s := range steps {
+		if s.FromCardinality == domain.CardinalityMany && s.ToCardinality == domain.CardinalityMany {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s and %s are related many-to-many; each %s row repeats for every matching %s row",
+				s.FromTable, s.ToTable, s.FromTable, s.ToTable))
+			continue
+		}
+		if i == 0 {
+			continue
+		}
+		prev := steps[i-1]
+		if prev.ToCardinality == domain.CardinalityMany && prev.FromCardinality == domain.CardinalityOne &&
+			s.FromCardinality == domain.CardinalityMany && s.ToCardinality == domain.CardinalityOne {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s and %s are joined many-to-many through %s; each %s row repeats for every related %s row",
+				prev.FromTable, s.ToTable, s.FromTable, prev.FromTable, s.ToTable))
+		}
+	}
+	return warnings
+}
+
+// tableAlias builds a short alias from the initials of a table name: order_items -> oi
+func tableAlias(name string) string {
+	var b strings.Builder
+	prev := rune(0)
+	for i, r := range name {
+		isStart := i == 0 || prev == '_' || prev == '-' || prev == ' ' || prev == '.' ||
+			(unicode.IsUpper(r) && unicode.IsLower(prev))
+		if isStart && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+		prev = r
+	}
+	a := b.String()
+	if a == "" || !unicode.IsLetter(rune(a[0])) {
+		a = "t" + a
+	}
+	return a
+}
+
+// uniqueAlias appends a number when the alias is taken or is a keyword
+func uniqueAlias(base string, used map[string]bool) string {
+	a := base
+	for n := 2; used[a] || reservedAliases[a]; n++ {
+		a = base + strconv.Itoa(n)
+	}
+	used[a] = true
+	return a
+}
@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/graph"
+)
+
+const (
+	defaultJoinPaths = 5
+	maxJoinPaths     = 50
+)
+
+type graphUsecase struct {
+	diagramRepo      domain.DiagramRepository
+	tableRepo        domain.TableRepository
+	relationshipRepo domain.RelationshipRepository
+	dependencyRepo   domain.DependencyRepository
+	contextTimeout   time.Duration
+}
+
+func NewGraphUsecase(
+	d domain.DiagramRepository,
+	t domain.TableRepository,
+	r domain.RelationshipRepository,
+	dep domain.DependencyRepository,
+	timeout time.Duration,
+) domain.GraphUsecase {
+	return &graphUsecase{
+		diagramRepo:      d,
+		tableRepo:        t,
+		relationshipRepo: r,
+		dependencyRepo:   dep,
+		contextTimeout:   timeout,
+	}
+}
+
+// diagramGraph is a diagram's tables with the graphs built from its records
+type diagramGraph struct {
+	diagram       *domain.Diagram
+	tables        map[string]domain.Table
+	relationships map[string]domain.Relationship
+	deps          *graph.Graph // Relationships and dependencies, edges point at what a table depends on
+	joins         *graph.Graph // Relationships only, for join paths
+}
+
+func (u *graphUsecase) load(ctx context.Context, diagramID string) (*diagramGraph, error) {
+	diagram, err := u.diagramRepo.GetByID(ctx, diagramID)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := u.tableRepo.GetByDiagramID(ctx, diagramID)
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := u.relationshipRepo.GetByDiagramID(ctx, diagramID)
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := u.dependencyRepo.GetByDiagramID(ctx, diagramID)
+	if err != nil {
+		return nil, err
+	}
+
+	dg := &diagramGraph{
+		diagram:       diagram,
+		tables:        make(map[string]domain.Table, len(tables)),
+		relationships: make(map[string]domain.Relationship, len(relationships)),
+	}
+	ids := make([]string, 0, len(tables))
+	for _, t := range tables {
+		dg.tables[t.TableID] = t
+		ids = append(ids, t.TableID)
+	}
+
+	fkEdges := make([]graph.Edge, 0, len(relationships))
+	for _, r := range relationships {
+		dg.relationships[r.RelationshipID] = r
+		parent, child := r.ParentChild()
+		fkEdges = append(fkEdges, graph.Edge{ID: r.RelationshipID, From: child, To: parent})
+	}
+	allEdges := append([]graph.Edge{}, fkEdges...)
+	for _, d := range dependencies {
+		allEdges = append(allEdges, graph.Edge{ID: d.DependencyID, From: d.DependentTableID, To: d.TableID})
+	}
+
+	dg.deps = graph.New(ids, allEdges)
+	dg.joins = graph.New(ids, fkEdges)
+	return dg, nil
+}
+
+func (dg *diagramGraph) table(id string) (domain.Table, error) {
+	t, ok := dg.tables[id]
+	if !ok {
+		return t, fmt.Errorf("%w: table %s", domain.ErrNotFound, id)
+	}
+	return t, nil
+}
+
+func (dg *diagramGraph) refs(ids []string) []domain.TableRef {
+	refs := make([]domain.TableRef, 0, len(ids))
+	for _, id := range ids {
+		refs = append(refs, dg.tables[id].Ref())
+	}
+	return refs
+}
+
+// Impact returns every table the given table transitively depends on
+// (upstream) or that transitively depends on it (downstream)
+func (u *graphUsecase) Impact(c context.Context, diagramID, tableID, direction string) (*domain.ImpactResult, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if direction == "" {
+		direction = domain.DirectionDownstream
+	}
+	if direction != domain.DirectionUpstream && direction != domain.DirectionDownstream {
+		return nil, fmt.Errorf("%w: unsupported direction %q", domain.ErrBadParamInput, direction)
+	}
+
+	dg, err := u.load(ctx, diagramID)
+	if err != nil {
+		return nil, err
+	}
+	start, err := dg.table(tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := dg.deps.Reachable(tableID, direction == domain.DirectionDownstream)
+	result := &domain.ImpactResult{
+		DiagramID: diagramID,
+		Table:     start.Ref(),
+		Direction: direction,
+		Tables:    make([]domain.ImpactedTable, 0, len(steps)),
+	}
+	for _, s := range steps {
+		result.Tables = append(result.Tables, domain.ImpactedTable{
+			TableRef: dg.tables[s.Node].Ref(),
+			Depth:    s.Depth,
+			Via:      s.Via,
+		})
+	}
+	return result, nil
+}
+
+// JoinPaths returns the shortest relationship paths between two tables,
+// walking relationships in either direction
+func (u *graphUsecase) JoinPaths(c context.Context, diagramID, fromTableID, toTableID string, limit int) (*domain.JoinPathResult, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if limit <= 0 {
+		limit = defaultJoinPaths
+	}
+	if limit > maxJoinPaths {
+		limit = maxJoinPaths
+	}
+
+	dg, err := u.load(ctx, diagramID)
+	if err != nil {
+		return nil, err
+	}
+	from, err := dg.table(fromTableID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := dg.table(toTableID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.JoinPathResult{
+		DiagramID: diagramID,
+		From:      from.Ref(),
+		To:        to.Ref(),
+		Paths:     make([]domain.JoinPath, 0),
+	}
+	for _, edges := range dg.joins.ShortestPaths(fromTableID, toTableID, limit) {
+		result.Paths = append(result.Paths, dg.joinPath(fromTableID, edges))
+	}
+	return result, nil
+}
+
+// joinPath orients each relationship of a path from the current table to the next one
+func (dg *diagramGraph) joinPath(start string, edges []graph.Edge) domain.JoinPath {
+	path := domain.JoinPath{Length: len(edges), Steps: make([]domain.JoinStep, 0, len(edges))}
+	cur := start
+	for _, e := range edges {
+		r := dg.relationships[e.ID]
+		next, fromField, toField := r.TargetTableID, r.SourceFieldID, r.TargetFieldID
+		fromCard, toCard := r.SourceCardinality, r.TargetCardinality
+		if r.SourceTableID != cur {
+			next, fromField, toField = r.SourceTableID, r.TargetFieldID, r.SourceFieldID
+			fromCard, toCard = r.TargetCardinality, r.SourceCardinality
+		}
+
+		step := domain.JoinStep{
+			RelationshipID:  r.RelationshipID,
+			Name:            r.Name,
+			FromTableID:     cur,
+			FromTable:       dg.tables[cur].Name,
+			FromFieldID:     fromField,
+			ToTableID:       next,
+			ToTable:         dg.tables[next].Name,
+			ToFieldID:       toField,
+			FromCardinality: fromCard,
+			ToCardinality:   toCard,
+		}
+		if f, ok := dg.tables[cur].FieldByID(fromField); ok {
+			step.FromField = f.Name
+		}
+		if f, ok := dg.tables[next].FieldByID(toField); ok {
+			step.ToField = f.Name
+		}
+		path.Steps = append(path.Steps, step)
+		cur = next
+	}
+	return path
+}
+
+// Cycles returns groups of tables that depend on each other in a loop
+func (u *graphUsecase) Cycles(c context.Context, diagramID string) (*domain.CycleResult, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	dg, err := u.load(ctx, diagramID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.CycleResult{DiagramID: diagramID, Components: make([][]domain.TableRef, 0)}
+	for _, comp := range dg.deps.StronglyConnected() {
+		result.Components = append(result.Components, dg.refs(comp))
+	}
+	return result, nil
+}
+
+// Orphans returns tables with no relationship or dependency to any table
+func (u *graphUsecase) Orphans(c context.Context, diagramID string) (*domain.OrphanResult, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	dg, err := u.load(ctx, diagramID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.OrphanResult{DiagramID: diagramID, Tables: dg.refs(dg.deps.Isolated())}, nil
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Same alphabet and length as the IDs generated by the frontend
+const (
+	idAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+	idLength   = 25
+)
+
+// generateID returns a random entity ID in the frontend's format
+func generateID() string {
+	max := big.NewInt(int64(len(idAlphabet)))
+	b := make([]byte, idLength)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = idAlphabet[n.Int64()]
+	}
+	return string(b)
+}
+
+// idRemapper assigns fresh IDs and remembers old -> new so references can follow
+type idRemapper struct {
+	ids map[string]string
+}
+
+func newIDRemapper() *idRemapper {
+	return &idRemapper{ids: make(map[string]string)}
+}
+
+// remap returns the new ID for old, generating one the first time old is seen
+func (r *idRemapper) remap(old string) string {
+	if old == "" {
+		return ""
+	}
+	if id, ok := r.ids[old]; ok {
+		return id
+	}
+	id := generateID()
+	r.ids[old] = id
+	return id
+}
+
+// set forces old to map to id
+func (r *idRemapper) set(old, id string) {
+	r.ids[old] = id
+}
+
+// lookup returns the new ID for old, or old itself when it was never remapped
+func (r *idRemapper) lookup(old string) string {
+	if id, ok := r.ids[old]; ok {
+		return id
+	}
+	return old
+}
+
+// remapSnapshot deep-copies src into diagramID with fresh table, field, index,
+// relationship and dependency IDs. References are remapped through ids, so the
+// caller may pre-seed it (e.g. to point relationships at existing tables).
+func remapSnapshot(src *domain.DiagramSnapshot, diagramID string, ids *idRemapper) *domain.DiagramSnapshot {
+	dst := &domain.DiagramSnapshot{Diagram: src.Diagram}
+
+	// Tables and fields first so that every reference below can be resolved
+	dst.Tables = make([]domain.Table, 0, len(src.Tables))
+	for _, t := range src.Tables {
+		t.ID = ""
+		t.DiagramID = diagramID
+		t.TableID = ids.remap(t.TableID)
+		t.Fields = copyMapArray(t.Fields)
+		for _, f := range t.Fields {
+			if id, ok := f["id"].(string); ok {
+				f["id"] = ids.remap(id)
+			}
+		}
+		dst.Tables = append(dst.Tables, t)
+	}
+
+	// Indexes reference fields, which are now all known
+	for i := range dst.Tables {
+		dst.Tables[i].Indexes = copyMapArray(dst.Tables[i].Indexes)
+		for _, idx := range dst.Tables[i].Indexes {
+			if id, ok := idx["id"].(string); ok {
+				idx["id"] = ids.remap(id)
+			}
+			fieldIDs := toSlice(idx["fieldIds"])
+			for j, fid := range fieldIDs {
+				if s, ok := fid.(string); ok {
+					fieldIDs[j] = ids.lookup(s)
+				}
+			}
+			if fieldIDs != nil {
+				idx["fieldIds"] = fieldIDs
+			}
+		}
+	}
+
+	dst.Relationships = make([]domain.Relationship, 0, len(src.Relationships))
+	for _, r := range src.Relationships {
+		r.ID = ""
+		r.DiagramID = diagramID
+		r.RelationshipID = ids.remap(r.RelationshipID)
+		r.SourceTableID = ids.lookup(r.SourceTableID)
+		r.TargetTableID = ids.lookup(r.TargetTableID)
+		r.SourceFieldID = ids.lookup(r.SourceFieldID)
+		r.TargetFieldID = ids.lookup(r.TargetFieldID)
+		dst.Relationships = append(dst.Relationships, r)
+	}
+
+	dst.Dependencies = make([]domain.Dependency, 0, len(src.Dependencies))
+	for _, d := range src.Dependencies {
+		d.ID = ""
+		d.DiagramID = diagramID
+		d.DependencyID = ids.remap(d.DependencyID)
+		d.TableID = ids.lookup(d.TableID)
+		d.DependentTableID = ids.lookup(d.DependentTableID)
+		dst.Dependencies = append(dst.Dependencies, d)
+	}
+
+	dst.Areas = make([]domain.Area, 0, len(src.Areas))
+	for _, a := range src.Areas {
+		a.ID = ""
+		a.AreaID = ids.remap(a.AreaID)
+		if a.AreaID == "" {
+			a.AreaID = generateID()
+		}
+		a.DiagramID = diagramID
+		if a.TableIDs != nil {
+			members := make([]string, 0, len(a.TableIDs))
+			for _, id := range a.TableIDs {
+				members = append(members, ids.lookup(id))
+			}
+			a.TableIDs = members
+		}
+		dst.Areas = append(dst.Areas, a)
+	}
+
+	dst.CustomTypes = make([]domain.CustomType, 0, len(src.CustomTypes))
+	for _, ct := range src.CustomTypes {
+		ct.ID = ""
+		ct.DiagramID = diagramID
+		ct.Values = deepCopyValue(ct.Values)
+		ct.Fields = deepCopyValue(ct.Fields)
+		dst.CustomTypes = append(dst.CustomTypes, ct)
+	}
+
+	dst.Notes = make([]domain.Note, 0, len(src.Notes))
+	for _, n := range src.Notes {
+		n.ID = ""
+		n.DiagramID = diagramID
+		dst.Notes = append(dst.Notes, n)
+	}
+
+	if src.Filter != nil {
+		f := *src.Filter
+		f.ID = ""
+		f.DiagramID = diagramID
+		f.TableIDs = make([]string, 0, len(src.Filter.TableIDs))
+		for _, id := range src.Filter.TableIDs {
+			f.TableIDs = append(f.TableIDs, ids.lookup(id))
+		}
+		f.SchemaIDs = append([]string{}, src.Filter.SchemaIDs...)
+		f.AreaIDs = make([]string, 0, len(src.Filter.AreaIDs))
+		for _, id := range src.Filter.AreaIDs {
+			f.AreaIDs = append(f.AreaIDs, ids.lookup(id))
+		}
+		dst.Filter = &f
+	}
+
+	return dst
+}
+
+func copyMapArray(src []map[string]interface{}) []map[string]interface{} {
+	dst := make([]map[string]interface{}, 0, len(src))
+	for _, m := range src {
+		dst = append(dst, deepCopyMap(m))
+	}
+	return dst
+}
+
+func deepCopyMap(src map[string]interface{}) map[string]interface{} {
+	if src == nil {
+		return nil
+	}
+	dst := make(map[string]interface{}, len(src))
+	for k, v := range src {
+		dst[k] = deepCopyValue(v)
+	}
+	return dst
+}
+
+// deepCopyValue copies JSON- or BSON-decoded values, normalizing BSON
+// containers to plain maps and slices
+func deepCopyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return deepCopyMap(val)
+	case primitive.M:
+		return deepCopyMap(val)
+	case primitive.D:
+		dst := make(map[string]interface{}, len(val))
+		for _, e := range val {
+			dst[e.Key] = deepCopyValue(e.Value)
+		}
+		return dst
+	case []interface{}, primitive.A:
+		src := toSlice(val)
+		dst := make([]interface{}, len(src))
+		for i, item := range src {
+			dst[i] = deepCopyValue(item)
+		}
+		return dst
+	case []map[string]interface{}:
+		return copyMapArray(val)
+	case []string:
+		return append([]string{}, val...)
+	default:
+		return v
+	}
+}
+
+// toSlice returns v as []interface{} whether it was decoded from JSON or BSON
+func toSlice(v interface{}) []interface{} {
+	switch val := v.(type) {
+	case []interface{}:
+		return val
+	case primitive.A:
+		return []interface{}(val)
+	case []string:
+		out := make([]interface{}, len(val))
+		for i, s := range val {
+			out[i] = s
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+	"github.com/iots1/vertex-diagram/layout"
+)
+
+// Space kept between an area's border (and its title) and the tables inside it
+const (
+	areaPadding      = 40
+	areaHeaderHeight = 40
+)
+
+type layoutUsecase struct {
+	diagramRepo      domain.DiagramRepository
+	tableRepo        domain.TableRepository
+	relationshipRepo domain.RelationshipRepository
+	areaRepo         domain.AreaRepository
+	contextTimeout   time.Duration
+}
+
+func NewLayoutUsecase(
+	d domain.DiagramRepository,
+	t domain.TableRepository,
+	r domain.RelationshipRepository,
+	area domain.AreaRepository,
+	timeout time.Duration,
+) domain.LayoutUsecase {
+	return &layoutUsecase{
+		diagramRepo:      d,
+		tableRepo:        t,
+		relationshipRepo: r,
+		areaRepo:         area,
+		contextTimeout:   timeout,
+	}
+}
+
+func (u *layoutUsecase) Layout(c context.Context, req domain.LayoutRequest) (*domain.LayoutResult, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	var run func(nodes []layout.Node, edges []layout.Edge) layout.Result
+	switch req.Algorithm {
+	case "", domain.LayoutLayered:
+		req.Algorithm = domain.LayoutLayered
+		run = layout.Layered
+	case domain.LayoutForce:
+		run = layout.Force
+	case domain.LayoutGrid:
+		run = func(nodes []layout.Node, _ []layout.Edge) layout.Result { return layout.Grid(nodes) }
+	default:
+		return nil, fmt.Errorf("%w: unsupported layout algorithm %q", domain.ErrBadParamInput, req.Algorithm)
+	}
+
+	diagram, err := u.diagramRepo.GetByID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := u.tableRepo.GetByDiagramID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := u.relationshipRepo.GetByDiagramID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	areas, err := u.areaRepo.GetByDiagramID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deterministic input order: by schema, then name
+	sort.SliceStable(tables, func(i, j int) bool {
+		if tables[i].Schema != tables[j].Schema {
+			return tables[i].Schema < tables[j].Schema
+		}
+		return tables[i].Name < tables[j].Name
+	})
+
+	edges := make([]layout.Edge, 0, len(relationships))
+	for _, r := range relationships {
+		parent, child := r.ParentChild()
+		edges = append(edges, layout.Edge{From: parent, To: child})
+	}
+
+	// Tables inside an area are laid out within that area; the rest are laid out together
+	groups := make([][]domain.Table, len(areas))
+	free := make([]domain.Table, 0)
+	for _, t := range tables {
+		if i := containingArea(areas, t); i >= 0 {
+			groups[i] = append(groups[i], t)
+		} else {
+			free = append(free, t)
+		}
+	}
+
+	result := &domain.LayoutResult{
+		DiagramID: req.DiagramID,
+		Algorithm: req.Algorithm,
+		Preview:   req.Preview,
+		Tables:    make([]domain.TablePosition, 0, len(tables)),
+		Areas:     make([]domain.Area, 0),
+	}
+
+	updated := make(map[int]bool)
+	areasRight, areasTop := 0, 0
+	for i := range areas {
+		a := &areas[i]
+		if len(groups[i]) > 0 {
+			res := run(layoutNodes(groups[i]), edges)
+			result.Crossings += res.Crossings
+			appendPositions(result, res, a.X+areaPadding, a.Y+areaHeaderHeight)
+
+			// Grow the area when its tables no longer fit
+			width, height := res.Width+2*areaPadding, res.Height+areaHeaderHeight+areaPadding
+			if width > a.Width || height > a.Height {
+				a.Width = max(a.Width, width)
+				a.Height = max(a.Height, height)
+				updated[i] = true
+			}
+		}
+		if i == 0 || a.X+a.Width > areasRight {
+			areasRight = a.X + a.Width
+		}
+		if i == 0 || a.Y < areasTop {
+			areasTop = a.Y
+		}
+	}
+
+	if len(free) > 0 {
+		res := run(layoutNodes(free), edges)
+		result.Crossings += res.Crossings
+
+		// Keep the free block where it was, but never on top of the areas
+		originX, originY := free[0].X, free[0].Y
+		for _, t := range free {
+			originX = min(originX, t.X)
+			originY = min(originY, t.Y)
+		}
+		if len(areas) > 0 {
+			originX, originY = areasRight+layout.HorizontalGap, areasTop
+		}
+		appendPositions(result, res, originX, originY)
+	}
+
+	// Keep area membership in sync with the new positions
+	moved := make(map[string]domain.TablePosition, len(result.Tables))
+	for _, p := range result.Tables {
+		moved[p.TableID] = p
+	}
+	for i := range tables {
+		if p, ok := moved[tables[i].TableID]; ok {
+			tables[i].X, tables[i].Y = p.X, p.Y
+		}
+	}
+	for _, i := range domain.AssignAreaMembers(areas, tables) {
+		updated[i] = true
+	}
+	for i := range areas {
+		if updated[i] {
+			result.Areas = append(result.Areas, areas[i])
+		}
+	}
+
+	if req.Preview {
+		return result, nil
+	}
+
+	if err := u.tableRepo.UpdatePositions(ctx, req.DiagramID, result.Tables); err != nil {
+		return nil, err
+	}
+	for i := range result.Areas {
+		if err := u.areaRepo.Update(ctx, &result.Areas[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := u.diagramRepo.Update(ctx, diagram); err != nil {
+		return nil, err
+	}
+
+	log.Printf("📐 Layout %s applied to diagram %s: %d tables, %d areas updated, %d crossings",
+		req.Algorithm, req.DiagramID, len(result.Tables), len(result.Areas), result.Crossings)
+	return result, nil
+}
+
+func layoutNodes(tables []domain.Table) []layout.Node {
+	nodes := make([]layout.Node, 0, len(tables))
+	for _, t := range tables {
+		w, h := t.Size()
+		nodes = append(nodes, layout.Node{ID: t.TableID, Width: w, Height: h})
+	}
+	return nodes
+}
+
+// appendPositions translates a layout block to (x, y) and adds its positions to the result
+func appendPositions(result *domain.LayoutResult, res layout.Result, x, y int) {
+	ids := make([]string, 0, len(res.Positions))
+	for id := range res.Positions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		p := res.Positions[id]
+		result.Tables = append(result.Tables, domain.TablePosition{TableID: id, X: x + p.X, Y: y + p.Y})
+	}
+}
+
+// containingArea returns the index of the first area the table belongs to, or -1
+func containingArea(areas []domain.Area, t domain.Table) int {
+	for i, a := range areas {
+		if a.Includes(t) {
+			return i
+		}
+	}
+	return -1
+}
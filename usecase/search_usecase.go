@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+type searchUsecase struct {
+	searchRepo     domain.SearchRepository
+	contextTimeout time.Duration
+}
+
+func NewSearchUsecase(repo domain.SearchRepository, timeout time.Duration) domain.SearchUsecase {
+	return &searchUsecase{
+		searchRepo:     repo,
+		contextTimeout: timeout,
+	}
+}
+
+func (u *searchUsecase) Search(c context.Context, q domain.SearchQuery) ([]domain.SearchHit, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	q.Text = strings.TrimSpace(q.Text)
+	if q.Text == "" {
+		return nil, fmt.Errorf("%w: search text is required", domain.ErrBadParamInput)
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = defaultSearchLimit
+	}
+	if q.Limit > maxSearchLimit {
+		q.Limit = maxSearchLimit
+	}
+
+	return u.searchRepo.Search(ctx, q)
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+type statsUsecase struct {
+	statsRepo        domain.StatsRepository
+	diagramRepo      domain.DiagramRepository
+	tableRepo        domain.TableRepository
+	relationshipRepo domain.RelationshipRepository
+	customTypeRepo   domain.CustomTypeRepository
+	contextTimeout   time.Duration
+}
+
+func NewStatsUsecase(
+	s domain.StatsRepository,
+	d domain.DiagramRepository,
+	t domain.TableRepository,
+	r domain.RelationshipRepository,
+	ct domain.CustomTypeRepository,
+	timeout time.Duration,
+) domain.StatsUsecase {
+	return &statsUsecase{
+		statsRepo:        s,
+		diagramRepo:      d,
+		tableRepo:        t,
+		relationshipRepo: r,
+		customTypeRepo:   ct,
+		contextTimeout:   timeout,
+	}
+}
+
+func (u *statsUsecase) Diagram(c context.Context, diagramID string) (*domain.DiagramStats, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if _, err := u.diagramRepo.GetByID(ctx, diagramID); err != nil {
+		return nil, err
+	}
+	tables, err := u.tableRepo.GetByDiagramID(ctx, diagramID)
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := u.relationshipRepo.GetByDiagramID(ctx, diagramID)
+	if err != nil {
+		return nil, err
+	}
+	customTypes, err := u.customTypeRepo.GetByDiagramID(ctx, diagramID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(tables, func(i, j int) bool {
+		if tables[i].Schema != tables[j].Schema {
+			return tables[i].Schema < tables[j].Schema
+		}
+		return tables[i].Name < tables[j].Name
+	})
+
+	stats := &domain.DiagramStats{
+		DiagramID:            diagramID,
+		Schemas:              make([]domain.SchemaStats, 0),
+		Tables:               make([]domain.TableStats, 0, len(tables)),
+		TablesWithoutIndexes: make([]domain.TableRef, 0),
+		CustomTypes:          make([]domain.CustomTypeUsage, 0, len(customTypes)),
+	}
+
+	bySchema := make(map[string]*domain.SchemaStats)
+	schemaOf := func(name string) *domain.SchemaStats {
+		if s, ok := bySchema[name]; ok {
+			return s
+		}
+		s := &domain.SchemaStats{Schema: name}
+		bySchema[name] = s
+		return s
+	}
+
+	// 1. Sizes per table and schema
+	byID := make(map[string]int, len(tables))
+	typeUsage := make(map[string]int)
+	for i, t := range tables {
+		byID[t.TableID] = i
+		s := schemaOf(t.Schema)
+		if t.IsView {
+			s.Views++
+		} else {
+			s.Tables++
+		}
+		s.Fields += len(t.Fields)
+		s.Indexes += len(t.Indexes)
+
+		stats.Tables = append(stats.Tables, domain.TableStats{
+			TableRef: t.Ref(),
+			Fields:   len(t.Fields),
+			Indexes:  len(t.Indexes),
+		})
+		stats.MaxFieldsPerTable = max(stats.MaxFieldsPerTable, len(t.Fields))
+		if !t.IsView && len(t.Indexes) == 0 {
+			stats.TablesWithoutIndexes = append(stats.TablesWithoutIndexes, t.Ref())
+		}
+		for _, f := range t.FieldList() {
+			typeUsage[strings.ToLower(f.Type)]++
+		}
+	}
+
+	// 2. Fan-in/fan-out and coupling from relationships between known tables
+	neighbors := make(map[string]map[string]bool)
+	link := func(a, b string) {
+		if neighbors[a] == nil {
+			neighbors[a] = make(map[string]bool)
+		}
+		neighbors[a][b] = true
+	}
+	for _, r := range relationships {
+		parent, child := r.ParentChild()
+		pi, ok1 := byID[parent]
+		ci, ok2 := byID[child]
+		if !ok1 || !ok2 {
+			continue
+		}
+		stats.Tables[pi].FanIn++
+		stats.Tables[ci].FanOut++
+		schemaOf(tables[byID[r.SourceTableID]].Schema).Relationships++
+		if tables[pi].Schema != tables[ci].Schema {
+			stats.CrossSchemaRelationships++
+		}
+		if parent != child {
+			link(parent, child)
+			link(child, parent)
+		}
+	}
+
+	// 3. Custom type usage by type name
+	for _, ct := range customTypes {
+		stats.CustomTypes = append(stats.CustomTypes, domain.CustomTypeUsage{
+			Schema: ct.Schema,
+			Type:   ct.Type,
+			Kind:   ct.Kind,
+			Fields: typeUsage[strings.ToLower(ct.Type)],
+		})
+	}
+
+	for _, s := range bySchema {
+		stats.Schemas = append(stats.Schemas, *s)
+		stats.Totals.Tables += s.Tables
+		stats.Totals.Views += s.Views
+		stats.Totals.Fields += s.Fields
+		stats.Totals.Indexes += s.Indexes
+		stats.Totals.Relationships += s.Relationships
+	}
+	sort.Slice(stats.Schemas, func(i, j int) bool { return stats.Schemas[i].Schema < stats.Schemas[j].Schema })
+
+	if len(tables) > 0 {
+		stats.AvgFieldsPerTable = float64(stats.Totals.Fields) / float64(len(tables))
+		degree := 0
+		for _, n := range neighbors {
+			degree += len(n)
+		}
+		stats.CouplingScore = float64(degree) / float64(len(tables))
+	}
+	return stats, nil
+}
+
+func (u *statsUsecase) Workspace(c context.Context) (*domain.WorkspaceStats, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+	return u.statsRepo.Workspace(ctx)
+}
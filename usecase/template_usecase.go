@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/iots1/vertex-diagram/domain"
+)
+
+type templateUsecase struct {
+	templateRepo     domain.TemplateRepository
+	diagramRepo      domain.DiagramRepository
+	tableRepo        domain.TableRepository
+	relationshipRepo domain.RelationshipRepository
+	customTypeRepo   domain.CustomTypeRepository
+	areaRepo         domain.AreaRepository
+	contextTimeout   time.Duration
+}
+
+func NewTemplateUsecase(
+	tpl domain.TemplateRepository,
+	d domain.DiagramRepository,
+	t domain.TableRepository,
+	r domain.RelationshipRepository,
+	ct domain.CustomTypeRepository,
+	area domain.AreaRepository,
+	timeout time.Duration,
+) domain.TemplateUsecase {
+	return &templateUsecase{
+		templateRepo:     tpl,
+		diagramRepo:      d,
+		tableRepo:        t,
+		relationshipRepo: r,
+		customTypeRepo:   ct,
+		areaRepo:         area,
+		contextTimeout:   timeout,
+	}
+}
+
+func (u *templateUsecase) GetAll(c context.Context) ([]domain.Template, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+	return u.templateRepo.Fetch(ctx)
+}
+
+func (u *templateUsecase) GetOne(c context.Context, id string) (*domain.Template, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+	return u.templateRepo.GetByID(ctx, id)
+}
+
+func (u *templateUsecase) Create(c context.Context, t *domain.Template) (*domain.Template, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if err := normalizeTemplate(t); err != nil {
+		return nil, err
+	}
+	if err := u.templateRepo.Store(ctx, t); err != nil {
+		return nil, err
+	}
+	log.Printf("🧩 Template created: ID=%s, Name=%s, %d tables", t.ID, t.Name, len(t.Tables))
+	return t, nil
+}
+
+func (u *templateUsecase) CreateFromDiagram(c context.Context, req domain.TemplateFromDiagramRequest) (*domain.Template, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	diagram, err := u.diagramRepo.GetByID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := u.tableRepo.GetByDiagramID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := u.relationshipRepo.GetByDiagramID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	customTypes, err := u.customTypeRepo.GetByDiagramID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	areas, err := u.areaRepo.GetByDiagramID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(req.TableIDs))
+	for _, id := range req.TableIDs {
+		wanted[id] = true
+	}
+
+	t := &domain.Template{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if t.Name == "" {
+		t.Name = diagram.Name
+	}
+
+	selected := make(map[string]bool)
+	usedTypes := make(map[string]bool)
+	for _, table := range tables {
+		if len(wanted) > 0 && !wanted[table.TableID] {
+			continue
+		}
+		selected[table.TableID] = true
+		t.Tables = append(t.Tables, table)
+		for _, f := range table.FieldList() {
+			usedTypes[strings.ToLower(f.Type)] = true
+		}
+	}
+	if len(t.Tables) == 0 {
+		return nil, fmt.Errorf("%w: no tables selected", domain.ErrBadParamInput)
+	}
+
+	// Only relationships fully inside the selection
+	for _, r := range relationships {
+		if selected[r.SourceTableID] && selected[r.TargetTableID] {
+			t.Relationships = append(t.Relationships, r)
+		}
+	}
+	for _, ct := range customTypes {
+		if usedTypes[strings.ToLower(ct.Type)] {
+			t.CustomTypes = append(t.CustomTypes, ct)
+		}
+	}
+	for _, a := range areas {
+		for _, table := range t.Tables {
+			if a.Includes(table) {
+				t.Areas = append(t.Areas, a)
+				break
+			}
+		}
+	}
+
+	if err := normalizeTemplate(t); err != nil {
+		return nil, err
+	}
+	if err := u.templateRepo.Store(ctx, t); err != nil {
+		return nil, err
+	}
+	log.Printf("🧩 Template created from diagram %s: ID=%s, %d tables", req.DiagramID, t.ID, len(t.Tables))
+	return t, nil
+}
+
+func (u *templateUsecase) Update(c context.Context, t *domain.Template) (*domain.Template, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	if err := normalizeTemplate(t); err != nil {
+		return nil, err
+	}
+	if err := u.templateRepo.Update(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (u *templateUsecase) Delete(c context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+	return u.templateRepo.Delete(ctx, id)
+}
+
+func (u *templateUsecase) Instantiate(c context.Context, id string, req domain.InstantiateTemplateRequest) (*domain.InstantiateTemplateResult, error) {
+	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+	defer cancel()
+
+	tpl, err := u.templateRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	diagram, err := u.diagramRepo.GetByID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	existingTables, err := u.tableRepo.GetByDiagramID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+	existingTypes, err := u.customTypeRepo.GetByDiagramID(ctx, req.DiagramID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fresh IDs, then move the template's top-left corner to the requested offset
+	snap := remapSnapshot(&domain.DiagramSnapshot{
+		Tables:        tpl.Tables,
+		Relationships: tpl.Relationships,
+		CustomTypes:   tpl.CustomTypes,
+		Areas:         tpl.Areas,
+	}, req.DiagramID, newIDRemapper())
+
+	b, _ := snapshotBounds(snap)
+	dx, dy := req.OffsetX-b.minX, req.OffsetY-b.minY
+
+	taken := make(map[string]bool, len(existingTables))
+	for _, t := range existingTables {
+		taken[tableKey(t.Schema, t.Name)] = true
+	}
+
+	result := &domain.InstantiateTemplateResult{
+		DiagramID:       req.DiagramID,
+		TemplateID:      tpl.ID,
+		TemplateVersion: tpl.Version,
+		TableIDs:        make([]string, 0, len(snap.Tables)),
+	}
+
+	collisions := make([]string, 0)
+	inserted := make(map[string]bool, len(snap.Tables))
+	for i := range snap.Tables {
+		t := &snap.Tables[i]
+		if req.Schema != "" {
+			t.Schema = req.Schema
+		}
+		t.Name = req.NamePrefix + t.Name
+		t.X += dx
+		t.Y += dy
+		if taken[tableKey(t.Schema, t.Name)] {
+			collisions = append(collisions, tableKey(t.Schema, t.Name))
+		}
+		inserted[t.TableID] = true
+		result.TableIDs = append(result.TableIDs, t.TableID)
+	}
+	if len(collisions) > 0 {
+		return nil, fmt.Errorf("%w: tables already exist: %s", domain.ErrConflict, strings.Join(collisions, ", "))
+	}
+
+	relationships := make([]domain.Relationship, 0, len(snap.Relationships))
+	for _, r := range snap.Relationships {
+		if inserted[r.SourceTableID] && inserted[r.TargetTableID] {
+			relationships = append(relationships, r)
+		}
+	}
+	result.RelationshipsAdded = len(relationships)
+
+	types := make(map[string]bool, len(existingTypes))
+	for _, ct := range existingTypes {
+		types[tableKey(ct.Schema, ct.Type)] = true
+	}
+	customTypes := make([]domain.CustomType, 0, len(snap.CustomTypes))
+	for _, ct := range snap.CustomTypes {
+		if req.Schema != "" {
+			ct.Schema = req.Schema
+		}
+		if types[tableKey(ct.Schema, ct.Type)] {
+			result.CustomTypesSkipped = append(result.CustomTypesSkipped, tableKey(ct.Schema, ct.Type))
+			continue
+		}
+		customTypes = append(customTypes, ct)
+	}
+	result.CustomTypesAdded = len(customTypes)
+
+	for i := range snap.Areas {
+		snap.Areas[i].X += dx
+		snap.Areas[i].Y += dy
+	}
+	result.AreasAdded = len(snap.Areas)
+
+	if err := u.tableRepo.StoreMultiple(ctx, snap.Tables); err != nil {
+		return nil, err
+	}
+	if err := u.relationshipRepo.StoreMultiple(ctx, relationships); err != nil {
+		return nil, err
+	}
+	if err := u.customTypeRepo.StoreMultiple(ctx, customTypes); err != nil {
+		return nil, err
+	}
+	domain.AssignAreaMembers(snap.Areas, snap.Tables)
+	if err := u.areaRepo.StoreMultiple(ctx, snap.Areas); err != nil {
+		return nil, err
+	}
+	if err := u.diagramRepo.Update(ctx, diagram); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🧩 Template %s v%d instantiated into diagram %s: %d tables",
+		tpl.ID, tpl.Version, req.DiagramID, len(result.TableIDs))
+	return result, nil
+}
+
+// normalizeTemplate validates a template and strips diagram-specific data from its entities
+func normalizeTemplate(t *domain.Template) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return fmt.Errorf("%w: template name is required", domain.ErrBadParamInput)
+	}
+
+	for i := range t.Tables {
+		tb := &t.Tables[i]
+		tb.ID, tb.DiagramID = "", ""
+		if tb.TableID == "" {
+			tb.TableID = generateID()
+		}
+		for _, f := range tb.Fields {
+			if id, _ := f["id"].(string); id == "" {
+				f["id"] = generateID()
+			}
+		}
+	}
+	for i := range t.Relationships {
+		r := &t.Relationships[i]
+		r.ID, r.DiagramID = "", ""
+		if r.RelationshipID == "" {
+			r.RelationshipID = generateID()
+		}
+	}
+	for i := range t.CustomTypes {
+		t.CustomTypes[i].ID, t.CustomTypes[i].DiagramID = "", ""
+	}
+	for i := range t.Areas {
+		t.Areas[i].ID, t.Areas[i].DiagramID = "", ""
+	}
+
+	if t.Tables == nil {
+		t.Tables = []domain.Table{}
+	}
+	if t.Relationships == nil {
+		t.Relationships = []domain.Relationship{}
+	}
+	if t.CustomTypes == nil {
+		t.CustomTypes = []domain.CustomType{}
+	}
+	if t.Areas == nil {
+		t.Areas = []domain.Area{}
+	}
+	return nil
+}